@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -131,6 +132,10 @@ type IdentityRepository interface {
 	FindIdentityMemberships(ctx context.Context, identityID uuid.UUID, resourceType *string) ([]authorization.IdentityAssociation, error)
 	FindIdentitiesByResourceTypeWithParentResource(ctx context.Context, resourceTypeID uuid.UUID, parentResourceID string) ([]Identity, error)
 	AddMember(ctx context.Context, identityID uuid.UUID, memberID uuid.UUID) error
+	RemoveMember(ctx context.Context, identityID uuid.UUID, memberID uuid.UUID) error
+	ListMembers(ctx context.Context, identityID uuid.UUID) ([]Identity, error)
+	DeleteMemberships(ctx context.Context, identityID uuid.UUID) error
+	FindMembershipPath(ctx context.Context, memberID uuid.UUID, memberOf uuid.UUID) ([]uuid.UUID, error)
 }
 
 // TableName overrides the table name settings in Gorm to force a specific table name
@@ -349,6 +354,13 @@ func IdentityFilterByID(identityID uuid.UUID) func(db *gorm.DB) *gorm.DB {
 	}
 }
 
+// IdentityFilterByResourceID is a gorm filter for the resource of the identity.
+func IdentityFilterByResourceID(resourceID string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("identity_resource_id = ?", resourceID)
+	}
+}
+
 // IdentityWithUser is a gorm filter for preloading the User relationship.
 func IdentityWithUser() func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
@@ -577,3 +589,91 @@ func (m *GormIdentityRepository) AddMember(ctx context.Context, identityID uuid.
 
 	return nil
 }
+
+// RemoveMember removes the direct membership of the specified member in the specified identity.
+// NotFoundError returned if the member is not a direct member of the identity
+func (m *GormIdentityRepository) RemoveMember(ctx context.Context, identityID uuid.UUID, memberID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "identity", "RemoveMember"}, time.Now())
+
+	result := m.db.Where("member_of = ? AND member_id = ?", identityID, memberID).Delete(&Membership{})
+	if result.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"member_of": identityID,
+			"member_id": memberID,
+			"err":       result.Error,
+		}, "unable to delete the membership")
+		return errs.WithStack(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewNotFoundErrorFromString(fmt.Sprintf("membership with member_of '%s' and member_id '%s' not found", identityID, memberID))
+	}
+	log.Info(ctx, map[string]interface{}{
+		"member_of": identityID,
+		"member_id": memberID,
+	}, "Membership deleted!")
+
+	return nil
+}
+
+// DeleteMemberships deletes all the memberships of the specified identity, i.e. both the memberships of its members and
+// its own memberships in other identities. No error is returned if there is no such membership.
+func (m *GormIdentityRepository) DeleteMemberships(ctx context.Context, identityID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "identity", "DeleteMemberships"}, time.Now())
+
+	err := m.db.Where("member_of = ? OR member_id = ?", identityID, identityID).Delete(&Membership{}).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		log.Error(ctx, map[string]interface{}{
+			"identity_id": identityID,
+			"err":         err,
+		}, "unable to delete the memberships")
+		return errs.WithStack(err)
+	}
+	return nil
+}
+
+// ListMembers returns the identities which are direct members of the specified identity, along with their user
+func (m *GormIdentityRepository) ListMembers(ctx context.Context, identityID uuid.UUID) ([]Identity, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "identity", "ListMembers"}, time.Now())
+
+	var members []Identity
+	err := m.db.Table(m.TableName()).Preload("User").
+		Where("id IN (SELECT member_id FROM membership WHERE member_of = ?)", identityID).
+		Order("username").Find(&members).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errs.WithStack(err)
+	}
+	return members, nil
+}
+
+// FindMembershipPath returns the shortest chain of memberships through which the specified member is (directly or indirectly)
+// a member of the specified identity. The returned array starts with the identity which the member is a direct member of,
+// and ends with the specified identity. A not found error is returned if the identity is not a member.
+func (m *GormIdentityRepository) FindMembershipPath(ctx context.Context, memberID uuid.UUID, memberOf uuid.UUID) ([]uuid.UUID, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "identity", "FindMembershipPath"}, time.Now())
+
+	var result struct {
+		Path string
+	}
+	err := m.db.Raw(`WITH RECURSIVE m AS (
+			SELECT member_of, ARRAY[member_of] AS path FROM membership WHERE member_id = ?
+			UNION SELECT p.member_of, m.path || p.member_of FROM membership p INNER JOIN m ON m.member_of = p.member_id
+			WHERE NOT p.member_of = ANY(m.path))
+		SELECT array_to_string(path, ',') AS path FROM m WHERE member_of = ? ORDER BY array_length(path, 1) LIMIT 1`,
+		memberID, memberOf).Scan(&result).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errs.WithStack(errors.NewNotFoundError("membership", memberOf.String()))
+	}
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+
+	var path []uuid.UUID
+	for _, id := range strings.Split(result.Path, ",") {
+		identityID, err := uuid.FromString(id)
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		path = append(path, identityID)
+	}
+	return path, nil
+}
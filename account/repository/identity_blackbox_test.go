@@ -315,6 +315,68 @@ func (s *identityBlackBoxTest) TestAddMemberFailsForDuplicateMembership() {
 	require.Error(s.T(), err)
 }
 
+func (s *identityBlackBoxTest) TestListAndRemoveMembers() {
+	team := s.Graph.CreateTeam()
+	member1 := s.Graph.CreateUser()
+	member2 := s.Graph.CreateUser()
+	team.AddMember(member1).AddMember(member2)
+	// Indirect members are not listed
+	nestedTeam := s.Graph.CreateTeam().AddMember(s.Graph.CreateUser())
+	err := s.Application.Identities().AddMember(s.Ctx, team.TeamID(), nestedTeam.TeamID())
+	require.NoError(s.T(), err)
+
+	members, err := s.Application.Identities().ListMembers(s.Ctx, team.TeamID())
+	require.NoError(s.T(), err)
+	require.Len(s.T(), members, 3)
+	for _, member := range members {
+		if member.ID == member1.IdentityID() {
+			assert.Equal(s.T(), member1.User().ID, member.User.ID)
+		}
+	}
+
+	err = s.Application.Identities().RemoveMember(s.Ctx, team.TeamID(), member1.IdentityID())
+	require.NoError(s.T(), err)
+
+	members, err = s.Application.Identities().ListMembers(s.Ctx, team.TeamID())
+	require.NoError(s.T(), err)
+	require.Len(s.T(), members, 2)
+	for _, member := range members {
+		assert.NotEqual(s.T(), member1.IdentityID(), member.ID)
+	}
+
+	// The membership was already removed
+	err = s.Application.Identities().RemoveMember(s.Ctx, team.TeamID(), member1.IdentityID())
+	testsupport.AssertError(s.T(), err, errors.NotFoundError{}, "membership with member_of '%s' and member_id '%s' not found", team.TeamID(), member1.IdentityID())
+}
+
+func (s *identityBlackBoxTest) TestDeleteMemberships() {
+	team := s.Graph.CreateTeam()
+	member := s.Graph.CreateUser()
+	team.AddMember(member)
+	otherTeam := s.Graph.CreateTeam()
+	err := s.Application.Identities().AddMember(s.Ctx, otherTeam.TeamID(), team.TeamID())
+	require.NoError(s.T(), err)
+	// noise
+	noise := s.Graph.CreateTeam().AddMember(member)
+
+	err = s.Application.Identities().DeleteMemberships(s.Ctx, team.TeamID())
+	require.NoError(s.T(), err)
+
+	members, err := s.Application.Identities().ListMembers(s.Ctx, team.TeamID())
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), members)
+	members, err = s.Application.Identities().ListMembers(s.Ctx, otherTeam.TeamID())
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), members)
+	members, err = s.Application.Identities().ListMembers(s.Ctx, noise.TeamID())
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), members, 1)
+
+	// No error if there is no membership left
+	err = s.Application.Identities().DeleteMemberships(s.Ctx, team.TeamID())
+	require.NoError(s.T(), err)
+}
+
 func createAndLoad(s *identityBlackBoxTest) *repository.Identity {
 	identity := &repository.Identity{
 		ID:           uuid.NewV4(),
@@ -110,6 +110,35 @@ func (c *EmailVerificationClient) VerifyCode(ctx context.Context, code string) (
 			"code": code,
 			"err":  err,
 		}, "verification failed")
+		return verificationCode, err
+	}
+
+	// Bind the invitations which were sent to the e-mail address before it was verified
+	c.bindEmailInvitations(ctx, verificationCode.User)
+	return verificationCode, nil
+}
+
+// bindEmailInvitations binds the pending invitations addressed to the verified e-mail address of the user to their identities.
+// The e-mail address has been verified at this point, so the errors are only logged.
+func (c *EmailVerificationClient) bindEmailInvitations(ctx context.Context, user repository.User) {
+	if user.Email == "" {
+		return
+	}
+	identities, err := c.app.Identities().Query(repository.IdentityFilterByUserID(user.ID))
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"user_id": user.ID,
+			"err":     err,
+		}, "unable to load the identities of the user")
+		return
+	}
+	for _, identity := range identities {
+		err = c.app.InvitationService().BindEmailInvitations(ctx, identity.ID, user.Email)
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"identity_id": identity.ID,
+				"err":         err,
+			}, "unable to bind the pending invitations to the identity")
+		}
 	}
-	return verificationCode, err
 }
@@ -6,6 +6,8 @@ import (
 
 	"github.com/fabric8-services/fabric8-auth/account/repository"
 	"github.com/fabric8-services/fabric8-auth/account/service"
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	"github.com/fabric8-services/fabric8-auth/authorization/invitation"
 	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
 	"github.com/fabric8-services/fabric8-auth/test"
 	"github.com/goadesign/goa"
@@ -73,6 +75,37 @@ func (s *verificationServiceBlackboxTest) TestVerifyCodeOK() {
 	require.Empty(s.T(), verificationCodes)
 }
 
+func (s *verificationServiceBlackboxTest) TestVerifyCodeBindsEmailInvitations() {
+	identity, err := test.CreateTestIdentityAndUser(s.DB, uuid.NewV4().String(), "kc")
+	require.NoError(s.T(), err)
+
+	// The user is invited before their e-mail address is verified
+	spaceAdmin := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(spaceAdmin)
+	email := identity.User.Email
+	err = s.Application.InvitationService().Issue(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID(), []invitation.Invitation{
+		{
+			Email: &email,
+			Roles: []string{authorization.SpaceViewerRole},
+		},
+	})
+	require.NoError(s.T(), err)
+
+	generatedCode := uuid.NewV4().String()
+	err = s.Application.VerificationCodes().Create(context.Background(), &repository.VerificationCode{
+		User: identity.User,
+		Code: generatedCode,
+	})
+	require.NoError(s.T(), err)
+	_, err = s.verificationService.VerifyCode(context.Background(), generatedCode)
+	require.NoError(s.T(), err)
+
+	invitations, err := s.Application.InvitationRepository().ListForUser(s.Ctx, identity.ID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), invitations, 1)
+	require.Equal(s.T(), space.SpaceID(), *invitations[0].ResourceID)
+}
+
 func (s *verificationServiceBlackboxTest) TestVerifyCodeFails() {
 	identity, err := test.CreateTestIdentity(s.DB, uuid.NewV4().String(), "kc")
 	require.NoError(s.T(), err)
@@ -0,0 +1,3 @@
+// The serviceaccount package provides features relating to the management of the service accounts stored in the
+// database; the accounts which authenticate the other services with the client credentials grant
+package serviceaccount
@@ -0,0 +1,2 @@
+// Package repository provides the APIs for making 'service account' related database interactions.
+package repository
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/gormsupport"
+	"github.com/fabric8-services/fabric8-auth/log"
+
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+)
+
+// ServiceAccount is an account which authenticates a service with the client credentials grant
+type ServiceAccount struct {
+	gormsupport.Lifecycle
+
+	// This is the primary key value, used as the client ID of the service
+	ServiceAccountID uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key;column:service_account_id"`
+	// The name of the service account, set as the "service_accountname" claim of its tokens
+	Name string `gorm:"column:name"`
+	// The last time at which the service account obtained a token
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m ServiceAccount) TableName() string {
+	return "service_account"
+}
+
+// GetLastModified returns the last modification time
+func (m ServiceAccount) GetLastModified() time.Time {
+	return m.UpdatedAt
+}
+
+// GormServiceAccountRepository is the implementation of the storage interface for ServiceAccount.
+type GormServiceAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewServiceAccountRepository creates a new storage type.
+func NewServiceAccountRepository(db *gorm.DB) ServiceAccountRepository {
+	return &GormServiceAccountRepository{db: db}
+}
+
+// ServiceAccountRepository represents the storage interface.
+type ServiceAccountRepository interface {
+	Load(ctx context.Context, id uuid.UUID) (*ServiceAccount, error)
+	Create(ctx context.Context, account *ServiceAccount) error
+	List(ctx context.Context) ([]ServiceAccount, error)
+	UpdateLastUsed(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m *GormServiceAccountRepository) TableName() string {
+	return "service_account"
+}
+
+// Load returns the service account for the given id
+func (m *GormServiceAccountRepository) Load(ctx context.Context, id uuid.UUID) (*ServiceAccount, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "service_account", "load"}, time.Now())
+	var native ServiceAccount
+	err := m.db.Table(m.TableName()).Where("service_account_id = ?", id).Find(&native).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewNotFoundError("service account", id.String())
+	}
+	return &native, errs.WithStack(err)
+}
+
+// Create creates a new record.
+func (m *GormServiceAccountRepository) Create(ctx context.Context, account *ServiceAccount) error {
+	defer goa.MeasureSince([]string{"goa", "db", "service_account", "create"}, time.Now())
+	if account.ServiceAccountID == uuid.Nil {
+		account.ServiceAccountID = uuid.NewV4()
+	}
+	err := m.db.Create(account).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"service_account_id": account.ServiceAccountID,
+			"name":               account.Name,
+			"err":                err,
+		}, "unable to create the service account")
+		if gormsupport.IsUniqueViolation(err, "service_account_pkey") {
+			return errors.NewDataConflictError(fmt.Sprintf("service account with ID %s already exists", account.ServiceAccountID))
+		}
+		if gormsupport.IsUniqueViolation(err, "idx_service_account_name") {
+			return errors.NewDataConflictError(fmt.Sprintf("service account with name %s already exists", account.Name))
+		}
+		return errs.WithStack(err)
+	}
+	log.Info(ctx, map[string]interface{}{
+		"service_account_id": account.ServiceAccountID,
+		"name":               account.Name,
+	}, "Service account created!")
+	return nil
+}
+
+// List returns all the service accounts, ordered by name
+func (m *GormServiceAccountRepository) List(ctx context.Context) ([]ServiceAccount, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "service_account", "list"}, time.Now())
+	var rows []ServiceAccount
+
+	err := m.db.Model(&ServiceAccount{}).Order("name").Find(&rows).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errs.WithStack(err)
+	}
+	return rows, nil
+}
+
+// UpdateLastUsed records the last time at which the service account obtained a token, without changing its
+// modification time
+func (m *GormServiceAccountRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error {
+	defer goa.MeasureSince([]string{"goa", "db", "service_account", "updateLastUsed"}, time.Now())
+	db := m.db.Model(&ServiceAccount{}).Where("service_account_id = ?", id).UpdateColumn("last_used_at", lastUsedAt)
+	if db.Error != nil {
+		return errs.WithStack(db.Error)
+	}
+	if db.RowsAffected == 0 {
+		return errors.NewNotFoundError("service account", id.String())
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	serviceaccount "github.com/fabric8-services/fabric8-auth/account/serviceaccount/repository"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
+
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type serviceAccountBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	repo serviceaccount.ServiceAccountRepository
+}
+
+func TestRunServiceAccountBlackBoxTest(t *testing.T) {
+	suite.Run(t, &serviceAccountBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *serviceAccountBlackBoxTest) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.repo = serviceaccount.NewServiceAccountRepository(s.DB)
+}
+
+func (s *serviceAccountBlackBoxTest) TestCreateAndLoad() {
+	account := serviceaccount.ServiceAccount{
+		Name: "sa-" + uuid.NewV4().String(),
+	}
+	err := s.repo.Create(s.Ctx, &account)
+	require.NoError(s.T(), err)
+	require.NotEqual(s.T(), uuid.Nil, account.ServiceAccountID)
+
+	loaded, err := s.repo.Load(s.Ctx, account.ServiceAccountID)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), account.Name, loaded.Name)
+	require.Nil(s.T(), loaded.LastUsedAt)
+
+	// The ID and the name are unique
+	err = s.repo.Create(s.Ctx, &serviceaccount.ServiceAccount{
+		ServiceAccountID: account.ServiceAccountID,
+		Name:             "sa-" + uuid.NewV4().String(),
+	})
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.DataConflictError{}, err)
+	err = s.repo.Create(s.Ctx, &serviceaccount.ServiceAccount{
+		Name: account.Name,
+	})
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.DataConflictError{}, err)
+}
+
+func (s *serviceAccountBlackBoxTest) TestCreateWithID() {
+	id := uuid.NewV4()
+	err := s.repo.Create(s.Ctx, &serviceaccount.ServiceAccount{
+		ServiceAccountID: id,
+		Name:             "sa-" + uuid.NewV4().String(),
+	})
+	require.NoError(s.T(), err)
+
+	_, err = s.repo.Load(s.Ctx, id)
+	require.NoError(s.T(), err)
+}
+
+func (s *serviceAccountBlackBoxTest) TestLoadUnknownFails() {
+	id := uuid.NewV4()
+	_, err := s.repo.Load(s.Ctx, id)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, err)
+}
+
+func (s *serviceAccountBlackBoxTest) TestList() {
+	first := serviceaccount.ServiceAccount{Name: "sa-b-" + uuid.NewV4().String()}
+	require.NoError(s.T(), s.repo.Create(s.Ctx, &first))
+	second := serviceaccount.ServiceAccount{Name: "sa-a-" + uuid.NewV4().String()}
+	require.NoError(s.T(), s.repo.Create(s.Ctx, &second))
+
+	accounts, err := s.repo.List(s.Ctx)
+	require.NoError(s.T(), err)
+	positions := map[uuid.UUID]int{}
+	for i, account := range accounts {
+		positions[account.ServiceAccountID] = i
+	}
+	require.Contains(s.T(), positions, first.ServiceAccountID)
+	require.Contains(s.T(), positions, second.ServiceAccountID)
+	// Ordered by name
+	require.True(s.T(), positions[second.ServiceAccountID] < positions[first.ServiceAccountID])
+}
+
+func (s *serviceAccountBlackBoxTest) TestUpdateLastUsed() {
+	account := serviceaccount.ServiceAccount{Name: "sa-" + uuid.NewV4().String()}
+	require.NoError(s.T(), s.repo.Create(s.Ctx, &account))
+
+	now := time.Now()
+	err := s.repo.UpdateLastUsed(s.Ctx, account.ServiceAccountID, now)
+	require.NoError(s.T(), err)
+
+	loaded, err := s.repo.Load(s.Ctx, account.ServiceAccountID)
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), loaded.LastUsedAt)
+	require.WithinDuration(s.T(), now, *loaded.LastUsedAt, time.Millisecond)
+
+	err = s.repo.UpdateLastUsed(s.Ctx, uuid.NewV4(), now)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, err)
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/log"
+
+	"github.com/fabric8-services/fabric8-auth/gormsupport"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+)
+
+// ServiceAccountSecret is a secret which authenticates a service account. Only the bcrypt hash of the secret is stored.
+type ServiceAccountSecret struct {
+	gormsupport.Lifecycle
+
+	// This is the primary key value
+	SecretID uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key;column:secret_id"`
+	// The service account authenticated by the secret
+	ServiceAccountID uuid.UUID `sql:"type:uuid" gorm:"column:service_account_id"`
+	// The bcrypt hash of the secret
+	SecretHash string `gorm:"column:secret_hash"`
+	// The time after which a retired secret is not accepted anymore. Nil if the secret has not been retired.
+	ExpiresAt *time.Time `gorm:"column:expires_at"`
+	// The last time at which the secret was used to obtain a token
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m ServiceAccountSecret) TableName() string {
+	return "service_account_secret"
+}
+
+// Retired returns true if the secret has been retired. A retired secret is accepted until it expires.
+func (m ServiceAccountSecret) Retired() bool {
+	return m.ExpiresAt != nil
+}
+
+// Expired returns true if the secret was retired and is not accepted anymore at the given time
+func (m ServiceAccountSecret) Expired(now time.Time) bool {
+	return m.ExpiresAt != nil && !m.ExpiresAt.After(now)
+}
+
+// GormServiceAccountSecretRepository is the implementation of the storage interface for ServiceAccountSecret.
+type GormServiceAccountSecretRepository struct {
+	db *gorm.DB
+}
+
+// NewServiceAccountSecretRepository creates a new storage type.
+func NewServiceAccountSecretRepository(db *gorm.DB) ServiceAccountSecretRepository {
+	return &GormServiceAccountSecretRepository{db: db}
+}
+
+// ServiceAccountSecretRepository represents the storage interface.
+type ServiceAccountSecretRepository interface {
+	Load(ctx context.Context, id uuid.UUID) (*ServiceAccountSecret, error)
+	Create(ctx context.Context, secret *ServiceAccountSecret) error
+	Save(ctx context.Context, secret *ServiceAccountSecret) error
+	ListForServiceAccount(ctx context.Context, serviceAccountID uuid.UUID) ([]ServiceAccountSecret, error)
+	UpdateLastUsed(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m *GormServiceAccountSecretRepository) TableName() string {
+	return "service_account_secret"
+}
+
+// Load returns the service account secret for the given id
+func (m *GormServiceAccountSecretRepository) Load(ctx context.Context, id uuid.UUID) (*ServiceAccountSecret, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "service_account_secret", "load"}, time.Now())
+	var native ServiceAccountSecret
+	err := m.db.Table(m.TableName()).Where("secret_id = ?", id).Find(&native).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewNotFoundError("service account secret", id.String())
+	}
+	return &native, errs.WithStack(err)
+}
+
+// Create creates a new record.
+func (m *GormServiceAccountSecretRepository) Create(ctx context.Context, secret *ServiceAccountSecret) error {
+	defer goa.MeasureSince([]string{"goa", "db", "service_account_secret", "create"}, time.Now())
+	if secret.SecretID == uuid.Nil {
+		secret.SecretID = uuid.NewV4()
+	}
+	err := m.db.Create(secret).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"service_account_id": secret.ServiceAccountID,
+			"err":                err,
+		}, "unable to create the service account secret")
+		return errs.WithStack(err)
+	}
+	log.Info(ctx, map[string]interface{}{
+		"service_account_id": secret.ServiceAccountID,
+		"secret_id":          secret.SecretID,
+	}, "Service account secret created!")
+	return nil
+}
+
+// Save modifies a single record.
+func (m *GormServiceAccountSecretRepository) Save(ctx context.Context, secret *ServiceAccountSecret) error {
+	defer goa.MeasureSince([]string{"goa", "db", "service_account_secret", "save"}, time.Now())
+
+	obj, err := m.Load(ctx, secret.SecretID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"secret_id": secret.SecretID,
+			"err":       err,
+		}, "unable to update service account secret")
+		return errs.WithStack(err)
+	}
+	err = m.db.Model(obj).Updates(secret).Error
+	if err != nil {
+		return errs.WithStack(err)
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"secret_id": secret.SecretID,
+	}, "Service account secret saved!")
+	return nil
+}
+
+// ListForServiceAccount returns all the secrets of the specified service account, including the expired ones,
+// oldest first
+func (m *GormServiceAccountSecretRepository) ListForServiceAccount(ctx context.Context, serviceAccountID uuid.UUID) ([]ServiceAccountSecret, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "service_account_secret", "listForServiceAccount"}, time.Now())
+	var rows []ServiceAccountSecret
+
+	err := m.db.Model(&ServiceAccountSecret{}).Where("service_account_id = ?", serviceAccountID).Order("created_at").Find(&rows).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errs.WithStack(err)
+	}
+	return rows, nil
+}
+
+// UpdateLastUsed records the last time at which the secret was used to obtain a token, without changing its
+// modification time
+func (m *GormServiceAccountSecretRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error {
+	defer goa.MeasureSince([]string{"goa", "db", "service_account_secret", "updateLastUsed"}, time.Now())
+	db := m.db.Model(&ServiceAccountSecret{}).Where("secret_id = ?", id).UpdateColumn("last_used_at", lastUsedAt)
+	if db.Error != nil {
+		return errs.WithStack(db.Error)
+	}
+	if db.RowsAffected == 0 {
+		return errors.NewNotFoundError("service account secret", id.String())
+	}
+	return nil
+}
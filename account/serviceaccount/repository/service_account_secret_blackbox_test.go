@@ -0,0 +1,135 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	serviceaccount "github.com/fabric8-services/fabric8-auth/account/serviceaccount/repository"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
+
+	errs "github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type serviceAccountSecretBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	accounts serviceaccount.ServiceAccountRepository
+	repo     serviceaccount.ServiceAccountSecretRepository
+}
+
+func TestRunServiceAccountSecretBlackBoxTest(t *testing.T) {
+	suite.Run(t, &serviceAccountSecretBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *serviceAccountSecretBlackBoxTest) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.accounts = serviceaccount.NewServiceAccountRepository(s.DB)
+	s.repo = serviceaccount.NewServiceAccountSecretRepository(s.DB)
+}
+
+func (s *serviceAccountSecretBlackBoxTest) createServiceAccount() serviceaccount.ServiceAccount {
+	account := serviceaccount.ServiceAccount{Name: "sa-" + uuid.NewV4().String()}
+	require.NoError(s.T(), s.accounts.Create(s.Ctx, &account))
+	return account
+}
+
+func (s *serviceAccountSecretBlackBoxTest) TestCreateAndLoad() {
+	account := s.createServiceAccount()
+	secret := serviceaccount.ServiceAccountSecret{
+		ServiceAccountID: account.ServiceAccountID,
+		SecretHash:       "$2a$04$somehash",
+	}
+	err := s.repo.Create(s.Ctx, &secret)
+	require.NoError(s.T(), err)
+	require.NotEqual(s.T(), uuid.Nil, secret.SecretID)
+
+	loaded, err := s.repo.Load(s.Ctx, secret.SecretID)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), account.ServiceAccountID, loaded.ServiceAccountID)
+	require.Equal(s.T(), secret.SecretHash, loaded.SecretHash)
+	require.False(s.T(), loaded.Retired())
+	require.False(s.T(), loaded.Expired(time.Now()))
+
+	// The service account must exist
+	err = s.repo.Create(s.Ctx, &serviceaccount.ServiceAccountSecret{
+		ServiceAccountID: uuid.NewV4(),
+		SecretHash:       "$2a$04$somehash",
+	})
+	require.Error(s.T(), err)
+}
+
+func (s *serviceAccountSecretBlackBoxTest) TestLoadUnknownFails() {
+	id := uuid.NewV4()
+	_, err := s.repo.Load(s.Ctx, id)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, err)
+}
+
+func (s *serviceAccountSecretBlackBoxTest) TestSaveExpiry() {
+	account := s.createServiceAccount()
+	secret := serviceaccount.ServiceAccountSecret{
+		ServiceAccountID: account.ServiceAccountID,
+		SecretHash:       "$2a$04$somehash",
+	}
+	require.NoError(s.T(), s.repo.Create(s.Ctx, &secret))
+
+	expiresAt := time.Now().Add(time.Hour)
+	secret.ExpiresAt = &expiresAt
+	err := s.repo.Save(s.Ctx, &secret)
+	require.NoError(s.T(), err)
+
+	loaded, err := s.repo.Load(s.Ctx, secret.SecretID)
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), loaded.ExpiresAt)
+	assert.True(s.T(), loaded.Retired())
+	assert.False(s.T(), loaded.Expired(time.Now()))
+	assert.True(s.T(), loaded.Expired(expiresAt.Add(time.Second)))
+
+	err = s.repo.Save(s.Ctx, &serviceaccount.ServiceAccountSecret{SecretID: uuid.NewV4()})
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *serviceAccountSecretBlackBoxTest) TestListForServiceAccount() {
+	account := s.createServiceAccount()
+	first := serviceaccount.ServiceAccountSecret{ServiceAccountID: account.ServiceAccountID, SecretHash: "first"}
+	require.NoError(s.T(), s.repo.Create(s.Ctx, &first))
+	second := serviceaccount.ServiceAccountSecret{ServiceAccountID: account.ServiceAccountID, SecretHash: "second"}
+	require.NoError(s.T(), s.repo.Create(s.Ctx, &second))
+	// noise
+	other := s.createServiceAccount()
+	require.NoError(s.T(), s.repo.Create(s.Ctx, &serviceaccount.ServiceAccountSecret{ServiceAccountID: other.ServiceAccountID, SecretHash: "other"}))
+
+	secrets, err := s.repo.ListForServiceAccount(s.Ctx, account.ServiceAccountID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), secrets, 2)
+	assert.Equal(s.T(), first.SecretID, secrets[0].SecretID)
+	assert.Equal(s.T(), second.SecretID, secrets[1].SecretID)
+
+	secrets, err = s.repo.ListForServiceAccount(s.Ctx, uuid.NewV4())
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), secrets)
+}
+
+func (s *serviceAccountSecretBlackBoxTest) TestUpdateLastUsed() {
+	account := s.createServiceAccount()
+	secret := serviceaccount.ServiceAccountSecret{ServiceAccountID: account.ServiceAccountID, SecretHash: "hash"}
+	require.NoError(s.T(), s.repo.Create(s.Ctx, &secret))
+
+	now := time.Now()
+	err := s.repo.UpdateLastUsed(s.Ctx, secret.SecretID, now)
+	require.NoError(s.T(), err)
+
+	loaded, err := s.repo.Load(s.Ctx, secret.SecretID)
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), loaded.LastUsedAt)
+	require.WithinDuration(s.T(), now, *loaded.LastUsedAt, time.Millisecond)
+
+	err = s.repo.UpdateLastUsed(s.Ctx, uuid.NewV4(), now)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, err)
+}
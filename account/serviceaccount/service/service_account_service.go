@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	serviceaccount "github.com/fabric8-services/fabric8-auth/account/serviceaccount/repository"
+	"github.com/fabric8-services/fabric8-auth/application/service"
+	"github.com/fabric8-services/fabric8-auth/application/service/base"
+	servicecontext "github.com/fabric8-services/fabric8-auth/application/service/context"
+	"github.com/fabric8-services/fabric8-auth/configuration"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/log"
+
+	"github.com/satori/go.uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// secretLength is the number of random bytes of the generated secrets
+const secretLength = 32
+
+// ServiceAccountServiceConfiguration the configuration for the service account service
+type ServiceAccountServiceConfiguration interface {
+	GetServiceAccounts() map[string]configuration.ServiceAccount
+	GetServiceAccountSecretOverlap() time.Duration
+}
+
+// serviceAccountServiceImpl is the default implementation of ServiceAccountService. It is a private struct and should only
+// be instantiated via the NewServiceAccountService() function.
+type serviceAccountServiceImpl struct {
+	base.BaseService
+	config ServiceAccountServiceConfiguration
+}
+
+// NewServiceAccountService creates a new service to manage the service accounts stored in the database
+func NewServiceAccountService(context servicecontext.ServiceContext, config ServiceAccountServiceConfiguration) service.ServiceAccountService {
+	return &serviceAccountServiceImpl{
+		BaseService: base.NewBaseService(context),
+		config:      config,
+	}
+}
+
+// Create creates a new service account with the given name and, if specified, the given ID, along with its first secret.
+// The plain value of the secret is returned, since only its hash is stored.
+// A service account which is also configured in the service account configuration file must be created with the
+// configured ID and name; its configured secrets are then imported, so that the service can keep using them until
+// they are retired.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *serviceAccountServiceImpl) Create(ctx context.Context, id *uuid.UUID, name string) (*serviceaccount.ServiceAccount, *serviceaccount.ServiceAccountSecret, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil, "", errors.NewBadParameterErrorFromString("name", name, "service account name must not be empty")
+	}
+
+	var importedHashes []string
+	for _, sa := range s.config.GetServiceAccounts() {
+		if sa.Name != name && (id == nil || sa.ID != id.String()) {
+			continue
+		}
+		if sa.Name != name || id == nil || sa.ID != id.String() {
+			return nil, nil, "", errors.NewDataConflictError(fmt.Sprintf("the configured service account '%s' must be created with ID %s", sa.Name, sa.ID))
+		}
+		importedHashes = sa.Secrets
+	}
+
+	account := &serviceaccount.ServiceAccount{
+		Name: name,
+	}
+	if id != nil {
+		account.ServiceAccountID = *id
+	}
+
+	var secret *serviceaccount.ServiceAccountSecret
+	var plainSecret string
+	err := s.ExecuteInTransaction(func() error {
+		err := s.Repositories().ServiceAccountRepository().Create(ctx, account)
+		if err != nil {
+			return err
+		}
+		for _, hash := range importedHashes {
+			err = s.Repositories().ServiceAccountSecretRepository().Create(ctx, &serviceaccount.ServiceAccountSecret{
+				ServiceAccountID: account.ServiceAccountID,
+				SecretHash:       hash,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		secret, plainSecret, err = s.createSecret(ctx, account.ServiceAccountID)
+		return err
+	})
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"service_account_id":   account.ServiceAccountID,
+		"service_account_name": account.Name,
+		"imported_secrets":     len(importedHashes),
+	}, "service account created")
+	return account, secret, plainSecret, nil
+}
+
+// List returns all the service accounts stored in the database
+func (s *serviceAccountServiceImpl) List(ctx context.Context) ([]serviceaccount.ServiceAccount, error) {
+	return s.Repositories().ServiceAccountRepository().List(ctx)
+}
+
+// Load returns the specified service account along with all its secrets, including the retired ones
+func (s *serviceAccountServiceImpl) Load(ctx context.Context, id uuid.UUID) (*serviceaccount.ServiceAccount, []serviceaccount.ServiceAccountSecret, error) {
+	account, err := s.Repositories().ServiceAccountRepository().Load(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	secrets, err := s.Repositories().ServiceAccountSecretRepository().ListForServiceAccount(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, secrets, nil
+}
+
+// AddSecret generates a new secret for the specified service account. The plain value of the secret is returned,
+// since only its hash is stored. The existing secrets remain valid until they are retired.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *serviceAccountServiceImpl) AddSecret(ctx context.Context, id uuid.UUID) (*serviceaccount.ServiceAccountSecret, string, error) {
+	var secret *serviceaccount.ServiceAccountSecret
+	var plainSecret string
+	err := s.ExecuteInTransaction(func() error {
+		_, err := s.Repositories().ServiceAccountRepository().Load(ctx, id)
+		if err != nil {
+			return err
+		}
+		secret, plainSecret, err = s.createSecret(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return secret, plainSecret, nil
+}
+
+// RetireSecret retires a secret of the specified service account. The secret is still accepted during the given overlap
+// period, or the configured one if nil, so that the service can switch to its new secret. A secret can only be retired
+// if the service account has another secret which has not been retired.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *serviceAccountServiceImpl) RetireSecret(ctx context.Context, id uuid.UUID, secretID uuid.UUID, overlap *time.Duration) (*serviceaccount.ServiceAccountSecret, error) {
+	period := s.config.GetServiceAccountSecretOverlap()
+	if overlap != nil {
+		if *overlap < 0 {
+			return nil, errors.NewBadParameterErrorFromString("overlap", *overlap, "the overlap period must not be negative")
+		}
+		period = *overlap
+	}
+
+	var retired *serviceaccount.ServiceAccountSecret
+	err := s.ExecuteInTransaction(func() error {
+		_, err := s.Repositories().ServiceAccountRepository().Load(ctx, id)
+		if err != nil {
+			return err
+		}
+		secrets, err := s.Repositories().ServiceAccountSecretRepository().ListForServiceAccount(ctx, id)
+		if err != nil {
+			return err
+		}
+		activeSecrets := 0
+		for i := range secrets {
+			if secrets[i].SecretID == secretID {
+				retired = &secrets[i]
+			}
+			if !secrets[i].Retired() {
+				activeSecrets++
+			}
+		}
+		if retired == nil {
+			return errors.NewNotFoundError("service account secret", secretID.String())
+		}
+		if retired.Retired() {
+			return errors.NewDataConflictError(fmt.Sprintf("secret %s of service account %s has already been retired", secretID, id))
+		}
+		if activeSecrets < 2 {
+			return errors.NewDataConflictError(fmt.Sprintf("secret %s is the last secret of service account %s which has not been retired", secretID, id))
+		}
+
+		expiresAt := time.Now().Add(period)
+		retired.ExpiresAt = &expiresAt
+		return s.Repositories().ServiceAccountSecretRepository().Save(ctx, retired)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"service_account_id": id,
+		"secret_id":          secretID,
+		"expires_at":         retired.ExpiresAt,
+	}, "service account secret retired")
+	return retired, nil
+}
+
+// Authenticate checks the credentials of a service account and returns its ID and name. The service accounts stored in the
+// database take precedence over the ones of the service account configuration, which are only checked if there is no
+// service account with the given client ID in the database.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *serviceAccountServiceImpl) Authenticate(ctx context.Context, clientID string, clientSecret string) (string, string, error) {
+	id, err := uuid.FromString(clientID)
+	if err == nil {
+		account, err := s.Repositories().ServiceAccountRepository().Load(ctx, id)
+		if err == nil {
+			return s.authenticateStored(ctx, account, clientSecret)
+		}
+		if notFound, _ := errors.IsNotFoundError(err); !notFound {
+			return "", "", err
+		}
+	}
+
+	sa, found := s.config.GetServiceAccounts()[clientID]
+	if !found {
+		log.Error(ctx, map[string]interface{}{
+			"client_id": clientID,
+		}, "Unknown Service Account ID")
+		return "", "", errors.NewUnauthorizedError("invalid Service Account ID or secret")
+	}
+	for _, hash := range sa.Secrets {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(clientSecret)) == nil {
+			return sa.ID, sa.Name, nil
+		}
+	}
+	log.Error(ctx, map[string]interface{}{
+		"client_id": clientID,
+	}, "Service Account secret doesn't match")
+	return "", "", errors.NewUnauthorizedError("invalid Service Account ID or secret")
+}
+
+// authenticateStored checks the secret of a service account stored in the database against its secrets which have not
+// expired, and records the use of the matching secret. Failing to record the use does not fail the authentication.
+func (s *serviceAccountServiceImpl) authenticateStored(ctx context.Context, account *serviceaccount.ServiceAccount, clientSecret string) (string, string, error) {
+	secrets, err := s.Repositories().ServiceAccountSecretRepository().ListForServiceAccount(ctx, account.ServiceAccountID)
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now()
+	for _, secret := range secrets {
+		if secret.Expired(now) {
+			continue
+		}
+		if bcrypt.CompareHashAndPassword([]byte(secret.SecretHash), []byte(clientSecret)) != nil {
+			continue
+		}
+		err = s.ExecuteInTransaction(func() error {
+			err := s.Repositories().ServiceAccountSecretRepository().UpdateLastUsed(ctx, secret.SecretID, now)
+			if err != nil {
+				return err
+			}
+			return s.Repositories().ServiceAccountRepository().UpdateLastUsed(ctx, account.ServiceAccountID, now)
+		})
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"err":       err,
+				"client_id": account.ServiceAccountID,
+				"secret_id": secret.SecretID,
+			}, "unable to record the use of the Service Account secret")
+		}
+		return account.ServiceAccountID.String(), account.Name, nil
+	}
+	log.Error(ctx, map[string]interface{}{
+		"client_id": account.ServiceAccountID,
+	}, "Service Account secret doesn't match")
+	return "", "", errors.NewUnauthorizedError("invalid Service Account ID or secret")
+}
+
+// createSecret generates a new secret for the service account and stores its hash. It must be called within a transaction.
+func (s *serviceAccountServiceImpl) createSecret(ctx context.Context, id uuid.UUID) (*serviceaccount.ServiceAccountSecret, string, error) {
+	b := make([]byte, secretLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, "", errors.NewInternalError(ctx, err)
+	}
+	plainSecret := base64.RawURLEncoding.EncodeToString(b)
+	hash, err := bcrypt.GenerateFromPassword([]byte(plainSecret), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, "", errors.NewInternalError(ctx, err)
+	}
+	secret := &serviceaccount.ServiceAccountSecret{
+		ServiceAccountID: id,
+		SecretHash:       string(hash),
+	}
+	err = s.Repositories().ServiceAccountSecretRepository().Create(ctx, secret)
+	if err != nil {
+		return nil, "", err
+	}
+	return secret, plainSecret, nil
+}
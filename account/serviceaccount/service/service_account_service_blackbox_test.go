@@ -0,0 +1,236 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
+
+	errs "github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type serviceAccountServiceBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunServiceAccountServiceBlackBoxTest(t *testing.T) {
+	suite.Run(t, &serviceAccountServiceBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *serviceAccountServiceBlackBoxTest) TestCreateServiceAccount() {
+	name := "sa-" + uuid.NewV4().String()
+	account, secret, plainSecret, err := s.Application.ServiceAccountService().Create(s.Ctx, nil, name)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), name, account.Name)
+	assert.NotEqual(s.T(), uuid.Nil, account.ServiceAccountID)
+	assert.Equal(s.T(), account.ServiceAccountID, secret.ServiceAccountID)
+	assert.NotEmpty(s.T(), plainSecret)
+	// Only the hash of the secret is stored
+	assert.NotEqual(s.T(), plainSecret, secret.SecretHash)
+
+	loaded, secrets, err := s.Application.ServiceAccountService().Load(s.Ctx, account.ServiceAccountID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), name, loaded.Name)
+	require.Len(s.T(), secrets, 1)
+	assert.Equal(s.T(), secret.SecretID, secrets[0].SecretID)
+
+	accounts, err := s.Application.ServiceAccountService().List(s.Ctx)
+	require.NoError(s.T(), err)
+	found := false
+	for _, a := range accounts {
+		if a.ServiceAccountID == account.ServiceAccountID {
+			found = true
+		}
+	}
+	assert.True(s.T(), found)
+
+	// The name is unique
+	_, _, _, err = s.Application.ServiceAccountService().Create(s.Ctx, nil, name)
+	require.Error(s.T(), err)
+	assert.IsType(s.T(), errors.DataConflictError{}, errs.Cause(err))
+}
+
+func (s *serviceAccountServiceBlackBoxTest) TestCreateServiceAccountWithID() {
+	id := uuid.NewV4()
+	account, _, _, err := s.Application.ServiceAccountService().Create(s.Ctx, &id, "sa-"+uuid.NewV4().String())
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), id, account.ServiceAccountID)
+}
+
+func (s *serviceAccountServiceBlackBoxTest) TestCreateServiceAccountWithoutNameFails() {
+	_, _, _, err := s.Application.ServiceAccountService().Create(s.Ctx, nil, " ")
+	require.Error(s.T(), err)
+	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+}
+
+func (s *serviceAccountServiceBlackBoxTest) TestCreateConfiguredServiceAccount() {
+	sa := s.Configuration.GetServiceAccounts()["5dec5fdb-09e3-4453-b73f-5c828832b28e"]
+	require.Equal(s.T(), "fabric8-wit", sa.Name)
+	id := uuid.FromStringOrNil(sa.ID)
+
+	s.T().Run("the configured ID is required", func(t *testing.T) {
+		_, _, _, err := s.Application.ServiceAccountService().Create(s.Ctx, nil, sa.Name)
+		require.Error(t, err)
+		assert.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+		otherID := uuid.NewV4()
+		_, _, _, err = s.Application.ServiceAccountService().Create(s.Ctx, &otherID, sa.Name)
+		require.Error(t, err)
+		assert.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("the configured name is required", func(t *testing.T) {
+		_, _, _, err := s.Application.ServiceAccountService().Create(s.Ctx, &id, "sa-"+uuid.NewV4().String())
+		require.Error(t, err)
+		assert.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("the configured secrets are imported", func(t *testing.T) {
+		account, secret, plainSecret, err := s.Application.ServiceAccountService().Create(s.Ctx, &id, sa.Name)
+		require.NoError(t, err)
+		_, secrets, err := s.Application.ServiceAccountService().Load(s.Ctx, account.ServiceAccountID)
+		require.NoError(t, err)
+		require.Len(t, secrets, len(sa.Secrets)+1)
+
+		// Both the configured and the new secrets are accepted
+		saID, saName, err := s.Application.ServiceAccountService().Authenticate(s.Ctx, sa.ID, "witsecret")
+		require.NoError(t, err)
+		assert.Equal(t, sa.ID, saID)
+		assert.Equal(t, sa.Name, saName)
+		_, _, err = s.Application.ServiceAccountService().Authenticate(s.Ctx, sa.ID, plainSecret)
+		require.NoError(t, err)
+
+		// The configured secret is not accepted anymore once retired
+		zero := time.Duration(0)
+		_, err = s.Application.ServiceAccountService().RetireSecret(s.Ctx, id, secrets[0].SecretID, &zero)
+		require.NoError(t, err)
+		require.NotEqual(t, secret.SecretID, secrets[0].SecretID)
+		_, _, err = s.Application.ServiceAccountService().Authenticate(s.Ctx, sa.ID, "witsecret")
+		require.Error(t, err)
+		assert.IsType(t, errors.UnauthorizedError{}, errs.Cause(err))
+	})
+}
+
+func (s *serviceAccountServiceBlackBoxTest) TestLoadUnknownServiceAccountFails() {
+	_, _, err := s.Application.ServiceAccountService().Load(s.Ctx, uuid.NewV4())
+	require.Error(s.T(), err)
+	assert.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+
+	_, _, err = s.Application.ServiceAccountService().AddSecret(s.Ctx, uuid.NewV4())
+	require.Error(s.T(), err)
+	assert.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *serviceAccountServiceBlackBoxTest) TestAuthenticate() {
+	account, _, plainSecret, err := s.Application.ServiceAccountService().Create(s.Ctx, nil, "sa-"+uuid.NewV4().String())
+	require.NoError(s.T(), err)
+	clientID := account.ServiceAccountID.String()
+
+	s.T().Run("ok", func(t *testing.T) {
+		saID, saName, err := s.Application.ServiceAccountService().Authenticate(s.Ctx, clientID, plainSecret)
+		require.NoError(t, err)
+		assert.Equal(t, clientID, saID)
+		assert.Equal(t, account.Name, saName)
+
+		// The use of the service account and of its secret is recorded
+		loaded, secrets, err := s.Application.ServiceAccountService().Load(s.Ctx, account.ServiceAccountID)
+		require.NoError(t, err)
+		require.NotNil(t, loaded.LastUsedAt)
+		require.NotNil(t, secrets[0].LastUsedAt)
+	})
+
+	s.T().Run("wrong secret", func(t *testing.T) {
+		_, _, err := s.Application.ServiceAccountService().Authenticate(s.Ctx, clientID, "wrong")
+		require.Error(t, err)
+		assert.IsType(t, errors.UnauthorizedError{}, errs.Cause(err))
+	})
+
+	s.T().Run("unknown service account", func(t *testing.T) {
+		_, _, err := s.Application.ServiceAccountService().Authenticate(s.Ctx, uuid.NewV4().String(), plainSecret)
+		require.Error(t, err)
+		assert.IsType(t, errors.UnauthorizedError{}, errs.Cause(err))
+		_, _, err = s.Application.ServiceAccountService().Authenticate(s.Ctx, "unknown", plainSecret)
+		require.Error(t, err)
+		assert.IsType(t, errors.UnauthorizedError{}, errs.Cause(err))
+	})
+
+	s.T().Run("configured service account", func(t *testing.T) {
+		saID, saName, err := s.Application.ServiceAccountService().Authenticate(s.Ctx, "c211f1bd-17a7-4f8c-9f80-0917d167889d", "tenantsecretNew")
+		require.NoError(t, err)
+		assert.Equal(t, "c211f1bd-17a7-4f8c-9f80-0917d167889d", saID)
+		assert.Equal(t, "fabric8-tenant", saName)
+	})
+}
+
+func (s *serviceAccountServiceBlackBoxTest) TestRotateSecret() {
+	account, oldSecret, oldPlainSecret, err := s.Application.ServiceAccountService().Create(s.Ctx, nil, "sa-"+uuid.NewV4().String())
+	require.NoError(s.T(), err)
+	id := account.ServiceAccountID
+
+	// The last secret can't be retired
+	_, err = s.Application.ServiceAccountService().RetireSecret(s.Ctx, id, oldSecret.SecretID, nil)
+	require.Error(s.T(), err)
+	assert.IsType(s.T(), errors.DataConflictError{}, errs.Cause(err))
+
+	newSecret, newPlainSecret, err := s.Application.ServiceAccountService().AddSecret(s.Ctx, id)
+	require.NoError(s.T(), err)
+	assert.NotEqual(s.T(), oldPlainSecret, newPlainSecret)
+
+	// The overlap period must not be negative
+	negative := -time.Hour
+	_, err = s.Application.ServiceAccountService().RetireSecret(s.Ctx, id, oldSecret.SecretID, &negative)
+	require.Error(s.T(), err)
+	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+
+	// The retired secret is still accepted during the configured overlap period
+	before := time.Now()
+	retired, err := s.Application.ServiceAccountService().RetireSecret(s.Ctx, id, oldSecret.SecretID, nil)
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), retired.ExpiresAt)
+	assert.WithinDuration(s.T(), before.Add(s.Configuration.GetServiceAccountSecretOverlap()), *retired.ExpiresAt, time.Minute)
+	_, _, err = s.Application.ServiceAccountService().Authenticate(s.Ctx, id.String(), oldPlainSecret)
+	require.NoError(s.T(), err)
+	_, _, err = s.Application.ServiceAccountService().Authenticate(s.Ctx, id.String(), newPlainSecret)
+	require.NoError(s.T(), err)
+
+	// A secret can't be retired twice, and the new secret is now the last one
+	_, err = s.Application.ServiceAccountService().RetireSecret(s.Ctx, id, oldSecret.SecretID, nil)
+	require.Error(s.T(), err)
+	assert.IsType(s.T(), errors.DataConflictError{}, errs.Cause(err))
+	_, err = s.Application.ServiceAccountService().RetireSecret(s.Ctx, id, newSecret.SecretID, nil)
+	require.Error(s.T(), err)
+	assert.IsType(s.T(), errors.DataConflictError{}, errs.Cause(err))
+
+	// Unknown secrets
+	_, err = s.Application.ServiceAccountService().RetireSecret(s.Ctx, id, uuid.NewV4(), nil)
+	require.Error(s.T(), err)
+	assert.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+	other, otherSecret, _, err := s.Application.ServiceAccountService().Create(s.Ctx, nil, "sa-"+uuid.NewV4().String())
+	require.NoError(s.T(), err)
+	_, _, err = s.Application.ServiceAccountService().AddSecret(s.Ctx, other.ServiceAccountID)
+	require.NoError(s.T(), err)
+	_, err = s.Application.ServiceAccountService().RetireSecret(s.Ctx, id, otherSecret.SecretID, nil)
+	require.Error(s.T(), err)
+	assert.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *serviceAccountServiceBlackBoxTest) TestExpiredSecretIsRejected() {
+	account, oldSecret, oldPlainSecret, err := s.Application.ServiceAccountService().Create(s.Ctx, nil, "sa-"+uuid.NewV4().String())
+	require.NoError(s.T(), err)
+	_, newPlainSecret, err := s.Application.ServiceAccountService().AddSecret(s.Ctx, account.ServiceAccountID)
+	require.NoError(s.T(), err)
+
+	zero := time.Duration(0)
+	_, err = s.Application.ServiceAccountService().RetireSecret(s.Ctx, account.ServiceAccountID, oldSecret.SecretID, &zero)
+	require.NoError(s.T(), err)
+
+	_, _, err = s.Application.ServiceAccountService().Authenticate(s.Ctx, account.ServiceAccountID.String(), oldPlainSecret)
+	require.Error(s.T(), err)
+	assert.IsType(s.T(), errors.UnauthorizedError{}, errs.Cause(err))
+	_, _, err = s.Application.ServiceAccountService().Authenticate(s.Ctx, account.ServiceAccountID.String(), newPlainSecret)
+	require.NoError(s.T(), err)
+}
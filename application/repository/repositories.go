@@ -2,12 +2,15 @@ package repository
 
 import (
 	account "github.com/fabric8-services/fabric8-auth/account/repository"
+	serviceaccount "github.com/fabric8-services/fabric8-auth/account/serviceaccount/repository"
 	"github.com/fabric8-services/fabric8-auth/auth"
+	accessrequest "github.com/fabric8-services/fabric8-auth/authorization/accessrequest/repository"
 	invitation "github.com/fabric8-services/fabric8-auth/authorization/invitation/repository"
 	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
 	resourcetype "github.com/fabric8-services/fabric8-auth/authorization/resourcetype/repository"
 	role "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
 	token "github.com/fabric8-services/fabric8-auth/authorization/token/repository"
+	signingkey "github.com/fabric8-services/fabric8-auth/token/keystore/repository"
 	"github.com/fabric8-services/fabric8-auth/token/provider"
 )
 
@@ -19,12 +22,18 @@ type Repositories interface {
 	ExternalTokens() provider.ExternalTokenRepository
 	VerificationCodes() account.VerificationCodeRepository
 	InvitationRepository() invitation.InvitationRepository
+	AccessRequestRepository() accessrequest.AccessRequestRepository
 	ResourceRepository() resource.ResourceRepository
+	ResourceOwnershipTransferRepository() resource.ResourceOwnershipTransferRepository
 	ResourceTypeRepository() resourcetype.ResourceTypeRepository
 	ResourceTypeScopeRepository() resourcetype.ResourceTypeScopeRepository
 	IdentityRoleRepository() role.IdentityRoleRepository
 	RoleRepository() role.RoleRepository
+	RoleScopeRepository() role.RoleScopeRepository
 	DefaultRoleMappingRepository() role.DefaultRoleMappingRepository
 	RoleMappingRepository() role.RoleMappingRepository
 	TokenRepository() token.TokenRepository
+	SigningKeyRepository() signingkey.SigningKeyRepository
+	ServiceAccountRepository() serviceaccount.ServiceAccountRepository
+	ServiceAccountSecretRepository() serviceaccount.ServiceAccountSecretRepository
 }
@@ -5,14 +5,18 @@ import (
 	"time"
 
 	userservice "github.com/fabric8-services/fabric8-auth/account/service"
+	serviceaccountservice "github.com/fabric8-services/fabric8-auth/account/serviceaccount/service"
 	"github.com/fabric8-services/fabric8-auth/application/repository"
 	"github.com/fabric8-services/fabric8-auth/application/service"
 	"github.com/fabric8-services/fabric8-auth/application/service/context"
 	"github.com/fabric8-services/fabric8-auth/application/transaction"
+	accessrequestservice "github.com/fabric8-services/fabric8-auth/authorization/accessrequest/service"
+	groupservice "github.com/fabric8-services/fabric8-auth/authorization/group/service"
 	invitationservice "github.com/fabric8-services/fabric8-auth/authorization/invitation/service"
 	organizationservice "github.com/fabric8-services/fabric8-auth/authorization/organization/service"
 	permissionservice "github.com/fabric8-services/fabric8-auth/authorization/permission/service"
 	resourceservice "github.com/fabric8-services/fabric8-auth/authorization/resource/service"
+	resourcetypeservice "github.com/fabric8-services/fabric8-auth/authorization/resourcetype/service"
 	roleservice "github.com/fabric8-services/fabric8-auth/authorization/role/service"
 	spaceservice "github.com/fabric8-services/fabric8-auth/authorization/space/service"
 	teamservice "github.com/fabric8-services/fabric8-auth/authorization/team/service"
@@ -123,9 +127,10 @@ func (s *serviceContextImpl) endTransaction() {
 type ServiceContextProducer func() context.ServiceContext
 
 type ServiceFactory struct {
-	contextProducer ServiceContextProducer
-	config          *configuration.ConfigurationData
-	witServiceFunc  func() service.WITService // the function to call when `WITService()` is called on this factory
+	contextProducer         ServiceContextProducer
+	config                  *configuration.ConfigurationData
+	witServiceFunc          func() service.WITService          // the function to call when `WITService()` is called on this factory
+	notificationServiceFunc func() service.NotificationService // the function to call when `NotificationService()` is called on this factory
 }
 
 // Option an option to configure the Service Factory
@@ -138,12 +143,25 @@ func WithWITService(s service.WITService) Option {
 		}
 	}
 }
+
+func WithNotificationService(s service.NotificationService) Option {
+	return func(f *ServiceFactory) {
+		f.notificationServiceFunc = func() service.NotificationService {
+			return s
+		}
+	}
+}
+
 func NewServiceFactory(producer ServiceContextProducer, config *configuration.ConfigurationData, options ...Option) *ServiceFactory {
 	f := &ServiceFactory{contextProducer: producer, config: config}
 	// default function to return an instance of WIT Service
 	f.witServiceFunc = func() service.WITService {
 		return witservice.NewWITService(f.getContext(), f.config)
 	}
+	// default function to return an instance of Notification Service
+	f.notificationServiceFunc = func() service.NotificationService {
+		return notificationservice.NewNotificationService(f.getContext(), f.config)
+	}
 	log.Info(nil, map[string]interface{}{}, "configuring a new service factory with %d options", len(options))
 	// and options
 	for _, opt := range options {
@@ -160,6 +178,10 @@ func (f *ServiceFactory) OrganizationService() service.OrganizationService {
 	return organizationservice.NewOrganizationService(f.getContext())
 }
 
+func (f *ServiceFactory) AccessRequestService() service.AccessRequestService {
+	return accessrequestservice.NewAccessRequestService(f.getContext())
+}
+
 func (f *ServiceFactory) InvitationService() service.InvitationService {
 	return invitationservice.NewInvitationService(f.getContext(), f.config)
 }
@@ -172,10 +194,18 @@ func (f *ServiceFactory) ResourceService() service.ResourceService {
 	return resourceservice.NewResourceService(f.getContext())
 }
 
+func (f *ServiceFactory) ResourceTypeService() service.ResourceTypeService {
+	return resourcetypeservice.NewResourceTypeService(f.getContext())
+}
+
 func (f *ServiceFactory) RoleManagementService() service.RoleManagementService {
 	return roleservice.NewRoleManagementService(f.getContext())
 }
 
+func (f *ServiceFactory) RoleMappingService() service.RoleMappingService {
+	return roleservice.NewRoleMappingService(f.getContext())
+}
+
 func (f *ServiceFactory) SpaceService() service.SpaceService {
 	return spaceservice.NewSpaceService(f.getContext())
 }
@@ -184,14 +214,22 @@ func (f *ServiceFactory) TeamService() service.TeamService {
 	return teamservice.NewTeamService(f.getContext())
 }
 
+func (f *ServiceFactory) GroupService() service.GroupService {
+	return groupservice.NewGroupService(f.getContext())
+}
+
 func (f *ServiceFactory) UserService() service.UserService {
 	return userservice.NewUserService(f.getContext())
 }
 
 func (f *ServiceFactory) NotificationService() service.NotificationService {
-	return notificationservice.NewNotificationService(f.getContext(), f.config)
+	return f.notificationServiceFunc()
 }
 
 func (f *ServiceFactory) WITService() service.WITService {
 	return f.witServiceFunc()
 }
+
+func (f *ServiceFactory) ServiceAccountService() service.ServiceAccountService {
+	return serviceaccountservice.NewServiceAccountService(f.getContext(), f.config)
+}
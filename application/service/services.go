@@ -2,12 +2,18 @@ package service
 
 import (
 	"context"
+	"time"
 
 	account "github.com/fabric8-services/fabric8-auth/account/repository"
+	serviceaccount "github.com/fabric8-services/fabric8-auth/account/serviceaccount/repository"
 	"github.com/fabric8-services/fabric8-auth/app"
 	"github.com/fabric8-services/fabric8-auth/authorization"
+	accessrequest "github.com/fabric8-services/fabric8-auth/authorization/accessrequest/repository"
 	"github.com/fabric8-services/fabric8-auth/authorization/invitation"
+	invitationrepo "github.com/fabric8-services/fabric8-auth/authorization/invitation/repository"
+	"github.com/fabric8-services/fabric8-auth/authorization/organization"
 	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
+	resourcetype "github.com/fabric8-services/fabric8-auth/authorization/resourcetype/repository"
 	"github.com/fabric8-services/fabric8-auth/authorization/role"
 	rolerepo "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
 	"github.com/fabric8-services/fabric8-auth/notification"
@@ -28,6 +34,17 @@ Steps for adding a new Service:
    and use the factory method from the step #4
 */
 
+type AccessRequestService interface {
+	// Create files a request from a user for access to a space, optionally naming the desired role.
+	Create(ctx context.Context, requesterID uuid.UUID, spaceID string, roleName *string, message *string) (*accessrequest.AccessRequest, error)
+	// ListPending returns the pending access requests for a space.
+	ListPending(ctx context.Context, listingUserID uuid.UUID, spaceID string) ([]accessrequest.AccessRequest, error)
+	// Approve approves a pending access request and assigns the requesting user with a role in the space.
+	Approve(ctx context.Context, approverID uuid.UUID, spaceID string, accessRequestID uuid.UUID, roleName *string) error
+	// Deny denies a pending access request.
+	Deny(ctx context.Context, denierID uuid.UUID, spaceID string, accessRequestID uuid.UUID) error
+}
+
 type InvitationService interface {
 	// Issue creates a new invitation for a user.
 	Issue(ctx context.Context, issuingUserID uuid.UUID, inviteTo string, invitations []invitation.Invitation) error
@@ -35,41 +52,109 @@ type InvitationService interface {
 	Rescind(ctx context.Context, rescindingUserID, invitationID uuid.UUID) error
 	// Accept processes the invitation acceptance action from the user, converting the invitation into real memberships/roles
 	Accept(ctx context.Context, currentIdentityID uuid.UUID, token uuid.UUID) (string, error)
+	// BindEmailInvitations binds the pending invitations addressed to an e-mail address to the identity of the user.
+	BindEmailInvitations(ctx context.Context, identityID uuid.UUID, email string) error
+	// Resend renews the acceptance code and the expiry of an invitation, and notifies the invited user again.
+	Resend(ctx context.Context, resendingUserID, invitationID uuid.UUID) error
+	// Decline deletes an invitation on behalf of the invited user.
+	Decline(ctx context.Context, decliningUserID, invitationID uuid.UUID) error
+	// ListForInviteTo returns the pending invitations to an organization, team, security group or resource.
+	ListForInviteTo(ctx context.Context, listingUserID uuid.UUID, inviteTo string) ([]invitationrepo.Invitation, error)
+	// ListForUser returns the pending invitations issued to a user.
+	ListForUser(ctx context.Context, identityID uuid.UUID) ([]invitationrepo.Invitation, error)
+	// DeleteExpiredInvitations deletes the invitations which have expired.
+	DeleteExpiredInvitations(ctx context.Context) (int, error)
 }
 
 type OrganizationService interface {
 	CreateOrganization(ctx context.Context, creatorIdentityID uuid.UUID, organizationName string) (*uuid.UUID, error)
 	ListOrganizations(ctx context.Context, identityID uuid.UUID) ([]authorization.IdentityAssociation, error)
+	ListMembers(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID) ([]organization.Member, error)
+	RemoveMember(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID, memberID uuid.UUID) error
+	ChangeMemberRole(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID, memberID uuid.UUID, roleName string) error
+	Leave(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID) error
+	UpdateOrganization(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID, organizationName string) error
+	DeleteOrganization(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID) error
 }
 
 type PermissionService interface {
 	HasScope(ctx context.Context, identityID uuid.UUID, resourceID string, scopeName string) (bool, error)
 	RequireScope(ctx context.Context, identityID uuid.UUID, resourceID string, scopeName string) error
+	HasScopes(ctx context.Context, checks []authorization.PermissionCheck) ([]bool, error)
+	ListScopes(ctx context.Context, identityID uuid.UUID, resourceID string) ([]string, error)
+	ExplainScope(ctx context.Context, identityID uuid.UUID, resourceID string, scopeName string) (*authorization.PermissionExplanation, error)
+	ListResourcesWithScope(ctx context.Context, identityID uuid.UUID, resourceTypeName string, scopeName string, offset int, limit int) ([]resource.Resource, int, error)
 }
 
 type ResourceService interface {
 	Delete(ctx context.Context, resourceID string) error
 	Read(ctx context.Context, resourceID string) (*app.Resource, error)
 	Register(ctx context.Context, resourceTypeName string, resourceID, parentResourceID *string) (*resource.Resource, error)
+	ApplyDefaultRoleMappings(ctx context.Context, res *resource.Resource) error
+	Move(ctx context.Context, resourceID string, parentResourceID *string) error
+	List(ctx context.Context, resourceTypeName, parentResourceID, namePrefix *string, createdAfter, createdBefore *time.Time, offset, limit int) ([]resource.Resource, int, error)
+	TransferOwnership(ctx context.Context, transferredBy uuid.UUID, resourceID string, fromIdentityID uuid.UUID, toIdentityID uuid.UUID, includeDescendants bool) error
+}
+
+type ResourceTypeService interface {
+	List(ctx context.Context) ([]resourcetype.ResourceType, error)
+	Load(ctx context.Context, resourceTypeID uuid.UUID) (*resourcetype.ResourceType, error)
+	Create(ctx context.Context, name string, scopeNames []string) (*resourcetype.ResourceType, error)
+	Delete(ctx context.Context, resourceTypeID uuid.UUID) error
+	ListScopes(ctx context.Context, resourceTypeID uuid.UUID) ([]resourcetype.ResourceTypeScope, error)
+	AddScope(ctx context.Context, resourceTypeID uuid.UUID, scopeName string) (*resourcetype.ResourceTypeScope, error)
+	DeleteScope(ctx context.Context, resourceTypeID uuid.UUID, scopeName string) error
+	ListRoles(ctx context.Context, resourceTypeID uuid.UUID) ([]role.RoleDescriptor, error)
+	CreateRole(ctx context.Context, resourceTypeID uuid.UUID, roleName string, scopeNames []string) (*rolerepo.Role, error)
+	DeleteRole(ctx context.Context, resourceTypeID uuid.UUID, roleName string) error
+	AddRoleScope(ctx context.Context, resourceTypeID uuid.UUID, roleName string, scopeName string) error
+	RemoveRoleScope(ctx context.Context, resourceTypeID uuid.UUID, roleName string, scopeName string) error
 }
 
 type RoleManagementService interface {
 	ListByResource(ctx context.Context, currentIdentity uuid.UUID, resourceID string) ([]rolerepo.IdentityRole, error)
 	ListAvailableRolesByResourceType(ctx context.Context, resourceType string) ([]role.RoleDescriptor, error)
 	ListByResourceAndRoleName(ctx context.Context, currentIdentity uuid.UUID, resourceID string, roleName string) ([]rolerepo.IdentityRole, error)
-	Assign(ctx context.Context, assignedBy uuid.UUID, roleAssignments map[string][]uuid.UUID, resourceID string, appendToExistingRoles bool) error
+	Assign(ctx context.Context, assignedBy uuid.UUID, roleAssignments map[string][]uuid.UUID, roleExpiries map[string]time.Time, resourceID string, appendToExistingRoles bool) error
+	AssignApprovedAccess(ctx context.Context, approvedBy uuid.UUID, identityID uuid.UUID, roleName string, resourceID string) error
 	ForceAssign(ctx context.Context, assignedTo uuid.UUID, roleName string, res resource.Resource) error
 	RevokeResourceRoles(ctx context.Context, currentIdentity uuid.UUID, identities []uuid.UUID, resourceID string) error
+	RevokeResourceRole(ctx context.Context, currentIdentity uuid.UUID, identities []uuid.UUID, resourceID string, roleName string) error
+	DeleteExpiredRoles(ctx context.Context) (int, error)
+}
+
+type RoleMappingService interface {
+	ListByResource(ctx context.Context, currentIdentity uuid.UUID, resourceID string) ([]rolerepo.RoleMapping, error)
+	Create(ctx context.Context, currentIdentity uuid.UUID, resourceID string, from role.RoleReference, to role.RoleReference) (*rolerepo.RoleMapping, error)
+	Delete(ctx context.Context, currentIdentity uuid.UUID, resourceID string, roleMappingID uuid.UUID) error
+	ListDefaultByResourceType(ctx context.Context, resourceTypeID uuid.UUID) ([]rolerepo.DefaultRoleMapping, error)
+	CreateDefault(ctx context.Context, resourceTypeID uuid.UUID, from role.RoleReference, to role.RoleReference) (*rolerepo.DefaultRoleMapping, error)
+	DeleteDefault(ctx context.Context, resourceTypeID uuid.UUID, defaultRoleMappingID uuid.UUID) error
 }
 
 type TeamService interface {
 	CreateTeam(ctx context.Context, identityID uuid.UUID, spaceID string, teamName string) (*uuid.UUID, error)
 	ListTeamsInSpace(ctx context.Context, identityID uuid.UUID, spaceID string) ([]account.Identity, error)
 	ListTeamsForIdentity(ctx context.Context, identityID uuid.UUID) ([]authorization.IdentityAssociation, error)
+	ListMembers(ctx context.Context, identityID uuid.UUID, teamID uuid.UUID) ([]account.Identity, error)
+	AddMembers(ctx context.Context, identityID uuid.UUID, teamID uuid.UUID, memberIDs []uuid.UUID) error
+	RemoveMembers(ctx context.Context, identityID uuid.UUID, teamID uuid.UUID, memberIDs []uuid.UUID) error
+	UpdateTeam(ctx context.Context, identityID uuid.UUID, teamID uuid.UUID, teamName string) error
+	DeleteTeam(ctx context.Context, identityID uuid.UUID, teamID uuid.UUID) error
+}
+
+type GroupService interface {
+	CreateGroup(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID, groupName string) (*uuid.UUID, error)
+	ListGroupsInOrganization(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID) ([]account.Identity, error)
+	ListGroupsForIdentity(ctx context.Context, identityID uuid.UUID) ([]authorization.IdentityAssociation, error)
+	ListMembers(ctx context.Context, identityID uuid.UUID, groupID uuid.UUID) ([]account.Identity, error)
+	AddMembers(ctx context.Context, identityID uuid.UUID, groupID uuid.UUID, memberIDs []uuid.UUID) error
+	RemoveMembers(ctx context.Context, identityID uuid.UUID, groupID uuid.UUID, memberIDs []uuid.UUID) error
+	AssignRole(ctx context.Context, identityID uuid.UUID, groupID uuid.UUID, resourceID string, roleName string) error
 }
 
 type SpaceService interface {
-	CreateSpace(ctx context.Context, spaceCreatorIdentityID uuid.UUID, spaceID string) error
+	CreateSpace(ctx context.Context, spaceCreatorIdentityID uuid.UUID, spaceID string, organizationID *uuid.UUID) error
 	DeleteSpace(ctx context.Context, byIdentityID uuid.UUID, spaceID string) error
 }
 
@@ -89,16 +174,36 @@ type WITService interface {
 	GetSpace(ctx context.Context, spaceID string) (space *wit.Space, e error)
 }
 
+type ServiceAccountService interface {
+	// Create creates a service account along with its first secret, whose plain value is returned.
+	Create(ctx context.Context, id *uuid.UUID, name string) (*serviceaccount.ServiceAccount, *serviceaccount.ServiceAccountSecret, string, error)
+	// List returns the service accounts stored in the database.
+	List(ctx context.Context) ([]serviceaccount.ServiceAccount, error)
+	// Load returns a service account along with its secrets.
+	Load(ctx context.Context, id uuid.UUID) (*serviceaccount.ServiceAccount, []serviceaccount.ServiceAccountSecret, error)
+	// AddSecret generates a new secret for a service account and returns its plain value.
+	AddSecret(ctx context.Context, id uuid.UUID) (*serviceaccount.ServiceAccountSecret, string, error)
+	// RetireSecret retires a secret of a service account, which is still accepted during the overlap period.
+	RetireSecret(ctx context.Context, id uuid.UUID, secretID uuid.UUID, overlap *time.Duration) (*serviceaccount.ServiceAccountSecret, error)
+	// Authenticate checks the client credentials of a service account and returns its ID and name.
+	Authenticate(ctx context.Context, clientID string, clientSecret string) (string, string, error)
+}
+
 //Services creates instances of service layer objects
 type Services interface {
+	AccessRequestService() AccessRequestService
 	InvitationService() InvitationService
 	OrganizationService() OrganizationService
 	ResourceService() ResourceService
+	ResourceTypeService() ResourceTypeService
 	PermissionService() PermissionService
 	RoleManagementService() RoleManagementService
+	RoleMappingService() RoleMappingService
 	TeamService() TeamService
+	GroupService() GroupService
 	SpaceService() SpaceService
 	UserService() UserService
 	NotificationService() NotificationService
 	WITService() WITService
+	ServiceAccountService() ServiceAccountService
 }
@@ -0,0 +1,3 @@
+// The accessrequest package provides features relating to the management of access requests; a request filed by a user
+// who wants to be granted a role in a space
+package accessrequest
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	rolerepo "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/gormsupport"
+	"github.com/fabric8-services/fabric8-auth/log"
+
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+)
+
+const (
+	// StatusPending is the status of an access request which has not been decided yet
+	StatusPending = "pending"
+	// StatusApproved is the status of an access request which has been approved by an administrator of the resource
+	StatusApproved = "approved"
+	// StatusDenied is the status of an access request which has been denied by an administrator of the resource
+	StatusDenied = "denied"
+)
+
+// AccessRequest is a request filed by a user who wants to be granted a role in a resource
+type AccessRequest struct {
+	gormsupport.Lifecycle
+
+	// This is the primary key value
+	AccessRequestID uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key;column:access_request_id"`
+	// The resource which the user requests access to
+	ResourceID string `gorm:"column:resource_id"`
+	// The identity of the user who filed the request
+	IdentityID uuid.UUID `sql:"type:uuid" gorm:"column:identity_id"`
+	// The role which the user would like to be granted, if any
+	Role   *rolerepo.Role `gorm:"ForeignKey:RoleID;AssociationForeignKey:RoleID"`
+	RoleID *uuid.UUID     `sql:"type:uuid" gorm:"column:role_id"`
+	// An optional message from the user to the administrators of the resource
+	Message *string `gorm:"column:message"`
+	// The status of the request, one of StatusPending, StatusApproved or StatusDenied
+	Status string `gorm:"column:status"`
+	// The identity of the administrator who approved or denied the request
+	DecidedBy *uuid.UUID `sql:"type:uuid" gorm:"column:decided_by"`
+	// The time at which the request was approved or denied
+	DecidedAt *time.Time `gorm:"column:decided_at"`
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m AccessRequest) TableName() string {
+	return "access_request"
+}
+
+// GetLastModified returns the last modification time
+func (m AccessRequest) GetLastModified() time.Time {
+	return m.UpdatedAt
+}
+
+// GormAccessRequestRepository is the implementation of the storage interface for AccessRequest.
+type GormAccessRequestRepository struct {
+	db *gorm.DB
+}
+
+// NewAccessRequestRepository creates a new storage type.
+func NewAccessRequestRepository(db *gorm.DB) AccessRequestRepository {
+	return &GormAccessRequestRepository{db: db}
+}
+
+// AccessRequestRepository represents the storage interface.
+type AccessRequestRepository interface {
+	Load(ctx context.Context, id uuid.UUID) (*AccessRequest, error)
+	Create(ctx context.Context, request *AccessRequest) error
+	Save(ctx context.Context, request *AccessRequest) error
+	ListForResource(ctx context.Context, resourceID string, status string) ([]AccessRequest, error)
+	FindPending(ctx context.Context, resourceID string, identityID uuid.UUID) (*AccessRequest, error)
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m *GormAccessRequestRepository) TableName() string {
+	return "access_request"
+}
+
+// Load returns the access request for the given id, with its requested role
+func (m *GormAccessRequestRepository) Load(ctx context.Context, id uuid.UUID) (*AccessRequest, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "access_request", "load"}, time.Now())
+	var native AccessRequest
+	err := m.db.Table(m.TableName()).Preload("Role").Where("access_request_id = ?", id).Find(&native).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewNotFoundError("access request", id.String())
+	}
+	return &native, errs.WithStack(err)
+}
+
+// Create creates a new record.
+func (m *GormAccessRequestRepository) Create(ctx context.Context, request *AccessRequest) error {
+	defer goa.MeasureSince([]string{"goa", "db", "access_request", "create"}, time.Now())
+	if request.AccessRequestID == uuid.Nil {
+		request.AccessRequestID = uuid.NewV4()
+	}
+	if request.Status == "" {
+		request.Status = StatusPending
+	}
+	err := m.db.Create(request).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_id": request.ResourceID,
+			"identity_id": request.IdentityID,
+			"err":         err,
+		}, "unable to create the access request")
+		if gormsupport.IsUniqueViolation(err, "idx_access_request_pending") {
+			return errors.NewDataConflictError("the identity has a pending access request for the resource already")
+		}
+		return errs.WithStack(err)
+	}
+	log.Debug(ctx, map[string]interface{}{
+		"access_request_id": request.AccessRequestID,
+		"resource_id":       request.ResourceID,
+	}, "Access request created!")
+	return nil
+}
+
+// Save modifies a single record.
+func (m *GormAccessRequestRepository) Save(ctx context.Context, request *AccessRequest) error {
+	defer goa.MeasureSince([]string{"goa", "db", "access_request", "save"}, time.Now())
+
+	obj, err := m.Load(ctx, request.AccessRequestID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"access_request_id": request.AccessRequestID,
+			"err":               err,
+		}, "unable to update access request")
+		return errs.WithStack(err)
+	}
+	err = m.db.Model(obj).Updates(request).Error
+	if err != nil {
+		return errs.WithStack(err)
+	}
+
+	log.Debug(ctx, map[string]interface{}{
+		"access_request_id": request.AccessRequestID,
+	}, "Access request saved!")
+	return nil
+}
+
+// ListForResource returns the access requests for the specified resource which have the given status, oldest first
+func (m *GormAccessRequestRepository) ListForResource(ctx context.Context, resourceID string, status string) ([]AccessRequest, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "access_request", "listForResource"}, time.Now())
+	var rows []AccessRequest
+
+	err := m.db.Model(&AccessRequest{}).Preload("Role").Where("resource_id = ? AND status = ?", resourceID, status).Order("created_at").Find(&rows).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errs.WithStack(err)
+	}
+	return rows, nil
+}
+
+// FindPending returns the pending access request filed by the specified identity for the resource
+func (m *GormAccessRequestRepository) FindPending(ctx context.Context, resourceID string, identityID uuid.UUID) (*AccessRequest, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "access_request", "findPending"}, time.Now())
+	var native AccessRequest
+	err := m.db.Table(m.TableName()).Preload("Role").Where("resource_id = ? AND identity_id = ? AND status = ?", resourceID, identityID, StatusPending).Find(&native).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewNotFoundErrorWithKey("access request", "identity_id", identityID.String())
+	}
+	return &native, errs.WithStack(err)
+}
@@ -0,0 +1,104 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	accessrequest "github.com/fabric8-services/fabric8-auth/authorization/accessrequest/repository"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
+
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type accessRequestBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	repo accessrequest.AccessRequestRepository
+}
+
+func TestRunAccessRequestBlackBoxTest(t *testing.T) {
+	suite.Run(t, &accessRequestBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *accessRequestBlackBoxTest) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.repo = accessrequest.NewAccessRequestRepository(s.DB)
+}
+
+func (s *accessRequestBlackBoxTest) TestCreateAndLoad() {
+	space := s.Graph.CreateSpace()
+	user := s.Graph.CreateUser()
+	r := s.Graph.CreateRole(s.Graph.LoadResourceType(authorization.ResourceTypeSpace))
+	message := "let me in"
+
+	request := accessrequest.AccessRequest{
+		ResourceID: space.SpaceID(),
+		IdentityID: user.IdentityID(),
+		RoleID:     &r.Role().RoleID,
+		Message:    &message,
+	}
+	err := s.repo.Create(s.Ctx, &request)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), accessrequest.StatusPending, request.Status)
+
+	loaded, err := s.repo.Load(s.Ctx, request.AccessRequestID)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), space.SpaceID(), loaded.ResourceID)
+	require.Equal(s.T(), user.IdentityID(), loaded.IdentityID)
+	require.NotNil(s.T(), loaded.Role)
+	require.Equal(s.T(), r.Role().Name, loaded.Role.Name)
+	require.Equal(s.T(), message, *loaded.Message)
+
+	// Only one pending request per user and resource
+	err = s.repo.Create(s.Ctx, &accessrequest.AccessRequest{
+		ResourceID: space.SpaceID(),
+		IdentityID: user.IdentityID(),
+	})
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.DataConflictError{}, err)
+}
+
+func (s *accessRequestBlackBoxTest) TestLoadUnknownFails() {
+	id := uuid.NewV4()
+	_, err := s.repo.Load(s.Ctx, id)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, err)
+}
+
+func (s *accessRequestBlackBoxTest) TestListPendingAndApproved() {
+	space := s.Graph.CreateSpace()
+	user := s.Graph.CreateUser()
+	request := accessrequest.AccessRequest{
+		ResourceID: space.SpaceID(),
+		IdentityID: user.IdentityID(),
+	}
+	err := s.repo.Create(s.Ctx, &request)
+	require.NoError(s.T(), err)
+	// noise
+	err = s.repo.Create(s.Ctx, &accessrequest.AccessRequest{
+		ResourceID: s.Graph.CreateSpace().SpaceID(),
+		IdentityID: user.IdentityID(),
+	})
+	require.NoError(s.T(), err)
+
+	requests, err := s.repo.ListForResource(s.Ctx, space.SpaceID(), accessrequest.StatusPending)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), requests, 1)
+	require.Equal(s.T(), request.AccessRequestID, requests[0].AccessRequestID)
+
+	pending, err := s.repo.FindPending(s.Ctx, space.SpaceID(), user.IdentityID())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), request.AccessRequestID, pending.AccessRequestID)
+
+	request.Status = accessrequest.StatusApproved
+	err = s.repo.Save(s.Ctx, &request)
+	require.NoError(s.T(), err)
+
+	requests, err = s.repo.ListForResource(s.Ctx, space.SpaceID(), accessrequest.StatusPending)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), requests)
+	_, err = s.repo.FindPending(s.Ctx, space.SpaceID(), user.IdentityID())
+	require.IsType(s.T(), errors.NotFoundError{}, err)
+}
@@ -0,0 +1,2 @@
+// Package repository provides the APIs for making 'access request' related database interactions.
+package repository
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fabric8-services/fabric8-auth/application/service"
+	"github.com/fabric8-services/fabric8-auth/application/service/base"
+	servicecontext "github.com/fabric8-services/fabric8-auth/application/service/context"
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	accessrequest "github.com/fabric8-services/fabric8-auth/authorization/accessrequest/repository"
+	"github.com/fabric8-services/fabric8-auth/authorization/permission/cache"
+	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
+	"github.com/fabric8-services/fabric8-auth/authorization/role"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/log"
+	"github.com/fabric8-services/fabric8-auth/notification"
+
+	"github.com/satori/go.uuid"
+)
+
+// accessRequestServiceImpl is the default implementation of AccessRequestService. It is a private struct and should only
+// be instantiated via the NewAccessRequestService() function.
+type accessRequestServiceImpl struct {
+	base.BaseService
+}
+
+// NewAccessRequestService creates a new service to manage the requests for access to spaces
+func NewAccessRequestService(context servicecontext.ServiceContext) service.AccessRequestService {
+	return &accessRequestServiceImpl{base.NewBaseService(context)}
+}
+
+// Create files a request from the specified user for access to a space, optionally naming the role which the user would
+// like to be granted. The administrators of the space are notified of the new request.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *accessRequestServiceImpl) Create(ctx context.Context, requesterID uuid.UUID, spaceID string, roleName *string, message *string) (*accessrequest.AccessRequest, error) {
+	space, err := s.loadSpace(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &accessrequest.AccessRequest{
+		ResourceID: spaceID,
+		IdentityID: requesterID,
+		Message:    message,
+		Status:     accessrequest.StatusPending,
+	}
+	if roleName != nil {
+		r, err := s.lookupAvailableRole(ctx, *roleName)
+		if err != nil {
+			return nil, err
+		}
+		roleID, err := uuid.FromString(r.RoleID)
+		if err != nil {
+			return nil, errors.NewInternalError(ctx, err)
+		}
+		request.RoleID = &roleID
+	}
+
+	requester, err := s.Repositories().Identities().Load(ctx, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.Repositories().IdentityRoleRepository().FindIdentityRolesByIdentityAndResource(ctx, spaceID, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) > 0 {
+		return nil, errors.NewDataConflictError(fmt.Sprintf("identity %s has a role in space %s already", requesterID, spaceID))
+	}
+
+	err = s.ExecuteInTransaction(func() error {
+		return s.Repositories().AccessRequestRepository().Create(ctx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Reload the request along with its requested role
+	request, err = s.Repositories().AccessRequestRepository().Load(ctx, request.AccessRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"access_request_id": request.AccessRequestID,
+		"space_id":          spaceID,
+		"identity_id":       requesterID,
+	}, "access to space requested")
+
+	var requestedRoleName, requestMessage string
+	if roleName != nil {
+		requestedRoleName = *roleName
+	}
+	if message != nil {
+		requestMessage = *message
+	}
+
+	admins, err := s.Repositories().IdentityRoleRepository().FindIdentityRolesByResourceAndRoleName(ctx, spaceID, authorization.SpaceAdminRole, false)
+	if err != nil {
+		// The request has been committed already, so a failure to notify the administrators is not reported to the caller
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"err":      err,
+		}, "unable to look up the administrators of the space")
+		return request, nil
+	}
+	var messages []notification.Message
+	for _, admin := range admins {
+		if admin.Identity.UserID.Valid {
+			messages = append(messages, notification.NewSpaceAccessRequestedEmail(admin.Identity.UserID.UUID.String(),
+				spaceID,
+				space.Name,
+				requester.Username,
+				requestedRoleName,
+				requestMessage))
+		}
+	}
+	s.notify(ctx, spaceID, messages)
+
+	return request, nil
+}
+
+// ListPending returns the pending access requests for the specified space. The listing user must be allowed to manage
+// the role assignments of the space.
+func (s *accessRequestServiceImpl) ListPending(ctx context.Context, listingUserID uuid.UUID, spaceID string) ([]accessrequest.AccessRequest, error) {
+	_, err := s.loadSpace(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Services().PermissionService().RequireScope(ctx, listingUserID, spaceID, authorization.ManageRoleAssignmentsInSpaceScope)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Repositories().AccessRequestRepository().ListForResource(ctx, spaceID, accessrequest.StatusPending)
+}
+
+// Approve approves a pending access request for the specified space, and assigns the requesting user with a role in
+// the space. The role is the one specified by roleName if any, otherwise the one named in the request, otherwise the
+// space viewer role. The approving user must be allowed to manage the role assignments of the space. The requesting
+// user is notified of the approval.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *accessRequestServiceImpl) Approve(ctx context.Context, approverID uuid.UUID, spaceID string, accessRequestID uuid.UUID, roleName *string) error {
+	space, request, err := s.loadPendingRequest(ctx, approverID, spaceID, accessRequestID)
+	if err != nil {
+		return err
+	}
+
+	assignedRoleName := authorization.SpaceViewerRole
+	if roleName != nil {
+		assignedRoleName = *roleName
+	} else if request.Role != nil {
+		assignedRoleName = request.Role.Name
+	}
+	_, err = s.lookupAvailableRole(ctx, assignedRoleName)
+	if err != nil {
+		return err
+	}
+
+	err = s.ExecuteInTransaction(func() error {
+		err := s.decide(ctx, request, approverID, accessrequest.StatusApproved)
+		if err != nil {
+			return err
+		}
+
+		return s.Services().RoleManagementService().AssignApprovedAccess(ctx, approverID, request.IdentityID, assignedRoleName, spaceID)
+	})
+	if err != nil {
+		return err
+	}
+	// The role assignment invalidated the cache before the approval was committed, so that a concurrent permission
+	// check may have cached the permissions of the requester from before the approval
+	cache.Invalidate()
+
+	log.Info(ctx, map[string]interface{}{
+		"access_request_id": accessRequestID,
+		"space_id":          spaceID,
+		"identity_id":       request.IdentityID,
+		"role_name":         assignedRoleName,
+		"decided_by":        approverID,
+	}, "access request approved")
+
+	requester, err := s.Repositories().Identities().Load(ctx, request.IdentityID)
+	if err == nil && requester.UserID.Valid {
+		s.notify(ctx, spaceID, []notification.Message{notification.NewSpaceAccessRequestApprovedEmail(requester.UserID.UUID.String(),
+			spaceID,
+			space.Name,
+			assignedRoleName,
+			s.identityName(ctx, approverID))})
+	}
+	return nil
+}
+
+// Deny denies a pending access request for the specified space. The denying user must be allowed to manage the role
+// assignments of the space. The requesting user is notified of the denial.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *accessRequestServiceImpl) Deny(ctx context.Context, denierID uuid.UUID, spaceID string, accessRequestID uuid.UUID) error {
+	space, request, err := s.loadPendingRequest(ctx, denierID, spaceID, accessRequestID)
+	if err != nil {
+		return err
+	}
+
+	err = s.ExecuteInTransaction(func() error {
+		return s.decide(ctx, request, denierID, accessrequest.StatusDenied)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"access_request_id": accessRequestID,
+		"space_id":          spaceID,
+		"identity_id":       request.IdentityID,
+		"decided_by":        denierID,
+	}, "access request denied")
+
+	requester, err := s.Repositories().Identities().Load(ctx, request.IdentityID)
+	if err == nil && requester.UserID.Valid {
+		s.notify(ctx, spaceID, []notification.Message{notification.NewSpaceAccessRequestDeniedEmail(requester.UserID.UUID.String(),
+			spaceID,
+			space.Name,
+			s.identityName(ctx, denierID))})
+	}
+	return nil
+}
+
+// loadSpace loads the space resource with the specified identifier
+func (s *accessRequestServiceImpl) loadSpace(ctx context.Context, spaceID string) (*resource.Resource, error) {
+	res, err := s.Repositories().ResourceRepository().Load(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	if res.ResourceType.Name != authorization.ResourceTypeSpace {
+		return nil, errors.NewNotFoundError("space", spaceID)
+	}
+	return res, nil
+}
+
+// loadPendingRequest loads the space and the pending access request with the specified identifiers, after checking
+// that the user is allowed to manage the role assignments of the space
+func (s *accessRequestServiceImpl) loadPendingRequest(ctx context.Context, userID uuid.UUID, spaceID string, accessRequestID uuid.UUID) (*resource.Resource, *accessrequest.AccessRequest, error) {
+	space, err := s.loadSpace(ctx, spaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = s.Services().PermissionService().RequireScope(ctx, userID, spaceID, authorization.ManageRoleAssignmentsInSpaceScope)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	request, err := s.Repositories().AccessRequestRepository().Load(ctx, accessRequestID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if request.ResourceID != spaceID {
+		return nil, nil, errors.NewNotFoundError("access request", accessRequestID.String())
+	}
+	if request.Status != accessrequest.StatusPending {
+		return nil, nil, errors.NewBadParameterErrorFromString("accessRequestID", accessRequestID, fmt.Sprintf("the access request has been %s already", request.Status))
+	}
+	return space, request, nil
+}
+
+// lookupAvailableRole returns the space role with the specified name
+func (s *accessRequestServiceImpl) lookupAvailableRole(ctx context.Context, roleName string) (*role.RoleDescriptor, error) {
+	roles, err := s.Services().RoleManagementService().ListAvailableRolesByResourceType(ctx, authorization.ResourceTypeSpace)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range roles {
+		if r.RoleName == roleName {
+			return &r, nil
+		}
+	}
+	return nil, errors.NewBadParameterErrorFromString("role", roleName, fmt.Sprintf("no role named %s for spaces", roleName))
+}
+
+// decide records the decision of an administrator on an access request
+func (s *accessRequestServiceImpl) decide(ctx context.Context, request *accessrequest.AccessRequest, decidedBy uuid.UUID, status string) error {
+	now := time.Now()
+	request.Status = status
+	request.DecidedBy = &decidedBy
+	request.DecidedAt = &now
+	return s.Repositories().AccessRequestRepository().Save(ctx, request)
+}
+
+// identityName returns the username of the specified identity, or its identifier if it can not be loaded
+func (s *accessRequestServiceImpl) identityName(ctx context.Context, identityID uuid.UUID) string {
+	if identity, err := s.Repositories().Identities().Load(ctx, identityID); err == nil {
+		return identity.Username
+	}
+	return identityID.String()
+}
+
+// notify sends the messages about an access request. The access request has been committed already, so a failure to
+// notify the parties is not reported to the caller.
+func (s *accessRequestServiceImpl) notify(ctx context.Context, spaceID string, messages []notification.Message) {
+	if len(messages) == 0 {
+		return
+	}
+	err := s.Services().NotificationService().SendMessagesAsync(ctx, messages)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"err":      err,
+		}, "unable to notify the parties of the access request")
+	}
+}
@@ -0,0 +1,265 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-auth/application"
+	"github.com/fabric8-services/fabric8-auth/application/service/factory"
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	accessrequest "github.com/fabric8-services/fabric8-auth/authorization/accessrequest/repository"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/gormapplication"
+	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
+	testsupport "github.com/fabric8-services/fabric8-auth/test"
+
+	errs "github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type accessRequestServiceBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	notifications *testsupport.RecordingNotificationService
+	application   application.Application
+}
+
+func TestRunAccessRequestServiceBlackBoxTest(t *testing.T) {
+	suite.Run(t, &accessRequestServiceBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *accessRequestServiceBlackBoxTest) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.notifications = &testsupport.RecordingNotificationService{}
+	s.application = gormapplication.NewGormDB(s.DB, s.Configuration, factory.WithNotificationService(s.notifications))
+}
+
+func (s *accessRequestServiceBlackBoxTest) assertRole(t require.TestingT, spaceID string, identityID uuid.UUID, roleName string) {
+	r, err := s.Application.RoleRepository().Lookup(s.Ctx, roleName, authorization.ResourceTypeSpace)
+	require.NoError(t, err)
+	roles, err := s.Application.IdentityRoleRepository().FindIdentityRolesByIdentityAndResource(s.Ctx, spaceID, identityID)
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, r.RoleID, roles[0].RoleID)
+}
+
+func stringPointer(value string) *string {
+	return &value
+}
+
+func (s *accessRequestServiceBlackBoxTest) TestCreateAccessRequest() {
+	admin := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(admin)
+	requester := s.Graph.CreateUser()
+
+	request, err := s.application.AccessRequestService().Create(s.Ctx, requester.IdentityID(), space.SpaceID(), stringPointer(authorization.SpaceContributorRole), stringPointer("let me in"))
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), space.SpaceID(), request.ResourceID)
+	assert.Equal(s.T(), requester.IdentityID(), request.IdentityID)
+	assert.Equal(s.T(), accessrequest.StatusPending, request.Status)
+	require.NotNil(s.T(), request.Role)
+	assert.Equal(s.T(), authorization.SpaceContributorRole, request.Role.Name)
+
+	// The administrators of the space are notified
+	messages := s.notifications.Messages()
+	require.Len(s.T(), messages, 1)
+	assert.Equal(s.T(), "space.access.requested", messages[0].MessageType)
+	assert.Equal(s.T(), space.SpaceID(), messages[0].TargetID)
+	assert.Equal(s.T(), admin.Identity().UserID.UUID.String(), *messages[0].UserID)
+	assert.Equal(s.T(), requester.Identity().Username, messages[0].Custom["requester"])
+	assert.Equal(s.T(), authorization.SpaceContributorRole, messages[0].Custom["roleName"])
+	assert.Equal(s.T(), "let me in", messages[0].Custom["message"])
+
+	s.T().Run("pending request exists already", func(t *testing.T) {
+		_, err := s.application.AccessRequestService().Create(s.Ctx, requester.IdentityID(), space.SpaceID(), nil, nil)
+		require.Error(t, err)
+		require.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+}
+
+func (s *accessRequestServiceBlackBoxTest) TestCreateAccessRequestFails() {
+	space := s.Graph.CreateSpace()
+	requester := s.Graph.CreateUser()
+
+	s.T().Run("unknown role", func(t *testing.T) {
+		_, err := s.application.AccessRequestService().Create(s.Ctx, requester.IdentityID(), space.SpaceID(), stringPointer("foo"), nil)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		_, err := s.application.AccessRequestService().Create(s.Ctx, requester.IdentityID(), uuid.NewV4().String(), nil, nil)
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+
+	s.T().Run("not a space", func(t *testing.T) {
+		org := s.Graph.CreateOrganization()
+		_, err := s.application.AccessRequestService().Create(s.Ctx, requester.IdentityID(), org.ResourceID(), nil, nil)
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+
+	s.T().Run("already has a role", func(t *testing.T) {
+		contributor := s.Graph.CreateUser()
+		space.AddContributor(contributor)
+		_, err := s.application.AccessRequestService().Create(s.Ctx, contributor.IdentityID(), space.SpaceID(), nil, nil)
+		require.Error(t, err)
+		require.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+}
+
+func (s *accessRequestServiceBlackBoxTest) TestListPendingAccessRequests() {
+	admin := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(admin)
+	requester := s.Graph.CreateUser()
+	request, err := s.application.AccessRequestService().Create(s.Ctx, requester.IdentityID(), space.SpaceID(), nil, nil)
+	require.NoError(s.T(), err)
+	// noise
+	_, err = s.application.AccessRequestService().Create(s.Ctx, requester.IdentityID(), s.Graph.CreateSpace().SpaceID(), nil, nil)
+	require.NoError(s.T(), err)
+
+	requests, err := s.application.AccessRequestService().ListPending(s.Ctx, admin.IdentityID(), space.SpaceID())
+	require.NoError(s.T(), err)
+	require.Len(s.T(), requests, 1)
+	assert.Equal(s.T(), request.AccessRequestID, requests[0].AccessRequestID)
+
+	// Only the users who can manage the role assignments of the space can list the requests
+	_, err = s.application.AccessRequestService().ListPending(s.Ctx, requester.IdentityID(), space.SpaceID())
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.ForbiddenError{}, errs.Cause(err))
+}
+
+func (s *accessRequestServiceBlackBoxTest) TestApproveAccessRequest() {
+	admin := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(admin)
+	requester := s.Graph.CreateUser()
+	request, err := s.application.AccessRequestService().Create(s.Ctx, requester.IdentityID(), space.SpaceID(), stringPointer(authorization.SpaceContributorRole), nil)
+	require.NoError(s.T(), err)
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		err := s.application.AccessRequestService().Approve(s.Ctx, requester.IdentityID(), space.SpaceID(), request.AccessRequestID, nil)
+		require.Error(t, err)
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+	})
+
+	s.T().Run("unknown request", func(t *testing.T) {
+		err := s.application.AccessRequestService().Approve(s.Ctx, admin.IdentityID(), space.SpaceID(), uuid.NewV4(), nil)
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+
+	s.T().Run("request for another space", func(t *testing.T) {
+		otherSpace := s.Graph.CreateSpace().AddAdmin(admin)
+		err := s.application.AccessRequestService().Approve(s.Ctx, admin.IdentityID(), otherSpace.SpaceID(), request.AccessRequestID, nil)
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+
+	s.T().Run("ok", func(t *testing.T) {
+		err := s.application.AccessRequestService().Approve(s.Ctx, admin.IdentityID(), space.SpaceID(), request.AccessRequestID, nil)
+		require.NoError(t, err)
+
+		// The requester is granted the requested role
+		s.assertRole(t, space.SpaceID(), requester.IdentityID(), authorization.SpaceContributorRole)
+
+		approved, err := s.Application.AccessRequestRepository().Load(s.Ctx, request.AccessRequestID)
+		require.NoError(t, err)
+		assert.Equal(t, accessrequest.StatusApproved, approved.Status)
+		require.NotNil(t, approved.DecidedBy)
+		assert.Equal(t, admin.IdentityID(), *approved.DecidedBy)
+
+		// The requester is notified
+		messages := s.notifications.Messages()
+		msg := messages[len(messages)-1]
+		assert.Equal(t, "space.access.approved", msg.MessageType)
+		assert.Equal(t, requester.Identity().UserID.UUID.String(), *msg.UserID)
+		assert.Equal(t, authorization.SpaceContributorRole, msg.Custom["roleName"])
+		assert.Equal(t, admin.Identity().Username, msg.Custom["decidedBy"])
+	})
+
+	s.T().Run("already decided", func(t *testing.T) {
+		err := s.application.AccessRequestService().Approve(s.Ctx, admin.IdentityID(), space.SpaceID(), request.AccessRequestID, nil)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("approved request does not allow later assignments", func(t *testing.T) {
+		err := s.application.RoleManagementService().RevokeResourceRoles(s.Ctx, admin.IdentityID(), []uuid.UUID{requester.IdentityID()}, space.SpaceID())
+		require.NoError(t, err)
+
+		// Once the requester lost their role, the admin must invite them again
+		err = s.application.RoleManagementService().Assign(s.Ctx, admin.IdentityID(), map[string][]uuid.UUID{
+			authorization.SpaceAdminRole: {requester.IdentityID()},
+		}, nil, space.SpaceID(), false)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+}
+
+func (s *accessRequestServiceBlackBoxTest) TestApproveAccessRequestWithRole() {
+	admin := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(admin)
+	requester := s.Graph.CreateUser()
+	request, err := s.application.AccessRequestService().Create(s.Ctx, requester.IdentityID(), space.SpaceID(), nil, nil)
+	require.NoError(s.T(), err)
+
+	err = s.application.AccessRequestService().Approve(s.Ctx, admin.IdentityID(), space.SpaceID(), request.AccessRequestID, stringPointer("foo"))
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+
+	err = s.application.AccessRequestService().Approve(s.Ctx, admin.IdentityID(), space.SpaceID(), request.AccessRequestID, stringPointer(authorization.SpaceContributorRole))
+	require.NoError(s.T(), err)
+
+	s.assertRole(s.T(), space.SpaceID(), requester.IdentityID(), authorization.SpaceContributorRole)
+}
+
+func (s *accessRequestServiceBlackBoxTest) TestApproveAccessRequestWithDefaultRole() {
+	admin := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(admin)
+	requester := s.Graph.CreateUser()
+	request, err := s.application.AccessRequestService().Create(s.Ctx, requester.IdentityID(), space.SpaceID(), nil, nil)
+	require.NoError(s.T(), err)
+
+	err = s.application.AccessRequestService().Approve(s.Ctx, admin.IdentityID(), space.SpaceID(), request.AccessRequestID, nil)
+	require.NoError(s.T(), err)
+
+	s.assertRole(s.T(), space.SpaceID(), requester.IdentityID(), authorization.SpaceViewerRole)
+}
+
+func (s *accessRequestServiceBlackBoxTest) TestDenyAccessRequest() {
+	admin := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(admin)
+	requester := s.Graph.CreateUser()
+	request, err := s.application.AccessRequestService().Create(s.Ctx, requester.IdentityID(), space.SpaceID(), nil, nil)
+	require.NoError(s.T(), err)
+
+	err = s.application.AccessRequestService().Deny(s.Ctx, requester.IdentityID(), space.SpaceID(), request.AccessRequestID)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.ForbiddenError{}, errs.Cause(err))
+
+	err = s.application.AccessRequestService().Deny(s.Ctx, admin.IdentityID(), space.SpaceID(), request.AccessRequestID)
+	require.NoError(s.T(), err)
+
+	denied, err := s.Application.AccessRequestRepository().Load(s.Ctx, request.AccessRequestID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), accessrequest.StatusDenied, denied.Status)
+
+	// No role is assigned to the requester
+	roles, err := s.Application.IdentityRoleRepository().FindIdentityRolesByIdentityAndResource(s.Ctx, space.SpaceID(), requester.IdentityID())
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), roles)
+
+	messages := s.notifications.Messages()
+	msg := messages[len(messages)-1]
+	assert.Equal(s.T(), "space.access.denied", msg.MessageType)
+	assert.Equal(s.T(), requester.Identity().UserID.UUID.String(), *msg.UserID)
+
+	// A denied request can not be approved anymore, but the user may file a new one
+	err = s.application.AccessRequestService().Approve(s.Ctx, admin.IdentityID(), space.SpaceID(), request.AccessRequestID, nil)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	_, err = s.application.AccessRequestService().Create(s.Ctx, requester.IdentityID(), space.SpaceID(), nil, nil)
+	require.NoError(s.T(), err)
+}
@@ -0,0 +1,2 @@
+// Package service encapsulates the business logic for managing access requests
+package service
@@ -1,6 +1,8 @@
 package authorization
 
 import (
+	"time"
+
 	"github.com/satori/go.uuid"
 )
 
@@ -37,6 +39,9 @@ const (
 	// OrganizationAdminRole is the constant used to denote the name of the organization resource's administrator role
 	OrganizationAdminRole = adminRole
 
+	// OrganizationViewerRole is the constant used to denote the name of the organization resource's viewer role
+	OrganizationViewerRole = viewerRole
+
 	// SpaceAdminRole is the constant used to denote the name of a space resource's administrator role
 	SpaceAdminRole = adminRole
 
@@ -46,6 +51,18 @@ const (
 	// SpaceViewerRole is the constant used to denote the name of the space's viewer role
 	SpaceViewerRole = viewerRole
 
+	// TeamAdminRole is the constant used to denote the name of a team resource's administrator role
+	TeamAdminRole = adminRole
+
+	// TeamViewerRole is the constant used to denote the name of a team resource's viewer role
+	TeamViewerRole = viewerRole
+
+	// SecurityGroupAdminRole is the constant used to denote the name of a security group resource's administrator role
+	SecurityGroupAdminRole = adminRole
+
+	// SecurityGroupViewerRole is the constant used to denote the name of a security group resource's viewer role
+	SecurityGroupViewerRole = viewerRole
+
 	// viewSpaceScope is a general scope required to perform many space-related operations
 	viewSpaceScope = viewScope
 
@@ -70,6 +87,9 @@ const (
 	// ManageOrganizationMembersScope is the scope required for users wishing to manage members of an organization
 	ManageOrganizationMembersScope = manageScope
 
+	// ManageOrganizationScope is the scope required for users wishing to rename or delete an organization
+	ManageOrganizationScope = manageScope
+
 	// ManageTeamMembersScope is the scope required for users wishing to manage members of a team
 	ManageTeamMembersScope = manageScope
 
@@ -79,6 +99,15 @@ const (
 	// ViewTeamsInSpaceScope is the scope required for users wishing to view the teams in a space
 	ViewTeamsInSpaceScope = viewSpaceScope
 
+	// ManageSecurityGroupsInOrganizationScope is the scope required for users wishing to manage security groups for an organization
+	ManageSecurityGroupsInOrganizationScope = manageScope
+
+	// ViewSecurityGroupsInOrganizationScope is the scope required for users wishing to view the security groups in an organization
+	ViewSecurityGroupsInOrganizationScope = viewOrganizationScope
+
+	// ManageSpacesInOrganizationScope is the scope required for users wishing to create spaces owned by an organization
+	ManageSpacesInOrganizationScope = manageScope
+
 	// ManageRoleAssignmentsInSpaceScope is the scope required for managing role assignments in a space
 	ManageRoleAssignmentsInSpaceScope = manageScope
 
@@ -105,6 +134,53 @@ func CanHaveMembers(resourceTypeName string) bool {
 		resourceTypeName == IdentityResourceTypeGroup
 }
 
+// CanHaveParentOfType returns a boolean indicating whether a resource of the specified type may be a child of a resource
+// of the specified parent type. Organizations may not have any parent, spaces and security groups may only belong to
+// organizations and teams may only belong to spaces. There is no restriction for the other resource types.
+func CanHaveParentOfType(resourceTypeName string, parentResourceTypeName string) bool {
+	switch resourceTypeName {
+	case IdentityResourceTypeOrganization:
+		return false
+	case ResourceTypeSpace:
+		return parentResourceTypeName == IdentityResourceTypeOrganization
+	case IdentityResourceTypeTeam:
+		return parentResourceTypeName == ResourceTypeSpace
+	case IdentityResourceTypeGroup:
+		return parentResourceTypeName == IdentityResourceTypeOrganization
+	}
+	return true
+}
+
+// CanHaveDescendantOfType returns a boolean indicating whether a resource of the specified descendant type may be a
+// descendant of a resource of the specified type, given the names of all the resource types, i.e. whether there is a
+// chain of resource types from the latter to the former which may be children of each other.
+func CanHaveDescendantOfType(resourceTypeName string, descendantResourceTypeName string, resourceTypeNames []string) bool {
+	visited := map[string]bool{resourceTypeName: true}
+	parents := []string{resourceTypeName}
+	for len(parents) > 0 {
+		parent := parents[0]
+		parents = parents[1:]
+		for _, name := range resourceTypeNames {
+			if visited[name] || !CanHaveParentOfType(name, parent) {
+				continue
+			}
+			if name == descendantResourceTypeName {
+				return true
+			}
+			visited[name] = true
+			parents = append(parents, name)
+		}
+	}
+	return false
+}
+
+// CanHaveNoParent returns a boolean indicating whether a resource of the specified type may be a top level resource, i.e.
+// may have no parent. Teams and security groups always belong to a space or an organization, while the resources of the
+// other types may have no parent.
+func CanHaveNoParent(resourceTypeName string) bool {
+	return resourceTypeName != IdentityResourceTypeTeam && resourceTypeName != IdentityResourceTypeGroup
+}
+
 // ScopeForManagingRolesInResourceType returns the name of the scope that gives a user privileges to manage roles in a resource
 func ScopeForManagingRolesInResourceType(resourceType string) string {
 	switch resourceType {
@@ -137,6 +213,22 @@ func ScopeForViewingRolesInResourceType(resourceType string) string {
 	return ViewRoleAssignmentsInSpaceScope
 }
 
+// AdminRoleForResourceType returns the name of the administrator role of a resource type
+func AdminRoleForResourceType(resourceType string) string {
+	switch resourceType {
+	case ResourceTypeSpace:
+		return SpaceAdminRole
+	case IdentityResourceTypeOrganization:
+		return OrganizationAdminRole
+	case IdentityResourceTypeTeam:
+		return TeamAdminRole
+	case IdentityResourceTypeGroup:
+		return SecurityGroupAdminRole
+	}
+	// a default which we can choose to change later
+	return adminRole
+}
+
 // IdentityAssociation represents an association between an Identity and either another Identity or a Resource, whether by
 // membership or by having been granted a role.  It contains metadata about the Identity's relationship with the other
 // entity, including its membership state, and any roles it may have been assigned.
@@ -149,6 +241,62 @@ type IdentityAssociation struct {
 	Roles            []string
 }
 
+// PermissionCheck represents a single permission check, i.e. whether an Identity has been granted a specific scope for a Resource
+type PermissionCheck struct {
+	IdentityID uuid.UUID
+	ResourceID string
+	ScopeName  string
+}
+
+// PermissionCheckResult is the result of a PermissionCheck. ExpiresAt is the earliest expiry of the role assignments which
+// grant the scope, or nil if the scope has not been granted or none of these role assignments expires.
+type PermissionCheckResult struct {
+	Granted   bool
+	ExpiresAt *time.Time
+}
+
+// PermissionExplanation describes how an Identity has been granted a scope for a Resource. It contains a ScopeGrant for each
+// of the role assignments which grant the scope, and no grants at all if the scope has not been granted.
+type PermissionExplanation struct {
+	IdentityID uuid.UUID
+	ResourceID string
+	ScopeName  string
+	Granted    bool
+	Grants     []ScopeGrant
+}
+
+// ScopeGrant describes a single role assignment which grants a scope to an Identity, i.e. which role was assigned for which
+// resource (either the checked resource or one of its ancestors), through which memberships the Identity inherits the role,
+// and which role mappings translate it into a role granting the scope
+type ScopeGrant struct {
+	IdentityRoleID uuid.UUID
+	ResourceID     string
+	RoleName       string
+	// AssigneeID is the ID of the identity which has been assigned the role, either the checked Identity itself or a team,
+	// organization or security group it is a member of
+	AssigneeID uuid.UUID
+	// Memberships is the chain of memberships from the checked Identity to the assignee, empty if the role was assigned
+	// to the Identity itself
+	Memberships []MembershipStep
+	// RoleMappings is the chain of role mappings applied to the assigned role, empty if the assigned role grants the
+	// scope itself
+	RoleMappings []RoleMappingStep
+}
+
+// MembershipStep represents an identity (team, organization or security group) in a chain of memberships
+type MembershipStep struct {
+	IdentityID       uuid.UUID
+	ResourceTypeName string
+}
+
+// RoleMappingStep represents a role mapping in a chain of role mappings
+type RoleMappingStep struct {
+	RoleMappingID uuid.UUID
+	ResourceID    string
+	FromRoleName  string
+	ToRoleName    string
+}
+
 // AppendAssociation appends the association state specified by the parameter values to an existing IdentityAssociation array
 func AppendAssociation(associations []IdentityAssociation, resourceID string, resourceName *string, parentResourceID *string,
 	identityID *uuid.UUID, member bool, role *string) []IdentityAssociation {
@@ -0,0 +1,2 @@
+// Package service provides the code which encapsulates business logic for managing security groups
+package service
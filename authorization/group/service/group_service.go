@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	account "github.com/fabric8-services/fabric8-auth/account/repository"
+	"github.com/fabric8-services/fabric8-auth/application/service"
+	"github.com/fabric8-services/fabric8-auth/application/service/base"
+	servicecontext "github.com/fabric8-services/fabric8-auth/application/service/context"
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	"github.com/fabric8-services/fabric8-auth/authorization/permission/cache"
+	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
+	rolerepo "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
+	tokenrepo "github.com/fabric8-services/fabric8-auth/authorization/token/repository"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/log"
+	"github.com/satori/go.uuid"
+)
+
+// groupServiceImpl is the default implementation of GroupService. It is a private struct and should only be instantiated
+// via the NewGroupService() function.
+type groupServiceImpl struct {
+	base.BaseService
+}
+
+// NewGroupService creates a new service.
+func NewGroupService(context servicecontext.ServiceContext) service.GroupService {
+	return &groupServiceImpl{base.NewBaseService(context)}
+}
+
+// CreateGroup creates a new security group. The specified identityID is the user creating the group, and the organizationID
+// is the identity ID of the organization in which the group will be created. The name parameter specifies the group name.
+// The group's identity ID is returned. Also assigns the admin role of the group to its creator.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *groupServiceImpl) CreateGroup(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID, groupName string) (*uuid.UUID, error) {
+	var groupID uuid.UUID
+
+	err := s.ExecuteInTransaction(func() error {
+		// Validate the identity for the current user
+		identity, err := s.Repositories().Identities().LoadWithUser(ctx, identityID)
+		if err != nil {
+			return errors.NewUnauthorizedError(fmt.Sprintf("unknown Identity ID %s", identityID))
+		}
+
+		if identity.User.Deprovisioned {
+			return errors.NewUnauthorizedError(fmt.Sprintf("user %s has been deprovisioned", identity.Username))
+		}
+
+		org, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, organizationID, authorization.IdentityResourceTypeOrganization)
+		if err != nil {
+			return errors.NewBadParameterErrorFromString("organizationID", organizationID, "invalid organization ID specified")
+		}
+
+		// Confirm that the user has the 'manage' scope for the organization
+		err = s.Services().PermissionService().RequireScope(ctx, identityID, org.ResourceID, authorization.ManageSecurityGroupsInOrganizationScope)
+		if err != nil {
+			return err
+		}
+
+		// Lookup the group resource type
+		resourceType, err := s.Repositories().ResourceTypeRepository().Lookup(ctx, authorization.IdentityResourceTypeGroup)
+		if err != nil {
+			return err
+		}
+
+		// Create the group resource
+		res := &resource.Resource{
+			Name:             groupName,
+			ResourceType:     *resourceType,
+			ResourceTypeID:   resourceType.ResourceTypeID,
+			ParentResourceID: &org.ResourceID,
+		}
+
+		err = s.Repositories().ResourceRepository().Create(ctx, res)
+		if err != nil {
+			return errors.NewInternalError(ctx, err)
+		}
+
+		// Create the group identity
+		groupIdentity := &account.Identity{
+			IdentityResourceID: sql.NullString{String: res.ResourceID, Valid: true},
+		}
+
+		err = s.Repositories().Identities().Create(ctx, groupIdentity)
+		if err != nil {
+			return errors.NewInternalError(ctx, err)
+		}
+
+		groupID = groupIdentity.ID
+
+		// Assign the group admin role to the creator of the group, so that they can manage the members of the group
+		adminRole, err := s.Repositories().RoleRepository().Lookup(ctx, authorization.SecurityGroupAdminRole, authorization.IdentityResourceTypeGroup)
+		if err != nil {
+			return err
+		}
+
+		err = s.Repositories().IdentityRoleRepository().Create(ctx, &rolerepo.IdentityRole{
+			ResourceID: res.ResourceID,
+			IdentityID: identityID,
+			RoleID:     adminRole.RoleID,
+		})
+		if err != nil {
+			return errors.NewInternalError(ctx, err)
+		}
+
+		log.Debug(ctx, map[string]interface{}{
+			"group_id": groupID.String(),
+		}, "security group created")
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &groupID, nil
+}
+
+// ListGroupsInOrganization returns an array of all security group identities within an organization
+func (s *groupServiceImpl) ListGroupsInOrganization(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID) ([]account.Identity, error) {
+	org, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, organizationID, authorization.IdentityResourceTypeOrganization)
+	if err != nil {
+		return nil, err
+	}
+
+	// Confirm the user has the necessary privileges to list the groups in this organization
+	err = s.Services().PermissionService().RequireScope(ctx, identityID, org.ResourceID, authorization.ViewSecurityGroupsInOrganizationScope)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceType, err := s.Repositories().ResourceTypeRepository().Lookup(ctx, authorization.IdentityResourceTypeGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Repositories().Identities().FindIdentitiesByResourceTypeWithParentResource(ctx, resourceType.ResourceTypeID, org.ResourceID)
+}
+
+// ListGroupsForIdentity returns an array of all security groups in which the specified identity is a member or is assigned a role
+func (s *groupServiceImpl) ListGroupsForIdentity(ctx context.Context, identityID uuid.UUID) ([]authorization.IdentityAssociation, error) {
+	resourceType := authorization.IdentityResourceTypeGroup
+
+	memberships, err := s.Repositories().Identities().FindIdentityMemberships(ctx, identityID, &resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.Repositories().IdentityRoleRepository().FindIdentityRolesForIdentity(ctx, identityID, &resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	return authorization.MergeAssociations(memberships, roles), nil
+}
+
+// ListMembers returns the identities which are direct members of the specified security group, if the specified identity
+// has the necessary privileges to view the members of the group
+func (s *groupServiceImpl) ListMembers(ctx context.Context, identityID uuid.UUID, groupID uuid.UUID) ([]account.Identity, error) {
+	group, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, groupID, authorization.IdentityResourceTypeGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Services().PermissionService().RequireScope(ctx, identityID, group.ResourceID, authorization.ViewSecurityGroupMembersScope)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Repositories().Identities().ListMembers(ctx, groupID)
+}
+
+// AddMembers adds the specified identities as direct members of the specified security group, if the specified identity
+// has the necessary privileges to manage the members of the group
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *groupServiceImpl) AddMembers(ctx context.Context, identityID uuid.UUID, groupID uuid.UUID, memberIDs []uuid.UUID) error {
+	group, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, groupID, authorization.IdentityResourceTypeGroup)
+	if err != nil {
+		return err
+	}
+
+	err = s.Services().PermissionService().RequireScope(ctx, identityID, group.ResourceID, authorization.ManageSecurityGroupMembersScope)
+	if err != nil {
+		return err
+	}
+
+	err = s.ExecuteInTransaction(func() error {
+		members, err := s.Repositories().Identities().ListMembers(ctx, groupID)
+		if err != nil {
+			return err
+		}
+		existing := make(map[uuid.UUID]bool)
+		for _, member := range members {
+			existing[member.ID] = true
+		}
+
+		for _, memberID := range memberIDs {
+			if existing[memberID] {
+				return errors.NewDataConflictError(fmt.Sprintf("identity %s is already a member of security group %s", memberID, groupID))
+			}
+			err = s.Repositories().Identities().AddMember(ctx, groupID, memberID)
+			if err != nil {
+				return err
+			}
+			existing[memberID] = true
+		}
+
+		// The members inherit the roles of the group, so the permissions embedded in the RPTs are now out of date
+		return s.markGroupResourcesStale(ctx, groupID)
+	})
+	cache.Invalidate()
+
+	return err
+}
+
+// RemoveMembers removes the specified identities from the direct members of the specified security group, if the specified
+// identity has the necessary privileges to manage the members of the group
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *groupServiceImpl) RemoveMembers(ctx context.Context, identityID uuid.UUID, groupID uuid.UUID, memberIDs []uuid.UUID) error {
+	group, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, groupID, authorization.IdentityResourceTypeGroup)
+	if err != nil {
+		return err
+	}
+
+	err = s.Services().PermissionService().RequireScope(ctx, identityID, group.ResourceID, authorization.ManageSecurityGroupMembersScope)
+	if err != nil {
+		return err
+	}
+
+	err = s.ExecuteInTransaction(func() error {
+		for _, memberID := range memberIDs {
+			err := s.Repositories().Identities().RemoveMember(ctx, groupID, memberID)
+			if err != nil {
+				return err
+			}
+		}
+
+		// The former members no longer inherit the roles of the group, so the permissions embedded in the RPTs are now out of date
+		return s.markGroupResourcesStale(ctx, groupID)
+	})
+	cache.Invalidate()
+
+	return err
+}
+
+// AssignRole assigns the role with the specified name to the specified security group for the specified resource, if the
+// specified identity has the necessary privileges to manage the members of the group and to manage the role assignments
+// of the resource. The members of the group are then granted the scopes of the role for the resource.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *groupServiceImpl) AssignRole(ctx context.Context, identityID uuid.UUID, groupID uuid.UUID, resourceID string, roleName string) error {
+	group, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, groupID, authorization.IdentityResourceTypeGroup)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.Repositories().ResourceRepository().Load(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+
+	permService := s.Services().PermissionService()
+	err = permService.RequireScope(ctx, identityID, group.ResourceID, authorization.ManageSecurityGroupMembersScope)
+	if err != nil {
+		return err
+	}
+	err = permService.RequireScope(ctx, identityID, res.ResourceID, authorization.ScopeForManagingRolesInResourceType(res.ResourceType.Name))
+	if err != nil {
+		return err
+	}
+
+	r, err := s.Repositories().RoleRepository().Lookup(ctx, roleName, res.ResourceType.Name)
+	if err != nil {
+		if notFound, _ := errors.IsNotFoundError(err); notFound {
+			return errors.NewBadParameterErrorFromString("role", roleName, fmt.Sprintf("no role named %s exists for resource type %s", roleName, res.ResourceType.Name))
+		}
+		return err
+	}
+
+	err = s.ExecuteInTransaction(func() error {
+		assigned, err := s.Repositories().IdentityRoleRepository().FindIdentityRolesByIdentityAndResource(ctx, res.ResourceID, groupID)
+		if err != nil {
+			return err
+		}
+		for _, ir := range assigned {
+			if ir.RoleID == r.RoleID {
+				return errors.NewDataConflictError(fmt.Sprintf("role %s is already assigned to security group %s for resource %s", roleName, groupID, res.ResourceID))
+			}
+		}
+
+		err = s.Repositories().IdentityRoleRepository().Create(ctx, &rolerepo.IdentityRole{
+			ResourceID: res.ResourceID,
+			IdentityID: groupID,
+			RoleID:     r.RoleID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, res.ResourceID, tokenrepo.TokenStatusStale)
+	})
+	cache.Invalidate()
+	if err != nil {
+		return err
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"group_id":    groupID,
+		"resource_id": res.ResourceID,
+		"role_name":   roleName,
+		"identity_id": identityID,
+	}, "role assigned to security group")
+	return nil
+}
+
+// markGroupResourcesStale marks the tokens of all the resources for which the group was assigned a role as stale
+func (s *groupServiceImpl) markGroupResourcesStale(ctx context.Context, groupID uuid.UUID) error {
+	associations, err := s.Repositories().IdentityRoleRepository().FindIdentityRolesForIdentity(ctx, groupID, nil)
+	if err != nil {
+		return err
+	}
+	for _, association := range associations {
+		err = s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, association.ResourceID, tokenrepo.TokenStatusStale)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
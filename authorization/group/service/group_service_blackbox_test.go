@@ -0,0 +1,160 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
+
+	errs "github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type groupServiceBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunGroupServiceBlackBoxTest(t *testing.T) {
+	suite.Run(t, &groupServiceBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *groupServiceBlackBoxTest) TestCreateAndListGroups() {
+	orgAdmin := s.Graph.CreateUser()
+	org := s.Graph.CreateOrganization(orgAdmin)
+
+	groupName := "release-managers-" + uuid.NewV4().String()
+	groupID, err := s.Application.GroupService().CreateGroup(s.Ctx, orgAdmin.IdentityID(), org.OrganizationID(), groupName)
+	require.NoError(s.T(), err)
+
+	// noise
+	s.Graph.CreateGroup()
+
+	s.T().Run("list in organization", func(t *testing.T) {
+		groups, err := s.Application.GroupService().ListGroupsInOrganization(s.Ctx, orgAdmin.IdentityID(), org.OrganizationID())
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		require.Equal(t, *groupID, groups[0].ID)
+		require.Equal(t, groupName, groups[0].IdentityResource.Name)
+		require.Equal(t, org.ResourceID(), *groups[0].IdentityResource.ParentResourceID)
+	})
+
+	s.T().Run("list for identity", func(t *testing.T) {
+		groups, err := s.Application.GroupService().ListGroupsForIdentity(s.Ctx, orgAdmin.IdentityID())
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		require.Equal(t, *groupID, *groups[0].IdentityID)
+		require.Equal(t, []string{authorization.SecurityGroupAdminRole}, groups[0].Roles)
+	})
+
+	s.T().Run("create forbidden", func(t *testing.T) {
+		_, err := s.Application.GroupService().CreateGroup(s.Ctx, s.Graph.CreateUser().IdentityID(), org.OrganizationID(), "forbidden")
+		require.Error(t, err)
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+	})
+
+	s.T().Run("create in unknown organization", func(t *testing.T) {
+		_, err := s.Application.GroupService().CreateGroup(s.Ctx, orgAdmin.IdentityID(), uuid.NewV4(), "unknown")
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("list in organization forbidden", func(t *testing.T) {
+		_, err := s.Application.GroupService().ListGroupsInOrganization(s.Ctx, s.Graph.CreateUser().IdentityID(), org.OrganizationID())
+		require.Error(t, err)
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+	})
+}
+
+func (s *groupServiceBlackBoxTest) TestManageMembers() {
+	orgAdmin := s.Graph.CreateUser()
+	group := s.Graph.CreateGroup(s.Graph.CreateOrganization(orgAdmin))
+	member := s.Graph.CreateUser()
+
+	err := s.Application.GroupService().AddMembers(s.Ctx, orgAdmin.IdentityID(), group.GroupID(), []uuid.UUID{member.IdentityID()})
+	require.NoError(s.T(), err)
+
+	members, err := s.Application.GroupService().ListMembers(s.Ctx, orgAdmin.IdentityID(), group.GroupID())
+	require.NoError(s.T(), err)
+	require.Len(s.T(), members, 1)
+	require.Equal(s.T(), member.IdentityID(), members[0].ID)
+
+	err = s.Application.GroupService().AddMembers(s.Ctx, orgAdmin.IdentityID(), group.GroupID(), []uuid.UUID{member.IdentityID()})
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.DataConflictError{}, errs.Cause(err))
+
+	err = s.Application.GroupService().RemoveMembers(s.Ctx, orgAdmin.IdentityID(), group.GroupID(), []uuid.UUID{member.IdentityID()})
+	require.NoError(s.T(), err)
+
+	members, err = s.Application.GroupService().ListMembers(s.Ctx, orgAdmin.IdentityID(), group.GroupID())
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), members)
+
+	err = s.Application.GroupService().RemoveMembers(s.Ctx, orgAdmin.IdentityID(), group.GroupID(), []uuid.UUID{member.IdentityID()})
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *groupServiceBlackBoxTest) TestManageMembersForbidden() {
+	group := s.Graph.CreateGroup()
+	user := s.Graph.CreateUser()
+
+	_, err := s.Application.GroupService().ListMembers(s.Ctx, user.IdentityID(), group.GroupID())
+	require.IsType(s.T(), errors.ForbiddenError{}, errs.Cause(err))
+
+	err = s.Application.GroupService().AddMembers(s.Ctx, user.IdentityID(), group.GroupID(), []uuid.UUID{user.IdentityID()})
+	require.IsType(s.T(), errors.ForbiddenError{}, errs.Cause(err))
+
+	err = s.Application.GroupService().RemoveMembers(s.Ctx, user.IdentityID(), group.GroupID(), []uuid.UUID{user.IdentityID()})
+	require.IsType(s.T(), errors.ForbiddenError{}, errs.Cause(err))
+}
+
+func (s *groupServiceBlackBoxTest) TestAssignRole() {
+	orgAdmin := s.Graph.CreateUser()
+	group := s.Graph.CreateGroup(s.Graph.CreateOrganization(orgAdmin))
+	member := s.Graph.CreateUser()
+	group.AddMember(member)
+
+	// release managers of several spaces
+	space1 := s.Graph.CreateSpace().AddAdmin(orgAdmin)
+	space2 := s.Graph.CreateSpace().AddAdmin(orgAdmin)
+
+	s.T().Run("ok", func(t *testing.T) {
+		for _, space := range []string{space1.SpaceID(), space2.SpaceID()} {
+			err := s.Application.GroupService().AssignRole(s.Ctx, orgAdmin.IdentityID(), group.GroupID(), space, authorization.SpaceContributorRole)
+			require.NoError(t, err)
+
+			hasScope, err := s.Application.PermissionService().HasScope(s.Ctx, member.IdentityID(), space, "contribute")
+			require.NoError(t, err)
+			require.True(t, hasScope)
+		}
+	})
+
+	s.T().Run("already assigned", func(t *testing.T) {
+		err := s.Application.GroupService().AssignRole(s.Ctx, orgAdmin.IdentityID(), group.GroupID(), space1.SpaceID(), authorization.SpaceContributorRole)
+		require.Error(t, err)
+		require.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("unknown role", func(t *testing.T) {
+		err := s.Application.GroupService().AssignRole(s.Ctx, orgAdmin.IdentityID(), group.GroupID(), space1.SpaceID(), "unknown")
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("forbidden for resource", func(t *testing.T) {
+		err := s.Application.GroupService().AssignRole(s.Ctx, orgAdmin.IdentityID(), group.GroupID(), s.Graph.CreateSpace().SpaceID(), authorization.SpaceViewerRole)
+		require.Error(t, err)
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+	})
+
+	s.T().Run("forbidden for group", func(t *testing.T) {
+		spaceAdmin := s.Graph.CreateUser()
+		space := s.Graph.CreateSpace().AddAdmin(spaceAdmin)
+		err := s.Application.GroupService().AssignRole(s.Ctx, spaceAdmin.IdentityID(), group.GroupID(), space.SpaceID(), authorization.SpaceViewerRole)
+		require.Error(t, err)
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+	})
+}
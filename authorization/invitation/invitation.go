@@ -4,9 +4,11 @@ import (
 	"github.com/satori/go.uuid"
 )
 
-// Invitation is a DTO used to pass state between the controller and service layers when issuing new invitations
+// Invitation is a DTO used to pass state between the controller and service layers when issuing new invitations.
+// The invited user is identified either by IdentityID or, if they do not have an account yet, by Email
 type Invitation struct {
 	IdentityID *uuid.UUID
+	Email      *string
 	Roles      []string
 	Member     bool
 }
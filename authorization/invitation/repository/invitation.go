@@ -29,13 +29,20 @@ type Invitation struct {
 	// or, the Resource ID to which the user is being invited to accept a role
 	ResourceID *string `sql:"type:string" gorm:"column:resource_id"`
 
+	// The invited user, unless the invitation is addressed to an e-mail address for which there is no user yet
 	Identity   account.Identity `gorm:"ForeignKey:IdentityID;AssociationForeignKey:ID"`
-	IdentityID uuid.UUID
+	IdentityID *uuid.UUID       `sql:"type:uuid" gorm:"column:identity_id"`
+
+	// Email is the address to which the invitation was sent when the invited user did not have an account yet
+	Email *string `gorm:"column:email"`
 
 	// AcceptCode is the code sent in the invitation e-mail to the user, used to accept the invitation
 	AcceptCode uuid.UUID `sql:"type:uuid" gorm:"column:accept_code"`
 
 	Member bool
+
+	// ExpiresAt is the time after which the invitation can not be accepted anymore, if any
+	ExpiresAt *time.Time `gorm:"column:expires_at"`
 }
 
 func (m Invitation) TableName() string {
@@ -47,6 +54,12 @@ func (m Invitation) GetLastModified() time.Time {
 	return m.UpdatedAt
 }
 
+// IsExpired returns true if the invitation has expired at the given time. Invitations without an expiry time, e.g. the
+// invitations created before invitations could expire, never expire.
+func (m Invitation) IsExpired(now time.Time) bool {
+	return m.ExpiresAt != nil && !now.Before(*m.ExpiresAt)
+}
+
 // InvitationRole represents the storage interface for storing an invitation's roles
 type InvitationRole struct {
 	InvitationID uuid.UUID `sql:"type:uuid" gorm:"primary_key;column:invitation_id"`
@@ -77,7 +90,11 @@ type InvitationRepository interface {
 	Save(ctx context.Context, i *Invitation) error
 	ListForIdentity(ctx context.Context, inviteToID uuid.UUID) ([]Invitation, error)
 	ListForResource(ctx context.Context, resourceID string) ([]Invitation, error)
+	ListPendingForEmail(ctx context.Context, email string) ([]Invitation, error)
+	ListForUser(ctx context.Context, identityID uuid.UUID) ([]Invitation, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteForIdentity(ctx context.Context, inviteToID uuid.UUID) error
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
 
 	ListRoles(ctx context.Context, id uuid.UUID) ([]rolerepo.Role, error)
 	AddRole(ctx context.Context, invitationId uuid.UUID, roleId uuid.UUID) error
@@ -187,6 +204,31 @@ func (m *GormInvitationRepository) ListForResource(ctx context.Context, resource
 	return rows, nil
 }
 
+// ListPendingForEmail returns the invitations addressed to the given e-mail address (case insensitive) which have not
+// been bound to an identity yet
+func (m *GormInvitationRepository) ListPendingForEmail(ctx context.Context, email string) ([]Invitation, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "invitation", "listPendingForEmail"}, time.Now())
+	var rows []Invitation
+
+	err := m.db.Model(&Invitation{}).Where("identity_id IS NULL AND lower(email) = lower(?)", email).Find(&rows).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errs.WithStack(err)
+	}
+	return rows, nil
+}
+
+// ListForUser returns the invitations issued to the specified user identity
+func (m *GormInvitationRepository) ListForUser(ctx context.Context, identityID uuid.UUID) ([]Invitation, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "invitation", "listForUser"}, time.Now())
+	var rows []Invitation
+
+	err := m.db.Model(&Invitation{}).Where("identity_id = ?", identityID).Order("created_at").Find(&rows).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errs.WithStack(err)
+	}
+	return rows, nil
+}
+
 func (m *GormInvitationRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	defer goa.MeasureSince([]string{"goa", "db", "invitation", "delete"}, time.Now())
 
@@ -232,6 +274,60 @@ func (m *GormInvitationRepository) Delete(ctx context.Context, id uuid.UUID) err
 	return nil
 }
 
+// DeleteForIdentity permanently deletes all the invitations to the specified identity (organization, team or security group),
+// along with their roles, so that the identity itself can be deleted. No error is returned if there is no such invitation.
+func (m *GormInvitationRepository) DeleteForIdentity(ctx context.Context, inviteToID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "invitation", "deleteForIdentity"}, time.Now())
+
+	err := m.db.Where("invitation_id IN (SELECT invitation_id FROM invitation WHERE invite_to = ?)", inviteToID).Delete(&InvitationRole{}).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return errs.WithStack(err)
+	}
+
+	result := m.db.Unscoped().Where("invite_to = ?", inviteToID).Delete(&Invitation{})
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		log.Error(ctx, map[string]interface{}{
+			"invite_to": inviteToID,
+			"err":       result.Error,
+		}, "unable to delete the invitations")
+		return errs.WithStack(result.Error)
+	}
+
+	log.Debug(ctx, map[string]interface{}{
+		"invite_to": inviteToID,
+		"count":     result.RowsAffected,
+	}, "Invitations deleted!")
+
+	return nil
+}
+
+// DeleteExpired permanently deletes all the invitations which expired before the given time, along with their roles,
+// and returns the number of deleted invitations
+func (m *GormInvitationRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "invitation", "deleteExpired"}, time.Now())
+
+	err := m.db.Where("invitation_id IN (SELECT invitation_id FROM invitation WHERE expires_at IS NOT NULL AND expires_at <= ?)", before).Delete(&InvitationRole{}).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return 0, errs.WithStack(err)
+	}
+
+	result := m.db.Unscoped().Where("expires_at IS NOT NULL AND expires_at <= ?", before).Delete(&Invitation{})
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		log.Error(ctx, map[string]interface{}{
+			"before": before,
+			"err":    result.Error,
+		}, "unable to delete the expired invitations")
+		return 0, errs.WithStack(result.Error)
+	}
+
+	log.Debug(ctx, map[string]interface{}{
+		"before": before,
+		"count":  result.RowsAffected,
+	}, "Expired invitations deleted!")
+
+	return int(result.RowsAffected), nil
+}
+
 func (m *GormInvitationRepository) ListRoles(ctx context.Context, id uuid.UUID) ([]rolerepo.Role, error) {
 	defer goa.MeasureSince([]string{"goa", "db", "invitation", "list_roles"}, time.Now())
 
@@ -1,7 +1,9 @@
 package repository_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/fabric8-services/fabric8-auth/authorization"
 	invitationRepo "github.com/fabric8-services/fabric8-auth/authorization/invitation/repository"
@@ -196,7 +198,7 @@ func (s *invitationBlackBoxTest) TestFindByAcceptCode() {
 	g.CreateInvitation()
 	g.CreateInvitation()
 
-	invitation, err := s.repo.FindByAcceptCode(s.Ctx, i.Invitation().IdentityID, i.Invitation().AcceptCode)
+	invitation, err := s.repo.FindByAcceptCode(s.Ctx, *i.Invitation().IdentityID, i.Invitation().AcceptCode)
 	require.NoError(s.T(), err)
 
 	require.Equal(s.T(), i.Invitation().InvitationID, invitation.InvitationID)
@@ -210,11 +212,105 @@ func (s *invitationBlackBoxTest) TestFindByAcceptCodeNotFound() {
 	g.CreateInvitation()
 	g.CreateInvitation()
 
-	_, err := s.repo.FindByAcceptCode(s.Ctx, i.Invitation().IdentityID, uuid.NewV4())
+	_, err := s.repo.FindByAcceptCode(s.Ctx, *i.Invitation().IdentityID, uuid.NewV4())
 	require.Error(s.T(), err)
 	require.IsType(s.T(), errors.NotFoundError{}, err)
 }
 
+func (s *invitationBlackBoxTest) TestDeleteForIdentity() {
+	team := s.Graph.CreateTeam()
+	role := s.Graph.CreateRole(s.Graph.LoadResourceType(authorization.IdentityResourceTypeTeam))
+	s.Graph.CreateInvitation(team, role)
+	s.Graph.CreateInvitation(team)
+	// noise
+	otherTeam := s.Graph.CreateTeam()
+	s.Graph.CreateInvitation(otherTeam)
+
+	err := s.repo.DeleteForIdentity(s.Ctx, team.TeamID())
+	require.NoError(s.T(), err)
+
+	invitations, err := s.repo.ListForIdentity(s.Ctx, team.TeamID())
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), invitations)
+	invitations, err = s.repo.ListForIdentity(s.Ctx, otherTeam.TeamID())
+	require.NoError(s.T(), err)
+	require.Len(s.T(), invitations, 1)
+
+	// the invitations were deleted permanently, so that the team itself can be deleted
+	err = s.Application.Identities().DeleteForResource(s.Ctx, team.ResourceID())
+	require.NoError(s.T(), err)
+}
+
+func (s *invitationBlackBoxTest) TestListPendingForEmail() {
+	team := s.Graph.CreateTeam()
+	teamID := team.TeamID()
+	email := "Invitee-" + uuid.NewV4().String() + "@example.com"
+	pending := invitationRepo.Invitation{
+		InviteTo: &teamID,
+		Email:    &email,
+	}
+	err := s.repo.Create(s.Ctx, &pending)
+	require.NoError(s.T(), err)
+	// noise
+	s.Graph.CreateInvitation(team)
+
+	invitations, err := s.repo.ListPendingForEmail(s.Ctx, strings.ToLower(email))
+	require.NoError(s.T(), err)
+	require.Len(s.T(), invitations, 1)
+	require.Equal(s.T(), pending.InvitationID, invitations[0].InvitationID)
+	require.Nil(s.T(), invitations[0].IdentityID)
+
+	// Once bound to an identity, the invitation is not pending anymore
+	invitations[0].IdentityID = &s.Graph.CreateUser().Identity().ID
+	err = s.repo.Save(s.Ctx, &invitations[0])
+	require.NoError(s.T(), err)
+	invitations, err = s.repo.ListPendingForEmail(s.Ctx, email)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), invitations)
+}
+
+func (s *invitationBlackBoxTest) TestListForUser() {
+	user := s.Graph.CreateUser()
+	first := s.Graph.CreateInvitation(s.Graph.CreateTeam(), user)
+	second := s.Graph.CreateInvitation(s.Graph.CreateSpace(), user)
+	// noise
+	s.Graph.CreateInvitation(s.Graph.CreateTeam())
+
+	invitations, err := s.repo.ListForUser(s.Ctx, user.IdentityID())
+	require.NoError(s.T(), err)
+	require.Len(s.T(), invitations, 2)
+	require.Equal(s.T(), first.Invitation().InvitationID, invitations[0].InvitationID)
+	require.Equal(s.T(), second.Invitation().InvitationID, invitations[1].InvitationID)
+}
+
+func (s *invitationBlackBoxTest) TestDeleteExpired() {
+	now := time.Now()
+	r := s.Graph.CreateRole(s.Graph.LoadResourceType(authorization.IdentityResourceTypeTeam))
+	expired := s.Graph.CreateInvitation(r)
+	expiresAt := now.Add(-time.Hour)
+	expired.Invitation().ExpiresAt = &expiresAt
+	err := s.repo.Save(s.Ctx, expired.Invitation())
+	require.NoError(s.T(), err)
+
+	valid := s.Graph.CreateInvitation()
+	expiresAt = now.Add(time.Hour)
+	valid.Invitation().ExpiresAt = &expiresAt
+	err = s.repo.Save(s.Ctx, valid.Invitation())
+	require.NoError(s.T(), err)
+
+	count, err := s.repo.DeleteExpired(s.Ctx, now)
+	require.NoError(s.T(), err)
+	require.True(s.T(), count >= 1)
+
+	_, err = s.repo.Load(s.Ctx, expired.Invitation().InvitationID)
+	require.IsType(s.T(), errors.NotFoundError{}, err)
+	roles, err := s.repo.ListRoles(s.Ctx, expired.Invitation().InvitationID)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), roles)
+	_, err = s.repo.Load(s.Ctx, valid.Invitation().InvitationID)
+	require.NoError(s.T(), err)
+}
+
 func (s *invitationBlackBoxTest) CreateTestInvitation() (invitationRepo.Invitation, error) {
 	var invitation invitationRepo.Invitation
 
@@ -230,7 +326,7 @@ func (s *invitationBlackBoxTest) CreateTestInvitation() (invitationRepo.Invitati
 
 	invitation = invitationRepo.Invitation{
 		InviteTo:   &orgIdentity.ID,
-		IdentityID: userIdentity.ID,
+		IdentityID: &userIdentity.ID,
 		Member:     false,
 	}
 
@@ -254,7 +350,7 @@ func (s *invitationBlackBoxTest) CreateTestResourceInvitation() (invitationRepo.
 
 	invitation = invitationRepo.Invitation{
 		ResourceID: &resource.ResourceID,
-		IdentityID: userIdentity.ID,
+		IdentityID: &userIdentity.ID,
 		Member:     false,
 	}
 
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-auth/application/service"
+	"github.com/fabric8-services/fabric8-auth/log"
+	"github.com/fabric8-services/fabric8-auth/worker"
+)
+
+// StartExpiredInvitationSweeper starts a goroutine which deletes the expired invitations at the specified interval,
+// using the given invitation service. The sweeper is disabled if the interval is zero or negative.
+// The returned function stops the sweeper.
+func StartExpiredInvitationSweeper(invitationService service.InvitationService, interval time.Duration) func() {
+	return worker.RunPeriodically(interval, func(ctx context.Context) error {
+		count, err := invitationService.DeleteExpiredInvitations(ctx)
+		if err == nil && count > 0 {
+			log.Info(ctx, map[string]interface{}{
+				"count": count,
+			}, "expired invitations deleted")
+		}
+		return err
+	}, "delete the expired invitations")
+}
@@ -3,14 +3,18 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
 
 	account "github.com/fabric8-services/fabric8-auth/account/repository"
 	servicecontext "github.com/fabric8-services/fabric8-auth/application/service/context"
 	"github.com/fabric8-services/fabric8-auth/authorization"
 	"github.com/fabric8-services/fabric8-auth/authorization/invitation"
 	invitationrepo "github.com/fabric8-services/fabric8-auth/authorization/invitation/repository"
+	"github.com/fabric8-services/fabric8-auth/authorization/permission/cache"
 	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
+	tokenrepo "github.com/fabric8-services/fabric8-auth/authorization/token/repository"
 	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/log"
 
 	"strings"
 
@@ -24,6 +28,7 @@ import (
 type InvitationConfiguration interface {
 	GetAuthServiceURL() string
 	IsPostgresDeveloperModeEnabled() bool
+	GetInvitationTTL() time.Duration
 }
 
 type invitationServiceImpl struct {
@@ -37,6 +42,38 @@ func NewInvitationService(context servicecontext.ServiceContext, config Invitati
 		config:      config}
 }
 
+// loadInviteTo returns either the identity (of an organization, team or security group) or the resource which the
+// given identifier refers to
+func (s *invitationServiceImpl) loadInviteTo(ctx context.Context, inviteTo string) (*account.Identity, *resource.Resource, error) {
+	// First try to convert inviteTo to a uuid
+	inviteToUUID, err := uuid.FromString(inviteTo)
+	// If we get an error here, the value is definitely not for an Identity so we'll treat it as a resource ID
+	if err == nil {
+		// Attempt to lookup the identity of the organization, team or security group
+		inviteToIdentity, err := s.Repositories().Identities().Load(ctx, inviteToUUID)
+		if err == nil {
+			return inviteToIdentity, nil, nil
+		}
+	}
+
+	// That didn't work, try to lookup a resource with the same ID value
+	inviteToResource, err := s.Repositories().ResourceRepository().Load(ctx, inviteTo)
+	if err != nil {
+		return nil, nil, errors.NewNotFoundError(fmt.Sprintf("invalid identifier '%s' provided for organization, team, security group or resource", inviteTo), inviteTo)
+	}
+	return nil, inviteToResource, nil
+}
+
+// expiresAt returns the expiry time of an invitation issued now, or nil if invitations never expire
+func (s *invitationServiceImpl) expiresAt() *time.Time {
+	ttl := s.config.GetInvitationTTL()
+	if ttl <= 0 {
+		return nil
+	}
+	expiresAt := time.Now().Add(ttl)
+	return &expiresAt
+}
+
 // Issue creates new invitations. The inviteTo parameter is the unique id of the organization, team, security group
 // (the Identity ID) or resource (Resource ID) for which the invitations will be issued, and the invitations parameter
 // contains the users and state for each individual user invitation.
@@ -52,28 +89,10 @@ func (s *invitationServiceImpl) Issue(ctx context.Context, issuingUserId uuid.UU
 
 	err := s.ExecuteInTransaction(func() error {
 
-		// First try to convert inviteTo to a uuid
-		inviteToUUID, err := uuid.FromString(inviteTo)
-		// If we get an error here, the value is definitely not for an Identity so we'll treat it as a resource ID
+		var err error
+		inviteToIdentity, inviteToResource, err = s.loadInviteTo(ctx, inviteTo)
 		if err != nil {
-			// Try to lookup a resource with the same ID value
-			inviteToResource, err = s.Repositories().ResourceRepository().Load(ctx, inviteTo)
-			if err != nil {
-				return errors.NewNotFoundError(fmt.Sprintf("invalid identifier '%s' provided for organization, team, security group or resource", inviteTo), inviteTo)
-			}
-		}
-
-		// If we didn't successfully find a valid resource already, it means the inviteTo is a UUID
-		if inviteToResource == nil {
-			// Attempt to lookup the identity of the organization, team or security group that invitations will be issued for
-			inviteToIdentity, err = s.Repositories().Identities().Load(ctx, inviteToUUID)
-			if err != nil {
-				// That didn't work, try to lookup a resource with the same ID value
-				inviteToResource, err = s.Repositories().ResourceRepository().Load(ctx, inviteTo)
-				if err != nil {
-					return errors.NewNotFoundError(fmt.Sprintf("invalid identifier '%s' provided for organization, team, security group or resource", inviteTo), inviteTo)
-				}
-			}
+			return err
 		}
 
 		// We currently only support:
@@ -125,10 +144,28 @@ func (s *invitationServiceImpl) Issue(ctx context.Context, issuingUserId uuid.UU
 		}
 
 		// Iterate through all of the invitations and confirm that for each one:
-		// 1) a valid user has been specified via its Identity ID
+		// 1) a valid user has been specified via its Identity ID, or an e-mail address has been specified
 		// 2) any roles specified are valid roles for the organization, team or security group
-		// For each invitation, ensure that the IdentityID value can be found and set it
-		for _, invitation := range invitations {
+		// Invitations addressed to the e-mail address of an existing user are issued to the identity of that user
+		for i, invitation := range invitations {
+			if invitation.IdentityID == nil {
+				if invitation.Email == nil || strings.TrimSpace(*invitation.Email) == "" {
+					return errors.NewBadParameterErrorFromString("Identity ID", nil, "either an identity ID or an e-mail address must be provided")
+				}
+				identity, err := s.lookupIdentityByEmail(ctx, strings.TrimSpace(*invitation.Email))
+				if err != nil {
+					return errors.NewInternalError(ctx, err)
+				}
+				if identity != nil {
+					invitations[i].IdentityID = &identity.ID
+				}
+				if invitation.Member && inviteToResource != nil {
+					// We cannot invite members to a resource, only certain identity types
+					return errors.NewBadParameterErrorFromString("Member", *invitation.Email, "can not invite members to a resource")
+				}
+				continue
+			}
+
 			// Load the identity
 			identity, err := s.Repositories().Identities().Load(ctx, *invitation.IdentityID)
 			if err != nil {
@@ -148,7 +185,14 @@ func (s *invitationServiceImpl) Issue(ctx context.Context, issuingUserId uuid.UU
 		// Create the invitation records
 		for _, invitation := range invitations {
 			inv := new(invitationrepo.Invitation)
-			inv.IdentityID = *invitation.IdentityID
+			inv.ExpiresAt = s.expiresAt()
+			if invitation.IdentityID != nil {
+				inv.IdentityID = invitation.IdentityID
+			} else {
+				// The invitation remains pending until a user with this e-mail address is provisioned
+				email := strings.TrimSpace(*invitation.Email)
+				inv.Email = &email
+			}
 
 			if inviteToIdentity != nil {
 				inv.InviteTo = &inviteToIdentity.ID
@@ -254,6 +298,14 @@ func (s *invitationServiceImpl) processTeamInviteNotifications(ctx context.Conte
 	for _, n := range notifications {
 		acceptURL := fmt.Sprintf("%s/api/invitations/accept?code=%s", s.config.GetAuthServiceURL(), n.invitation.AcceptCode.String())
 
+		if n.invitation.IdentityID == nil {
+			messages = append(messages, notification.NewTeamInvitationEmailToAddress(*n.invitation.Email,
+				teamName,
+				inviterName,
+				spaceName,
+				acceptURL))
+			continue
+		}
 		messages = append(messages, notification.NewTeamInvitationEmail(n.invitation.Identity.UserID.UUID.String(),
 			teamName,
 			inviterName,
@@ -278,6 +330,14 @@ func (s *invitationServiceImpl) processSpaceInviteNotifications(ctx context.Cont
 	for _, n := range notifications {
 		acceptURL := fmt.Sprintf("%s/api/invitations/accept?code=%s", s.config.GetAuthServiceURL(), n.invitation.AcceptCode.String())
 
+		if n.invitation.IdentityID == nil {
+			messages = append(messages, notification.NewSpaceInvitationEmailToAddress(*n.invitation.Email,
+				spaceName,
+				inviterName,
+				strings.Join(n.roles, ","),
+				acceptURL))
+			continue
+		}
 		messages = append(messages, notification.NewSpaceInvitationEmail(n.invitation.Identity.UserID.UUID.String(),
 			spaceName,
 			inviterName,
@@ -288,14 +348,56 @@ func (s *invitationServiceImpl) processSpaceInviteNotifications(ctx context.Cont
 	return s.Services().NotificationService().SendMessagesAsync(ctx, messages)
 }
 
-// Rescind revokes an invitation request
-func (s *invitationServiceImpl) Rescind(ctx context.Context, rescindingUserID, invitationID uuid.UUID) error {
-	// Locate the invitation
-	inv, err := s.Repositories().InvitationRepository().Load(ctx, invitationID)
+// lookupIdentityByEmail returns the identity of the user with the given verified e-mail address, or nil if there is
+// no such user
+func (s *invitationServiceImpl) lookupIdentityByEmail(ctx context.Context, email string) (*account.Identity, error) {
+	users, err := s.Repositories().Users().Query(account.UserFilterByEmail(email))
 	if err != nil {
-		return errors.NewNotFoundErrorFromString(fmt.Sprintf("invalid identifier '%s' provided for invitation", invitationID.String()))
+		return nil, err
+	}
+	for _, user := range users {
+		if !user.EmailVerified {
+			continue
+		}
+		identities, err := s.Repositories().Identities().Query(account.IdentityFilterByUserID(user.ID), account.IdentityFilterByProviderType(account.KeycloakIDP))
+		if err != nil {
+			return nil, err
+		}
+		if len(identities) > 0 {
+			return &identities[0], nil
+		}
 	}
+	return nil, nil
+}
 
+// BindEmailInvitations binds the pending invitations addressed to the given e-mail address to the specified identity,
+// so that the user can accept them. It is invoked when a user with a verified e-mail address logs in, and when a user
+// verifies their e-mail address.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *invitationServiceImpl) BindEmailInvitations(ctx context.Context, identityID uuid.UUID, email string) error {
+	return s.ExecuteInTransaction(func() error {
+		invitations, err := s.Repositories().InvitationRepository().ListPendingForEmail(ctx, email)
+		if err != nil {
+			return err
+		}
+		for i := range invitations {
+			invitations[i].IdentityID = &identityID
+			err = s.Repositories().InvitationRepository().Save(ctx, &invitations[i])
+			if err != nil {
+				return err
+			}
+			log.Info(ctx, map[string]interface{}{
+				"invitation_id": invitations[i].InvitationID,
+				"identity_id":   identityID,
+			}, "invitation bound to the identity of the invited user")
+		}
+		return nil
+	})
+}
+
+// requireManageScope verifies that the specified user is allowed to manage the invitations to the organization, team,
+// security group or resource of the given invitation, and returns the identity or the resource which the invitation is for
+func (s *invitationServiceImpl) requireManageScope(ctx context.Context, userID uuid.UUID, inv *invitationrepo.Invitation) (*account.Identity, *resource.Resource, error) {
 	// Create the permission service
 	permService := s.Services().PermissionService()
 
@@ -303,35 +405,53 @@ func (s *invitationServiceImpl) Rescind(ctx context.Context, rescindingUserID, i
 		// Lookup identity with InviteTo ID
 		inviteToIdentity, err := s.Repositories().Identities().Load(ctx, *inv.InviteTo)
 		if err != nil {
-			return errors.NewNotFoundErrorFromString(fmt.Sprintf("invalid identifier '%s' provided for organization, team or security group", inv.InviteTo.String()))
+			return nil, nil, errors.NewNotFoundErrorFromString(fmt.Sprintf("invalid identifier '%s' provided for organization, team or security group", inv.InviteTo.String()))
 		}
 
 		if !inviteToIdentity.IdentityResourceID.Valid {
-			return errors.NewNotFoundErrorFromString(fmt.Sprintf("specified identity '%s' has no resource", inv.InviteTo.String()))
+			return nil, nil, errors.NewNotFoundErrorFromString(fmt.Sprintf("specified identity '%s' has no resource", inv.InviteTo.String()))
 		}
 
 		identityResource, err := s.Repositories().ResourceRepository().Load(ctx, inviteToIdentity.IdentityResourceID.String)
 		if err != nil {
-			return errors.NewInternalError(ctx, err)
+			return nil, nil, errors.NewInternalError(ctx, err)
 		}
+		inviteToIdentity.IdentityResource = *identityResource
 
-		// Confirm that the rescinding user has the necessary scope to manage members for the organization, team or security group
-		err = permService.RequireScope(ctx, rescindingUserID, inviteToIdentity.IdentityResourceID.String, authorization.ScopeForManagingRolesInResourceType(identityResource.ResourceType.Name))
+		// Confirm that the user has the necessary scope to manage members for the organization, team or security group
+		err = permService.RequireScope(ctx, userID, inviteToIdentity.IdentityResourceID.String, authorization.ScopeForManagingRolesInResourceType(identityResource.ResourceType.Name))
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
+		return inviteToIdentity, nil, nil
 	} else if inv.ResourceID != nil {
 		// Lookup a resource with the ResourceID value
 		inviteToResource, err := s.Repositories().ResourceRepository().Load(ctx, *inv.ResourceID)
 		if err != nil {
-			return errors.NewNotFoundErrorFromString(fmt.Sprintf("invalid identifier '%s' provided for resource", *inv.ResourceID))
+			return nil, nil, errors.NewNotFoundErrorFromString(fmt.Sprintf("invalid identifier '%s' provided for resource", *inv.ResourceID))
 		}
 
-		// Confirm that the rescinding user has the manage members scope for the resource
-		err = permService.RequireScope(ctx, rescindingUserID, inviteToResource.ResourceID, authorization.ScopeForManagingRolesInResourceType(inviteToResource.ResourceType.Name))
+		// Confirm that the user has the manage members scope for the resource
+		err = permService.RequireScope(ctx, userID, inviteToResource.ResourceID, authorization.ScopeForManagingRolesInResourceType(inviteToResource.ResourceType.Name))
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
+		return nil, inviteToResource, nil
+	}
+	return nil, nil, errors.NewInternalErrorFromString(ctx, fmt.Sprintf("invitation '%s' is neither for an identity nor for a resource", inv.InvitationID))
+}
+
+// Rescind revokes an invitation request
+func (s *invitationServiceImpl) Rescind(ctx context.Context, rescindingUserID, invitationID uuid.UUID) error {
+	// Locate the invitation
+	inv, err := s.Repositories().InvitationRepository().Load(ctx, invitationID)
+	if err != nil {
+		return errors.NewNotFoundErrorFromString(fmt.Sprintf("invalid identifier '%s' provided for invitation", invitationID.String()))
+	}
+
+	_, _, err = s.requireManageScope(ctx, rescindingUserID, inv)
+	if err != nil {
+		return err
 	}
 
 	err = s.ExecuteInTransaction(func() error {
@@ -341,6 +461,150 @@ func (s *invitationServiceImpl) Rescind(ctx context.Context, rescindingUserID, i
 	return err
 }
 
+// Resend renews the acceptance code and the expiry of an invitation, and sends the invitation e-mail again
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *invitationServiceImpl) Resend(ctx context.Context, resendingUserID, invitationID uuid.UUID) error {
+	inv, err := s.Repositories().InvitationRepository().Load(ctx, invitationID)
+	if err != nil {
+		return errors.NewNotFoundErrorFromString(fmt.Sprintf("invalid identifier '%s' provided for invitation", invitationID.String()))
+	}
+
+	inviteToIdentity, inviteToResource, err := s.requireManageScope(ctx, resendingUserID, inv)
+	if err != nil {
+		return err
+	}
+
+	var roleNames []string
+	err = s.ExecuteInTransaction(func() error {
+		// The previous acceptance code is not valid anymore
+		inv.AcceptCode = uuid.NewV4()
+		inv.ExpiresAt = s.expiresAt()
+		err := s.Repositories().InvitationRepository().Save(ctx, inv)
+		if err != nil {
+			return err
+		}
+
+		roles, err := s.Repositories().InvitationRepository().ListRoles(ctx, inv.InvitationID)
+		if err != nil {
+			return err
+		}
+		for _, role := range roles {
+			roleNames = append(roleNames, role.Name)
+		}
+
+		if inv.IdentityID != nil {
+			identity, err := s.Repositories().Identities().Load(ctx, *inv.IdentityID)
+			if err != nil {
+				return err
+			}
+			inv.Identity = *identity
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	inviter, err := s.Repositories().Identities().LoadWithUser(ctx, resendingUserID)
+	if err != nil {
+		return err
+	}
+
+	notifications := []invitationNotification{{invitation: inv, roles: roleNames}}
+	if inviteToIdentity != nil && inviteToIdentity.IdentityResource.ResourceType.Name == authorization.IdentityResourceTypeTeam {
+		return s.processTeamInviteNotifications(ctx, inviteToIdentity, inviter.User.FullName, notifications)
+	} else if inviteToResource != nil && inviteToResource.ResourceType.Name == authorization.ResourceTypeSpace {
+		return s.processSpaceInviteNotifications(ctx, inviteToResource, inviter.User.FullName, notifications)
+	}
+	return nil
+}
+
+// Decline deletes an invitation issued to the specified user, on behalf of this user
+func (s *invitationServiceImpl) Decline(ctx context.Context, decliningUserID, invitationID uuid.UUID) error {
+	inv, err := s.Repositories().InvitationRepository().Load(ctx, invitationID)
+	if err != nil {
+		return errors.NewNotFoundErrorFromString(fmt.Sprintf("invalid identifier '%s' provided for invitation", invitationID.String()))
+	}
+
+	if inv.IdentityID == nil || *inv.IdentityID != decliningUserID {
+		return errors.NewForbiddenError(fmt.Sprintf("invitation '%s' was not issued to identity '%s'", invitationID, decliningUserID))
+	}
+
+	err = s.ExecuteInTransaction(func() error {
+		return s.Repositories().InvitationRepository().Delete(ctx, invitationID)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"invitation_id": invitationID,
+		"identity_id":   decliningUserID,
+	}, "invitation declined")
+	return nil
+}
+
+// ListForInviteTo returns the pending invitations to the organization, team, security group or resource with the given
+// identifier. The listing user must be allowed to manage the invitations.
+func (s *invitationServiceImpl) ListForInviteTo(ctx context.Context, listingUserID uuid.UUID, inviteTo string) ([]invitationrepo.Invitation, error) {
+	inviteToIdentity, inviteToResource, err := s.loadInviteTo(ctx, inviteTo)
+	if err != nil {
+		return nil, err
+	}
+
+	var invitations []invitationrepo.Invitation
+	if inviteToIdentity != nil {
+		_, _, err = s.requireManageScope(ctx, listingUserID, &invitationrepo.Invitation{InviteTo: &inviteToIdentity.ID})
+		if err != nil {
+			return nil, err
+		}
+		invitations, err = s.Repositories().InvitationRepository().ListForIdentity(ctx, inviteToIdentity.ID)
+	} else {
+		_, _, err = s.requireManageScope(ctx, listingUserID, &invitationrepo.Invitation{ResourceID: &inviteToResource.ResourceID})
+		if err != nil {
+			return nil, err
+		}
+		invitations, err = s.Repositories().InvitationRepository().ListForResource(ctx, inviteToResource.ResourceID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pending(invitations), nil
+}
+
+// ListForUser returns the pending invitations issued to the specified user
+func (s *invitationServiceImpl) ListForUser(ctx context.Context, identityID uuid.UUID) ([]invitationrepo.Invitation, error) {
+	invitations, err := s.Repositories().InvitationRepository().ListForUser(ctx, identityID)
+	if err != nil {
+		return nil, err
+	}
+	return pending(invitations), nil
+}
+
+// pending filters out the expired invitations
+func pending(invitations []invitationrepo.Invitation) []invitationrepo.Invitation {
+	now := time.Now()
+	result := make([]invitationrepo.Invitation, 0, len(invitations))
+	for _, inv := range invitations {
+		if !inv.IsExpired(now) {
+			result = append(result, inv)
+		}
+	}
+	return result
+}
+
+// DeleteExpiredInvitations permanently deletes all the invitations which have expired, and returns their number
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *invitationServiceImpl) DeleteExpiredInvitations(ctx context.Context) (int, error) {
+	var count int
+	err := s.ExecuteInTransaction(func() error {
+		var err error
+		count, err = s.Repositories().InvitationRepository().DeleteExpired(ctx, time.Now())
+		return err
+	})
+	return count, err
+}
+
 // Accept processes an invitation acceptance click, and returns the resource ID of the resource or identity resource which the invitation is for
 func (s *invitationServiceImpl) Accept(ctx context.Context, currentIdentityID uuid.UUID, token uuid.UUID) (string, error) {
 	var resourceID string
@@ -352,6 +616,13 @@ func (s *invitationServiceImpl) Accept(ctx context.Context, currentIdentityID uu
 		return resourceID, err
 	}
 
+	if inv.IsExpired(time.Now()) {
+		return resourceID, errors.NewBadParameterErrorFromString("acceptCode", token, "the invitation has expired")
+	}
+
+	// Accepting the invitation grants new memberships and roles to the current identity
+	defer cache.Invalidate()
+
 	// If this invitation is for an identity
 	if inv.InviteTo != nil {
 		inviteToIdentity, err := s.Repositories().Identities().Load(ctx, *inv.InviteTo)
@@ -382,6 +653,10 @@ func (s *invitationServiceImpl) Accept(ctx context.Context, currentIdentityID uu
 				return resourceID, err
 			}
 		}
+		err = s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, inviteToIdentity.IdentityResourceID.String, tokenrepo.TokenStatusStale)
+		if err != nil {
+			return resourceID, err
+		}
 
 		// Delete the invitation
 		s.Repositories().InvitationRepository().Delete(ctx, inv.InvitationID)
@@ -412,6 +687,10 @@ func (s *invitationServiceImpl) Accept(ctx context.Context, currentIdentityID uu
 				return resourceID, err
 			}
 		}
+		err = s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, inviteToResource.ResourceID, tokenrepo.TokenStatusStale)
+		if err != nil {
+			return resourceID, err
+		}
 
 		// Delete the invitation
 		s.Repositories().InvitationRepository().Delete(ctx, inv.InvitationID)
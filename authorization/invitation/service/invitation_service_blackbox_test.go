@@ -1,7 +1,9 @@
 package service_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	account "github.com/fabric8-services/fabric8-auth/account/repository"
 	"github.com/fabric8-services/fabric8-auth/application/service"
@@ -13,6 +15,7 @@ import (
 	"github.com/fabric8-services/fabric8-auth/gormapplication"
 	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
 	"github.com/fabric8-services/fabric8-auth/test"
+	errs "github.com/pkg/errors"
 	"github.com/satori/go.uuid"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -126,7 +129,8 @@ func (s *invitationServiceBlackBoxTest) TestIssueInvitationOKForResource() {
 	// There should be 1 invitation only
 	require.Equal(s.T(), 1, len(invs))
 	require.False(s.T(), invs[0].Member)
-	require.Equal(s.T(), invitee.IdentityID(), invs[0].IdentityID)
+	require.NotNil(s.T(), invs[0].IdentityID)
+	require.Equal(s.T(), invitee.IdentityID(), *invs[0].IdentityID)
 
 	// List the roles for our invitation
 	roles, err := s.invitationRepo.ListRoles(s.Ctx, invs[0].InvitationID)
@@ -343,10 +347,9 @@ func (s *invitationServiceBlackBoxTest) TestIssueMultipleInvitations() {
 	found := false
 
 	for _, inv := range invs {
-		if inv.IdentityID == invitee1.IdentityID() {
+		if inv.IdentityID != nil && *inv.IdentityID == invitee1.IdentityID() {
 			found = true
 			require.True(s.T(), inv.Member)
-			require.Equal(s.T(), invitee1.IdentityID(), inv.IdentityID)
 			require.Equal(s.T(), team.TeamID(), *inv.InviteTo)
 		}
 	}
@@ -355,7 +358,7 @@ func (s *invitationServiceBlackBoxTest) TestIssueMultipleInvitations() {
 
 	found = false
 	for _, inv := range invs {
-		if inv.IdentityID == invitee2.IdentityID() {
+		if inv.IdentityID != nil && *inv.IdentityID == invitee2.IdentityID() {
 			found = true
 			require.True(s.T(), inv.Member)
 		}
@@ -424,7 +427,8 @@ func (s *invitationServiceBlackBoxTest) TestIssueTeamMemberInvite() {
 	require.NoError(s.T(), err)
 
 	require.Len(s.T(), invs, 1)
-	require.Equal(s.T(), user.IdentityID(), invs[0].IdentityID)
+	require.NotNil(s.T(), invs[0].IdentityID)
+	require.Equal(s.T(), user.IdentityID(), *invs[0].IdentityID)
 	require.True(s.T(), invs[0].Member)
 }
 
@@ -452,10 +456,95 @@ func (s *invitationServiceBlackBoxTest) TestIssueSpaceInvite() {
 	require.NoError(s.T(), err)
 
 	require.Len(s.T(), invs, 1)
-	require.Equal(s.T(), invitee.IdentityID(), invs[0].IdentityID)
+	require.NotNil(s.T(), invs[0].IdentityID)
+	require.Equal(s.T(), invitee.IdentityID(), *invs[0].IdentityID)
 	require.False(s.T(), invs[0].Member)
 }
 
+func (s *invitationServiceBlackBoxTest) TestIssueEmailInvitation() {
+	space := s.Graph.CreateSpace()
+	spaceAdmin := s.Graph.CreateUser()
+	space.AddAdmin(spaceAdmin)
+	r := s.Graph.CreateRole(s.Graph.LoadResourceType(authorization.ResourceTypeSpace))
+
+	s.T().Run("pending until bound", func(t *testing.T) {
+		email := "invitee-" + uuid.NewV4().String() + "@example.com"
+		invitations := []invitation.Invitation{
+			{
+				Email: &email,
+				Roles: []string{r.Role().Name},
+			},
+		}
+
+		err := s.Application.InvitationService().Issue(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID(), invitations)
+		require.NoError(t, err)
+
+		invs, err := s.invitationRepo.ListPendingForEmail(s.Ctx, email)
+		require.NoError(t, err)
+		require.Len(t, invs, 1)
+		require.Nil(t, invs[0].IdentityID)
+		require.Equal(t, space.SpaceID(), *invs[0].ResourceID)
+
+		// The invitation is bound once the user has been provisioned, and may then be accepted
+		invitee := s.Graph.CreateUser()
+		err = s.Application.InvitationService().BindEmailInvitations(s.Ctx, invitee.IdentityID(), strings.ToUpper(email))
+		require.NoError(t, err)
+
+		pending, err := s.invitationRepo.ListPendingForEmail(s.Ctx, email)
+		require.NoError(t, err)
+		require.Empty(t, pending)
+		inv, err := s.invitationRepo.Load(s.Ctx, invs[0].InvitationID)
+		require.NoError(t, err)
+		require.NotNil(t, inv.IdentityID)
+		require.Equal(t, invitee.IdentityID(), *inv.IdentityID)
+
+		resourceID, err := s.Application.InvitationService().Accept(s.Ctx, invitee.IdentityID(), inv.AcceptCode)
+		require.NoError(t, err)
+		require.Equal(t, space.SpaceID(), resourceID)
+	})
+
+	s.T().Run("existing user", func(t *testing.T) {
+		invitee := s.Graph.CreateUser()
+		invitee.User().EmailVerified = true
+		err := s.Application.Users().Save(s.Ctx, invitee.User())
+		require.NoError(t, err)
+		email := invitee.User().Email
+
+		invitations := []invitation.Invitation{
+			{
+				Email: &email,
+				Roles: []string{r.Role().Name},
+			},
+		}
+		err = s.Application.InvitationService().Issue(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID(), invitations)
+		require.NoError(t, err)
+
+		pending, err := s.invitationRepo.ListPendingForEmail(s.Ctx, email)
+		require.NoError(t, err)
+		require.Empty(t, pending)
+		invs, err := s.invitationRepo.ListForResource(s.Ctx, space.SpaceID())
+		require.NoError(t, err)
+		found := false
+		for _, inv := range invs {
+			if inv.IdentityID != nil && *inv.IdentityID == invitee.IdentityID() {
+				found = true
+			}
+		}
+		require.True(t, found, "invitation not issued to the existing user")
+	})
+
+	s.T().Run("no identifier", func(t *testing.T) {
+		invitations := []invitation.Invitation{
+			{
+				Roles: []string{r.Role().Name},
+			},
+		}
+		err := s.Application.InvitationService().Issue(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID(), invitations)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+}
+
 func (s *invitationServiceBlackBoxTest) TestAcceptTeamMembershipInvitation() {
 	team := s.Graph.CreateTeam()
 	user := s.Graph.CreateUser()
@@ -650,3 +739,171 @@ func (s *invitationServiceBlackBoxTest) TestRescindUnauthorisedInvitationFailsFo
 	_, err = s.Application.InvitationRepository().Load(s.Ctx, inv.Invitation().InvitationID)
 	require.NoError(s.T(), err)
 }
+
+func (s *invitationServiceBlackBoxTest) expireInvitation(t *testing.T, inv *invitationrepo.Invitation) {
+	expiresAt := time.Now().Add(-time.Minute)
+	inv.ExpiresAt = &expiresAt
+	err := s.invitationRepo.Save(s.Ctx, inv)
+	require.NoError(t, err)
+}
+
+func (s *invitationServiceBlackBoxTest) TestIssueSetsExpiry() {
+	space := s.Graph.CreateSpace()
+	spaceAdmin := s.Graph.CreateUser()
+	space.AddAdmin(spaceAdmin)
+	id := s.Graph.CreateUser().IdentityID()
+	r := s.Graph.CreateRole(s.Graph.LoadResourceType(authorization.ResourceTypeSpace))
+
+	err := s.Application.InvitationService().Issue(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID(), []invitation.Invitation{
+		{
+			IdentityID: &id,
+			Roles:      []string{r.Role().Name},
+		},
+	})
+	require.NoError(s.T(), err)
+
+	invs, err := s.invitationRepo.ListForResource(s.Ctx, space.SpaceID())
+	require.NoError(s.T(), err)
+	require.Len(s.T(), invs, 1)
+	require.NotNil(s.T(), invs[0].ExpiresAt)
+	require.WithinDuration(s.T(), time.Now().Add(s.Configuration.GetInvitationTTL()), *invs[0].ExpiresAt, time.Minute)
+}
+
+func (s *invitationServiceBlackBoxTest) TestAcceptFailsForExpiredInvitation() {
+	space := s.Graph.CreateSpace()
+	user := s.Graph.CreateUser()
+	spaceRole := s.Graph.CreateRole(s.Graph.LoadResourceType(authorization.ResourceTypeSpace))
+	inv := s.Graph.CreateInvitation(space, user, spaceRole)
+	s.expireInvitation(s.T(), inv.Invitation())
+
+	_, err := s.Application.InvitationService().Accept(s.Ctx, user.IdentityID(), inv.Invitation().AcceptCode)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+
+	roles, err := s.Application.IdentityRoleRepository().FindIdentityRolesForIdentity(s.Ctx, user.IdentityID(), nil)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), roles)
+}
+
+func (s *invitationServiceBlackBoxTest) TestResendInvitation() {
+	space := s.Graph.CreateSpace()
+	spaceAdmin := s.Graph.CreateUser()
+	space.AddAdmin(spaceAdmin)
+	user := s.Graph.CreateUser()
+	spaceRole := s.Graph.CreateRole(s.Graph.LoadResourceType(authorization.ResourceTypeSpace))
+	inv := s.Graph.CreateInvitation(space, user, spaceRole)
+	previousCode := inv.Invitation().AcceptCode
+	s.expireInvitation(s.T(), inv.Invitation())
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		err := s.Application.InvitationService().Resend(s.Ctx, user.IdentityID(), inv.Invitation().InvitationID)
+		require.Error(t, err)
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+	})
+
+	s.T().Run("unknown invitation", func(t *testing.T) {
+		err := s.Application.InvitationService().Resend(s.Ctx, spaceAdmin.IdentityID(), uuid.NewV4())
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+
+	s.T().Run("ok", func(t *testing.T) {
+		err := s.Application.InvitationService().Resend(s.Ctx, spaceAdmin.IdentityID(), inv.Invitation().InvitationID)
+		require.NoError(t, err)
+
+		resent, err := s.invitationRepo.Load(s.Ctx, inv.Invitation().InvitationID)
+		require.NoError(t, err)
+		require.NotEqual(t, previousCode, resent.AcceptCode)
+		require.False(t, resent.IsExpired(time.Now()))
+
+		// The previous code is not valid anymore, but the new one is
+		_, err = s.Application.InvitationService().Accept(s.Ctx, user.IdentityID(), previousCode)
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+		resourceID, err := s.Application.InvitationService().Accept(s.Ctx, user.IdentityID(), resent.AcceptCode)
+		require.NoError(t, err)
+		require.Equal(t, space.SpaceID(), resourceID)
+	})
+}
+
+func (s *invitationServiceBlackBoxTest) TestDeclineInvitation() {
+	space := s.Graph.CreateSpace()
+	user := s.Graph.CreateUser()
+	spaceRole := s.Graph.CreateRole(s.Graph.LoadResourceType(authorization.ResourceTypeSpace))
+	inv := s.Graph.CreateInvitation(space, user, spaceRole)
+
+	// Only the invited user may decline the invitation
+	err := s.Application.InvitationService().Decline(s.Ctx, s.Graph.CreateUser().IdentityID(), inv.Invitation().InvitationID)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.ForbiddenError{}, errs.Cause(err))
+
+	err = s.Application.InvitationService().Decline(s.Ctx, user.IdentityID(), inv.Invitation().InvitationID)
+	require.NoError(s.T(), err)
+
+	_, err = s.invitationRepo.Load(s.Ctx, inv.Invitation().InvitationID)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+
+	err = s.Application.InvitationService().Decline(s.Ctx, user.IdentityID(), inv.Invitation().InvitationID)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *invitationServiceBlackBoxTest) TestListPendingInvitations() {
+	space := s.Graph.CreateSpace()
+	spaceAdmin := s.Graph.CreateUser()
+	space.AddAdmin(spaceAdmin)
+	user := s.Graph.CreateUser()
+	spaceRole := s.Graph.CreateRole(s.Graph.LoadResourceType(authorization.ResourceTypeSpace))
+	inv := s.Graph.CreateInvitation(space, user, spaceRole)
+	expired := s.Graph.CreateInvitation(space, spaceRole)
+	s.expireInvitation(s.T(), expired.Invitation())
+	// noise
+	s.Graph.CreateInvitation(s.Graph.CreateSpace(), user, spaceRole)
+
+	s.T().Run("for resource", func(t *testing.T) {
+		invs, err := s.Application.InvitationService().ListForInviteTo(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID())
+		require.NoError(t, err)
+		require.Len(t, invs, 1)
+		require.Equal(t, inv.Invitation().InvitationID, invs[0].InvitationID)
+	})
+
+	s.T().Run("for resource forbidden", func(t *testing.T) {
+		_, err := s.Application.InvitationService().ListForInviteTo(s.Ctx, user.IdentityID(), space.SpaceID())
+		require.Error(t, err)
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+	})
+
+	s.T().Run("for unknown resource", func(t *testing.T) {
+		_, err := s.Application.InvitationService().ListForInviteTo(s.Ctx, spaceAdmin.IdentityID(), uuid.NewV4().String())
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+
+	s.T().Run("for user", func(t *testing.T) {
+		invs, err := s.Application.InvitationService().ListForUser(s.Ctx, user.IdentityID())
+		require.NoError(t, err)
+		require.Len(t, invs, 2)
+
+		invs, err = s.Application.InvitationService().ListForUser(s.Ctx, *expired.Invitation().IdentityID)
+		require.NoError(t, err)
+		require.Empty(t, invs)
+	})
+}
+
+func (s *invitationServiceBlackBoxTest) TestDeleteExpiredInvitations() {
+	space := s.Graph.CreateSpace()
+	spaceRole := s.Graph.CreateRole(s.Graph.LoadResourceType(authorization.ResourceTypeSpace))
+	inv := s.Graph.CreateInvitation(space, spaceRole)
+	expired := s.Graph.CreateInvitation(space, spaceRole)
+	s.expireInvitation(s.T(), expired.Invitation())
+
+	count, err := s.Application.InvitationService().DeleteExpiredInvitations(s.Ctx)
+	require.NoError(s.T(), err)
+	require.True(s.T(), count >= 1)
+
+	_, err = s.invitationRepo.Load(s.Ctx, expired.Invitation().InvitationID)
+	require.Error(s.T(), err)
+	_, err = s.invitationRepo.Load(s.Ctx, inv.Invitation().InvitationID)
+	require.NoError(s.T(), err)
+}
@@ -0,0 +1,16 @@
+package organization
+
+import (
+	account "github.com/fabric8-services/fabric8-auth/account/repository"
+)
+
+// Member is a DTO used to pass the details of a member of an organization between the service layer and controller layer.
+// An identity is a member of an organization if it was added to the members of the organization, or if it was assigned
+// a role for the organization, or both.
+type Member struct {
+	Identity account.Identity
+	// Member is true if the identity was added to the members of the organization
+	Member bool
+	// Roles contains the names of the roles assigned to the identity for the organization
+	Roles []string
+}
@@ -8,8 +8,11 @@ import (
 	"github.com/fabric8-services/fabric8-auth/application/service/base"
 	servicecontext "github.com/fabric8-services/fabric8-auth/application/service/context"
 	"github.com/fabric8-services/fabric8-auth/authorization"
+	"github.com/fabric8-services/fabric8-auth/authorization/organization"
+	"github.com/fabric8-services/fabric8-auth/authorization/permission/cache"
 	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
 	role "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
+	tokenrepo "github.com/fabric8-services/fabric8-auth/authorization/token/repository"
 	"github.com/fabric8-services/fabric8-auth/errors"
 	"github.com/fabric8-services/fabric8-auth/log"
 
@@ -61,6 +64,12 @@ func (s *organizationServiceImpl) CreateOrganization(ctx context.Context, creato
 			return errors.NewInternalError(ctx, err)
 		}
 
+		// Apply the default role mappings of organizations, so that the roles of the organization flow down to its spaces
+		err = s.Services().ResourceService().ApplyDefaultRoleMappings(ctx, res)
+		if err != nil {
+			return err
+		}
+
 		// Create the organization identity
 		orgIdentity := &account.Identity{
 			IdentityResourceID: sql.NullString{res.ResourceID, true},
@@ -126,3 +135,296 @@ func (s *organizationServiceImpl) ListOrganizations(ctx context.Context, identit
 
 	return authorization.MergeAssociations(memberships, roles), nil
 }
+
+// ListMembers returns the members of the specified organization along with their roles, if the specified identity has
+// the necessary privileges to view the members of the organization. The identities which were assigned a role for the
+// organization without being added to its members are included.
+func (s *organizationServiceImpl) ListMembers(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID) ([]organization.Member, error) {
+	org, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, organizationID, authorization.IdentityResourceTypeOrganization)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Services().PermissionService().RequireScope(ctx, identityID, org.ResourceID, authorization.ViewOrganizationMembersScope)
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := s.Repositories().Identities().ListMembers(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	var members []organization.Member
+	memberIndexes := make(map[uuid.UUID]int)
+	for _, identity := range identities {
+		memberIndexes[identity.ID] = len(members)
+		members = append(members, organization.Member{Identity: identity, Member: true, Roles: []string{}})
+	}
+
+	identityRoles, err := s.Repositories().IdentityRoleRepository().FindIdentityRolesByResource(ctx, org.ResourceID, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, ir := range identityRoles {
+		i, found := memberIndexes[ir.IdentityID]
+		if !found {
+			identity := ir.Identity
+			// teams may be assigned roles too, in which case there is no user to load
+			withUser, err := s.Repositories().Identities().LoadWithUser(ctx, ir.IdentityID)
+			if err == nil {
+				identity = *withUser
+			} else if notFound, _ := errors.IsNotFoundError(err); !notFound {
+				return nil, err
+			}
+			i = len(members)
+			memberIndexes[ir.IdentityID] = i
+			members = append(members, organization.Member{Identity: identity, Roles: []string{}})
+		}
+		members[i].Roles = append(members[i].Roles, ir.Role.Name)
+	}
+	return members, nil
+}
+
+// RemoveMember removes the specified member from the specified organization, along with all the roles assigned to the
+// member for the organization, if the specified identity has the necessary privileges to manage the members of the
+// organization. The last administrator of the organization cannot be removed.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *organizationServiceImpl) RemoveMember(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID, memberID uuid.UUID) error {
+	org, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, organizationID, authorization.IdentityResourceTypeOrganization)
+	if err != nil {
+		return err
+	}
+
+	err = s.Services().PermissionService().RequireScope(ctx, identityID, org.ResourceID, authorization.ManageOrganizationMembersScope)
+	if err != nil {
+		return err
+	}
+
+	return s.removeMember(ctx, org, organizationID, memberID)
+}
+
+// Leave removes the specified identity from the members of the specified organization, along with all the roles assigned
+// to the identity for the organization. The last administrator of the organization cannot leave it.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *organizationServiceImpl) Leave(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID) error {
+	org, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, organizationID, authorization.IdentityResourceTypeOrganization)
+	if err != nil {
+		return err
+	}
+
+	return s.removeMember(ctx, org, organizationID, identityID)
+}
+
+// ChangeMemberRole replaces the roles assigned to the specified member for the specified organization with the role with
+// the specified name, if the specified identity has the necessary privileges to manage the members of the organization.
+// The role of the last administrator of the organization cannot be changed.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *organizationServiceImpl) ChangeMemberRole(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID, memberID uuid.UUID, roleName string) error {
+	org, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, organizationID, authorization.IdentityResourceTypeOrganization)
+	if err != nil {
+		return err
+	}
+
+	err = s.Services().PermissionService().RequireScope(ctx, identityID, org.ResourceID, authorization.ManageOrganizationMembersScope)
+	if err != nil {
+		return err
+	}
+
+	r, err := s.Repositories().RoleRepository().Lookup(ctx, roleName, authorization.IdentityResourceTypeOrganization)
+	if err != nil {
+		if notFound, _ := errors.IsNotFoundError(err); notFound {
+			return errors.NewBadParameterErrorFromString("role", roleName, fmt.Sprintf("no role named %s exists for organizations", roleName))
+		}
+		return err
+	}
+
+	err = s.ExecuteInTransaction(func() error {
+		isMember, err := s.isMember(ctx, org, organizationID, memberID)
+		if err != nil {
+			return err
+		}
+		if !isMember {
+			return errors.NewNotFoundError("organization member", memberID.String())
+		}
+
+		err = s.Repositories().IdentityRoleRepository().DeleteForIdentityAndResource(ctx, org.ResourceID, memberID)
+		if err != nil {
+			if notFound, _ := errors.IsNotFoundError(err); !notFound {
+				return err
+			}
+		}
+		err = s.Repositories().IdentityRoleRepository().Create(ctx, &role.IdentityRole{
+			IdentityID: memberID,
+			ResourceID: org.ResourceID,
+			RoleID:     r.RoleID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.requireAdmin(ctx, org)
+		if err != nil {
+			return err
+		}
+		return s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, org.ResourceID, tokenrepo.TokenStatusStale)
+	})
+	cache.Invalidate()
+	if err != nil {
+		return err
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"organization_id": organizationID,
+		"member_id":       memberID,
+		"role_name":       roleName,
+		"identity_id":     identityID,
+	}, "organization member role changed")
+	return nil
+}
+
+// UpdateOrganization renames the specified organization, if the specified identity has the necessary privileges to
+// manage the organization. The name of the organization is held by its resource, and must be unique.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *organizationServiceImpl) UpdateOrganization(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID, organizationName string) error {
+	return s.ExecuteInTransaction(func() error {
+		org, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, organizationID, authorization.IdentityResourceTypeOrganization)
+		if err != nil {
+			return err
+		}
+
+		err = s.Services().PermissionService().RequireScope(ctx, identityID, org.ResourceID, authorization.ManageOrganizationScope)
+		if err != nil {
+			return err
+		}
+
+		org.Name = organizationName
+		err = s.Repositories().ResourceRepository().Save(ctx, org)
+		if err != nil {
+			return err
+		}
+
+		log.Info(ctx, map[string]interface{}{
+			"organization_id":   organizationID,
+			"organization_name": organizationName,
+			"identity_id":       identityID,
+		}, "organization renamed")
+		return nil
+	})
+}
+
+// DeleteOrganization deletes the specified organization, if the specified identity has the necessary privileges to
+// manage the organization. The memberships of the organization, the roles assigned to it or for it, its security groups
+// and the pending invitations to join it are deleted along with the organization. An organization which still owns
+// other resources, such as spaces, cannot be deleted.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *organizationServiceImpl) DeleteOrganization(ctx context.Context, identityID uuid.UUID, organizationID uuid.UUID) error {
+	return s.ExecuteInTransaction(func() error {
+		org, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, organizationID, authorization.IdentityResourceTypeOrganization)
+		if err != nil {
+			return err
+		}
+
+		err = s.Services().PermissionService().RequireScope(ctx, identityID, org.ResourceID, authorization.ManageOrganizationScope)
+		if err != nil {
+			return err
+		}
+
+		children, err := s.Repositories().ResourceRepository().LoadChildren(ctx, org.ResourceID)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if !authorization.CanHaveMembers(child.ResourceType.Name) {
+				return errors.NewDataConflictError(fmt.Sprintf("organization %s still owns resource %s", org.Name, child.ResourceID))
+			}
+		}
+
+		err = s.Services().ResourceService().Delete(ctx, org.ResourceID)
+		if err != nil {
+			return err
+		}
+
+		log.Info(ctx, map[string]interface{}{
+			"organization_id": organizationID,
+			"identity_id":     identityID,
+		}, "organization deleted")
+		return nil
+	})
+}
+
+// removeMember removes the membership of the member and the roles assigned to the member for the organization, within
+// a transaction which is rolled back if the organization has no administrator left
+func (s *organizationServiceImpl) removeMember(ctx context.Context, org *resource.Resource, organizationID uuid.UUID, memberID uuid.UUID) error {
+	err := s.ExecuteInTransaction(func() error {
+		isMember, err := s.isMember(ctx, org, organizationID, memberID)
+		if err != nil {
+			return err
+		}
+		if !isMember {
+			return errors.NewNotFoundError("organization member", memberID.String())
+		}
+
+		err = s.Repositories().Identities().RemoveMember(ctx, organizationID, memberID)
+		if err != nil {
+			if notFound, _ := errors.IsNotFoundError(err); !notFound {
+				return err
+			}
+		}
+		err = s.Repositories().IdentityRoleRepository().DeleteForIdentityAndResource(ctx, org.ResourceID, memberID)
+		if err != nil {
+			if notFound, _ := errors.IsNotFoundError(err); !notFound {
+				return err
+			}
+		}
+
+		err = s.requireAdmin(ctx, org)
+		if err != nil {
+			return err
+		}
+		return s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, org.ResourceID, tokenrepo.TokenStatusStale)
+	})
+	cache.Invalidate()
+	if err != nil {
+		return err
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"organization_id": organizationID,
+		"member_id":       memberID,
+	}, "organization member removed")
+	return nil
+}
+
+// isMember returns true if the identity was added to the members of the organization, or was assigned a role for it
+func (s *organizationServiceImpl) isMember(ctx context.Context, org *resource.Resource, organizationID uuid.UUID, identityID uuid.UUID) (bool, error) {
+	members, err := s.Repositories().Identities().ListMembers(ctx, organizationID)
+	if err != nil {
+		return false, err
+	}
+	for _, member := range members {
+		if member.ID == identityID {
+			return true, nil
+		}
+	}
+
+	roles, err := s.Repositories().IdentityRoleRepository().FindIdentityRolesByIdentityAndResource(ctx, org.ResourceID, identityID)
+	if err != nil {
+		return false, err
+	}
+	return len(roles) > 0, nil
+}
+
+// requireAdmin returns a data conflict error if no identity has the admin role for the organization
+func (s *organizationServiceImpl) requireAdmin(ctx context.Context, org *resource.Resource) error {
+	admins, err := s.Repositories().IdentityRoleRepository().FindIdentityRolesByResourceAndRoleName(ctx, org.ResourceID, authorization.OrganizationAdminRole, false)
+	if err != nil {
+		return err
+	}
+	if len(admins) == 0 {
+		log.Error(ctx, map[string]interface{}{
+			"organization_resource_id": org.ResourceID,
+		}, "the organization would be left without any administrator")
+		return errors.NewDataConflictError(fmt.Sprintf("the last administrator of organization %s cannot be removed", org.Name))
+	}
+	return nil
+}
@@ -6,11 +6,14 @@ import (
 	account "github.com/fabric8-services/fabric8-auth/account/repository"
 	"github.com/fabric8-services/fabric8-auth/application/service"
 	"github.com/fabric8-services/fabric8-auth/authorization"
+	"github.com/fabric8-services/fabric8-auth/authorization/organization"
 	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
 	role "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
+	"github.com/fabric8-services/fabric8-auth/errors"
 	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
 	"github.com/fabric8-services/fabric8-auth/test"
 
+	errs "github.com/pkg/errors"
 	"github.com/satori/go.uuid"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -72,6 +75,11 @@ func (s *organizationServiceBlackBoxTest) TestCreateOrganization() {
 	}
 
 	require.Equal(s.T(), 1, roleCount, "Found more than 1 role")
+
+	// The default role mappings of organizations have been applied
+	mappings, err := s.Application.RoleMappingRepository().FindForResource(s.Ctx, orgResource.ResourceID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), mappings, 2)
 }
 
 func (s *organizationServiceBlackBoxTest) TestListOrganization() {
@@ -122,3 +130,247 @@ func (s *organizationServiceBlackBoxTest) equalOrganization(expectedOrgID uuid.U
 	require.Equal(s.T(), 1, len(actualOrg.Roles), "New organization should have assigned exactly 1 role")
 	require.Equal(s.T(), authorization.OrganizationAdminRole, actualOrg.Roles[0], "New organization should have assigned admin role")
 }
+
+func (s *organizationServiceBlackBoxTest) TestListMembers() {
+	admin := s.Graph.CreateUser()
+	org := s.Graph.CreateOrganization(admin)
+	member := s.Graph.CreateUser()
+	viewer := s.Graph.CreateUser()
+	org.AddMember(member).AddViewer(viewer).AddViewer(member)
+
+	s.T().Run("ok", func(t *testing.T) {
+		members, err := s.orgService.ListMembers(s.Ctx, viewer.IdentityID(), org.OrganizationID())
+		require.NoError(t, err)
+		require.Len(t, members, 3)
+
+		found := make(map[uuid.UUID]organization.Member)
+		for _, m := range members {
+			found[m.Identity.ID] = m
+		}
+		require.Equal(t, []string{authorization.OrganizationAdminRole}, found[admin.IdentityID()].Roles)
+		require.False(t, found[admin.IdentityID()].Member)
+		require.Equal(t, admin.User().Username, found[admin.IdentityID()].Identity.Username)
+		require.Equal(t, []string{authorization.OrganizationViewerRole}, found[member.IdentityID()].Roles)
+		require.True(t, found[member.IdentityID()].Member)
+		require.Equal(t, []string{authorization.OrganizationViewerRole}, found[viewer.IdentityID()].Roles)
+		require.False(t, found[viewer.IdentityID()].Member)
+	})
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		_, err := s.orgService.ListMembers(s.Ctx, s.Graph.CreateUser().IdentityID(), org.OrganizationID())
+		require.Error(t, err)
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+	})
+
+	s.T().Run("unknown organization", func(t *testing.T) {
+		_, err := s.orgService.ListMembers(s.Ctx, admin.IdentityID(), uuid.NewV4())
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+}
+
+func (s *organizationServiceBlackBoxTest) TestRemoveMember() {
+	admin := s.Graph.CreateUser()
+	org := s.Graph.CreateOrganization(admin)
+
+	s.T().Run("ok", func(t *testing.T) {
+		member := s.Graph.CreateUser()
+		org.AddMember(member).AddViewer(member)
+
+		err := s.orgService.RemoveMember(s.Ctx, admin.IdentityID(), org.OrganizationID(), member.IdentityID())
+		require.NoError(t, err)
+
+		members, err := s.identityRepo.ListMembers(s.Ctx, org.OrganizationID())
+		require.NoError(t, err)
+		require.Empty(t, members)
+		roles, err := s.identityRoleRepo.FindIdentityRolesByIdentityAndResource(s.Ctx, org.ResourceID(), member.IdentityID())
+		require.NoError(t, err)
+		require.Empty(t, roles)
+	})
+
+	s.T().Run("not a member", func(t *testing.T) {
+		err := s.orgService.RemoveMember(s.Ctx, admin.IdentityID(), org.OrganizationID(), s.Graph.CreateUser().IdentityID())
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		viewer := s.Graph.CreateUser()
+		org.AddViewer(viewer)
+
+		err := s.orgService.RemoveMember(s.Ctx, viewer.IdentityID(), org.OrganizationID(), admin.IdentityID())
+		require.Error(t, err)
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+	})
+
+	s.T().Run("last admin", func(t *testing.T) {
+		err := s.orgService.RemoveMember(s.Ctx, admin.IdentityID(), org.OrganizationID(), admin.IdentityID())
+		require.Error(t, err)
+		require.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+
+		roles, err := s.identityRoleRepo.FindIdentityRolesByIdentityAndResource(s.Ctx, org.ResourceID(), admin.IdentityID())
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+	})
+}
+
+func (s *organizationServiceBlackBoxTest) TestChangeMemberRole() {
+	admin := s.Graph.CreateUser()
+	org := s.Graph.CreateOrganization(admin)
+	member := s.Graph.CreateUser()
+	org.AddMember(member)
+
+	s.T().Run("ok", func(t *testing.T) {
+		err := s.orgService.ChangeMemberRole(s.Ctx, admin.IdentityID(), org.OrganizationID(), member.IdentityID(), authorization.OrganizationAdminRole)
+		require.NoError(t, err)
+		err = s.orgService.ChangeMemberRole(s.Ctx, admin.IdentityID(), org.OrganizationID(), member.IdentityID(), authorization.OrganizationViewerRole)
+		require.NoError(t, err)
+
+		roles, err := s.identityRoleRepo.FindIdentityRolesByResource(s.Ctx, org.ResourceID(), false)
+		require.NoError(t, err)
+		require.Len(t, roles, 2)
+		for _, r := range roles {
+			if r.IdentityID == member.IdentityID() {
+				require.Equal(t, authorization.OrganizationViewerRole, r.Role.Name)
+			} else {
+				require.Equal(t, authorization.OrganizationAdminRole, r.Role.Name)
+			}
+		}
+	})
+
+	s.T().Run("unknown role", func(t *testing.T) {
+		err := s.orgService.ChangeMemberRole(s.Ctx, admin.IdentityID(), org.OrganizationID(), member.IdentityID(), "unknown")
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("not a member", func(t *testing.T) {
+		err := s.orgService.ChangeMemberRole(s.Ctx, admin.IdentityID(), org.OrganizationID(), s.Graph.CreateUser().IdentityID(), authorization.OrganizationViewerRole)
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		err := s.orgService.ChangeMemberRole(s.Ctx, member.IdentityID(), org.OrganizationID(), member.IdentityID(), authorization.OrganizationAdminRole)
+		require.Error(t, err)
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+	})
+
+	s.T().Run("last admin", func(t *testing.T) {
+		err := s.orgService.ChangeMemberRole(s.Ctx, admin.IdentityID(), org.OrganizationID(), admin.IdentityID(), authorization.OrganizationViewerRole)
+		require.Error(t, err)
+		require.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+}
+
+func (s *organizationServiceBlackBoxTest) TestLeave() {
+	admin := s.Graph.CreateUser()
+	org := s.Graph.CreateOrganization(admin)
+
+	s.T().Run("ok", func(t *testing.T) {
+		member := s.Graph.CreateUser()
+		org.AddMember(member).AddViewer(member)
+
+		err := s.orgService.Leave(s.Ctx, member.IdentityID(), org.OrganizationID())
+		require.NoError(t, err)
+
+		orgs, err := s.orgService.ListOrganizations(s.Ctx, member.IdentityID())
+		require.NoError(t, err)
+		require.Empty(t, orgs)
+	})
+
+	s.T().Run("not a member", func(t *testing.T) {
+		err := s.orgService.Leave(s.Ctx, s.Graph.CreateUser().IdentityID(), org.OrganizationID())
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+
+	s.T().Run("last admin", func(t *testing.T) {
+		err := s.orgService.Leave(s.Ctx, admin.IdentityID(), org.OrganizationID())
+		require.Error(t, err)
+		require.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("one of two admins", func(t *testing.T) {
+		other := s.Graph.CreateUser()
+		org.AddAdmin(other)
+
+		err := s.orgService.Leave(s.Ctx, admin.IdentityID(), org.OrganizationID())
+		require.NoError(t, err)
+	})
+}
+
+func (s *organizationServiceBlackBoxTest) TestUpdateOrganization() {
+	admin := s.Graph.CreateUser()
+	org := s.Graph.CreateOrganization(admin)
+	other := s.Graph.CreateOrganization()
+
+	s.T().Run("ok", func(t *testing.T) {
+		name := "Renamed Organization " + uuid.NewV4().String()
+		err := s.orgService.UpdateOrganization(s.Ctx, admin.IdentityID(), org.OrganizationID(), name)
+		require.NoError(t, err)
+
+		res, err := s.resourceRepo.Load(s.Ctx, org.ResourceID())
+		require.NoError(t, err)
+		require.Equal(t, name, res.Name)
+	})
+
+	s.T().Run("duplicate name", func(t *testing.T) {
+		err := s.orgService.UpdateOrganization(s.Ctx, admin.IdentityID(), org.OrganizationID(), other.Resource().Name)
+		require.Error(t, err)
+		require.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		member := s.Graph.CreateUser()
+		org.AddMember(member)
+		err := s.orgService.UpdateOrganization(s.Ctx, member.IdentityID(), org.OrganizationID(), "forbidden")
+		require.Error(t, err)
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+	})
+}
+
+func (s *organizationServiceBlackBoxTest) TestDeleteOrganization() {
+	s.T().Run("ok", func(t *testing.T) {
+		admin := s.Graph.CreateUser()
+		org := s.Graph.CreateOrganization(admin)
+		member := s.Graph.CreateUser()
+		org.AddMember(member).AddViewer(member)
+		group := s.Graph.CreateGroup(org).AddMember(member)
+		s.Graph.CreateInvitation(org, s.Graph.CreateUser())
+
+		err := s.orgService.DeleteOrganization(s.Ctx, admin.IdentityID(), org.OrganizationID())
+		require.NoError(t, err)
+
+		_, err = s.identityRepo.Load(s.Ctx, org.OrganizationID())
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+		_, err = s.identityRepo.Load(s.Ctx, group.GroupID())
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+		orgs, err := s.orgService.ListOrganizations(s.Ctx, member.IdentityID())
+		require.NoError(t, err)
+		require.Empty(t, orgs)
+		orgs, err = s.orgService.ListOrganizations(s.Ctx, admin.IdentityID())
+		require.NoError(t, err)
+		require.Empty(t, orgs)
+	})
+
+	s.T().Run("owns spaces", func(t *testing.T) {
+		admin := s.Graph.CreateUser()
+		org := s.Graph.CreateOrganization(admin)
+		s.Graph.CreateSpace(org)
+
+		err := s.orgService.DeleteOrganization(s.Ctx, admin.IdentityID(), org.OrganizationID())
+		require.Error(t, err)
+		require.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		org := s.Graph.CreateOrganization()
+		viewer := s.Graph.CreateUser()
+		org.AddViewer(viewer)
+
+		err := s.orgService.DeleteOrganization(s.Ctx, viewer.IdentityID(), org.OrganizationID())
+		require.Error(t, err)
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+	})
+}
@@ -0,0 +1,2 @@
+// Package cache provides an in-process cache for the results of permission checks
+package cache
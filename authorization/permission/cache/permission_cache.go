@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/satori/go.uuid"
+)
+
+// maxEntries is the maximum number of entries kept in a cache. The cache is emptied when this limit is reached.
+const maxEntries = 100000
+
+var (
+	hitsCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "auth",
+		Subsystem: "permission_cache",
+		Name:      "hits_total",
+		Help:      "Number of permission checks answered by the permission cache",
+	})
+	missesCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "auth",
+		Subsystem: "permission_cache",
+		Name:      "misses_total",
+		Help:      "Number of permission checks which could not be answered by the permission cache",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(hitsCounter, missesCounter)
+}
+
+type permissionKey struct {
+	identityID uuid.UUID
+	resourceID string
+	scopeName  string
+}
+
+type permissionEntry struct {
+	granted bool
+	expiry  time.Time
+}
+
+// PermissionCache caches the results of permission checks, keyed by identity, resource and scope.
+// The cache must be invalidated whenever the roles, role mappings, memberships or resources change. Since the invalidation
+// only applies to the current process, the entries also expire after a configurable TTL. A TTL of zero disables the cache.
+type PermissionCache struct {
+	mutex      sync.RWMutex
+	ttl        time.Duration
+	generation uint64
+	entries    map[permissionKey]permissionEntry
+}
+
+// NewPermissionCache creates a new permission cache whose entries expire after the given TTL
+func NewPermissionCache(ttl time.Duration) *PermissionCache {
+	return &PermissionCache{
+		ttl:     ttl,
+		entries: make(map[permissionKey]permissionEntry),
+	}
+}
+
+// SetTTL sets the TTL of the cache entries and invalidates the existing ones. A TTL of zero disables the cache.
+func (c *PermissionCache) SetTTL(ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.ttl = ttl
+	c.invalidate()
+}
+
+// Generation returns the current generation of the cache, which changes every time the cache is invalidated.
+// It must be obtained before checking the permissions in the database, and passed to Put along with the result.
+func (c *PermissionCache) Generation() uint64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.generation
+}
+
+// Get returns the cached result of the permission check, and whether it was found in the cache
+func (c *PermissionCache) Get(identityID uuid.UUID, resourceID string, scopeName string) (granted bool, found bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.ttl <= 0 {
+		return false, false
+	}
+	entry, found := c.entries[permissionKey{identityID: identityID, resourceID: resourceID, scopeName: scopeName}]
+	if !found || time.Now().After(entry.expiry) {
+		missesCounter.Inc()
+		return false, false
+	}
+	hitsCounter.Inc()
+	return entry.granted, true
+}
+
+// Put stores the result of the permission check, unless the cache has been invalidated since the given generation
+// was obtained (in which case the result may already be out of date). If the permission is granted by role assignments
+// which expire, expiresAt is the earliest of their expiries, and the entry does not outlive it.
+func (c *PermissionCache) Put(generation uint64, identityID uuid.UUID, resourceID string, scopeName string, granted bool, expiresAt *time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.ttl <= 0 || generation != c.generation {
+		return
+	}
+	expiry := time.Now().Add(c.ttl)
+	if expiresAt != nil && expiresAt.Before(expiry) {
+		expiry = *expiresAt
+	}
+	if len(c.entries) >= maxEntries {
+		c.entries = make(map[permissionKey]permissionEntry)
+	}
+	c.entries[permissionKey{identityID: identityID, resourceID: resourceID, scopeName: scopeName}] = permissionEntry{
+		granted: granted,
+		expiry:  expiry,
+	}
+}
+
+// Invalidate removes all the entries from the cache
+func (c *PermissionCache) Invalidate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.invalidate()
+}
+
+func (c *PermissionCache) invalidate() {
+	c.generation++
+	c.entries = make(map[permissionKey]permissionEntry)
+}
+
+// defaultCache is the permission cache shared by the whole process. It is disabled until a TTL is set.
+var defaultCache = NewPermissionCache(0)
+
+// Default returns the permission cache shared by the whole process
+func Default() *PermissionCache {
+	return defaultCache
+}
+
+// Invalidate removes all the entries from the permission cache shared by the whole process.
+// It must be called whenever a change may affect the result of the permission checks.
+func Invalidate() {
+	defaultCache.Invalidate()
+}
@@ -0,0 +1,78 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-auth/authorization/permission/cache"
+	"github.com/fabric8-services/fabric8-auth/resource"
+
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissionCache(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	identityID := uuid.NewV4()
+	resourceID := uuid.NewV4().String()
+
+	t.Run("put and get", func(t *testing.T) {
+		c := cache.NewPermissionCache(time.Minute)
+		_, found := c.Get(identityID, resourceID, "view")
+		assert.False(t, found)
+
+		c.Put(c.Generation(), identityID, resourceID, "view", true, nil)
+		c.Put(c.Generation(), identityID, resourceID, "manage", false, nil)
+
+		granted, found := c.Get(identityID, resourceID, "view")
+		assert.True(t, found)
+		assert.True(t, granted)
+		granted, found = c.Get(identityID, resourceID, "manage")
+		assert.True(t, found)
+		assert.False(t, granted)
+		_, found = c.Get(uuid.NewV4(), resourceID, "view")
+		assert.False(t, found)
+	})
+
+	t.Run("entries expire", func(t *testing.T) {
+		c := cache.NewPermissionCache(time.Millisecond)
+		c.Put(c.Generation(), identityID, resourceID, "view", true, nil)
+		time.Sleep(5 * time.Millisecond)
+		_, found := c.Get(identityID, resourceID, "view")
+		assert.False(t, found)
+	})
+
+	t.Run("entries do not outlive the roles", func(t *testing.T) {
+		c := cache.NewPermissionCache(time.Minute)
+		expiresAt := time.Now().Add(time.Millisecond)
+		c.Put(c.Generation(), identityID, resourceID, "view", true, &expiresAt)
+		time.Sleep(5 * time.Millisecond)
+		_, found := c.Get(identityID, resourceID, "view")
+		assert.False(t, found)
+	})
+
+	t.Run("invalidate removes entries", func(t *testing.T) {
+		c := cache.NewPermissionCache(time.Minute)
+		c.Put(c.Generation(), identityID, resourceID, "view", true, nil)
+		c.Invalidate()
+		_, found := c.Get(identityID, resourceID, "view")
+		assert.False(t, found)
+	})
+
+	t.Run("put ignored after invalidation", func(t *testing.T) {
+		c := cache.NewPermissionCache(time.Minute)
+		generation := c.Generation()
+		c.Invalidate()
+		c.Put(generation, identityID, resourceID, "view", true, nil)
+		_, found := c.Get(identityID, resourceID, "view")
+		assert.False(t, found)
+	})
+
+	t.Run("disabled with zero ttl", func(t *testing.T) {
+		c := cache.NewPermissionCache(0)
+		c.Put(c.Generation(), identityID, resourceID, "view", true, nil)
+		_, found := c.Get(identityID, resourceID, "view")
+		assert.False(t, found)
+	})
+}
@@ -3,9 +3,15 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
+
 	"github.com/fabric8-services/fabric8-auth/application/service"
 	"github.com/fabric8-services/fabric8-auth/application/service/base"
 	servicecontext "github.com/fabric8-services/fabric8-auth/application/service/context"
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	"github.com/fabric8-services/fabric8-auth/authorization/permission/cache"
+	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
+	rolerepo "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
 	"github.com/fabric8-services/fabric8-auth/errors"
 	"github.com/satori/go.uuid"
 )
@@ -28,14 +34,218 @@ func NewPermissionService(context servicecontext.ServiceContext) service.Permiss
 // parent and other ancestor resources, and also takes into account role mappings, which allow roles assigned for a
 // certain type of resource in the resource ancestry to map to a role for a different resource type lower in the
 // resource hierarchy.
+// The results are cached until the next change of roles, memberships or resources.
 func (s *permissionServiceImpl) HasScope(ctx context.Context, identityID uuid.UUID, resourceID string, scopeName string) (bool, error) {
+	permissionCache := cache.Default()
+	if granted, found := permissionCache.Get(identityID, resourceID, scopeName); found {
+		return granted, nil
+	}
+	generation := permissionCache.Generation()
 
 	identityRoles, err := s.Repositories().IdentityRoleRepository().FindPermissions(ctx, identityID, resourceID, scopeName)
 	if err != nil {
 		return false, err
 	}
 
-	return len(identityRoles) > 0, nil
+	// The result must not be cached beyond the expiry of the roles which grant the scope
+	var expiresAt *time.Time
+	for _, identityRole := range identityRoles {
+		if identityRole.ExpiresAt != nil && (expiresAt == nil || identityRole.ExpiresAt.Before(*expiresAt)) {
+			expiresAt = identityRole.ExpiresAt
+		}
+	}
+
+	granted := len(identityRoles) > 0
+	permissionCache.Put(generation, identityID, resourceID, scopeName, granted, expiresAt)
+	return granted, nil
+}
+
+// HasScopes does the same permission check as HasScope for each of the specified checks, but evaluates all of them
+// in a single query. The returned array contains the result of each check, in the same order as the checks.
+func (s *permissionServiceImpl) HasScopes(ctx context.Context, checks []authorization.PermissionCheck) ([]bool, error) {
+	permissionCache := cache.Default()
+	results := make([]bool, len(checks))
+
+	// Only check the permissions which are not cached yet
+	var uncachedIndexes []int
+	var uncachedChecks []authorization.PermissionCheck
+	for i, check := range checks {
+		granted, found := permissionCache.Get(check.IdentityID, check.ResourceID, check.ScopeName)
+		if found {
+			results[i] = granted
+		} else {
+			uncachedIndexes = append(uncachedIndexes, i)
+			uncachedChecks = append(uncachedChecks, check)
+		}
+	}
+	if len(uncachedChecks) == 0 {
+		return results, nil
+	}
+	generation := permissionCache.Generation()
+
+	uncachedResults, err := s.Repositories().IdentityRoleRepository().CheckPermissions(ctx, uncachedChecks)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range uncachedResults {
+		results[uncachedIndexes[i]] = result.Granted
+		permissionCache.Put(generation, uncachedChecks[i].IdentityID, uncachedChecks[i].ResourceID, uncachedChecks[i].ScopeName, result.Granted, result.ExpiresAt)
+	}
+	return results, nil
+}
+
+// ListScopes returns the names of all the scopes which the identity has been granted for the specified resource
+func (s *permissionServiceImpl) ListScopes(ctx context.Context, identityID uuid.UUID, resourceID string) ([]string, error) {
+	res, err := s.Repositories().ResourceRepository().Load(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	resourceTypeScopes, err := s.Repositories().ResourceTypeScopeRepository().LookupForType(ctx, res.ResourceTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]authorization.PermissionCheck, len(resourceTypeScopes))
+	for i, scope := range resourceTypeScopes {
+		checks[i] = authorization.PermissionCheck{
+			IdentityID: identityID,
+			ResourceID: resourceID,
+			ScopeName:  scope.Name,
+		}
+	}
+	results, err := s.HasScopes(ctx, checks)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := []string{}
+	for i, granted := range results {
+		if granted {
+			scopes = append(scopes, checks[i].ScopeName)
+		}
+	}
+	return scopes, nil
+}
+
+// ExplainScope explains how the identity has been granted the scope for the specified resource, by returning the role
+// assignments found by the same query as HasScope along with the memberships and role mappings through which each of them
+// applies. The result is never cached.
+func (s *permissionServiceImpl) ExplainScope(ctx context.Context, identityID uuid.UUID, resourceID string, scopeName string) (*authorization.PermissionExplanation, error) {
+	// Make sure the resource exists, so that an unknown resource is not reported as a missing permission
+	_, err := s.Repositories().ResourceRepository().Load(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	identityRoles, err := s.Repositories().IdentityRoleRepository().FindPermissions(ctx, identityID, resourceID, scopeName)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &authorization.PermissionExplanation{
+		IdentityID: identityID,
+		ResourceID: resourceID,
+		ScopeName:  scopeName,
+		Granted:    len(identityRoles) > 0,
+		Grants:     []authorization.ScopeGrant{},
+	}
+	for _, identityRole := range identityRoles {
+		grant, err := s.explainGrant(ctx, identityID, identityRole, resourceID, scopeName)
+		if err != nil {
+			return nil, err
+		}
+		explanation.Grants = append(explanation.Grants, *grant)
+	}
+	return explanation, nil
+}
+
+// ListResourcesWithScope returns a page of the resources of the specified type for which the identity has been granted the scope,
+// using the same rules as HasScope, along with the total number of such resources. The result is never cached.
+func (s *permissionServiceImpl) ListResourcesWithScope(ctx context.Context, identityID uuid.UUID, resourceTypeName string, scopeName string, offset int, limit int) ([]resource.Resource, int, error) {
+	resourceType, err := s.Repositories().ResourceTypeRepository().Lookup(ctx, resourceTypeName)
+	if err != nil {
+		return nil, 0, errors.NewBadParameterErrorFromString("resource_type", resourceTypeName, err.Error())
+	}
+	resourceTypeScopes, err := s.Repositories().ResourceTypeScopeRepository().LookupForType(ctx, resourceType.ResourceTypeID)
+	if err != nil {
+		return nil, 0, err
+	}
+	validScope := false
+	for _, scope := range resourceTypeScopes {
+		if scope.Name == scopeName {
+			validScope = true
+			break
+		}
+	}
+	if !validScope {
+		return nil, 0, errors.NewBadParameterErrorFromString("scope", scopeName, fmt.Sprintf("scope is not defined for resource type '%s'", resourceTypeName))
+	}
+
+	return s.Repositories().IdentityRoleRepository().FindResourcesWithScope(ctx, identityID, resourceTypeName, scopeName, offset, limit)
+}
+
+// explainGrant returns the memberships and role mappings through which the identity role grants the scope for the resource
+// to the identity
+func (s *permissionServiceImpl) explainGrant(ctx context.Context, identityID uuid.UUID, identityRole rolerepo.IdentityRole, resourceID string, scopeName string) (*authorization.ScopeGrant, error) {
+	r, err := s.Repositories().RoleRepository().Load(ctx, identityRole.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	grant := &authorization.ScopeGrant{
+		IdentityRoleID: identityRole.IdentityRoleID,
+		ResourceID:     identityRole.ResourceID,
+		RoleName:       r.Name,
+		AssigneeID:     identityRole.IdentityID,
+		Memberships:    []authorization.MembershipStep{},
+		RoleMappings:   []authorization.RoleMappingStep{},
+	}
+
+	if identityRole.IdentityID != identityID {
+		path, err := s.Repositories().Identities().FindMembershipPath(ctx, identityID, identityRole.IdentityID)
+		if err != nil {
+			return nil, err
+		}
+		for _, memberOf := range path {
+			identity, err := s.Repositories().Identities().Load(ctx, memberOf)
+			if err != nil {
+				return nil, err
+			}
+			step := authorization.MembershipStep{IdentityID: memberOf}
+			if identity.IdentityResourceID.Valid {
+				res, err := s.Repositories().ResourceRepository().Load(ctx, identity.IdentityResourceID.String)
+				if err != nil {
+					return nil, err
+				}
+				step.ResourceTypeName = res.ResourceType.Name
+			}
+			grant.Memberships = append(grant.Memberships, step)
+		}
+	}
+
+	// No role mapping is involved if the assigned role grants the scope itself
+	scopes, err := s.Repositories().RoleRepository().ListScopes(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range scopes {
+		if scope.Name == scopeName {
+			return grant, nil
+		}
+	}
+
+	mappings, err := s.Repositories().RoleMappingRepository().FindMappingPath(ctx, r.RoleID, resourceID, scopeName)
+	if err != nil {
+		return nil, err
+	}
+	for _, mapping := range mappings {
+		grant.RoleMappings = append(grant.RoleMappings, authorization.RoleMappingStep{
+			RoleMappingID: mapping.RoleMappingID,
+			ResourceID:    mapping.ResourceID,
+			FromRoleName:  mapping.FromRole.Name,
+			ToRoleName:    mapping.ToRole.Name,
+		})
+	}
+	return grant, nil
 }
 
 // RequireScope is the same as HasScope, except instead of returning a boolean value it will just return an error if the
@@ -4,14 +4,17 @@ import (
 	"testing"
 
 	account "github.com/fabric8-services/fabric8-auth/account/repository"
+	"github.com/fabric8-services/fabric8-auth/authorization"
 	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
 	resourcetype "github.com/fabric8-services/fabric8-auth/authorization/resourcetype/repository"
 	roleRepo "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
+	"github.com/fabric8-services/fabric8-auth/errors"
 	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
 	"github.com/fabric8-services/fabric8-auth/test"
 
 	"github.com/fabric8-services/fabric8-auth/application/service"
 	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
 	"github.com/satori/go.uuid"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -180,6 +183,51 @@ func (s *permissionServiceBlackBoxTest) TestPermissionForUserAssignedDirectRoleF
 	require.Error(s.T(), s.permissionService.RequireScope(s.Ctx, identity.ID, otherResource.ResourceID, testAreaScopeName))
 }
 
+/*
+ *  Tests that multiple permission checks are evaluated at once, with the same results as individual checks
+ */
+func (s *permissionServiceBlackBoxTest) TestHasScopes() {
+	// Create the user identities
+	identity, err := test.CreateTestIdentity(s.DB, "permission-service-test-user-nina", "")
+	require.NoError(s.T(), err, "Could not create test identity")
+	otherIdentity, err := test.CreateTestIdentity(s.DB, "permission-service-test-user-oscar", "")
+	require.NoError(s.T(), err, "Could not create other test identity")
+
+	// Create a resource with a child resource, and assign our test role to the user
+	resource, err := s.createTestResourceAndAssignDefaultRole(identity)
+	require.NoError(s.T(), err)
+	childResource, err := s.createTestChildResource(*resource, testResourceTypeArea)
+	require.NoError(s.T(), err)
+
+	// Create another resource with no permissions assigned
+	otherResource, err := s.createTestResourceWithNoPermissions()
+	require.NoError(s.T(), err)
+
+	checks := []authorization.PermissionCheck{
+		{IdentityID: identity.ID, ResourceID: resource.ResourceID, ScopeName: testAreaScopeName},
+		{IdentityID: identity.ID, ResourceID: childResource.ResourceID, ScopeName: testAreaScopeName},
+		{IdentityID: identity.ID, ResourceID: otherResource.ResourceID, ScopeName: testAreaScopeName},
+		{IdentityID: identity.ID, ResourceID: resource.ResourceID, ScopeName: testWorkItemScopeName},
+		{IdentityID: otherIdentity.ID, ResourceID: resource.ResourceID, ScopeName: testAreaScopeName},
+		{IdentityID: identity.ID, ResourceID: uuid.NewV4().String(), ScopeName: testAreaScopeName},
+	}
+	results, err := s.permissionService.HasScopes(s.Ctx, checks)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []bool{true, true, false, false, false, false}, results)
+
+	// Check that the results are the same as for the individual checks
+	for i, check := range checks {
+		result, err := s.permissionService.HasScope(s.Ctx, check.IdentityID, check.ResourceID, check.ScopeName)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), result, results[i], "Unexpected result for check %d", i)
+	}
+
+	// Check that no checks return no results
+	results, err = s.permissionService.HasScopes(s.Ctx, []authorization.PermissionCheck{})
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), results)
+}
+
 /*
  *  Tests that a user has the scope for a child resource, when the role has been assigned to an organization of which
  *  the user is a member, for a parent resource of the same type
@@ -501,7 +549,130 @@ func (s *permissionServiceBlackBoxTest) TestPermissionForOrgMemberAssignedDouble
 	require.True(s.T(), result, "User should have assigned scope for child resource")
 }
 
+func (s *permissionServiceBlackBoxTest) TestExplainScope() {
+	identity, err := test.CreateTestIdentity(s.DB, "permission-service-test-user-"+uuid.NewV4().String(), "")
+	require.NoError(s.T(), err, "Could not create test identity")
+	org, err := test.CreateTestOrganization(s.Ctx, s.DB, s.Application, identity.ID, "test-permission-org-"+uuid.NewV4().String())
+	require.NoError(s.T(), err, "Could not create test organization")
+
+	grandparentResource, err := s.createTestResource(testResourceTypeArea)
+	require.NoError(s.T(), err, "Error creating grandparent resource")
+	parentResource, err := s.createTestChildResource(*grandparentResource, testResourceTypeWorkItem)
+	require.NoError(s.T(), err, "Error creating parent resource")
+	childResource, err := s.createTestChildResource(*parentResource, testResourceTypeWorkItemComment)
+	require.NoError(s.T(), err, "Error creating child resource")
+
+	err = s.assignRoleForResource(*grandparentResource, org, s.testAreaRole)
+	require.NoError(s.T(), err, "Error assigning role for grandparent resource")
+	err = test.CreateTestRoleMapping(s.Ctx, s.DB, s.Application, grandparentResource.ResourceID, s.testAreaRole.RoleID, s.testWorkItemRole.RoleID)
+	require.NoError(s.T(), err, "Could not create role mapping")
+	err = test.CreateTestRoleMapping(s.Ctx, s.DB, s.Application, parentResource.ResourceID, s.testWorkItemRole.RoleID, s.testWorkItemCommentRole.RoleID)
+	require.NoError(s.T(), err, "Could not create role mapping")
+
+	s.T().Run("not granted", func(t *testing.T) {
+		explanation, err := s.permissionService.ExplainScope(s.Ctx, identity.ID, childResource.ResourceID, testWorkItemCommentScopeName)
+		require.NoError(t, err)
+		require.False(t, explanation.Granted)
+		require.Empty(t, explanation.Grants)
+	})
+
+	err = s.addMember(s.DB, org.ID, identity.ID)
+	require.NoError(s.T(), err, "Error adding member to organization")
+	defer s.removeMember(s.DB, org.ID, identity.ID)
+
+	s.T().Run("granted through membership", func(t *testing.T) {
+		explanation, err := s.permissionService.ExplainScope(s.Ctx, identity.ID, grandparentResource.ResourceID, testAreaScopeName)
+		require.NoError(t, err)
+		require.True(t, explanation.Granted)
+		require.Len(t, explanation.Grants, 1)
+		grant := explanation.Grants[0]
+		require.Equal(t, grandparentResource.ResourceID, grant.ResourceID)
+		require.Equal(t, s.testAreaRole.Name, grant.RoleName)
+		require.Equal(t, org.ID, grant.AssigneeID)
+		require.Len(t, grant.Memberships, 1)
+		require.Equal(t, org.ID, grant.Memberships[0].IdentityID)
+		require.Equal(t, authorization.IdentityResourceTypeOrganization, grant.Memberships[0].ResourceTypeName)
+		require.Empty(t, grant.RoleMappings)
+	})
+
+	s.T().Run("granted through membership and role mappings", func(t *testing.T) {
+		explanation, err := s.permissionService.ExplainScope(s.Ctx, identity.ID, childResource.ResourceID, testWorkItemCommentScopeName)
+		require.NoError(t, err)
+		require.True(t, explanation.Granted)
+		require.Len(t, explanation.Grants, 1)
+		grant := explanation.Grants[0]
+		require.Equal(t, grandparentResource.ResourceID, grant.ResourceID)
+		require.Equal(t, org.ID, grant.AssigneeID)
+		require.Len(t, grant.Memberships, 1)
+		require.Len(t, grant.RoleMappings, 2)
+		require.Equal(t, grandparentResource.ResourceID, grant.RoleMappings[0].ResourceID)
+		require.Equal(t, s.testAreaRole.Name, grant.RoleMappings[0].FromRoleName)
+		require.Equal(t, s.testWorkItemRole.Name, grant.RoleMappings[0].ToRoleName)
+		require.Equal(t, parentResource.ResourceID, grant.RoleMappings[1].ResourceID)
+		require.Equal(t, s.testWorkItemRole.Name, grant.RoleMappings[1].FromRoleName)
+		require.Equal(t, s.testWorkItemCommentRole.Name, grant.RoleMappings[1].ToRoleName)
+	})
+
+	s.T().Run("unknown resource", func(t *testing.T) {
+		_, err := s.permissionService.ExplainScope(s.Ctx, identity.ID, uuid.NewV4().String(), testAreaScopeName)
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+}
+
 // Creates a test resource with the specified type
+func (s *permissionServiceBlackBoxTest) TestListResourcesWithScope() {
+	user := s.Graph.CreateUser()
+	org := s.Graph.CreateOrganization(user)
+	group := s.Graph.CreateGroup(org).AddMember(user)
+
+	directSpace := s.Graph.CreateSpace().AddContributor(user)
+	groupSpace := s.Graph.CreateSpace().AddContributor(group)
+	orgSpace := s.Graph.CreateSpace(org)
+	// noise
+	s.Graph.CreateSpace().AddViewer(user)
+	s.Graph.CreateSpace().AddContributor(s.Graph.CreateUser())
+
+	s.T().Run("all resources", func(t *testing.T) {
+		resources, count, err := s.permissionService.ListResourcesWithScope(s.Ctx, user.IdentityID(), authorization.ResourceTypeSpace, "contribute", 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+		require.Len(t, resources, 3)
+		resourceIDs := []string{}
+		for _, res := range resources {
+			require.Equal(t, authorization.ResourceTypeSpace, res.ResourceType.Name)
+			resourceIDs = append(resourceIDs, res.ResourceID)
+		}
+		require.ElementsMatch(t, []string{directSpace.SpaceID(), groupSpace.SpaceID(), orgSpace.SpaceID()}, resourceIDs)
+	})
+
+	s.T().Run("paged", func(t *testing.T) {
+		resources, count, err := s.permissionService.ListResourcesWithScope(s.Ctx, user.IdentityID(), authorization.ResourceTypeSpace, "contribute", 2, 2)
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+		require.Len(t, resources, 1)
+	})
+
+	s.T().Run("no resources", func(t *testing.T) {
+		resources, count, err := s.permissionService.ListResourcesWithScope(s.Ctx, s.Graph.CreateUser().IdentityID(), authorization.ResourceTypeSpace, "contribute", 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+		require.Empty(t, resources)
+	})
+
+	s.T().Run("unknown resource type", func(t *testing.T) {
+		_, _, err := s.permissionService.ListResourcesWithScope(s.Ctx, user.IdentityID(), uuid.NewV4().String(), "contribute", 0, 10)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("unknown scope", func(t *testing.T) {
+		_, _, err := s.permissionService.ListResourcesWithScope(s.Ctx, user.IdentityID(), authorization.ResourceTypeSpace, "unknown", 0, 10)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+}
+
 func (s *permissionServiceBlackBoxTest) createTestResource(resourceTypeName string) (*resource.Resource, error) {
 	// Lookup the specified resource type
 	resourceType, err := s.resourceTypeRepo.Lookup(s.Ctx, resourceTypeName)
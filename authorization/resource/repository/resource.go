@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/fabric8-services/fabric8-auth/application/repository/base"
@@ -61,9 +62,12 @@ type ResourceRepository interface {
 	base.Exister
 	Load(ctx context.Context, id string) (*Resource, error)
 	LoadChildren(ctx context.Context, id string) ([]Resource, error)
+	LoadIdentityResource(ctx context.Context, identityID uuid.UUID, resourceTypeName string) (*Resource, error)
 	Create(ctx context.Context, resource *Resource) error
 	Save(ctx context.Context, resource *Resource) error
 	Delete(ctx context.Context, id string) error
+	CountByResourceType(ctx context.Context, resourceTypeID uuid.UUID) (int, error)
+	List(ctx context.Context, offset int, limit int, funcs ...func(*gorm.DB) *gorm.DB) ([]Resource, int, error)
 }
 
 // TableName overrides the table name settings in Gorm to force a specific table name
@@ -99,6 +103,24 @@ func (m *GormResourceRepository) LoadChildren(ctx context.Context, id string) ([
 	return rows, nil
 }
 
+// LoadIdentityResource returns the resource of the identity with the given ID, e.g. the resource of an organization,
+// team or group identity. A not found error is returned if there is no such identity or if its resource is not of the
+// given type.
+func (m *GormResourceRepository) LoadIdentityResource(ctx context.Context, identityID uuid.UUID, resourceTypeName string) (*Resource, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "resource", "loadIdentityResource"}, time.Now())
+
+	var native Resource
+	err := m.db.Table(m.TableName()).Preload("ResourceType").
+		Joins("JOIN identities ON identities.identity_resource_id = resource.resource_id AND identities.deleted_at IS NULL").
+		Joins("JOIN resource_type ON resource_type.resource_type_id = resource.resource_type_id").
+		Where("identities.id = ? AND resource_type.name = ?", identityID, resourceTypeName).Find(&native).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errs.WithStack(errors.NewNotFoundError(resourceTypeName, identityID.String()))
+	}
+
+	return &native, errs.WithStack(err)
+}
+
 // CheckExists returns nil if the given ID exists otherwise returns an error
 func (m *GormResourceRepository) CheckExists(ctx context.Context, id string) error {
 	defer goa.MeasureSince([]string{"goa", "db", "resource", "exists"}, time.Now())
@@ -160,6 +182,13 @@ func (m *GormResourceRepository) Save(ctx context.Context, resource *Resource) e
 	err := m.db.Save(resource).Error
 
 	if err != nil {
+		if gormsupport.IsUniqueViolation(err, "unique_organization_names") {
+			log.Error(ctx, map[string]interface{}{
+				"err":  err,
+				"name": resource.Name,
+			}, "unable to update organization resource as an organization with the same name already exists")
+			return errors.NewDataConflictError(fmt.Sprintf("organization with same name already exists, '%s'", resource.Name))
+		}
 		log.Error(ctx, map[string]interface{}{
 			"resource_id": resource.ResourceID,
 			"err":         err,
@@ -198,3 +227,80 @@ func (m *GormResourceRepository) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// CountByResourceType returns the number of resources of the given resource type
+func (m *GormResourceRepository) CountByResourceType(ctx context.Context, resourceTypeID uuid.UUID) (int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "resource", "countByResourceType"}, time.Now())
+
+	var count int
+	err := m.db.Model(&Resource{}).Where("resource_type_id = ?", resourceTypeID).Count(&count).Error
+	if err != nil {
+		return 0, errs.WithStack(err)
+	}
+	return count, nil
+}
+
+// List returns the page of resources matching the given filters, ordered by creation time, along with the total
+// number of matching resources
+func (m *GormResourceRepository) List(ctx context.Context, offset int, limit int, funcs ...func(*gorm.DB) *gorm.DB) ([]Resource, int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "resource", "list"}, time.Now())
+
+	var count int
+	err := m.db.Model(&Resource{}).Scopes(funcs...).Count(&count).Error
+	if err != nil {
+		return nil, 0, errs.WithStack(err)
+	}
+
+	var rows []Resource
+	err = m.db.Model(&Resource{}).Scopes(funcs...).Preload("ResourceType").
+		Order("resource.created_at, resource.resource_id").Offset(offset).Limit(limit).Find(&rows).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, 0, errs.WithStack(err)
+	}
+
+	log.Debug(ctx, map[string]interface{}{
+		"offset": offset,
+		"limit":  limit,
+		"count":  count,
+	}, "Resource list executed successfully!")
+
+	return rows, count, nil
+}
+
+// ResourceFilterByResourceType is a gorm filter by the name of the resource type
+func ResourceFilterByResourceType(resourceTypeName string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("resource.resource_type_id IN (SELECT resource_type_id FROM resource_type WHERE name = ?)", resourceTypeName)
+	}
+}
+
+// ResourceFilterByParentResourceID is a gorm filter by 'parent_resource_id'
+func ResourceFilterByParentResourceID(parentResourceID string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("resource.parent_resource_id = ?", parentResourceID)
+	}
+}
+
+// namePrefixEscaper escapes the wildcard characters of a LIKE pattern
+var namePrefixEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ResourceFilterByNamePrefix is a gorm filter for the resources which name starts with the given prefix
+func ResourceFilterByNamePrefix(prefix string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("resource.name LIKE ?", namePrefixEscaper.Replace(prefix)+"%")
+	}
+}
+
+// ResourceFilterByCreatedAfter is a gorm filter for the resources created at or after the given time
+func ResourceFilterByCreatedAfter(t time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("resource.created_at >= ?", t)
+	}
+}
+
+// ResourceFilterByCreatedBefore is a gorm filter for the resources created strictly before the given time
+func ResourceFilterByCreatedBefore(t time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("resource.created_at < ?", t)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
 	testsupport "github.com/fabric8-services/fabric8-auth/test"
 
+	errs "github.com/pkg/errors"
 	"github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -62,6 +63,33 @@ func (s *resourceBlackBoxTest) TestLoadUnknownFails() {
 	testsupport.AssertError(s.T(), err, errors.NotFoundError{}, "resource with id '%s' not found", id)
 }
 
+func (s *resourceBlackBoxTest) TestLoadIdentityResource() {
+	org := s.Graph.CreateOrganization()
+
+	res, err := s.repo.LoadIdentityResource(s.Ctx, org.OrganizationID(), authorization.IdentityResourceTypeOrganization)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), org.ResourceID(), res.ResourceID)
+	assert.Equal(s.T(), authorization.IdentityResourceTypeOrganization, res.ResourceType.Name)
+
+	s.T().Run("resource of another type", func(t *testing.T) {
+		_, err := s.repo.LoadIdentityResource(s.Ctx, org.OrganizationID(), authorization.IdentityResourceTypeTeam)
+		testsupport.AssertError(t, err, errors.NotFoundError{}, "%s with id '%s' not found", authorization.IdentityResourceTypeTeam, org.OrganizationID())
+	})
+
+	s.T().Run("identity without resource", func(t *testing.T) {
+		user := s.Graph.CreateUser()
+		_, err := s.repo.LoadIdentityResource(s.Ctx, user.IdentityID(), authorization.IdentityResourceTypeOrganization)
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+
+	s.T().Run("unknown identity", func(t *testing.T) {
+		_, err := s.repo.LoadIdentityResource(s.Ctx, uuid.NewV4(), authorization.IdentityResourceTypeOrganization)
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+}
+
 func (s *resourceBlackBoxTest) TestOKToLoadChildren() {
 	parent := createAndLoadResource(s, nil)
 
@@ -177,6 +205,76 @@ func (s *resourceBlackBoxTest) TestCreateResourceDataConflict() {
 	//require.IsType(s.T(), errors.DataConflictError{}, err)
 }
 
+func (s *resourceBlackBoxTest) TestList() {
+	rt := s.Graph.CreateResourceType()
+	parent := s.Graph.CreateResource(rt, "parent-"+uuid.NewV4().String())
+	first := s.Graph.CreateResource(rt, parent, "child_a-"+uuid.NewV4().String())
+	second := s.Graph.CreateResource(rt, parent, "child_b-"+uuid.NewV4().String())
+	third := s.Graph.CreateResource(rt, parent, "other-"+uuid.NewV4().String())
+	// A resource of another type
+	s.Graph.CreateResource(parent)
+
+	s.T().Run("by resource type", func(t *testing.T) {
+		resources, count, err := s.repo.List(s.Ctx, 0, 10, resource.ResourceFilterByResourceType(rt.ResourceType().Name))
+		require.NoError(t, err)
+		assert.Equal(t, 4, count)
+		require.Len(t, resources, 4)
+		// Resources are ordered by creation time
+		assert.Equal(t, parent.ResourceID(), resources[0].ResourceID)
+		assert.Equal(t, first.ResourceID(), resources[1].ResourceID)
+		assert.Equal(t, second.ResourceID(), resources[2].ResourceID)
+		assert.Equal(t, third.ResourceID(), resources[3].ResourceID)
+		assert.Equal(t, rt.ResourceType().Name, resources[0].ResourceType.Name)
+	})
+
+	s.T().Run("paged", func(t *testing.T) {
+		resources, count, err := s.repo.List(s.Ctx, 1, 2,
+			resource.ResourceFilterByResourceType(rt.ResourceType().Name),
+			resource.ResourceFilterByParentResourceID(parent.ResourceID()))
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		require.Len(t, resources, 2)
+		assert.Equal(t, second.ResourceID(), resources[0].ResourceID)
+		assert.Equal(t, third.ResourceID(), resources[1].ResourceID)
+	})
+
+	s.T().Run("by name prefix", func(t *testing.T) {
+		resources, count, err := s.repo.List(s.Ctx, 0, 10,
+			resource.ResourceFilterByParentResourceID(parent.ResourceID()),
+			resource.ResourceFilterByNamePrefix("child_"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		require.Len(t, resources, 2)
+		assert.Equal(t, first.ResourceID(), resources[0].ResourceID)
+		assert.Equal(t, second.ResourceID(), resources[1].ResourceID)
+
+		// Wildcards are matched literally
+		_, count, err = s.repo.List(s.Ctx, 0, 10,
+			resource.ResourceFilterByParentResourceID(parent.ResourceID()),
+			resource.ResourceFilterByNamePrefix("%"))
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	s.T().Run("by creation time", func(t *testing.T) {
+		// Reload the resources to use the creation times as stored in the database
+		loadedFirst, err := s.repo.Load(s.Ctx, first.ResourceID())
+		require.NoError(t, err)
+		loadedThird, err := s.repo.Load(s.Ctx, third.ResourceID())
+		require.NoError(t, err)
+
+		resources, count, err := s.repo.List(s.Ctx, 0, 10,
+			resource.ResourceFilterByParentResourceID(parent.ResourceID()),
+			resource.ResourceFilterByCreatedAfter(loadedFirst.CreatedAt),
+			resource.ResourceFilterByCreatedBefore(loadedThird.CreatedAt))
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		require.Len(t, resources, 2)
+		assert.Equal(t, first.ResourceID(), resources[0].ResourceID)
+		assert.Equal(t, second.ResourceID(), resources[1].ResourceID)
+	})
+}
+
 func createAndLoadResource(s *resourceBlackBoxTest, parentResourceID *string) *resource.Resource {
 	resourceType, err := s.resourceTypeRepo.Lookup(s.Ctx, "openshift.io/resource/area")
 	require.Nil(s.T(), err, "Could not find resource type")
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-auth/gormsupport"
+	"github.com/fabric8-services/fabric8-auth/log"
+
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+)
+
+// ResourceOwnershipTransfer records the transfer of the ownership (i.e. the admin role) of a resource, and optionally of its
+// descendants, from one identity to another
+type ResourceOwnershipTransfer struct {
+	gormsupport.Lifecycle
+
+	// This is the primary key value
+	ResourceOwnershipTransferID uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key;column:resource_ownership_transfer_id"`
+	// The resource which ownership was transferred
+	ResourceID string
+	// The identity which owned the resource before the transfer
+	FromIdentityID uuid.UUID
+	// The identity which owns the resource after the transfer
+	ToIdentityID uuid.UUID
+	// The identity which performed the transfer
+	TransferredBy uuid.UUID
+	// Whether the ownership of the descendants of the resource was transferred too
+	IncludeDescendants bool
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m ResourceOwnershipTransfer) TableName() string {
+	return "resource_ownership_transfer"
+}
+
+// GormResourceOwnershipTransferRepository is the implementation of the storage interface for ResourceOwnershipTransfer.
+type GormResourceOwnershipTransferRepository struct {
+	db *gorm.DB
+}
+
+// NewResourceOwnershipTransferRepository creates a new storage type.
+func NewResourceOwnershipTransferRepository(db *gorm.DB) ResourceOwnershipTransferRepository {
+	return &GormResourceOwnershipTransferRepository{db: db}
+}
+
+// ResourceOwnershipTransferRepository represents the storage interface.
+type ResourceOwnershipTransferRepository interface {
+	Create(ctx context.Context, transfer *ResourceOwnershipTransfer) error
+	ListForResource(ctx context.Context, resourceID string) ([]ResourceOwnershipTransfer, error)
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m *GormResourceOwnershipTransferRepository) TableName() string {
+	return "resource_ownership_transfer"
+}
+
+// Create creates a new record.
+func (m *GormResourceOwnershipTransferRepository) Create(ctx context.Context, transfer *ResourceOwnershipTransfer) error {
+	defer goa.MeasureSince([]string{"goa", "db", "resource_ownership_transfer", "create"}, time.Now())
+	if transfer.ResourceOwnershipTransferID == uuid.Nil {
+		transfer.ResourceOwnershipTransferID = uuid.NewV4()
+	}
+	err := m.db.Create(transfer).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_id": transfer.ResourceID,
+			"err":         err,
+		}, "unable to create the resource ownership transfer")
+		return errs.WithStack(err)
+	}
+	log.Debug(ctx, map[string]interface{}{
+		"resource_ownership_transfer_id": transfer.ResourceOwnershipTransferID,
+		"resource_id":                    transfer.ResourceID,
+	}, "Resource ownership transfer created!")
+	return nil
+}
+
+// ListForResource returns the ownership transfers of the specified resource, most recent first
+func (m *GormResourceOwnershipTransferRepository) ListForResource(ctx context.Context, resourceID string) ([]ResourceOwnershipTransfer, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "resource_ownership_transfer", "listForResource"}, time.Now())
+	var rows []ResourceOwnershipTransfer
+	err := m.db.Model(&ResourceOwnershipTransfer{}).Where("resource_id = ?", resourceID).Order("created_at DESC").Find(&rows).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errs.WithStack(err)
+	}
+	return rows, nil
+}
@@ -3,15 +3,23 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
 
+	account "github.com/fabric8-services/fabric8-auth/account/repository"
 	"github.com/fabric8-services/fabric8-auth/app"
 	"github.com/fabric8-services/fabric8-auth/application/service"
 	"github.com/fabric8-services/fabric8-auth/application/service/base"
 	servicecontext "github.com/fabric8-services/fabric8-auth/application/service/context"
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	"github.com/fabric8-services/fabric8-auth/authorization/permission/cache"
 	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
 	"github.com/fabric8-services/fabric8-auth/authorization/role/repository"
+	token "github.com/fabric8-services/fabric8-auth/authorization/token/repository"
 	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/log"
+	"github.com/fabric8-services/fabric8-auth/notification"
 
+	"github.com/jinzhu/gorm"
 	"github.com/satori/go.uuid"
 )
 
@@ -31,8 +39,14 @@ func NewResourceService(context servicecontext.ServiceContext) service.ResourceS
 func (s *resourceServiceImpl) Delete(ctx context.Context, resourceID string) error {
 
 	err := s.ExecuteInTransaction(func() error {
+		// The RPTs issued for the resource and its descendants are not valid anymore
+		err := s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, resourceID, token.TokenStatusStale)
+		if err != nil {
+			return err
+		}
 		return s.delete(ctx, resourceID, make(map[string]bool))
 	})
+	cache.Invalidate()
 
 	return err
 }
@@ -69,6 +83,39 @@ func (s *resourceServiceImpl) delete(ctx context.Context, resourceID string, vis
 		return err
 	}
 
+	// Delete the memberships, roles and pending invitations of the associated identities in case of Organization, Team
+	// or Security Group, since they reference the identities
+	identities, err := s.Repositories().Identities().Query(account.IdentityFilterByResourceID(resourceID))
+	if err != nil {
+		return err
+	}
+	for _, identity := range identities {
+		// The tokens granting the permissions of the identity (and of its members) for other resources are not
+		// valid anymore once its roles and memberships are deleted
+		associations, err := s.Repositories().IdentityRoleRepository().FindIdentityRolesForIdentity(ctx, identity.ID, nil)
+		if err != nil {
+			return err
+		}
+		for _, association := range associations {
+			err = s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, association.ResourceID, token.TokenStatusStale)
+			if err != nil {
+				return err
+			}
+		}
+		err = s.Repositories().InvitationRepository().DeleteForIdentity(ctx, identity.ID)
+		if err != nil {
+			return err
+		}
+		err = s.Repositories().Identities().DeleteMemberships(ctx, identity.ID)
+		if err != nil {
+			return err
+		}
+		err = s.Repositories().IdentityRoleRepository().DeleteForIdentity(ctx, identity.ID)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Delete assosiated identities in case of Organization, Team or Security Group
 	err = s.Repositories().Identities().DeleteForResource(ctx, resourceID)
 	if err != nil {
@@ -129,6 +176,10 @@ func (s *resourceServiceImpl) Register(ctx context.Context, resourceTypeName str
 				return errors.NewBadParameterErrorFromString("parent resource ID", *parentResourceID, err.Error())
 			}
 		}
+		err = checkParentType(resourceType.Name, parentResource)
+		if err != nil {
+			return err
+		}
 
 		var rID string
 		if resourceID != nil {
@@ -152,8 +203,20 @@ func (s *resourceServiceImpl) Register(ctx context.Context, resourceTypeName str
 			return err
 		}
 
+		return s.ApplyDefaultRoleMappings(ctx, res)
+	})
+
+	return res, err
+}
+
+// ApplyDefaultRoleMappings creates a role mapping for the specified resource for each of the default role mappings
+// defined for its resource type. This method is invoked when registering a new resource, and should also be invoked by
+// any service which creates resources without registering them.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *resourceServiceImpl) ApplyDefaultRoleMappings(ctx context.Context, res *resource.Resource) error {
+	return s.ExecuteInTransaction(func() error {
 		// Search for any default role mappings for the resource type
-		defaultRoleMappings, err := s.Repositories().DefaultRoleMappingRepository().FindForResourceType(ctx, resourceType.ResourceTypeID)
+		defaultRoleMappings, err := s.Repositories().DefaultRoleMappingRepository().FindForResourceType(ctx, res.ResourceTypeID)
 		if err != nil {
 			return err
 		}
@@ -161,7 +224,7 @@ func (s *resourceServiceImpl) Register(ctx context.Context, resourceTypeName str
 		// For each default role mapping for the same resource type, create a role mapping for the resource
 		for _, m := range defaultRoleMappings {
 			roleMapping := &repository.RoleMapping{
-				ResourceID: rID,
+				ResourceID: res.ResourceID,
 				FromRoleID: m.FromRoleID,
 				ToRoleID:   m.ToRoleID,
 			}
@@ -174,6 +237,279 @@ func (s *resourceServiceImpl) Register(ctx context.Context, resourceTypeName str
 
 		return nil
 	})
+}
 
-	return res, err
+// Move changes the parent of the resource with the specified resourceID, or makes it a top level resource if no parent
+// is specified. The new parent must exist, must be of a type compatible with the type of the resource, and must not be
+// the resource itself or one of its descendants. Since the permissions of the identities may change along with the
+// ancestors of the resource, the RPTs issued for the resource and its descendants are marked as stale.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *resourceServiceImpl) Move(ctx context.Context, resourceID string, parentResourceID *string) error {
+	err := s.ExecuteInTransaction(func() error {
+		res, err := s.Repositories().ResourceRepository().Load(ctx, resourceID)
+		if err != nil {
+			return err
+		}
+
+		var parentResource *resource.Resource
+		if parentResourceID != nil {
+			parentResource, err = s.Repositories().ResourceRepository().Load(ctx, *parentResourceID)
+			if err != nil {
+				return errors.NewBadParameterErrorFromString("parent resource ID", *parentResourceID, err.Error())
+			}
+		}
+		err = checkParentType(res.ResourceType.Name, parentResource)
+		if err != nil {
+			return err
+		}
+
+		// Walk up the ancestors of the new parent to make sure the resource would not become its own ancestor
+		visited := make(map[string]bool)
+		ancestor := parentResource
+		for ancestor != nil && !visited[ancestor.ResourceID] {
+			if ancestor.ResourceID == resourceID {
+				return errors.NewBadParameterErrorFromString("parent resource ID", *parentResourceID, "a resource cannot be moved under itself or one of its descendants")
+			}
+			visited[ancestor.ResourceID] = true
+			if ancestor.ParentResourceID == nil {
+				break
+			}
+			ancestor, err = s.Repositories().ResourceRepository().Load(ctx, *ancestor.ParentResourceID)
+			if err != nil {
+				return err
+			}
+		}
+
+		res.ParentResourceID = parentResourceID
+		res.ParentResource = nil
+		err = s.Repositories().ResourceRepository().Save(ctx, res)
+		if err != nil {
+			return err
+		}
+
+		// The RPTs issued for the resource and its descendants are not valid anymore
+		return s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, resourceID, token.TokenStatusStale)
+	})
+	cache.Invalidate()
+	if err != nil {
+		return err
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"resource_id":        resourceID,
+		"parent_resource_id": parentResourceID,
+	}, "resource moved")
+
+	return nil
+}
+
+// checkParentType returns a bad parameter error if a resource of the specified type may not have the specified parent
+// resource, or may not be a top level resource when the parent resource is nil
+func checkParentType(resourceTypeName string, parentResource *resource.Resource) error {
+	if parentResource == nil {
+		if !authorization.CanHaveNoParent(resourceTypeName) {
+			return errors.NewBadParameterErrorFromString("parent resource ID", nil,
+				fmt.Sprintf("a resource of type '%s' must have a parent resource", resourceTypeName))
+		}
+		return nil
+	}
+	if !authorization.CanHaveParentOfType(resourceTypeName, parentResource.ResourceType.Name) {
+		return errors.NewBadParameterErrorFromString("parent resource ID", parentResource.ResourceID,
+			fmt.Sprintf("a resource of type '%s' cannot be a child of a resource of type '%s'", resourceTypeName, parentResource.ResourceType.Name))
+	}
+	return nil
+}
+
+// List returns a page of the resources matching the given optional filters, ordered by creation time, along with the
+// total number of matching resources
+func (s *resourceServiceImpl) List(ctx context.Context, resourceTypeName, parentResourceID, namePrefix *string, createdAfter, createdBefore *time.Time, offset, limit int) ([]resource.Resource, int, error) {
+	var filters []func(*gorm.DB) *gorm.DB
+	if resourceTypeName != nil {
+		if _, err := s.Repositories().ResourceTypeRepository().Lookup(ctx, *resourceTypeName); err != nil {
+			return nil, 0, errors.NewBadParameterErrorFromString("type", *resourceTypeName, err.Error())
+		}
+		filters = append(filters, resource.ResourceFilterByResourceType(*resourceTypeName))
+	}
+	if parentResourceID != nil {
+		if err := s.Repositories().ResourceRepository().CheckExists(ctx, *parentResourceID); err != nil {
+			return nil, 0, errors.NewBadParameterErrorFromString("parent_resource_id", *parentResourceID, err.Error())
+		}
+		filters = append(filters, resource.ResourceFilterByParentResourceID(*parentResourceID))
+	}
+	if namePrefix != nil {
+		filters = append(filters, resource.ResourceFilterByNamePrefix(*namePrefix))
+	}
+	if createdAfter != nil && createdBefore != nil && !createdAfter.Before(*createdBefore) {
+		return nil, 0, errors.NewBadParameterErrorFromString("created_before", createdBefore.String(), "must be after created_after")
+	}
+	if createdAfter != nil {
+		filters = append(filters, resource.ResourceFilterByCreatedAfter(*createdAfter))
+	}
+	if createdBefore != nil {
+		filters = append(filters, resource.ResourceFilterByCreatedBefore(*createdBefore))
+	}
+
+	return s.Repositories().ResourceRepository().List(ctx, offset, limit, filters...)
+}
+
+// TransferOwnership transfers the ownership (i.e. the admin role) of the resource, and optionally of all its descendants,
+// from one identity to another. The identity performing the transfer must be allowed to manage the roles of the resource.
+// The transfer is recorded, and both the previous and the new owners are notified.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *resourceServiceImpl) TransferOwnership(ctx context.Context, transferredBy uuid.UUID, resourceID string, fromIdentityID uuid.UUID, toIdentityID uuid.UUID, includeDescendants bool) error {
+	if fromIdentityID == toIdentityID {
+		return errors.NewBadParameterErrorFromString("to_identity_id", toIdentityID, "the ownership cannot be transferred to the current owner")
+	}
+
+	res, err := s.Repositories().ResourceRepository().Load(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+
+	err = s.Services().PermissionService().RequireScope(ctx, transferredBy, resourceID, authorization.ScopeForManagingRolesInResourceType(res.ResourceType.Name))
+	if err != nil {
+		return err
+	}
+
+	fromIdentity, err := s.Repositories().Identities().Load(ctx, fromIdentityID)
+	if err != nil {
+		return err
+	}
+	toIdentity, err := s.Repositories().Identities().Load(ctx, toIdentityID)
+	if err != nil {
+		return err
+	}
+
+	err = s.ExecuteInTransaction(func() error {
+		transferred, err := s.transferAdminRole(ctx, *res, fromIdentityID, toIdentityID)
+		if err != nil {
+			return err
+		}
+		if !transferred {
+			return errors.NewBadParameterErrorFromString("from_identity_id", fromIdentityID, fmt.Sprintf("identity %s is not an administrator of resource %s", fromIdentityID, resourceID))
+		}
+
+		if includeDescendants {
+			err = s.transferDescendants(ctx, resourceID, fromIdentityID, toIdentityID, map[string]bool{resourceID: true})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = s.Repositories().ResourceOwnershipTransferRepository().Create(ctx, &resource.ResourceOwnershipTransfer{
+			ResourceID:         resourceID,
+			FromIdentityID:     fromIdentityID,
+			ToIdentityID:       toIdentityID,
+			TransferredBy:      transferredBy,
+			IncludeDescendants: includeDescendants,
+		})
+		if err != nil {
+			return err
+		}
+
+		// The RPTs issued for the resource and its descendants are not valid anymore
+		return s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, resourceID, token.TokenStatusStale)
+	})
+	cache.Invalidate()
+	if err != nil {
+		return err
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"resource_id":         resourceID,
+		"from_identity_id":    fromIdentityID,
+		"to_identity_id":      toIdentityID,
+		"transferred_by":      transferredBy,
+		"include_descendants": includeDescendants,
+	}, "resource ownership transferred")
+
+	transferredByName := transferredBy.String()
+	if transferredByIdentity, err := s.Repositories().Identities().Load(ctx, transferredBy); err == nil {
+		transferredByName = transferredByIdentity.Username
+	}
+
+	var messages []notification.Message
+	for _, identity := range []*account.Identity{fromIdentity, toIdentity} {
+		if identity.UserID.Valid {
+			messages = append(messages, notification.NewResourceOwnershipTransferredEmail(identity.UserID.UUID.String(),
+				res.ResourceID,
+				res.Name,
+				res.ResourceType.Name,
+				fromIdentity.Username,
+				toIdentity.Username,
+				transferredByName))
+		}
+	}
+	if len(messages) > 0 {
+		// The transfer has been committed already, so a failure to notify the parties is not reported to the caller
+		err = s.Services().NotificationService().SendMessagesAsync(ctx, messages)
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"resource_id": resourceID,
+				"err":         err,
+			}, "unable to notify the parties of the resource ownership transfer")
+		}
+	}
+	return nil
+}
+
+// transferDescendants transfers the admin role for all the descendants of the resource which the "from" identity is an administrator of
+func (s *resourceServiceImpl) transferDescendants(ctx context.Context, resourceID string, fromIdentityID uuid.UUID, toIdentityID uuid.UUID, visitedChildren map[string]bool) error {
+	children, err := s.Repositories().ResourceRepository().LoadChildren(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if visitedChildren[child.ResourceID] {
+			return errors.NewInternalErrorFromString(ctx, fmt.Sprintf("cycle resource references detected for resource %s with parent %s", child.ResourceID, resourceID))
+		}
+		visitedChildren[child.ResourceID] = true
+		_, err = s.transferAdminRole(ctx, child, fromIdentityID, toIdentityID)
+		if err != nil {
+			return err
+		}
+		err = s.transferDescendants(ctx, child.ResourceID, fromIdentityID, toIdentityID, visitedChildren)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transferAdminRole revokes the admin role for the resource from the "from" identity and assigns it to the "to" identity,
+// unless it has it already. Returns false if the "from" identity is not an administrator of the resource.
+func (s *resourceServiceImpl) transferAdminRole(ctx context.Context, res resource.Resource, fromIdentityID uuid.UUID, toIdentityID uuid.UUID) (bool, error) {
+	adminRole, err := s.Repositories().RoleRepository().Lookup(ctx, authorization.AdminRoleForResourceType(res.ResourceType.Name), res.ResourceType.Name)
+	if err != nil {
+		if notFound, _ := errors.IsNotFoundError(err); notFound {
+			// the resource type has no admin role
+			return false, nil
+		}
+		return false, err
+	}
+
+	err = s.Repositories().IdentityRoleRepository().DeleteForIdentityResourceAndRole(ctx, res.ResourceID, fromIdentityID, adminRole.RoleID)
+	if err != nil {
+		if notFound, _ := errors.IsNotFoundError(err); notFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	roles, err := s.Repositories().IdentityRoleRepository().FindIdentityRolesByIdentityAndResource(ctx, res.ResourceID, toIdentityID)
+	if err != nil {
+		return false, err
+	}
+	for _, r := range roles {
+		if r.RoleID == adminRole.RoleID {
+			return true, nil
+		}
+	}
+
+	err = s.Repositories().IdentityRoleRepository().Create(ctx, &repository.IdentityRole{
+		ResourceID: res.ResourceID,
+		IdentityID: toIdentityID,
+		RoleID:     adminRole.RoleID,
+	})
+	return true, err
 }
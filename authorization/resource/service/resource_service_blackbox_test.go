@@ -4,13 +4,19 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/fabric8-services/fabric8-auth/application/service"
+	"github.com/fabric8-services/fabric8-auth/application/service/factory"
 	"github.com/fabric8-services/fabric8-auth/authorization"
+	rolerepo "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
+	tokenrepo "github.com/fabric8-services/fabric8-auth/authorization/token/repository"
 	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/gormapplication"
 	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
 	testsupport "github.com/fabric8-services/fabric8-auth/test"
 
+	errs "github.com/pkg/errors"
 	"github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -45,6 +51,18 @@ func (s *resourceServiceBlackBoxTest) TestRegisterResourceUnknownParentResourceF
 	require.EqualError(s.T(), err, fmt.Sprintf("Bad value for parameter 'parent resource ID': '%s' - resource with id '%s' not found", unknownParentID, unknownParentID))
 }
 
+func (s *resourceServiceBlackBoxTest) TestRegisterResourceIncompatibleParentFails() {
+	resourceID := uuid.NewV4().String()
+	parent := s.Graph.CreateSpace()
+	_, err := s.resourceService.Register(s.Ctx, authorization.ResourceTypeSpace, &resourceID, &parent.Resource().ResourceID)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+
+	_, err = s.resourceService.Register(s.Ctx, authorization.IdentityResourceTypeTeam, &resourceID, nil)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+}
+
 func (s *resourceServiceBlackBoxTest) TestRegisterReadDeleteResourceWithoutParentOK() {
 	resourceID := uuid.NewV4().String()
 
@@ -76,8 +94,7 @@ func (s *resourceServiceBlackBoxTest) TestRegisterReadDeleteResourceWithParentOK
 
 	// With parent resource
 	g := s.DBTestSuite.NewTestGraph()
-	g.CreateResource(g.ID("myparentresource"))
-	parent := g.ResourceByID("myparentresource").Resource()
+	parent := g.CreateOrganization().Resource()
 	resource, err := s.resourceService.Register(context.Background(), authorization.ResourceTypeSpace, &resourceID, &parent.ResourceID)
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), resourceID, resource.ResourceID)
@@ -180,6 +197,35 @@ func (s *resourceServiceBlackBoxTest) TestDeleteResourceOK() {
 	s.checkRoleMapping(true, spaceRoleMappingToStay.RoleMapping().RoleMappingID)
 }
 
+func (s *resourceServiceBlackBoxTest) TestDeleteResourceMarksTokensForRolesOfTeamsStale() {
+	g := s.DBTestSuite.NewTestGraph()
+	spaceToDelete := g.CreateSpace()
+	member := g.CreateUser()
+	team := g.CreateTeam(spaceToDelete).AddMember(member)
+
+	// The team has a role for another space, which is granted to its members
+	otherSpace := g.CreateSpace()
+	viewerRole, err := s.Application.RoleRepository().Lookup(s.Ctx, authorization.SpaceViewerRole, authorization.ResourceTypeSpace)
+	require.NoError(s.T(), err)
+	err = s.Application.IdentityRoleRepository().Create(s.Ctx, &rolerepo.IdentityRole{
+		ResourceID: otherSpace.SpaceID(),
+		IdentityID: team.TeamID(),
+		RoleID:     viewerRole.RoleID,
+	})
+	require.NoError(s.T(), err)
+	rpt := g.CreateToken(member)
+	err = s.Application.TokenRepository().AddResource(s.Ctx, rpt.TokenID(), otherSpace.SpaceID())
+	require.NoError(s.T(), err)
+
+	err = s.resourceService.Delete(s.Ctx, spaceToDelete.SpaceID())
+	require.NoError(s.T(), err)
+
+	loadedToken, err := s.Application.TokenRepository().Load(s.Ctx, rpt.TokenID())
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), tokenrepo.TokenStatusStale, loadedToken.Status)
+	s.checkIdentityRole(0, otherSpace.SpaceID())
+}
+
 func (s *resourceServiceBlackBoxTest) TestDeleteResourceWithCycleReferencesFails() {
 	g := s.DBTestSuite.NewTestGraph()
 	parent := g.CreateResource()
@@ -264,3 +310,213 @@ func (s *resourceServiceBlackBoxTest) checkUnknownResourceID(resourceID string)
 	_, err := s.resourceService.Read(context.Background(), resourceID)
 	require.EqualError(s.T(), err, fmt.Sprintf("resource with id '%s' not found", resourceID))
 }
+
+func (s *resourceServiceBlackBoxTest) TestTransferOwnershipWithDescendantsOK() {
+	owner := s.Graph.CreateUser()
+	newOwner := s.Graph.CreateUser()
+	org := s.Graph.CreateOrganization(owner)
+	space := s.Graph.CreateSpace(org).AddAdmin(owner)
+
+	notifications := &testsupport.RecordingNotificationService{}
+	application := gormapplication.NewGormDB(s.DB, s.Configuration, factory.WithNotificationService(notifications))
+
+	err := application.ResourceService().TransferOwnership(s.Ctx, owner.IdentityID(), org.ResourceID(), owner.IdentityID(), newOwner.IdentityID(), true)
+	require.NoError(s.T(), err)
+
+	for _, resourceID := range []string{org.ResourceID(), space.SpaceID()} {
+		roles, err := s.Application.IdentityRoleRepository().FindIdentityRolesByIdentityAndResource(s.Ctx, resourceID, owner.IdentityID())
+		require.NoError(s.T(), err)
+		assert.Empty(s.T(), roles)
+		roles, err = s.Application.IdentityRoleRepository().FindIdentityRolesByIdentityAndResource(s.Ctx, resourceID, newOwner.IdentityID())
+		require.NoError(s.T(), err)
+		require.Len(s.T(), roles, 1)
+	}
+
+	transfers, err := s.Application.ResourceOwnershipTransferRepository().ListForResource(s.Ctx, org.ResourceID())
+	require.NoError(s.T(), err)
+	require.Len(s.T(), transfers, 1)
+	assert.Equal(s.T(), owner.IdentityID(), transfers[0].FromIdentityID)
+	assert.Equal(s.T(), newOwner.IdentityID(), transfers[0].ToIdentityID)
+	assert.Equal(s.T(), owner.IdentityID(), transfers[0].TransferredBy)
+	assert.True(s.T(), transfers[0].IncludeDescendants)
+
+	messages := notifications.Messages()
+	require.Len(s.T(), messages, 2)
+	for _, msg := range messages {
+		assert.Equal(s.T(), "resource.ownership.transferred", msg.MessageType)
+		assert.Equal(s.T(), org.ResourceID(), msg.TargetID)
+		assert.Equal(s.T(), owner.Identity().Username, msg.Custom["previousOwner"])
+		assert.Equal(s.T(), newOwner.Identity().Username, msg.Custom["newOwner"])
+	}
+	assert.Equal(s.T(), owner.Identity().UserID.UUID.String(), *messages[0].UserID)
+	assert.Equal(s.T(), newOwner.Identity().UserID.UUID.String(), *messages[1].UserID)
+}
+
+func (s *resourceServiceBlackBoxTest) TestTransferOwnershipWithoutDescendantsOK() {
+	owner := s.Graph.CreateUser()
+	newOwner := s.Graph.CreateUser()
+	org := s.Graph.CreateOrganization(owner)
+	space := s.Graph.CreateSpace(org).AddAdmin(owner)
+
+	err := s.resourceService.TransferOwnership(s.Ctx, owner.IdentityID(), org.ResourceID(), owner.IdentityID(), newOwner.IdentityID(), false)
+	require.NoError(s.T(), err)
+
+	// The space is still owned by the previous owner
+	roles, err := s.Application.IdentityRoleRepository().FindIdentityRolesByIdentityAndResource(s.Ctx, space.SpaceID(), owner.IdentityID())
+	require.NoError(s.T(), err)
+	require.Len(s.T(), roles, 1)
+	roles, err = s.Application.IdentityRoleRepository().FindIdentityRolesByIdentityAndResource(s.Ctx, space.SpaceID(), newOwner.IdentityID())
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), roles)
+}
+
+func (s *resourceServiceBlackBoxTest) TestMove() {
+	s.T().Run("space moved to another organization", func(t *testing.T) {
+		oldOrgAdmin := s.Graph.CreateUser()
+		newOrgAdmin := s.Graph.CreateUser()
+		space := s.Graph.CreateSpace(s.Graph.CreateOrganization(oldOrgAdmin))
+		newOrg := s.Graph.CreateOrganization(newOrgAdmin)
+
+		hasScope, err := s.Application.PermissionService().HasScope(s.Ctx, oldOrgAdmin.IdentityID(), space.SpaceID(), authorization.DeleteSpaceScope)
+		require.NoError(t, err)
+		require.True(t, hasScope)
+
+		err = s.resourceService.Move(s.Ctx, space.SpaceID(), &newOrg.Resource().ResourceID)
+		require.NoError(t, err)
+
+		res, err := s.Application.ResourceRepository().Load(s.Ctx, space.SpaceID())
+		require.NoError(t, err)
+		require.NotNil(t, res.ParentResourceID)
+		assert.Equal(t, newOrg.ResourceID(), *res.ParentResourceID)
+
+		// The roles of the previous organization do not flow down to the space anymore
+		hasScope, err = s.Application.PermissionService().HasScope(s.Ctx, oldOrgAdmin.IdentityID(), space.SpaceID(), authorization.DeleteSpaceScope)
+		require.NoError(t, err)
+		assert.False(t, hasScope)
+		hasScope, err = s.Application.PermissionService().HasScope(s.Ctx, newOrgAdmin.IdentityID(), space.SpaceID(), authorization.DeleteSpaceScope)
+		require.NoError(t, err)
+		assert.True(t, hasScope)
+	})
+
+	s.T().Run("space moved to top level", func(t *testing.T) {
+		space := s.Graph.CreateSpace(s.Graph.CreateOrganization())
+
+		err := s.resourceService.Move(s.Ctx, space.SpaceID(), nil)
+		require.NoError(t, err)
+
+		res, err := s.Application.ResourceRepository().Load(s.Ctx, space.SpaceID())
+		require.NoError(t, err)
+		assert.Nil(t, res.ParentResourceID)
+
+		// A team must belong to a space
+		err = s.resourceService.Move(s.Ctx, s.Graph.CreateTeam(space).ResourceID(), nil)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("cycle", func(t *testing.T) {
+		rt := s.Graph.CreateResourceType()
+		parent := s.Graph.CreateResource(rt)
+		child := s.Graph.CreateResource(rt, parent)
+		grandChild := s.Graph.CreateResource(rt, child)
+
+		err := s.resourceService.Move(s.Ctx, parent.ResourceID(), &grandChild.Resource().ResourceID)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+
+		err = s.resourceService.Move(s.Ctx, parent.ResourceID(), &parent.Resource().ResourceID)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+
+		// A descendant may be moved up in the hierarchy
+		err = s.resourceService.Move(s.Ctx, grandChild.ResourceID(), &parent.Resource().ResourceID)
+		require.NoError(t, err)
+	})
+
+	s.T().Run("incompatible parent type", func(t *testing.T) {
+		space := s.Graph.CreateSpace()
+		err := s.resourceService.Move(s.Ctx, space.SpaceID(), &s.Graph.CreateSpace().Resource().ResourceID)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+
+		org := s.Graph.CreateOrganization()
+		err = s.resourceService.Move(s.Ctx, org.ResourceID(), &s.Graph.CreateOrganization().Resource().ResourceID)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("unknown resources", func(t *testing.T) {
+		err := s.resourceService.Move(s.Ctx, uuid.NewV4().String(), &s.Graph.CreateOrganization().Resource().ResourceID)
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+
+		unknownID := uuid.NewV4().String()
+		err = s.resourceService.Move(s.Ctx, s.Graph.CreateSpace().SpaceID(), &unknownID)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+}
+
+func (s *resourceServiceBlackBoxTest) TestList() {
+	s.T().Run("ok", func(t *testing.T) {
+		org := s.Graph.CreateOrganization()
+		space := s.Graph.CreateSpace(org)
+		s.Graph.CreateSpace()
+		typeName := authorization.ResourceTypeSpace
+		parentID := org.ResourceID()
+
+		resources, count, err := s.resourceService.List(s.Ctx, &typeName, &parentID, nil, nil, nil, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		require.Len(t, resources, 1)
+		assert.Equal(t, space.SpaceID(), resources[0].ResourceID)
+		assert.Equal(t, authorization.ResourceTypeSpace, resources[0].ResourceType.Name)
+	})
+
+	s.T().Run("unknown resource type", func(t *testing.T) {
+		typeName := "unknown-" + uuid.NewV4().String()
+		_, _, err := s.resourceService.List(s.Ctx, &typeName, nil, nil, nil, nil, 0, 10)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("unknown parent resource", func(t *testing.T) {
+		parentID := uuid.NewV4().String()
+		_, _, err := s.resourceService.List(s.Ctx, nil, &parentID, nil, nil, nil, 0, 10)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("invalid creation time range", func(t *testing.T) {
+		now := time.Now()
+		before := now.Add(-time.Hour)
+		_, _, err := s.resourceService.List(s.Ctx, nil, nil, nil, &now, &before, 0, 10)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+}
+
+func (s *resourceServiceBlackBoxTest) TestTransferOwnershipFails() {
+	owner := s.Graph.CreateUser()
+	contributor := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(owner).AddContributor(contributor)
+
+	// Same identity
+	err := s.resourceService.TransferOwnership(s.Ctx, owner.IdentityID(), space.SpaceID(), owner.IdentityID(), owner.IdentityID(), false)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+
+	// The "from" identity is not an administrator
+	err = s.resourceService.TransferOwnership(s.Ctx, owner.IdentityID(), space.SpaceID(), contributor.IdentityID(), owner.IdentityID(), false)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+
+	// Not allowed to manage the roles of the space
+	err = s.resourceService.TransferOwnership(s.Ctx, contributor.IdentityID(), space.SpaceID(), owner.IdentityID(), contributor.IdentityID(), false)
+	testsupport.AssertError(s.T(), err, errors.ForbiddenError{}, "identity with ID %s does not have required scope manage for resource %s", contributor.IdentityID(), space.SpaceID())
+
+	// Unknown identity
+	unknownID := uuid.NewV4()
+	err = s.resourceService.TransferOwnership(s.Ctx, owner.IdentityID(), space.SpaceID(), owner.IdentityID(), unknownID, false)
+	testsupport.AssertError(s.T(), err, errors.NotFoundError{}, "identity with id '%s' not found", unknownID)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/fabric8-services/fabric8-auth/errors"
@@ -86,11 +87,14 @@ func (m *GormResourceTypeRepository) Create(ctx context.Context, u *ResourceType
 			"resource_type_id": u.ResourceTypeID,
 			"err":              err,
 		}, "unable to create the resource type")
+		if gormsupport.IsUniqueViolation(err, "resource_type_name_key") {
+			return errs.WithStack(errors.NewDataConflictError(fmt.Sprintf("resource type already exists with name = %s", u.Name)))
+		}
 		return errs.WithStack(err)
 	}
 	log.Debug(ctx, map[string]interface{}{
 		"resource_type_id": u.ResourceTypeID,
-	}, "Resource Type Scope created!")
+	}, "Resource Type created!")
 	return nil
 }
 
@@ -0,0 +1,2 @@
+// Package service encapsulates the business logic for managing resource types, along with their scopes and roles
+package service
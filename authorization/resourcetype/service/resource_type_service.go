@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fabric8-services/fabric8-auth/application/service"
+	"github.com/fabric8-services/fabric8-auth/application/service/base"
+	servicecontext "github.com/fabric8-services/fabric8-auth/application/service/context"
+	"github.com/fabric8-services/fabric8-auth/authorization/permission/cache"
+	resourcetype "github.com/fabric8-services/fabric8-auth/authorization/resourcetype/repository"
+	"github.com/fabric8-services/fabric8-auth/authorization/role"
+	rolerepo "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/log"
+
+	"github.com/satori/go.uuid"
+)
+
+// resourceTypeServiceImpl is the implementation of the interface for ResourceTypeService.
+type resourceTypeServiceImpl struct {
+	base.BaseService
+}
+
+// NewResourceTypeService creates a new service.
+func NewResourceTypeService(context servicecontext.ServiceContext) service.ResourceTypeService {
+	return &resourceTypeServiceImpl{base.NewBaseService(context)}
+}
+
+// List returns all the resource types
+func (s *resourceTypeServiceImpl) List(ctx context.Context) ([]resourcetype.ResourceType, error) {
+	return s.Repositories().ResourceTypeRepository().List(ctx)
+}
+
+// Load returns the resource type with the specified ID
+func (s *resourceTypeServiceImpl) Load(ctx context.Context, resourceTypeID uuid.UUID) (*resourcetype.ResourceType, error) {
+	return s.Repositories().ResourceTypeRepository().Load(ctx, resourceTypeID)
+}
+
+// Create registers a new resource type with the specified name, along with the specified scopes
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *resourceTypeServiceImpl) Create(ctx context.Context, name string, scopeNames []string) (*resourcetype.ResourceType, error) {
+	if name == "" {
+		return nil, errors.NewBadParameterErrorFromString("name", name, "resource type name must not be empty")
+	}
+	var rt *resourcetype.ResourceType
+	err := s.ExecuteInTransaction(func() error {
+		rt = &resourcetype.ResourceType{
+			Name: name,
+		}
+		err := s.Repositories().ResourceTypeRepository().Create(ctx, rt)
+		if err != nil {
+			return err
+		}
+		for _, scopeName := range scopeNames {
+			_, err = s.addScope(ctx, *rt, scopeName)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"resource_type_id": rt.ResourceTypeID,
+		"name":             rt.Name,
+	}, "resource type registered")
+	return rt, nil
+}
+
+// Delete deletes the resource type with the specified ID, along with its scopes and roles. A resource type
+// may only be deleted if there are no resources of that type, and if none of its roles is in use.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *resourceTypeServiceImpl) Delete(ctx context.Context, resourceTypeID uuid.UUID) error {
+	return s.ExecuteInTransaction(func() error {
+		rt, err := s.Repositories().ResourceTypeRepository().Load(ctx, resourceTypeID)
+		if err != nil {
+			return err
+		}
+
+		count, err := s.Repositories().ResourceRepository().CountByResourceType(ctx, rt.ResourceTypeID)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.NewDataConflictError(fmt.Sprintf("resource type %s is used by %d resource(s)", rt.Name, count))
+		}
+
+		roles, err := s.Repositories().RoleRepository().FindRolesByResourceType(ctx, rt.Name)
+		if err != nil {
+			return err
+		}
+		for _, descriptor := range roles {
+			err = s.deleteRole(ctx, *rt, descriptor.RoleName)
+			if err != nil {
+				return err
+			}
+		}
+
+		scopes, err := s.Repositories().ResourceTypeScopeRepository().LookupForType(ctx, rt.ResourceTypeID)
+		if err != nil {
+			return err
+		}
+		for _, scope := range scopes {
+			err = s.Repositories().ResourceTypeScopeRepository().Delete(ctx, scope.ResourceTypeScopeID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.Repositories().ResourceTypeRepository().Delete(ctx, rt.ResourceTypeID)
+	})
+}
+
+// ListScopes returns the scopes of the resource type with the specified ID
+func (s *resourceTypeServiceImpl) ListScopes(ctx context.Context, resourceTypeID uuid.UUID) ([]resourcetype.ResourceTypeScope, error) {
+	rt, err := s.Repositories().ResourceTypeRepository().Load(ctx, resourceTypeID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Repositories().ResourceTypeScopeRepository().List(ctx, rt)
+}
+
+// AddScope declares a new scope for the resource type with the specified ID
+func (s *resourceTypeServiceImpl) AddScope(ctx context.Context, resourceTypeID uuid.UUID, scopeName string) (*resourcetype.ResourceTypeScope, error) {
+	rt, err := s.Repositories().ResourceTypeRepository().Load(ctx, resourceTypeID)
+	if err != nil {
+		return nil, err
+	}
+	return s.addScope(ctx, *rt, scopeName)
+}
+
+func (s *resourceTypeServiceImpl) addScope(ctx context.Context, rt resourcetype.ResourceType, scopeName string) (*resourcetype.ResourceTypeScope, error) {
+	if scopeName == "" {
+		return nil, errors.NewBadParameterErrorFromString("name", scopeName, "scope name must not be empty")
+	}
+	existing, err := s.Repositories().ResourceTypeScopeRepository().LookupByResourceTypeAndScope(ctx, rt.ResourceTypeID, scopeName)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.NewDataConflictError(fmt.Sprintf("scope %s already exists for resource type %s", scopeName, rt.Name))
+	}
+	scope := &resourcetype.ResourceTypeScope{
+		ResourceTypeID: rt.ResourceTypeID,
+		Name:           scopeName,
+	}
+	err = s.Repositories().ResourceTypeScopeRepository().Create(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	return scope, nil
+}
+
+// DeleteScope deletes the scope with the specified name from the resource type, and removes it from all the roles
+// which grant it
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *resourceTypeServiceImpl) DeleteScope(ctx context.Context, resourceTypeID uuid.UUID, scopeName string) error {
+	err := s.ExecuteInTransaction(func() error {
+		_, scope, err := s.lookupScope(ctx, resourceTypeID, scopeName)
+		if err != nil {
+			return err
+		}
+		err = s.Repositories().RoleScopeRepository().DeleteForScope(ctx, scope.ResourceTypeScopeID)
+		if err != nil {
+			return err
+		}
+		return s.Repositories().ResourceTypeScopeRepository().Delete(ctx, scope.ResourceTypeScopeID)
+	})
+	if err != nil {
+		return err
+	}
+	// The roles do not grant the scope anymore
+	cache.Invalidate()
+	return nil
+}
+
+// ListRoles returns the roles of the resource type with the specified ID, along with the scopes they grant
+func (s *resourceTypeServiceImpl) ListRoles(ctx context.Context, resourceTypeID uuid.UUID) ([]role.RoleDescriptor, error) {
+	rt, err := s.Repositories().ResourceTypeRepository().Load(ctx, resourceTypeID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Repositories().RoleRepository().FindRolesByResourceType(ctx, rt.Name)
+}
+
+// CreateRole defines a new role for the resource type with the specified ID, which grants the specified scopes
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *resourceTypeServiceImpl) CreateRole(ctx context.Context, resourceTypeID uuid.UUID, roleName string, scopeNames []string) (*rolerepo.Role, error) {
+	if roleName == "" {
+		return nil, errors.NewBadParameterErrorFromString("name", roleName, "role name must not be empty")
+	}
+	var r *rolerepo.Role
+	err := s.ExecuteInTransaction(func() error {
+		rt, err := s.Repositories().ResourceTypeRepository().Load(ctx, resourceTypeID)
+		if err != nil {
+			return err
+		}
+		r = &rolerepo.Role{
+			ResourceTypeID: rt.ResourceTypeID,
+			Name:           roleName,
+		}
+		err = s.Repositories().RoleRepository().Create(ctx, r)
+		if err != nil {
+			return err
+		}
+		for _, scopeName := range scopeNames {
+			_, scope, err := s.lookupScope(ctx, resourceTypeID, scopeName)
+			if err != nil {
+				return err
+			}
+			err = s.Repositories().RoleRepository().AddScope(ctx, r, scope)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DeleteRole deletes the role with the specified name from the resource type. A role may only be deleted if it
+// has not been assigned to any identity, and is not referenced by any role mapping or invitation.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *resourceTypeServiceImpl) DeleteRole(ctx context.Context, resourceTypeID uuid.UUID, roleName string) error {
+	return s.ExecuteInTransaction(func() error {
+		rt, err := s.Repositories().ResourceTypeRepository().Load(ctx, resourceTypeID)
+		if err != nil {
+			return err
+		}
+		return s.deleteRole(ctx, *rt, roleName)
+	})
+}
+
+func (s *resourceTypeServiceImpl) deleteRole(ctx context.Context, rt resourcetype.ResourceType, roleName string) error {
+	r, err := s.Repositories().RoleRepository().Lookup(ctx, roleName, rt.Name)
+	if err != nil {
+		return err
+	}
+	inUse, err := s.Repositories().RoleRepository().IsInUse(ctx, r.RoleID)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return errors.NewDataConflictError(fmt.Sprintf("role %s of resource type %s is in use", roleName, rt.Name))
+	}
+	scopes, err := s.Repositories().RoleRepository().ListScopes(ctx, r)
+	if err != nil {
+		return err
+	}
+	for i := range scopes {
+		err = s.Repositories().RoleRepository().RemoveScope(ctx, r, &scopes[i])
+		if err != nil {
+			return err
+		}
+	}
+	return s.Repositories().RoleRepository().Delete(ctx, r.RoleID)
+}
+
+// AddRoleScope adds the scope with the specified name to the role with the specified name, both belonging to the
+// resource type with the specified ID
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *resourceTypeServiceImpl) AddRoleScope(ctx context.Context, resourceTypeID uuid.UUID, roleName string, scopeName string) error {
+	err := s.ExecuteInTransaction(func() error {
+		r, scope, err := s.lookupRoleAndScope(ctx, resourceTypeID, roleName, scopeName)
+		if err != nil {
+			return err
+		}
+		return s.Repositories().RoleRepository().AddScope(ctx, r, scope)
+	})
+	if err != nil {
+		return err
+	}
+	// The identities which have been assigned the role are now granted the scope
+	cache.Invalidate()
+	return nil
+}
+
+// RemoveRoleScope removes the scope with the specified name from the role with the specified name, both belonging to
+// the resource type with the specified ID
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *resourceTypeServiceImpl) RemoveRoleScope(ctx context.Context, resourceTypeID uuid.UUID, roleName string, scopeName string) error {
+	err := s.ExecuteInTransaction(func() error {
+		r, scope, err := s.lookupRoleAndScope(ctx, resourceTypeID, roleName, scopeName)
+		if err != nil {
+			return err
+		}
+		return s.Repositories().RoleRepository().RemoveScope(ctx, r, scope)
+	})
+	if err != nil {
+		return err
+	}
+	// The identities which have been assigned the role are not granted the scope anymore
+	cache.Invalidate()
+	return nil
+}
+
+// lookupScope returns the resource type with the specified ID and its scope with the specified name
+func (s *resourceTypeServiceImpl) lookupScope(ctx context.Context, resourceTypeID uuid.UUID, scopeName string) (*resourcetype.ResourceType, *resourcetype.ResourceTypeScope, error) {
+	rt, err := s.Repositories().ResourceTypeRepository().Load(ctx, resourceTypeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	scope, err := s.Repositories().ResourceTypeScopeRepository().LookupByResourceTypeAndScope(ctx, rt.ResourceTypeID, scopeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if scope == nil {
+		return nil, nil, errors.NewNotFoundErrorWithKey("resource_type_scope", "name", scopeName)
+	}
+	return rt, scope, nil
+}
+
+// lookupRoleAndScope returns the role and the scope with the specified names, both belonging to the resource type
+// with the specified ID
+func (s *resourceTypeServiceImpl) lookupRoleAndScope(ctx context.Context, resourceTypeID uuid.UUID, roleName string, scopeName string) (*rolerepo.Role, *resourcetype.ResourceTypeScope, error) {
+	rt, scope, err := s.lookupScope(ctx, resourceTypeID, scopeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := s.Repositories().RoleRepository().Lookup(ctx, roleName, rt.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, scope, nil
+}
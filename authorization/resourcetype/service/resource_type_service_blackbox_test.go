@@ -0,0 +1,193 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-auth/application/service"
+	rolerepo "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
+
+	errs "github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type resourceTypeServiceBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	resourceTypeService service.ResourceTypeService
+}
+
+func TestRunResourceTypeServiceBlackBoxTest(t *testing.T) {
+	suite.Run(t, &resourceTypeServiceBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *resourceTypeServiceBlackBoxTest) SetupSuite() {
+	s.DBTestSuite.SetupSuite()
+	s.resourceTypeService = s.Application.ResourceTypeService()
+}
+
+func (s *resourceTypeServiceBlackBoxTest) TestCreateResourceTypeWithScopesAndRoles() {
+	name := "test.resourcetype/" + uuid.NewV4().String()
+	rt, err := s.resourceTypeService.Create(s.Ctx, name, []string{"view", "edit"})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), name, rt.Name)
+
+	loaded, err := s.resourceTypeService.Load(s.Ctx, rt.ResourceTypeID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), name, loaded.Name)
+
+	scopes, err := s.resourceTypeService.ListScopes(s.Ctx, rt.ResourceTypeID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), scopes, 2)
+	assert.Equal(s.T(), "edit", scopes[0].Name)
+	assert.Equal(s.T(), "view", scopes[1].Name)
+
+	_, err = s.resourceTypeService.CreateRole(s.Ctx, rt.ResourceTypeID, "editor", []string{"view", "edit"})
+	require.NoError(s.T(), err)
+	_, err = s.resourceTypeService.CreateRole(s.Ctx, rt.ResourceTypeID, "reader", []string{"view"})
+	require.NoError(s.T(), err)
+
+	roles, err := s.resourceTypeService.ListRoles(s.Ctx, rt.ResourceTypeID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), roles, 2)
+	for _, r := range roles {
+		switch r.RoleName {
+		case "editor":
+			assert.ElementsMatch(s.T(), []string{"view", "edit"}, r.Scopes)
+		case "reader":
+			assert.ElementsMatch(s.T(), []string{"view"}, r.Scopes)
+		default:
+			assert.Fail(s.T(), "unexpected role", r.RoleName)
+		}
+	}
+
+	s.T().Run("duplicate resource type", func(t *testing.T) {
+		_, err := s.resourceTypeService.Create(s.Ctx, name, nil)
+		require.Error(t, err)
+		assert.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("duplicate scope", func(t *testing.T) {
+		_, err := s.resourceTypeService.AddScope(s.Ctx, rt.ResourceTypeID, "view")
+		require.Error(t, err)
+		assert.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("duplicate role", func(t *testing.T) {
+		_, err := s.resourceTypeService.CreateRole(s.Ctx, rt.ResourceTypeID, "reader", nil)
+		require.Error(t, err)
+		assert.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("role with unknown scope", func(t *testing.T) {
+		_, err := s.resourceTypeService.CreateRole(s.Ctx, rt.ResourceTypeID, "admin", []string{"unknown"})
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+		// the role should not have been created
+		roles, err := s.resourceTypeService.ListRoles(s.Ctx, rt.ResourceTypeID)
+		require.NoError(t, err)
+		assert.Len(t, roles, 2)
+	})
+
+	s.T().Run("unknown resource type", func(t *testing.T) {
+		_, err := s.resourceTypeService.AddScope(s.Ctx, uuid.NewV4(), "view")
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+}
+
+func (s *resourceTypeServiceBlackBoxTest) TestManageRoleScopes() {
+	name := "test.resourcetype/" + uuid.NewV4().String()
+	rt, err := s.resourceTypeService.Create(s.Ctx, name, []string{"view", "edit"})
+	require.NoError(s.T(), err)
+	r, err := s.resourceTypeService.CreateRole(s.Ctx, rt.ResourceTypeID, "editor", []string{"view"})
+	require.NoError(s.T(), err)
+
+	// Assign the role to a user for a resource of the new type
+	user := s.Graph.CreateUser()
+	res, err := s.Application.ResourceService().Register(s.Ctx, name, nil, nil)
+	require.NoError(s.T(), err)
+	err = s.Application.IdentityRoleRepository().Create(s.Ctx, &rolerepo.IdentityRole{
+		ResourceID: res.ResourceID,
+		IdentityID: user.IdentityID(),
+		RoleID:     r.RoleID,
+	})
+	require.NoError(s.T(), err)
+
+	checkScope := func(t *testing.T, scopeName string, expected bool) {
+		granted, err := s.Application.PermissionService().HasScope(s.Ctx, user.IdentityID(), res.ResourceID, scopeName)
+		require.NoError(t, err)
+		assert.Equal(t, expected, granted)
+	}
+
+	s.T().Run("add scope to role", func(t *testing.T) {
+		checkScope(t, "edit", false)
+		err := s.resourceTypeService.AddRoleScope(s.Ctx, rt.ResourceTypeID, "editor", "edit")
+		require.NoError(t, err)
+		checkScope(t, "edit", true)
+
+		err = s.resourceTypeService.AddRoleScope(s.Ctx, rt.ResourceTypeID, "editor", "edit")
+		require.Error(t, err)
+		assert.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("remove scope from role", func(t *testing.T) {
+		err := s.resourceTypeService.RemoveRoleScope(s.Ctx, rt.ResourceTypeID, "editor", "edit")
+		require.NoError(t, err)
+		checkScope(t, "edit", false)
+		checkScope(t, "view", true)
+
+		err = s.resourceTypeService.RemoveRoleScope(s.Ctx, rt.ResourceTypeID, "editor", "edit")
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+
+	s.T().Run("delete scope", func(t *testing.T) {
+		err := s.resourceTypeService.DeleteScope(s.Ctx, rt.ResourceTypeID, "view")
+		require.NoError(t, err)
+		checkScope(t, "view", false)
+		scopes, err := s.resourceTypeService.ListScopes(s.Ctx, rt.ResourceTypeID)
+		require.NoError(t, err)
+		require.Len(t, scopes, 1)
+		assert.Equal(t, "edit", scopes[0].Name)
+	})
+
+	s.T().Run("delete role in use fails", func(t *testing.T) {
+		err := s.resourceTypeService.DeleteRole(s.Ctx, rt.ResourceTypeID, "editor")
+		require.Error(t, err)
+		assert.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("delete resource type in use fails", func(t *testing.T) {
+		err := s.resourceTypeService.Delete(s.Ctx, rt.ResourceTypeID)
+		require.Error(t, err)
+		assert.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+}
+
+func (s *resourceTypeServiceBlackBoxTest) TestDeleteResourceType() {
+	name := "test.resourcetype/" + uuid.NewV4().String()
+	rt, err := s.resourceTypeService.Create(s.Ctx, name, []string{"view"})
+	require.NoError(s.T(), err)
+	_, err = s.resourceTypeService.CreateRole(s.Ctx, rt.ResourceTypeID, "reader", []string{"view"})
+	require.NoError(s.T(), err)
+
+	err = s.resourceTypeService.DeleteRole(s.Ctx, rt.ResourceTypeID, "reader")
+	require.NoError(s.T(), err)
+	roles, err := s.resourceTypeService.ListRoles(s.Ctx, rt.ResourceTypeID)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), roles)
+
+	_, err = s.resourceTypeService.CreateRole(s.Ctx, rt.ResourceTypeID, "viewer", []string{"view"})
+	require.NoError(s.T(), err)
+
+	err = s.resourceTypeService.Delete(s.Ctx, rt.ResourceTypeID)
+	require.NoError(s.T(), err)
+
+	_, err = s.resourceTypeService.Load(s.Ctx, rt.ResourceTypeID)
+	require.Error(s.T(), err)
+	assert.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	account "github.com/fabric8-services/fabric8-auth/account/repository"
@@ -34,6 +35,8 @@ type IdentityRole struct {
 	// The role that is assigned
 	RoleID uuid.UUID `gorm:"type:uuid"`
 	Role   Role      `gorm:"foreignkey:RoleID;association_foreignkey:RoleID"`
+	// The optional time after which the role assignment no longer grants any permission
+	ExpiresAt *time.Time
 }
 
 // TableName overrides the table name settings in Gorm to force a specific table name
@@ -66,12 +69,17 @@ type IdentityRoleRepository interface {
 	List(ctx context.Context) ([]IdentityRole, error)
 	Delete(ctx context.Context, ID uuid.UUID) error
 	DeleteForResource(ctx context.Context, resourceID string) error
+	DeleteForIdentity(ctx context.Context, identityID uuid.UUID) error
 	DeleteForIdentityAndResource(ctx context.Context, resourceID string, identityID uuid.UUID) error
+	DeleteForIdentityResourceAndRole(ctx context.Context, resourceID string, identityID uuid.UUID, roleID uuid.UUID) error
 	FindPermissions(ctx context.Context, identityID uuid.UUID, resourceID string, scopeName string) ([]IdentityRole, error)
+	CheckPermissions(ctx context.Context, checks []authorization.PermissionCheck) ([]authorization.PermissionCheckResult, error)
+	FindResourcesWithScope(ctx context.Context, identityID uuid.UUID, resourceTypeName string, scopeName string, offset int, limit int) ([]resource.Resource, int, error)
 	FindIdentityRolesForIdentity(ctx context.Context, identityID uuid.UUID, resourceType *string) ([]authorization.IdentityAssociation, error)
 	FindIdentityRolesByResourceAndRoleName(ctx context.Context, resourceID string, roleName string, includeParenResources bool) ([]IdentityRole, error)
 	FindIdentityRolesByResource(ctx context.Context, resourceID string, includeParenResources bool) ([]IdentityRole, error)
 	FindIdentityRolesByIdentityAndResource(ctx context.Context, resourceID string, identityID uuid.UUID) ([]IdentityRole, error)
+	FindExpired(ctx context.Context, before time.Time) ([]IdentityRole, error)
 }
 
 // TableName overrides the table name settings in Gorm to force a specific table name
@@ -192,16 +200,121 @@ func (m *GormIdentityRoleRepository) List(ctx context.Context) ([]IdentityRole,
 	return rows, nil
 }
 
-// FindPermissions returns an IdentityRole array containing entries that match the specified identity, resource and scope
+// FindPermissions returns an IdentityRole array containing entries that match the specified identity, resource and scope.
+// Identity roles which have expired are ignored, even if they have not been deleted yet.
 func (m *GormIdentityRoleRepository) FindPermissions(ctx context.Context, identityID uuid.UUID, resourceID string, scopeName string) ([]IdentityRole, error) {
 	var results []IdentityRole
-	err := m.db.Table(m.TableName()).Where(`deleted_at IS NULL AND identity_id IN (
+	err := m.db.Table(m.TableName()).Where(permissionsCondition("?", "?", "?"),
+		identityID, identityID, resourceID, resourceID, scopeName, scopeName, resourceID).Scan(&results).Error
+
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+
+	return results, nil
+}
+
+// CheckPermissions evaluates all the specified permission checks in a single query, using the same rules as FindPermissions.
+// The returned array contains the result of each check, in the same order as the checks, along with the earliest expiry
+// of the identity roles which grant the scope.
+func (m *GormIdentityRoleRepository) CheckPermissions(ctx context.Context, checks []authorization.PermissionCheck) ([]authorization.PermissionCheckResult, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "identity_role", "CheckPermissions"}, time.Now())
+	results := make([]authorization.PermissionCheckResult, len(checks))
+	if len(checks) == 0 {
+		return results, nil
+	}
+
+	values := make([]string, len(checks))
+	var args []interface{}
+	for i, check := range checks {
+		values[i] = "(?::integer, ?::uuid, ?::text, ?::text)"
+		args = append(args, i, check.IdentityID, check.ResourceID, check.ScopeName)
+	}
+	query := fmt.Sprintf(`SELECT c.idx, p.expires_at FROM (VALUES %s) AS c (idx, check_identity_id, check_resource_id, check_scope_name),
+LATERAL (SELECT count(*) AS grants, min(expires_at) AS expires_at FROM identity_role WHERE %s) AS p
+WHERE p.grants > 0`, strings.Join(values, ", "),
+		permissionsCondition("c.check_identity_id", "c.check_resource_id", "c.check_scope_name"))
+
+	rows, err := m.db.Raw(query, args...).Rows()
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var idx int
+		var expiresAt *time.Time
+		err = rows.Scan(&idx, &expiresAt)
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		results[idx] = authorization.PermissionCheckResult{Granted: true, ExpiresAt: expiresAt}
+	}
+	return results, errs.WithStack(rows.Err())
+}
+
+// FindResourcesWithScope returns a page of the resources of the specified type for which the scope has been granted to the identity,
+// using the same rules as FindPermissions, along with the total number of such resources. The resources are ordered by name and identifier.
+func (m *GormIdentityRoleRepository) FindResourcesWithScope(ctx context.Context, identityID uuid.UUID, resourceTypeName string, scopeName string, offset int, limit int) ([]resource.Resource, int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "identity_role", "FindResourcesWithScope"}, time.Now())
+
+	// the alias of the resources must not clash with the aliases used in the permissions condition
+	condition := fmt.Sprintf(`FROM resource target INNER JOIN resource_type target_type ON target.resource_type_id = target_type.resource_type_id
+WHERE target.deleted_at IS NULL AND target_type.name = ? AND EXISTS (SELECT 1 FROM identity_role WHERE %s)`,
+		permissionsCondition("?", "target.resource_id", "?"))
+	args := []interface{}{resourceTypeName, identityID, identityID, scopeName, scopeName}
+
+	var count int
+	err := m.db.Raw("SELECT count(target.resource_id) "+condition, args...).Row().Scan(&count)
+	if err != nil {
+		return nil, 0, errs.WithStack(err)
+	}
+
+	rows, err := m.db.Raw("SELECT target.resource_id "+condition+" ORDER BY target.name, target.resource_id LIMIT ? OFFSET ?", append(args, limit, offset)...).Rows()
+	if err != nil {
+		return nil, 0, errs.WithStack(err)
+	}
+	defer rows.Close()
+	resourceIDs := []string{}
+	for rows.Next() {
+		var resourceID string
+		err = rows.Scan(&resourceID)
+		if err != nil {
+			return nil, 0, errs.WithStack(err)
+		}
+		resourceIDs = append(resourceIDs, resourceID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, errs.WithStack(err)
+	}
+
+	resources := []resource.Resource{}
+	if len(resourceIDs) == 0 {
+		return resources, count, nil
+	}
+	err = m.db.Table("resource").Preload("ResourceType").Where("resource_id IN (?)", resourceIDs).Order("name, resource_id").Find(&resources).Error
+	if err != nil {
+		return nil, 0, errs.WithStack(err)
+	}
+	return resources, count, nil
+}
+
+// permissionsCondition returns the condition matching the identity roles which grant the scope for the resource to the identity
+// (directly, through memberships, resource ancestry or role mappings). The identity, resource and scope are given as SQL expressions,
+// i.e. either query parameters or column references.
+func permissionsCondition(identityID, resourceID, scopeName string) string {
+	return strings.NewReplacer(
+		"{{IDENTITY_ID}}", identityID,
+		"{{RESOURCE_ID}}", resourceID,
+		"{{SCOPE}}", scopeName).Replace(permissionsConditionTemplate)
+}
+
+const permissionsConditionTemplate = `deleted_at IS NULL AND (expires_at IS NULL OR expires_at > now()) AND identity_id IN (
   SELECT
     id
   FROM
     identities i
   WHERE
-    id = ? /* IDENTITY_ID */
+    id = {{IDENTITY_ID}}
     OR id IN (
     WITH RECURSIVE m AS (
       SELECT 
@@ -209,7 +322,7 @@ func (m *GormIdentityRoleRepository) FindPermissions(ctx context.Context, identi
       FROM 
         membership 
       WHERE 
-        member_id = ? /* IDENTITY_ID */
+        member_id = {{IDENTITY_ID}}
       UNION SELECT 
         p.member_of 
       FROM 
@@ -226,7 +339,7 @@ func (m *GormIdentityRoleRepository) FindPermissions(ctx context.Context, identi
     resource
   WHERE
     deleted_at IS NULL
-    AND resource_id = ? /* RESOURCE_ID */
+    AND resource_id = {{RESOURCE_ID}}
   UNION SELECT
     p.resource_id, p.parent_resource_id
   FROM
@@ -246,14 +359,14 @@ func (m *GormIdentityRoleRepository) FindPermissions(ctx context.Context, identi
       role_scope rs,
       resource_type_scope rts
     WHERE
-      res.resource_id = ? /* RESOURCE_ID */
+      res.resource_id = {{RESOURCE_ID}}
       AND res.deleted_at IS NULL
       AND res.resource_type_id = r.resource_type_id
       AND r.deleted_at IS NULL
       AND r.role_id = rs.role_id
       AND rs.scope_id = rts.resource_type_scope_id
       AND rs.deleted_at IS NULL
-      AND rts.name = ? /* SCOPE */
+      AND rts.name = {{SCOPE}}
       AND rts.deleted_at IS NULL
   ) OR role_id IN (
     SELECT DISTINCT
@@ -277,14 +390,14 @@ func (m *GormIdentityRoleRepository) FindPermissions(ctx context.Context, identi
       AND rs.deleted_at IS NULL
       AND rs.scope_id = rts.resource_type_scope_id
       AND rts.deleted_at IS NULL
-      AND rts.name = ? /* SCOPE */
+      AND rts.name = {{SCOPE}}
       AND rm.resource_id IN (WITH RECURSIVE m AS ( /* only resources that are in the ancestor hierarchy */
       SELECT
         resource_id, parent_resource_id
       FROM
         resource
       WHERE
-        resource_id = ? /* RESOURCE_ID */
+        resource_id = {{RESOURCE_ID}}
         AND deleted_at IS NULL
       UNION SELECT
         p.resource_id, p.parent_resource_id
@@ -328,16 +441,13 @@ func (m *GormIdentityRoleRepository) FindPermissions(ctx context.Context, identi
     CROSS JOIN LATERAL (
       VALUES (from_role_id), (to_role_id)
       ) AS rl (role_id))
-  )`, identityID, identityID, resourceID, resourceID, scopeName, scopeName, resourceID).Scan(&results).Error
+  )`
 
-	if err != nil {
-		return nil, errs.WithStack(err)
-	}
+// identityRoleNotExpiredCondition excludes the identity roles which have expired but have not been deleted yet
+const identityRoleNotExpiredCondition = "identity_role.expires_at IS NULL OR identity_role.expires_at > now()"
 
-	return results, nil
-}
-
-// FindIdentityRolesForIdentity returns an IdentityAssociations describing the roles which the specified Identity has, optionally for a specified resource type
+// FindIdentityRolesForIdentity returns an IdentityAssociations describing the roles which the specified Identity has, optionally for a specified resource type.
+// Identity roles which have expired are ignored, even if they have not been deleted yet.
 func (m *GormIdentityRoleRepository) FindIdentityRolesForIdentity(ctx context.Context, identityID uuid.UUID, resourceType *string) ([]authorization.IdentityAssociation, error) {
 	defer goa.MeasureSince([]string{"goa", "db", "identity_role", "FindIdentityRolesForIdentity"}, time.Now())
 	associations := []authorization.IdentityAssociation{}
@@ -355,7 +465,7 @@ func (m *GormIdentityRoleRepository) FindIdentityRolesForIdentity(ctx context.Co
 	}
 	q = q.Joins("JOIN role ON role.role_id = identity_role.role_id")
 
-	rows, err := q.Where(`(identity_role.identity_id = ? OR identity_role.identity_id IN (WITH RECURSIVE m AS (
+	rows, err := q.Where(identityRoleNotExpiredCondition).Where(`(identity_role.identity_id = ? OR identity_role.identity_id IN (WITH RECURSIVE m AS (
 			SELECT member_of FROM	membership WHERE member_id = ? 
       UNION SELECT p.member_of	FROM membership p INNER JOIN m ON m.member_of = p.member_id)
 		  SELECT member_of FROM m))`, identityID, identityID).Rows()
@@ -379,7 +489,8 @@ func (m *GormIdentityRoleRepository) FindIdentityRolesForIdentity(ctx context.Co
 	return associations, nil
 }
 
-// FindIdentityRolesByResourceAndRoleName returns an array of IdentityRole objects that match the specified resource and role name
+// FindIdentityRolesByResourceAndRoleName returns an array of IdentityRole objects that match the specified resource and role name.
+// Identity roles which have expired are ignored, even if they have not been deleted yet.
 func (m *GormIdentityRoleRepository) FindIdentityRolesByResourceAndRoleName(ctx context.Context, resourceID string, roleName string, includeParenResources bool) ([]IdentityRole, error) {
 	if includeParenResources {
 		return m.findIdentityRolesByResourceAndRoleNameWithParents(ctx, resourceID, roleName)
@@ -394,7 +505,8 @@ func (m *GormIdentityRoleRepository) findIdentityRolesByResourceAndRoleName(ctx
 
 	err := m.db.Table(m.TableName()).Preload("Role").Preload("Resource").Preload("Identity").
 		Where(`resource_id = ?`, resourceID).
-		Joins("JOIN role ON identity_role.role_id = role.role_id AND role.name = ?", roleName).Order("created_at").Find(&identityRoles).Error
+		Joins("JOIN role ON identity_role.role_id = role.role_id AND role.name = ?", roleName).Where(identityRoleNotExpiredCondition).
+		Order("created_at").Find(&identityRoles).Error
 
 	return identityRoles, err
 }
@@ -409,12 +521,14 @@ func (m *GormIdentityRoleRepository) findIdentityRolesByResourceAndRoleNameWithP
       SELECT resource_id, parent_resource_id FROM resource WHERE resource_id = ? AND deleted_at IS NULL
       UNION SELECT p.resource_id, p.parent_resource_id FROM resource p INNER JOIN r ON r.parent_resource_id = p.resource_id)
 	    SELECT r.resource_id FROM r)`, resourceID).
-		Joins("JOIN role ON identity_role.role_id = role.role_id AND role.name = ?", roleName).Order("created_at").Find(&identityRoles).Error
+		Joins("JOIN role ON identity_role.role_id = role.role_id AND role.name = ?", roleName).Where(identityRoleNotExpiredCondition).
+		Order("created_at").Find(&identityRoles).Error
 
 	return identityRoles, err
 }
 
-// FindIdentityRolesByResource returns an array of IdentityRole for the specified resource
+// FindIdentityRolesByResource returns an array of IdentityRole for the specified resource.
+// Identity roles which have expired are ignored, even if they have not been deleted yet.
 func (m *GormIdentityRoleRepository) FindIdentityRolesByResource(ctx context.Context, resourceID string, includeParenResources bool) ([]IdentityRole, error) {
 	if includeParenResources {
 		return m.findIdentityRolesByResourceWithParents(ctx, resourceID)
@@ -428,7 +542,7 @@ func (m *GormIdentityRoleRepository) findIdentityRolesByResource(ctx context.Con
 	var identityRoles []IdentityRole
 
 	err := m.db.Table(m.TableName()).Preload("Role").Preload("Resource").Preload("Identity").
-		Where(`resource_id = ?`, resourceID).Order("created_at").Find(&identityRoles).Error
+		Where(`resource_id = ?`, resourceID).Where(identityRoleNotExpiredCondition).Order("created_at").Find(&identityRoles).Error
 
 	return identityRoles, err
 }
@@ -442,7 +556,7 @@ func (m *GormIdentityRoleRepository) findIdentityRolesByResourceWithParents(ctx
 		Where(`resource_id in (WITH RECURSIVE r AS (
       SELECT resource_id, parent_resource_id FROM resource WHERE resource_id = ? AND deleted_at IS NULL
       UNION SELECT p.resource_id, p.parent_resource_id FROM resource p INNER JOIN r ON r.parent_resource_id = p.resource_id)
-	    SELECT r.resource_id FROM r)`, resourceID).Order("created_at").
+	    SELECT r.resource_id FROM r)`, resourceID).Where(identityRoleNotExpiredCondition).Order("created_at").
 		Find(&identityRoles).Error
 
 	return identityRoles, err
@@ -460,6 +574,18 @@ func (m *GormIdentityRoleRepository) DeleteForResource(ctx context.Context, reso
 	return nil
 }
 
+// DeleteForIdentity deletes all identity roles assigned to the given identity, for any resource
+// No error is returned if no identity role found
+func (m *GormIdentityRoleRepository) DeleteForIdentity(ctx context.Context, identityID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "identity_role", "deleteForIdentity"}, time.Now())
+
+	err := m.db.Table(m.TableName()).Where("identity_id = ?", identityID).Delete(nil).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return errs.WithStack(err)
+	}
+	return nil
+}
+
 // DeleteForIdentityAndResource deletes all IdentityRoles for the specified identity and resource
 // NotFoundError returned if no identity roles found to delete
 func (m *GormIdentityRoleRepository) DeleteForIdentityAndResource(ctx context.Context, resourceID string, identityID uuid.UUID) error {
@@ -479,11 +605,68 @@ func (m *GormIdentityRoleRepository) DeleteForIdentityAndResource(ctx context.Co
 	return nil
 }
 
+// DeleteForIdentityResourceAndRole deletes the identity role with the specified role for the specified identity and resource
+func (m *GormIdentityRoleRepository) DeleteForIdentityResourceAndRole(ctx context.Context, resourceID string, identityID uuid.UUID, roleID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "identity_role", "deleteForIdentityResourceAndRole"}, time.Now())
+	result := m.db.Scopes(identityRoleFilterByIdentityID(identityID), identityRoleFilterByResource(resourceID), identityRoleFilterByRoleID(roleID)).Table(m.TableName()).Delete(nil)
+	if result.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":         result.Error,
+			"resource_id": resourceID,
+			"identity_id": identityID,
+			"role_id":     roleID,
+		}, "unable to delete identity role")
+		return errs.WithStack(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewNotFoundErrorFromString(fmt.Sprintf("identity_role with resource_id '%s', identity_id '%s' and role_id '%s' not found", resourceID, identityID, roleID))
+	}
+	return nil
+}
+
 // FindIdentityRolesByIdentityAndResource returns all identity roles by identity ID and resource ID
 func (m *GormIdentityRoleRepository) FindIdentityRolesByIdentityAndResource(ctx context.Context, resourceID string, identityID uuid.UUID) ([]IdentityRole, error) {
 	return m.query(identityRoleFilterByIdentityID(identityID), identityRoleFilterByResource(resourceID))
 }
 
+// FindExpired returns all identity roles which expired before the specified time, along with their role, resource and identity.
+// The identity roles are locked until the end of the current transaction, and the identity roles already locked by another
+// transaction are skipped, so that the instances of the service do not process the same expired identity roles.
+func (m *GormIdentityRoleRepository) FindExpired(ctx context.Context, before time.Time) ([]IdentityRole, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "identity_role", "FindExpired"}, time.Now())
+
+	// only lock the identity roles, not the rows loaded along with them
+	rows, err := m.db.Raw(`SELECT identity_role_id FROM identity_role
+WHERE deleted_at IS NULL AND expires_at IS NOT NULL AND expires_at <= ? FOR UPDATE SKIP LOCKED`, before).Rows()
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errs.WithStack(err)
+	}
+
+	var identityRoles []IdentityRole
+	if len(ids) == 0 {
+		return identityRoles, nil
+	}
+	err = m.db.Table(m.TableName()).Preload("Role").Preload("Resource").Preload("Resource.ResourceType").Preload("Identity").
+		Where("identity_role_id IN (?)", ids).Order("expires_at").Find(&identityRoles).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errs.WithStack(err)
+	}
+	return identityRoles, nil
+}
+
 // Query exposes an open ended Query model
 func (m *GormIdentityRoleRepository) query(funcs ...func(*gorm.DB) *gorm.DB) ([]IdentityRole, error) {
 	defer goa.MeasureSince([]string{"goa", "db", "identity_role", "list"}, time.Now())
@@ -506,6 +689,13 @@ func identityRoleFilterByResource(resourceID string) func(db *gorm.DB) *gorm.DB
 	}
 }
 
+// IdentityRoleFilterByRoleID is a gorm filter for Role ID.
+func identityRoleFilterByRoleID(roleID uuid.UUID) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("role_id = ?", roleID)
+	}
+}
+
 // IdentityRoleFilterByIdentityID is a gorm filter for Identity ID.
 func identityRoleFilterByIdentityID(identityID uuid.UUID) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
@@ -162,6 +162,31 @@ func (s *identityRoleBlackBoxTest) TestDeleteForIdentityAndResourceOK() {
 	assert.Len(s.T(), idRoles, 10)
 }
 
+func (s *identityRoleBlackBoxTest) TestDeleteForIdentity() {
+	user := s.Graph.CreateUser()
+	space1 := s.Graph.CreateSpace().AddViewer(user).AddContributor(user)
+	space2 := s.Graph.CreateSpace().AddAdmin(user)
+	// noise
+	space1.AddViewer(s.Graph.CreateUser())
+
+	err := s.repo.DeleteForIdentity(s.Ctx, user.IdentityID())
+	require.NoError(s.T(), err)
+
+	idRoles, err := s.repo.FindIdentityRolesByIdentityAndResource(s.Ctx, space1.SpaceID(), user.IdentityID())
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), idRoles)
+	idRoles, err = s.repo.FindIdentityRolesByIdentityAndResource(s.Ctx, space2.SpaceID(), user.IdentityID())
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), idRoles)
+	idRoles, err = s.repo.FindIdentityRolesByResource(s.Ctx, space1.SpaceID(), false)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), idRoles, 1)
+
+	// No error if there is no identity role left
+	err = s.repo.DeleteForIdentity(s.Ctx, user.IdentityID())
+	require.NoError(s.T(), err)
+}
+
 func (s *identityRoleBlackBoxTest) TestOKToDeleteForUnknownResource() {
 	err := s.repo.DeleteForResource(s.Ctx, uuid.NewV4().String())
 	require.NoError(s.T(), err)
@@ -266,6 +291,28 @@ func (s *identityRoleBlackBoxTest) TestFindPermissions() {
 	require.Len(s.T(), identityRoles, 0)
 }
 
+func (s *identityRoleBlackBoxTest) TestFindPermissionsThroughSecurityGroup() {
+	member := s.Graph.CreateUser()
+	group := s.Graph.CreateGroup().AddMember(member)
+	space := s.Graph.CreateSpace().AddContributor(group)
+
+	// the member of the group is granted the scopes of the group's role
+	identityRoles, err := s.repo.FindPermissions(s.Ctx, member.IdentityID(), space.SpaceID(), "contribute")
+	require.NoError(s.T(), err)
+	require.Len(s.T(), identityRoles, 1)
+	require.Equal(s.T(), group.GroupID(), identityRoles[0].IdentityID)
+
+	// but not the scopes of other roles
+	identityRoles, err = s.repo.FindPermissions(s.Ctx, member.IdentityID(), space.SpaceID(), "manage")
+	require.NoError(s.T(), err)
+	require.Len(s.T(), identityRoles, 0)
+
+	// and users which are not members of the group are not granted anything
+	identityRoles, err = s.repo.FindPermissions(s.Ctx, s.Graph.CreateUser().IdentityID(), space.SpaceID(), "contribute")
+	require.NoError(s.T(), err)
+	require.Len(s.T(), identityRoles, 0)
+}
+
 func (s *identityRoleBlackBoxTest) TestFindIdentityRolesForIdentity() {
 	identityRole := createAndLoadIdentityRole(s)
 	createAndLoadIdentityRole(s)
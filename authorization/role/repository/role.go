@@ -54,6 +54,8 @@ type RoleRepository interface {
 	Lookup(ctx context.Context, name string, resourceType string) (*Role, error)
 	ListScopes(ctx context.Context, u *Role) ([]resourcetype.ResourceTypeScope, error)
 	AddScope(ctx context.Context, u *Role, s *resourcetype.ResourceTypeScope) error
+	RemoveScope(ctx context.Context, u *Role, s *resourcetype.ResourceTypeScope) error
+	IsInUse(ctx context.Context, id uuid.UUID) (bool, error)
 
 	FindRolesByResourceType(ctx context.Context, resourceType string) ([]role.RoleDescriptor, error)
 }
@@ -234,6 +236,9 @@ func (m *GormRoleRepository) AddScope(ctx context.Context, u *Role, s *resourcet
 			"scope_id": s.ResourceTypeScopeID,
 			"err":      err,
 		}, "unable to create the role scope")
+		if gormsupport.IsUniqueViolation(err, "role_scope_pkey") {
+			return errs.WithStack(errors.NewDataConflictError(fmt.Sprintf("scope %s is already defined for role %s", s.Name, u.Name)))
+		}
 		return errs.WithStack(err)
 	}
 	log.Debug(ctx, map[string]interface{}{
@@ -243,6 +248,47 @@ func (m *GormRoleRepository) AddScope(ctx context.Context, u *Role, s *resourcet
 	return nil
 }
 
+// RemoveScope removes the scope from the role
+func (m *GormRoleRepository) RemoveScope(ctx context.Context, u *Role, s *resourcetype.ResourceTypeScope) error {
+	defer goa.MeasureSince([]string{"goa", "db", "role", "removescope"}, time.Now())
+
+	result := m.db.Unscoped().Where("role_id = ? AND scope_id = ?", u.RoleID, s.ResourceTypeScopeID).Delete(&RoleScope{})
+	if result.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"role_id":  u.RoleID,
+			"scope_id": s.ResourceTypeScopeID,
+			"err":      result.Error,
+		}, "unable to delete the role scope")
+		return errs.WithStack(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewNotFoundErrorWithKey("role_scope", "name", s.Name)
+	}
+	log.Debug(ctx, map[string]interface{}{
+		"role_id":  u.RoleID,
+		"scope_id": s.ResourceTypeScopeID,
+	}, "Role scope deleted!")
+	return nil
+}
+
+// IsInUse returns true if the role has been assigned to an identity, or is referenced by a role mapping, a default role
+// mapping or an invitation
+func (m *GormRoleRepository) IsInUse(ctx context.Context, id uuid.UUID) (bool, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "role", "isInUse"}, time.Now())
+
+	var result struct {
+		InUse bool
+	}
+	err := m.db.Raw(`SELECT EXISTS (SELECT 1 FROM identity_role WHERE role_id = ? AND deleted_at IS NULL)
+		OR EXISTS (SELECT 1 FROM role_mapping WHERE (from_role_id = ? OR to_role_id = ?) AND deleted_at IS NULL)
+		OR EXISTS (SELECT 1 FROM default_role_mapping WHERE (from_role_id = ? OR to_role_id = ?) AND deleted_at IS NULL)
+		OR EXISTS (SELECT 1 FROM invitation_role WHERE role_id = ?) AS in_use`, id, id, id, id, id, id).Scan(&result).Error
+	if err != nil {
+		return false, errs.WithStack(err)
+	}
+	return result.InUse, nil
+}
+
 func (m *GormRoleRepository) FindRolesByResourceType(ctx context.Context, resourceType string) ([]role.RoleDescriptor, error) {
 	defer goa.MeasureSince([]string{"goa", "db", "role", "FindRolesByResourceType"}, time.Now())
 	var roles []role.RoleDescriptor
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/fabric8-services/fabric8-auth/application/repository/base"
@@ -69,6 +70,7 @@ type RoleMappingRepository interface {
 	Delete(ctx context.Context, ID uuid.UUID) error
 	DeleteForResource(ctx context.Context, resourceID string) error
 	FindForResource(ctx context.Context, resourceID string) ([]RoleMapping, error)
+	FindMappingPath(ctx context.Context, fromRoleID uuid.UUID, resourceID string, scopeName string) ([]RoleMapping, error)
 }
 
 // TableName overrides the table name settings in Gorm to force a specific table name
@@ -200,3 +202,69 @@ func (m *GormRoleMappingRepository) FindForResource(ctx context.Context, resourc
 	}
 	return rows, nil
 }
+
+// FindMappingPath returns the shortest chain of role mappings which translates the specified role into a role granting the
+// specified scope for the resource, following the same rules as the permission checks (i.e. only the role mappings defined
+// for the resource or one of its ancestors are taken into account). The returned role mappings are in the order in which
+// they apply, starting with the mapping from the specified role. An empty array is returned if there is no such chain.
+func (m *GormRoleMappingRepository) FindMappingPath(ctx context.Context, fromRoleID uuid.UUID, resourceID string, scopeName string) ([]RoleMapping, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "role_mapping", "findMappingPath"}, time.Now())
+
+	var results []struct {
+		Path string
+	}
+	err := m.db.Raw(`WITH RECURSIVE ancestors AS (
+			SELECT resource_id, parent_resource_id FROM resource WHERE resource_id = ? AND deleted_at IS NULL
+			UNION SELECT p.resource_id, p.parent_resource_id FROM resource p INNER JOIN ancestors a ON a.parent_resource_id = p.resource_id
+		), prm AS (
+			SELECT
+				rm.from_role_id,
+				ARRAY[rm.role_mapping_id] AS path
+			FROM
+				role_mapping rm,
+				role r,
+				role_scope rs,
+				resource_type_scope rts
+			WHERE
+				rm.deleted_at IS NULL
+				AND rm.to_role_id = r.role_id
+				AND r.deleted_at IS NULL
+				AND r.role_id = rs.role_id
+				AND rs.deleted_at IS NULL
+				AND rs.scope_id = rts.resource_type_scope_id
+				AND rts.deleted_at IS NULL
+				AND rts.name = ?
+				AND rm.resource_id IN (SELECT resource_id FROM ancestors)
+			UNION SELECT
+				trm.from_role_id,
+				trm.role_mapping_id || prm.path
+			FROM
+				role_mapping trm INNER JOIN prm ON prm.from_role_id = trm.to_role_id
+			WHERE
+				trm.deleted_at IS NULL
+				AND trm.resource_id IN (SELECT resource_id FROM ancestors)
+				AND NOT trm.role_mapping_id = ANY(prm.path)
+		)
+		SELECT array_to_string(path, ',') AS path FROM prm WHERE from_role_id = ? ORDER BY array_length(path, 1) LIMIT 1`,
+		resourceID, scopeName, fromRoleID).Scan(&results).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errs.WithStack(err)
+	}
+
+	mappings := []RoleMapping{}
+	if len(results) == 0 {
+		return mappings, nil
+	}
+	for _, id := range strings.Split(results[0].Path, ",") {
+		mappingID, err := uuid.FromString(id)
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		mapping, err := m.Load(ctx, mappingID)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, *mapping)
+	}
+	return mappings, nil
+}
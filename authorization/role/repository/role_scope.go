@@ -57,6 +57,7 @@ type RoleScopeRepository interface {
 	LoadByScope(ctx context.Context, ID uuid.UUID) ([]RoleScope, error)
 	LoadByRole(ctx context.Context, ID uuid.UUID) ([]RoleScope, error)
 	Create(ctx context.Context, roleScope *RoleScope) error
+	DeleteForScope(ctx context.Context, scopeID uuid.UUID) error
 	Query(funcs ...func(*gorm.DB) *gorm.DB) ([]RoleScope, error)
 }
 
@@ -85,6 +86,20 @@ func (m *GormRoleScopeRepository) Create(ctx context.Context, roleScope *RoleSco
 	return nil
 }
 
+// DeleteForScope removes the scope from all the roles it has been added to
+func (m *GormRoleScopeRepository) DeleteForScope(ctx context.Context, scopeID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "role_scope", "deleteForScope"}, time.Now())
+	err := m.db.Unscoped().Where("scope_id = ?", scopeID).Delete(&RoleScope{}).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"scope_id": scopeID,
+			"err":      err,
+		}, "unable to delete the role scopes")
+		return errs.WithStack(err)
+	}
+	return nil
+}
+
 //LoadByScope loads a 'role & scope assocation' by the scope ID
 func (m *GormRoleScopeRepository) LoadByScope(ctx context.Context, ID uuid.UUID) ([]RoleScope, error) {
 	return m.Query(RoleScopeFilterByScope(ID))
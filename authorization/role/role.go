@@ -7,3 +7,9 @@ type RoleDescriptor struct {
 	Scopes       []string
 	ResourceType string
 }
+
+// RoleReference is a DTO used to identify a role by its name and the name of the resource type it belongs to
+type RoleReference struct {
+	RoleName     string
+	ResourceType string
+}
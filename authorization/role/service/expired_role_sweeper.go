@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-auth/application/service"
+	"github.com/fabric8-services/fabric8-auth/log"
+	"github.com/fabric8-services/fabric8-auth/worker"
+)
+
+// StartExpiredRoleSweeper starts a goroutine which deletes the expired role assignments at the specified interval,
+// using the given role management service. The sweeper is disabled if the interval is zero or negative.
+// The returned function stops the sweeper.
+func StartExpiredRoleSweeper(roleManagementService service.RoleManagementService, interval time.Duration) func() {
+	return worker.RunPeriodically(interval, func(ctx context.Context) error {
+		count, err := roleManagementService.DeleteExpiredRoles(ctx)
+		if err == nil && count > 0 {
+			log.Info(ctx, map[string]interface{}{
+				"count": count,
+			}, "expired role assignments deleted")
+		}
+		return err
+	}, "delete the expired role assignments")
+}
@@ -3,15 +3,19 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/fabric8-services/fabric8-auth/application/service/base"
 	servicecontext "github.com/fabric8-services/fabric8-auth/application/service/context"
 	"github.com/fabric8-services/fabric8-auth/authorization"
+	"github.com/fabric8-services/fabric8-auth/authorization/permission/cache"
 	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
 	"github.com/fabric8-services/fabric8-auth/authorization/role"
 	rolerepo "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
+	tokenrepo "github.com/fabric8-services/fabric8-auth/authorization/token/repository"
 	"github.com/fabric8-services/fabric8-auth/errors"
 	"github.com/fabric8-services/fabric8-auth/log"
+	"github.com/fabric8-services/fabric8-auth/notification"
 
 	"github.com/satori/go.uuid"
 )
@@ -67,14 +71,38 @@ func (s *roleManagementServiceImpl) ListAvailableRolesByResourceType(ctx context
 // which we want to assign the role to.
 // If appendToExistingRoles == true then the new roles for these identities will be appended to the existing roles.
 // If appendToExistingRoles == false then the new roles will replace the existing ones (the existing ones will be deleted).
+// roleExpiries optionally maps role names to the time at which the assignments of these roles expire; roles without an
+// entry are assigned permanently.
+// Each identity must already have a role for the resource, otherwise the invitation workflow should be used instead.
 // IMPORTANT: This is a transactional method, which manages its own transaction/s internally
-func (s *roleManagementServiceImpl) Assign(ctx context.Context, assignedBy uuid.UUID, roleAssignments map[string][]uuid.UUID, resourceID string, appendToExistingRoles bool) error {
+func (s *roleManagementServiceImpl) Assign(ctx context.Context, assignedBy uuid.UUID, roleAssignments map[string][]uuid.UUID, roleExpiries map[string]time.Time, resourceID string, appendToExistingRoles bool) error {
+	return s.assign(ctx, assignedBy, roleAssignments, roleExpiries, resourceID, appendToExistingRoles, false)
+}
+
+// AssignApprovedAccess assigns the identity whose access request for the resource has been approved with the given
+// role, in addition to its existing roles. Unlike Assign, the identity does not need to have a role for the resource
+// already. The approving identity must be allowed to manage the roles of the resource.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *roleManagementServiceImpl) AssignApprovedAccess(ctx context.Context, approvedBy uuid.UUID, identityID uuid.UUID, roleName string, resourceID string) error {
+	return s.assign(ctx, approvedBy, map[string][]uuid.UUID{roleName: {identityID}}, nil, resourceID, true, true)
+}
+
+// assign assigns the roles as described by Assign. If allowNewAssignees == true then the identities do not need to have
+// a role for the resource already.
+func (s *roleManagementServiceImpl) assign(ctx context.Context, assignedBy uuid.UUID, roleAssignments map[string][]uuid.UUID, roleExpiries map[string]time.Time, resourceID string, appendToExistingRoles bool, allowNewAssignees bool) error {
 	// Lookup the resourceID and ensure the resource is valid
 	rt, err := s.Repositories().ResourceRepository().Load(ctx, resourceID)
 	if err != nil {
 		return err
 	}
 
+	now := time.Now()
+	for roleName, expiresAt := range roleExpiries {
+		if !expiresAt.After(now) {
+			return errors.NewBadParameterErrorFromString("expires_at", expiresAt, fmt.Sprintf("the assignment of role %s must expire in the future", roleName))
+		}
+	}
+
 	// check if the current user token belongs to a user who has the necessary privileges
 	// for assigning roles to other users.
 	permissionService := s.Services().PermissionService()
@@ -84,8 +112,9 @@ func (s *roleManagementServiceImpl) Assign(ctx context.Context, assignedBy uuid.
 	}
 
 	// Valid all the roles and user identity IDs, and ensure each user has been previously assigned
-	// privileges for the resource, otherwise the invitation workflow should be used instead
+	// privileges for the resource, unless new assignees are allowed
 	assignments := make(map[uuid.UUID][]uuid.UUID)
+	expiries := make(map[uuid.UUID]time.Time)
 
 	var existingRoleIDs []uuid.UUID
 
@@ -101,6 +130,9 @@ func (s *roleManagementServiceImpl) Assign(ctx context.Context, assignedBy uuid.
 			roleID = roleRef.RoleID
 			roleIDByNameCache[roleName] = roleID
 		}
+		if expiresAt, found := roleExpiries[roleName]; found {
+			expiries[roleID] = expiresAt
+		}
 
 		for _, identityIDAsUUID := range identityIDs {
 			if found, _ := checkedIdentityIDs[identityIDAsUUID]; !found { // Don't check the same identity multiple times
@@ -112,7 +144,7 @@ func (s *roleManagementServiceImpl) Assign(ctx context.Context, assignedBy uuid.
 					}, "error looking up existing assignments")
 					return err
 				}
-				if len(assignedRoles) == 0 {
+				if len(assignedRoles) == 0 && !allowNewAssignees {
 					log.Error(ctx, map[string]interface{}{
 						"resource_id": resourceID,
 						"identity_id": identityIDAsUUID,
@@ -148,11 +180,16 @@ func (s *roleManagementServiceImpl) Assign(ctx context.Context, assignedBy uuid.
 		}
 
 		for roleID, ids := range assignments {
+			var expiresAt *time.Time
+			if e, found := expiries[roleID]; found {
+				expiresAt = &e
+			}
 			for _, identityIDAsUUID := range ids {
 				ir := rolerepo.IdentityRole{
 					ResourceID: resourceID,
 					IdentityID: identityIDAsUUID,
 					RoleID:     roleID,
+					ExpiresAt:  expiresAt,
 				}
 
 				err = s.Repositories().IdentityRoleRepository().Create(ctx, &ir)
@@ -168,8 +205,10 @@ func (s *roleManagementServiceImpl) Assign(ctx context.Context, assignedBy uuid.
 			}
 		}
 
-		return nil
+		// The permissions embedded in the RPTs issued for the resource are now out of date
+		return s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, resourceID, tokenrepo.TokenStatusStale)
 	})
+	cache.Invalidate()
 
 	return err
 }
@@ -192,8 +231,13 @@ func (s *roleManagementServiceImpl) ForceAssign(ctx context.Context, assignedTo
 			RoleID:     role.RoleID,
 		}
 
-		return s.Repositories().IdentityRoleRepository().Create(ctx, &ir)
+		err = s.Repositories().IdentityRoleRepository().Create(ctx, &ir)
+		if err != nil {
+			return err
+		}
+		return s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, res.ResourceID, tokenrepo.TokenStatusStale)
 	})
+	cache.Invalidate()
 
 	return err
 }
@@ -221,8 +265,128 @@ func (s *roleManagementServiceImpl) RevokeResourceRoles(ctx context.Context, cur
 				return err
 			}
 		}
-		return nil
+		return s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, resourceID, tokenrepo.TokenStatusStale)
 	})
+	cache.Invalidate()
 
 	return err
 }
+
+// RevokeResourceRole revokes the role with the specified name for the resource from the specified identities, leaving any
+// other roles they have for the resource in place. The last administrator of a resource cannot be revoked.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *roleManagementServiceImpl) RevokeResourceRole(ctx context.Context, currentIdentity uuid.UUID, identities []uuid.UUID, resourceID string, roleName string) error {
+	// Lookup the resourceID and ensure the resource is valid
+	rt, err := s.Repositories().ResourceRepository().Load(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+
+	// check if the current user token belongs to a user who has the necessary privileges
+	// for managing roles.
+	err = s.Services().PermissionService().RequireScope(ctx, currentIdentity, resourceID, authorization.ScopeForManagingRolesInResourceType(rt.ResourceType.Name))
+	if err != nil {
+		return err
+	}
+
+	r, err := s.Repositories().RoleRepository().Lookup(ctx, roleName, rt.ResourceType.Name)
+	if err != nil {
+		return err
+	}
+
+	err = s.ExecuteInTransaction(func() error {
+		for _, identityID := range identities {
+			err := s.Repositories().IdentityRoleRepository().DeleteForIdentityResourceAndRole(ctx, resourceID, identityID, r.RoleID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if roleName == authorization.AdminRoleForResourceType(rt.ResourceType.Name) {
+			admins, err := s.Repositories().IdentityRoleRepository().FindIdentityRolesByResourceAndRoleName(ctx, resourceID, roleName, false)
+			if err != nil {
+				return err
+			}
+			if len(admins) == 0 {
+				log.Error(ctx, map[string]interface{}{
+					"resource_id": resourceID,
+					"role_name":   roleName,
+				}, "cannot revoke the role of the last administrator of the resource")
+				return errors.NewDataConflictError(fmt.Sprintf("cannot revoke the %s role from the last administrator of resource %s", roleName, resourceID))
+			}
+		}
+
+		return s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, resourceID, tokenrepo.TokenStatusStale)
+	})
+	cache.Invalidate()
+
+	return err
+}
+
+// DeleteExpiredRoles deletes all the role assignments which have expired, and notifies the users who have lost these roles.
+// The number of deleted role assignments is returned. The role assignments being deleted by another instance of the service
+// are skipped, so that each expired role assignment is only deleted and notified once.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *roleManagementServiceImpl) DeleteExpiredRoles(ctx context.Context) (int, error) {
+	var expired []rolerepo.IdentityRole
+	err := s.ExecuteInTransaction(func() error {
+		var err error
+		expired, err = s.Repositories().IdentityRoleRepository().FindExpired(ctx, time.Now())
+		if err != nil {
+			return err
+		}
+
+		staleResources := make(map[string]bool)
+		for _, ir := range expired {
+			err = s.Repositories().IdentityRoleRepository().Delete(ctx, ir.IdentityRoleID)
+			if err != nil {
+				return err
+			}
+			staleResources[ir.ResourceID] = true
+		}
+
+		for resourceID := range staleResources {
+			err = s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, resourceID, tokenrepo.TokenStatusStale)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+	cache.Invalidate()
+
+	var messages []notification.Message
+	for _, ir := range expired {
+		log.Info(ctx, map[string]interface{}{
+			"identity_role_id": ir.IdentityRoleID,
+			"identity_id":      ir.IdentityID,
+			"resource_id":      ir.ResourceID,
+			"role_id":          ir.RoleID,
+			"expires_at":       ir.ExpiresAt,
+		}, "expired role assignment deleted")
+
+		if ir.Identity.UserID.Valid {
+			messages = append(messages, notification.NewRoleAssignmentExpiredEmail(ir.Identity.UserID.UUID.String(),
+				ir.ResourceID,
+				ir.Resource.Name,
+				ir.Resource.ResourceType.Name,
+				ir.Role.Name))
+		}
+	}
+	if len(messages) > 0 {
+		// The expired roles have been deleted already, so a failure to notify the users is not reported to the caller
+		err = s.Services().NotificationService().SendMessagesAsync(ctx, messages)
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"err": err,
+			}, "unable to notify the users of their expired role assignments")
+		}
+	}
+	return len(expired), nil
+}
@@ -3,14 +3,17 @@ package service_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/fabric8-services/fabric8-auth/application/service"
+	"github.com/fabric8-services/fabric8-auth/application/service/factory"
 	"github.com/fabric8-services/fabric8-auth/authorization"
 	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
 	resourcetype "github.com/fabric8-services/fabric8-auth/authorization/resourcetype/repository"
 	"github.com/fabric8-services/fabric8-auth/authorization/role"
 	rolerepo "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
 	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/gormapplication"
 	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
 	testsupport "github.com/fabric8-services/fabric8-auth/test"
 
@@ -235,7 +238,7 @@ func (s *roleManagementServiceBlackboxTest) checkAssignRoleOK(appendToExistingRo
 	roleAssignments[authorization.SpaceAdminRole] = usersToBeAssignedAsAdmin
 	roleAssignments[authorization.SpaceContributorRole] = usersToBeAssignedAsContributor
 
-	err := s.repo.Assign(context.Background(), adminUser.Identity().ID, roleAssignments, newSpace.SpaceID(), appendToExistingRoles)
+	err := s.repo.Assign(context.Background(), adminUser.Identity().ID, roleAssignments, nil, newSpace.SpaceID(), appendToExistingRoles)
 	require.NoError(s.T(), err)
 
 	s.addNoisyAssignments()
@@ -288,7 +291,7 @@ func (s *roleManagementServiceBlackboxTest) TestAssignRoleWithLackOfPermissionsF
 	roleAssignments := make(map[string][]uuid.UUID)
 	roleAssignments[authorization.SpaceAdminRole] = []uuid.UUID{userToBeAssigned.Identity().ID}
 
-	err := s.repo.Assign(context.Background(), viewer.Identity().ID, roleAssignments, newSpace.SpaceID(), false)
+	err := s.repo.Assign(context.Background(), viewer.Identity().ID, roleAssignments, nil, newSpace.SpaceID(), false)
 	testsupport.AssertError(s.T(), err, errors.ForbiddenError{}, "identity with ID %s does not have required scope manage for resource %s", viewer.Identity().ID.String(), newSpace.SpaceID())
 }
 
@@ -303,7 +306,7 @@ func (s *roleManagementServiceBlackboxTest) TestAssignRoleAlreadyExists() {
 	roleAssignments[authorization.SpaceAdminRole] = []uuid.UUID{userToBeAssigned.Identity().ID}
 
 	// lets try to add the same role again
-	err := s.repo.Assign(context.Background(), spaceAdmin.Identity().ID, roleAssignments, newSpace.SpaceID(), false)
+	err := s.repo.Assign(context.Background(), spaceAdmin.Identity().ID, roleAssignments, nil, newSpace.SpaceID(), false)
 	require.Error(s.T(), err)
 	require.IsType(s.T(), errors.DataConflictError{}, errs.Cause(err))
 }
@@ -315,7 +318,7 @@ func (s *roleManagementServiceBlackboxTest) TestAssignRoleResourceNotFound() {
 	roleAssignments := make(map[string][]uuid.UUID)
 	roleAssignments[authorization.SpaceContributorRole] = userToBeAdded
 
-	err := s.repo.Assign(context.Background(), identityID, roleAssignments, uuid.NewV4().String(), false)
+	err := s.repo.Assign(context.Background(), identityID, roleAssignments, nil, uuid.NewV4().String(), false)
 	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
 }
 
@@ -327,7 +330,7 @@ func (s *roleManagementServiceBlackboxTest) TestAssignRoleWithRoleNotFound() {
 	roleAssignments := make(map[string][]uuid.UUID)
 	roleAssignments[uuid.NewV4().String()] = userToBeAdded
 
-	err := s.repo.Assign(context.Background(), adminUser.Identity().ID, roleAssignments, newSpace.SpaceID(), false)
+	err := s.repo.Assign(context.Background(), adminUser.Identity().ID, roleAssignments, nil, newSpace.SpaceID(), false)
 	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
 }
 
@@ -339,7 +342,7 @@ func (s *roleManagementServiceBlackboxTest) TestAssignRoleWithIdentityNotFound()
 	roleAssignments := make(map[string][]uuid.UUID)
 	roleAssignments[authorization.SpaceAdminRole] = userToBeAdded
 
-	err := s.repo.Assign(context.Background(), adminUser.Identity().ID, roleAssignments, newSpace.SpaceID(), false)
+	err := s.repo.Assign(context.Background(), adminUser.Identity().ID, roleAssignments, nil, newSpace.SpaceID(), false)
 	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
 }
 
@@ -495,3 +498,117 @@ func validateAssignee(t *testing.T, amongUsers []uuid.UUID, resourceID string, r
 		require.True(t, foundUser)
 	}
 }
+
+func (s *roleManagementServiceBlackboxTest) TestRevokeResourceRoleOK() {
+	admin := s.Graph.CreateUser()
+	user := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(admin).AddViewer(user).AddContributor(user)
+
+	err := s.repo.RevokeResourceRole(s.Ctx, admin.IdentityID(), []uuid.UUID{user.IdentityID()}, space.SpaceID(), authorization.SpaceContributorRole)
+	require.NoError(s.T(), err)
+
+	identityRoles, err := s.Application.IdentityRoleRepository().FindIdentityRolesByIdentityAndResource(s.Ctx, space.SpaceID(), user.IdentityID())
+	require.NoError(s.T(), err)
+	require.Len(s.T(), identityRoles, 1)
+
+	// Unknown role
+	err = s.repo.RevokeResourceRole(s.Ctx, admin.IdentityID(), []uuid.UUID{user.IdentityID()}, space.SpaceID(), "unknown")
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+
+	// Not allowed to manage roles
+	err = s.repo.RevokeResourceRole(s.Ctx, user.IdentityID(), []uuid.UUID{admin.IdentityID()}, space.SpaceID(), authorization.SpaceAdminRole)
+	testsupport.AssertError(s.T(), err, errors.ForbiddenError{}, "identity with ID %s does not have required scope manage for resource %s", user.IdentityID(), space.SpaceID())
+}
+
+func (s *roleManagementServiceBlackboxTest) TestRevokeResourceRoleOfLastAdminFails() {
+	admin := s.Graph.CreateUser()
+	otherAdmin := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(admin).AddAdmin(otherAdmin)
+
+	// Revoking both admins at once is not allowed
+	err := s.repo.RevokeResourceRole(s.Ctx, admin.IdentityID(), []uuid.UUID{admin.IdentityID(), otherAdmin.IdentityID()}, space.SpaceID(), authorization.SpaceAdminRole)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.DataConflictError{}, errs.Cause(err))
+
+	admins, err := s.Application.IdentityRoleRepository().FindIdentityRolesByResourceAndRoleName(s.Ctx, space.SpaceID(), authorization.SpaceAdminRole, false)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), admins, 2)
+
+	err = s.repo.RevokeResourceRole(s.Ctx, admin.IdentityID(), []uuid.UUID{otherAdmin.IdentityID()}, space.SpaceID(), authorization.SpaceAdminRole)
+	require.NoError(s.T(), err)
+
+	err = s.repo.RevokeResourceRole(s.Ctx, admin.IdentityID(), []uuid.UUID{admin.IdentityID()}, space.SpaceID(), authorization.SpaceAdminRole)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.DataConflictError{}, errs.Cause(err))
+}
+
+func (s *roleManagementServiceBlackboxTest) TestAssignRoleWithExpiryOK() {
+	spaceAdmin := s.Graph.CreateUser()
+	user := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(spaceAdmin).AddViewer(user)
+
+	roleAssignments := map[string][]uuid.UUID{authorization.SpaceContributorRole: {user.IdentityID()}}
+	roleExpiries := map[string]time.Time{authorization.SpaceContributorRole: time.Now().Add(time.Hour)}
+	err := s.repo.Assign(s.Ctx, spaceAdmin.IdentityID(), roleAssignments, roleExpiries, space.SpaceID(), true)
+	require.NoError(s.T(), err)
+
+	contributors, err := s.Application.IdentityRoleRepository().FindIdentityRolesByResourceAndRoleName(s.Ctx, space.SpaceID(), authorization.SpaceContributorRole, false)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), contributors, 1)
+	require.NotNil(s.T(), contributors[0].ExpiresAt)
+	hasScope, err := s.Application.PermissionService().HasScope(s.Ctx, user.IdentityID(), space.SpaceID(), "contribute")
+	require.NoError(s.T(), err)
+	require.True(s.T(), hasScope)
+
+	// Once expired, the role assignment no longer grants any permission, even before it is deleted
+	err = s.DB.Table("identity_role").Where("identity_role_id = ?", contributors[0].IdentityRoleID).Update("expires_at", time.Now().Add(-time.Minute)).Error
+	require.NoError(s.T(), err)
+	hasScope, err = s.Application.PermissionService().HasScope(s.Ctx, user.IdentityID(), space.SpaceID(), "contribute")
+	require.NoError(s.T(), err)
+	require.False(s.T(), hasScope)
+	// nor is it listed along with the roles of the resource
+	contributors, err = s.Application.IdentityRoleRepository().FindIdentityRolesByResourceAndRoleName(s.Ctx, space.SpaceID(), authorization.SpaceContributorRole, false)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), contributors)
+	identityRoles, err := s.Application.IdentityRoleRepository().FindIdentityRolesByResource(s.Ctx, space.SpaceID(), false)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), identityRoles, 2)
+
+	notifications := &testsupport.RecordingNotificationService{}
+	application := gormapplication.NewGormDB(s.DB, s.Configuration, factory.WithNotificationService(notifications))
+	count, err := application.RoleManagementService().DeleteExpiredRoles(s.Ctx)
+	require.NoError(s.T(), err)
+	require.True(s.T(), count >= 1)
+
+	contributors, err = s.Application.IdentityRoleRepository().FindIdentityRolesByResourceAndRoleName(s.Ctx, space.SpaceID(), authorization.SpaceContributorRole, false)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), contributors)
+	// The permanent role assignments are not affected
+	viewers, err := s.Application.IdentityRoleRepository().FindIdentityRolesByResourceAndRoleName(s.Ctx, space.SpaceID(), authorization.SpaceViewerRole, false)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), viewers, 1)
+
+	var found bool
+	for _, msg := range notifications.Messages() {
+		if msg.UserID != nil && *msg.UserID == user.Identity().UserID.UUID.String() {
+			found = true
+			assert.Equal(s.T(), "role.assignment.expired", msg.MessageType)
+			assert.Equal(s.T(), space.SpaceID(), msg.TargetID)
+			assert.Equal(s.T(), authorization.SpaceContributorRole, msg.Custom["roleName"])
+		}
+	}
+	assert.True(s.T(), found)
+}
+
+func (s *roleManagementServiceBlackboxTest) TestAssignRoleWithPastExpiryFails() {
+	spaceAdmin := s.Graph.CreateUser()
+	user := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(spaceAdmin).AddViewer(user)
+
+	roleAssignments := map[string][]uuid.UUID{authorization.SpaceContributorRole: {user.IdentityID()}}
+	roleExpiries := map[string]time.Time{authorization.SpaceContributorRole: time.Now().Add(-time.Hour)}
+	err := s.repo.Assign(s.Ctx, spaceAdmin.IdentityID(), roleAssignments, roleExpiries, space.SpaceID(), true)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fabric8-services/fabric8-auth/application/service/base"
+	servicecontext "github.com/fabric8-services/fabric8-auth/application/service/context"
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	"github.com/fabric8-services/fabric8-auth/authorization/permission/cache"
+	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
+	"github.com/fabric8-services/fabric8-auth/authorization/role"
+	rolerepo "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
+	tokenrepo "github.com/fabric8-services/fabric8-auth/authorization/token/repository"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/log"
+
+	"github.com/satori/go.uuid"
+)
+
+// NewRoleMappingService creates a new service to manage role mappings and default role mappings
+func NewRoleMappingService(context servicecontext.ServiceContext) *roleMappingServiceImpl {
+	return &roleMappingServiceImpl{base.NewBaseService(context)}
+}
+
+// roleMappingServiceImpl implements the RoleMappingService to manage role mappings and default role mappings
+type roleMappingServiceImpl struct {
+	base.BaseService
+}
+
+// ListByResource lists the role mappings of the resource if the current user has permissions to view the roles
+func (s *roleMappingServiceImpl) ListByResource(ctx context.Context, currentIdentity uuid.UUID, resourceID string) ([]rolerepo.RoleMapping, error) {
+	res, err := s.Repositories().ResourceRepository().Load(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Services().PermissionService().RequireScope(ctx, currentIdentity, resourceID, authorization.ScopeForViewingRolesInResourceType(res.ResourceType.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	mappings, err := s.Repositories().RoleMappingRepository().FindForResource(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range mappings {
+		err = s.loadRoles(ctx, mappings[i].FromRoleID, &mappings[i].FromRole, mappings[i].ToRoleID, &mappings[i].ToRole)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mappings, nil
+}
+
+// Create creates a role mapping for the resource, if the current user has permissions to manage the roles of the resource.
+// Identities which have the "from" role for the resource (or one of its ancestors) will inherit the "to" role for the
+// resource and its descendants. The "from" role must belong to the type of the resource or of one of its ancestors, and
+// the "to" role to the type of the resource or of a possible descendant.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *roleMappingServiceImpl) Create(ctx context.Context, currentIdentity uuid.UUID, resourceID string, from role.RoleReference, to role.RoleReference) (*rolerepo.RoleMapping, error) {
+	res, err := s.Repositories().ResourceRepository().Load(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Services().PermissionService().RequireScope(ctx, currentIdentity, resourceID, authorization.ScopeForManagingRolesInResourceType(res.ResourceType.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	fromRole, toRole, err := s.lookupRoles(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	err = s.checkAncestorRoleType(ctx, res, fromRole)
+	if err != nil {
+		return nil, err
+	}
+	err = s.checkDescendantRoleType(ctx, res.ResourceType.Name, toRole)
+	if err != nil {
+		return nil, err
+	}
+
+	var mapping *rolerepo.RoleMapping
+	err = s.ExecuteInTransaction(func() error {
+		existing, err := s.Repositories().RoleMappingRepository().FindForResource(ctx, resourceID)
+		if err != nil {
+			return err
+		}
+		for _, m := range existing {
+			if m.FromRoleID == fromRole.RoleID && m.ToRoleID == toRole.RoleID {
+				return errors.NewDataConflictError(fmt.Sprintf("role mapping from %s to %s already exists for resource %s", from.RoleName, to.RoleName, resourceID))
+			}
+		}
+
+		mapping = &rolerepo.RoleMapping{
+			ResourceID: resourceID,
+			FromRoleID: fromRole.RoleID,
+			ToRoleID:   toRole.RoleID,
+		}
+		err = s.Repositories().RoleMappingRepository().Create(ctx, mapping)
+		if err != nil {
+			return err
+		}
+		return s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, resourceID, tokenrepo.TokenStatusStale)
+	})
+	if err != nil {
+		return nil, err
+	}
+	cache.Invalidate()
+
+	mapping.FromRole = *fromRole
+	mapping.ToRole = *toRole
+
+	log.Info(ctx, map[string]interface{}{
+		"resource_id":     resourceID,
+		"role_mapping_id": mapping.RoleMappingID,
+		"from_role_id":    fromRole.RoleID,
+		"to_role_id":      toRole.RoleID,
+		"identity_id":     currentIdentity,
+	}, "role mapping created")
+	return mapping, nil
+}
+
+// Delete deletes the specified role mapping of the resource, if the current user has permissions to manage the roles of the resource
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *roleMappingServiceImpl) Delete(ctx context.Context, currentIdentity uuid.UUID, resourceID string, roleMappingID uuid.UUID) error {
+	res, err := s.Repositories().ResourceRepository().Load(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+
+	err = s.Services().PermissionService().RequireScope(ctx, currentIdentity, resourceID, authorization.ScopeForManagingRolesInResourceType(res.ResourceType.Name))
+	if err != nil {
+		return err
+	}
+
+	err = s.ExecuteInTransaction(func() error {
+		mapping, err := s.Repositories().RoleMappingRepository().Load(ctx, roleMappingID)
+		if err != nil {
+			return err
+		}
+		if mapping.ResourceID != resourceID {
+			return errors.NewNotFoundError("role_mapping", roleMappingID.String())
+		}
+
+		err = s.Repositories().RoleMappingRepository().Delete(ctx, roleMappingID)
+		if err != nil {
+			return err
+		}
+		return s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, resourceID, tokenrepo.TokenStatusStale)
+	})
+	if err != nil {
+		return err
+	}
+	cache.Invalidate()
+	return nil
+}
+
+// ListDefaultByResourceType lists the default role mappings of the resource type with the specified ID
+func (s *roleMappingServiceImpl) ListDefaultByResourceType(ctx context.Context, resourceTypeID uuid.UUID) ([]rolerepo.DefaultRoleMapping, error) {
+	_, err := s.Repositories().ResourceTypeRepository().Load(ctx, resourceTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings, err := s.Repositories().DefaultRoleMappingRepository().FindForResourceType(ctx, resourceTypeID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range mappings {
+		err = s.loadRoles(ctx, mappings[i].FromRoleID, &mappings[i].FromRole, mappings[i].ToRoleID, &mappings[i].ToRole)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mappings, nil
+}
+
+// CreateDefault creates a default role mapping for the resource type with the specified ID. A role mapping with the same
+// roles is created for every resource of that type registered afterwards; existing resources are not affected.
+// The "from" role must belong to the resource type, and the "to" role to the resource type or to a possible descendant.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *roleMappingServiceImpl) CreateDefault(ctx context.Context, resourceTypeID uuid.UUID, from role.RoleReference, to role.RoleReference) (*rolerepo.DefaultRoleMapping, error) {
+	rt, err := s.Repositories().ResourceTypeRepository().Load(ctx, resourceTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	fromRole, toRole, err := s.lookupRoles(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if fromRole.ResourceTypeID != resourceTypeID {
+		return nil, errors.NewBadParameterErrorFromString("from_role", from.RoleName, fmt.Sprintf("the role must belong to resource type %s", rt.Name))
+	}
+	err = s.checkDescendantRoleType(ctx, rt.Name, toRole)
+	if err != nil {
+		return nil, err
+	}
+
+	var mapping *rolerepo.DefaultRoleMapping
+	err = s.ExecuteInTransaction(func() error {
+		existing, err := s.Repositories().DefaultRoleMappingRepository().FindForResourceType(ctx, resourceTypeID)
+		if err != nil {
+			return err
+		}
+		for _, m := range existing {
+			if m.FromRoleID == fromRole.RoleID && m.ToRoleID == toRole.RoleID {
+				return errors.NewDataConflictError(fmt.Sprintf("default role mapping from %s to %s already exists for resource type %s", from.RoleName, to.RoleName, rt.Name))
+			}
+		}
+
+		mapping = &rolerepo.DefaultRoleMapping{
+			ResourceTypeID: resourceTypeID,
+			FromRoleID:     fromRole.RoleID,
+			ToRoleID:       toRole.RoleID,
+		}
+		return s.Repositories().DefaultRoleMappingRepository().Create(ctx, mapping)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mapping.ResourceType = *rt
+	mapping.FromRole = *fromRole
+	mapping.ToRole = *toRole
+
+	log.Info(ctx, map[string]interface{}{
+		"resource_type_id":        resourceTypeID,
+		"default_role_mapping_id": mapping.DefaultRoleMappingID,
+		"from_role_id":            fromRole.RoleID,
+		"to_role_id":              toRole.RoleID,
+	}, "default role mapping created")
+	return mapping, nil
+}
+
+// DeleteDefault deletes the specified default role mapping of the resource type with the specified ID. Role mappings
+// previously created from this default role mapping are not affected.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *roleMappingServiceImpl) DeleteDefault(ctx context.Context, resourceTypeID uuid.UUID, defaultRoleMappingID uuid.UUID) error {
+	return s.ExecuteInTransaction(func() error {
+		mapping, err := s.Repositories().DefaultRoleMappingRepository().Load(ctx, defaultRoleMappingID)
+		if err != nil {
+			return err
+		}
+		if mapping.ResourceTypeID != resourceTypeID {
+			return errors.NewNotFoundError("default_role_mapping", defaultRoleMappingID.String())
+		}
+		return s.Repositories().DefaultRoleMappingRepository().Delete(ctx, defaultRoleMappingID)
+	})
+}
+
+// lookupRoles returns the roles referenced by the "from" and "to" references, or a bad parameter error if a role
+// does not belong to the referenced resource type
+func (s *roleMappingServiceImpl) lookupRoles(ctx context.Context, from role.RoleReference, to role.RoleReference) (*rolerepo.Role, *rolerepo.Role, error) {
+	fromRole, err := s.lookupRole(ctx, "from_role", from)
+	if err != nil {
+		return nil, nil, err
+	}
+	toRole, err := s.lookupRole(ctx, "to_role", to)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fromRole, toRole, nil
+}
+
+func (s *roleMappingServiceImpl) lookupRole(ctx context.Context, param string, ref role.RoleReference) (*rolerepo.Role, error) {
+	r, err := s.Repositories().RoleRepository().Lookup(ctx, ref.RoleName, ref.ResourceType)
+	if err != nil {
+		if notFound, _ := errors.IsNotFoundError(err); notFound {
+			return nil, errors.NewBadParameterErrorFromString(param, ref.RoleName, fmt.Sprintf("no role named %s exists for resource type %s", ref.RoleName, ref.ResourceType))
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+// checkAncestorRoleType returns a bad parameter error if the "from" role does not belong to the type of the resource or
+// of one of its ancestors
+func (s *roleMappingServiceImpl) checkAncestorRoleType(ctx context.Context, res *resource.Resource, fromRole *rolerepo.Role) error {
+	for r := res; ; {
+		if r.ResourceTypeID == fromRole.ResourceTypeID {
+			return nil
+		}
+		if r.ParentResourceID == nil {
+			break
+		}
+		parent, err := s.Repositories().ResourceRepository().Load(ctx, *r.ParentResourceID)
+		if err != nil {
+			return err
+		}
+		r = parent
+	}
+	return errors.NewBadParameterErrorFromString("from_role", fromRole.Name, fmt.Sprintf("the role must belong to the type of resource %s or of one of its ancestors", res.ResourceID))
+}
+
+// checkDescendantRoleType returns a bad parameter error if the "to" role does not belong to the resource type with the
+// given name or to a possible descendant type
+func (s *roleMappingServiceImpl) checkDescendantRoleType(ctx context.Context, resourceTypeName string, toRole *rolerepo.Role) error {
+	if toRole.ResourceType.Name == resourceTypeName {
+		return nil
+	}
+	resourceTypes, err := s.Repositories().ResourceTypeRepository().List(ctx)
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(resourceTypes))
+	for i, rt := range resourceTypes {
+		names[i] = rt.Name
+	}
+	if !authorization.CanHaveDescendantOfType(resourceTypeName, toRole.ResourceType.Name, names) {
+		return errors.NewBadParameterErrorFromString("to_role", toRole.Name, fmt.Sprintf("the role must belong to resource type %s or to a possible descendant", resourceTypeName))
+	}
+	return nil
+}
+
+// loadRoles loads the "from" and "to" roles of a mapping, along with their resource types
+func (s *roleMappingServiceImpl) loadRoles(ctx context.Context, fromRoleID uuid.UUID, fromRole *rolerepo.Role, toRoleID uuid.UUID, toRole *rolerepo.Role) error {
+	r, err := s.Repositories().RoleRepository().Load(ctx, fromRoleID)
+	if err != nil {
+		return err
+	}
+	*fromRole = *r
+	r, err = s.Repositories().RoleRepository().Load(ctx, toRoleID)
+	if err != nil {
+		return err
+	}
+	*toRole = *r
+	return nil
+}
@@ -0,0 +1,199 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	"github.com/fabric8-services/fabric8-auth/authorization/role"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
+	testsupport "github.com/fabric8-services/fabric8-auth/test"
+
+	errs "github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type roleMappingServiceBlackboxTest struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunRoleMappingServiceBlackboxTest(t *testing.T) {
+	suite.Run(t, &roleMappingServiceBlackboxTest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *roleMappingServiceBlackboxTest) TestCreateRoleMappingGrantsMappedRole() {
+	// The organization viewers only inherit the viewer role for the spaces of the organization
+	orgViewer := s.Graph.CreateUser()
+	spaceAdmin := s.Graph.CreateUser()
+	org := s.Graph.CreateOrganization().AddViewer(orgViewer)
+	space := s.Graph.CreateSpace(org).AddAdmin(spaceAdmin)
+
+	from := role.RoleReference{RoleName: authorization.OrganizationViewerRole, ResourceType: authorization.IdentityResourceTypeOrganization}
+	to := role.RoleReference{RoleName: authorization.SpaceContributorRole, ResourceType: authorization.ResourceTypeSpace}
+
+	hasScope, err := s.Application.PermissionService().HasScope(s.Ctx, orgViewer.IdentityID(), space.SpaceID(), "contribute")
+	require.NoError(s.T(), err)
+	require.False(s.T(), hasScope)
+
+	// The organization viewer may not manage the roles of the space
+	_, err = s.Application.RoleMappingService().Create(s.Ctx, orgViewer.IdentityID(), space.SpaceID(), from, to)
+	testsupport.AssertError(s.T(), err, errors.ForbiddenError{}, "identity with ID %s does not have required scope manage for resource %s", orgViewer.IdentityID().String(), space.SpaceID())
+
+	mapping, err := s.Application.RoleMappingService().Create(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID(), from, to)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), space.SpaceID(), mapping.ResourceID)
+	assert.Equal(s.T(), authorization.OrganizationViewerRole, mapping.FromRole.Name)
+	assert.Equal(s.T(), authorization.ResourceTypeSpace, mapping.ToRole.ResourceType.Name)
+
+	hasScope, err = s.Application.PermissionService().HasScope(s.Ctx, orgViewer.IdentityID(), space.SpaceID(), "contribute")
+	require.NoError(s.T(), err)
+	require.True(s.T(), hasScope)
+
+	// The same mapping cannot be created twice
+	_, err = s.Application.RoleMappingService().Create(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID(), from, to)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.DataConflictError{}, errs.Cause(err))
+
+	mappings, err := s.Application.RoleMappingService().ListByResource(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID())
+	require.NoError(s.T(), err)
+	require.Len(s.T(), mappings, 1)
+	assert.Equal(s.T(), mapping.RoleMappingID, mappings[0].RoleMappingID)
+	assert.Equal(s.T(), authorization.IdentityResourceTypeOrganization, mappings[0].FromRole.ResourceType.Name)
+	assert.Equal(s.T(), authorization.SpaceContributorRole, mappings[0].ToRole.Name)
+
+	err = s.Application.RoleMappingService().Delete(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID(), mapping.RoleMappingID)
+	require.NoError(s.T(), err)
+
+	hasScope, err = s.Application.PermissionService().HasScope(s.Ctx, orgViewer.IdentityID(), space.SpaceID(), "contribute")
+	require.NoError(s.T(), err)
+	require.False(s.T(), hasScope)
+
+	mappings, err = s.Application.RoleMappingService().ListByResource(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID())
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), mappings)
+}
+
+func (s *roleMappingServiceBlackboxTest) TestCreateRoleMappingWithInvalidRoleFails() {
+	spaceAdmin := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(spaceAdmin)
+
+	// The contributor role does not belong to the organization resource type
+	_, err := s.Application.RoleMappingService().Create(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID(),
+		role.RoleReference{RoleName: authorization.SpaceContributorRole, ResourceType: authorization.IdentityResourceTypeOrganization},
+		role.RoleReference{RoleName: authorization.SpaceAdminRole, ResourceType: authorization.ResourceTypeSpace})
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+
+	_, err = s.Application.RoleMappingService().Create(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID(),
+		role.RoleReference{RoleName: authorization.OrganizationAdminRole, ResourceType: authorization.IdentityResourceTypeOrganization},
+		role.RoleReference{RoleName: "unknown", ResourceType: authorization.ResourceTypeSpace})
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+}
+
+func (s *roleMappingServiceBlackboxTest) TestCreateRoleMappingWithRoleOfIncompatibleTypeFails() {
+	spaceAdmin := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace(s.Graph.CreateOrganization()).AddAdmin(spaceAdmin)
+
+	s.T().Run("from role of a descendant type", func(t *testing.T) {
+		// Teams belong to spaces, so they can not be the ancestors of a space
+		_, err := s.Application.RoleMappingService().Create(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID(),
+			role.RoleReference{RoleName: authorization.TeamAdminRole, ResourceType: authorization.IdentityResourceTypeTeam},
+			role.RoleReference{RoleName: authorization.SpaceContributorRole, ResourceType: authorization.ResourceTypeSpace})
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("to role of an ancestor type", func(t *testing.T) {
+		// Organizations can not be the descendants of a space
+		_, err := s.Application.RoleMappingService().Create(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID(),
+			role.RoleReference{RoleName: authorization.SpaceAdminRole, ResourceType: authorization.ResourceTypeSpace},
+			role.RoleReference{RoleName: authorization.OrganizationViewerRole, ResourceType: authorization.IdentityResourceTypeOrganization})
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("to role of a descendant type", func(t *testing.T) {
+		_, err := s.Application.RoleMappingService().Create(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID(),
+			role.RoleReference{RoleName: authorization.SpaceAdminRole, ResourceType: authorization.ResourceTypeSpace},
+			role.RoleReference{RoleName: authorization.TeamAdminRole, ResourceType: authorization.IdentityResourceTypeTeam})
+		require.NoError(t, err)
+	})
+}
+
+func (s *roleMappingServiceBlackboxTest) TestCreateDefaultRoleMappingWithRoleOfIncompatibleTypeFails() {
+	spaceType, err := s.Application.ResourceTypeRepository().Lookup(s.Ctx, authorization.ResourceTypeSpace)
+	require.NoError(s.T(), err)
+
+	s.T().Run("from role of another type", func(t *testing.T) {
+		_, err := s.Application.RoleMappingService().CreateDefault(s.Ctx, spaceType.ResourceTypeID,
+			role.RoleReference{RoleName: authorization.OrganizationAdminRole, ResourceType: authorization.IdentityResourceTypeOrganization},
+			role.RoleReference{RoleName: authorization.SpaceContributorRole, ResourceType: authorization.ResourceTypeSpace})
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("to role of an ancestor type", func(t *testing.T) {
+		_, err := s.Application.RoleMappingService().CreateDefault(s.Ctx, spaceType.ResourceTypeID,
+			role.RoleReference{RoleName: authorization.SpaceAdminRole, ResourceType: authorization.ResourceTypeSpace},
+			role.RoleReference{RoleName: authorization.OrganizationViewerRole, ResourceType: authorization.IdentityResourceTypeOrganization})
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+}
+
+func (s *roleMappingServiceBlackboxTest) TestDeleteRoleMappingOfOtherResourceFails() {
+	spaceAdmin := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(spaceAdmin)
+	other := s.Graph.CreateRoleMapping()
+
+	err := s.Application.RoleMappingService().Delete(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID(), other.RoleMapping().RoleMappingID)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *roleMappingServiceBlackboxTest) TestManageDefaultRoleMappings() {
+	rt := s.Graph.CreateResourceType()
+	fromRole := s.Graph.CreateRole(rt)
+	toRT := s.Graph.CreateResourceType()
+	toRole := s.Graph.CreateRole(toRT)
+	from := role.RoleReference{RoleName: fromRole.Role().Name, ResourceType: rt.ResourceType().Name}
+	to := role.RoleReference{RoleName: toRole.Role().Name, ResourceType: toRT.ResourceType().Name}
+
+	mapping, err := s.Application.RoleMappingService().CreateDefault(s.Ctx, rt.ResourceType().ResourceTypeID, from, to)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), rt.ResourceType().ResourceTypeID, mapping.ResourceTypeID)
+
+	_, err = s.Application.RoleMappingService().CreateDefault(s.Ctx, rt.ResourceType().ResourceTypeID, from, to)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.DataConflictError{}, errs.Cause(err))
+
+	// A resource registered afterwards gets the role mapping
+	res, err := s.Application.ResourceService().Register(s.Ctx, rt.ResourceType().Name, nil, nil)
+	require.NoError(s.T(), err)
+	resourceMappings, err := s.Application.RoleMappingRepository().FindForResource(s.Ctx, res.ResourceID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), resourceMappings, 1)
+	assert.Equal(s.T(), fromRole.Role().RoleID, resourceMappings[0].FromRoleID)
+	assert.Equal(s.T(), toRole.Role().RoleID, resourceMappings[0].ToRoleID)
+
+	mappings, err := s.Application.RoleMappingService().ListDefaultByResourceType(s.Ctx, rt.ResourceType().ResourceTypeID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), mappings, 1)
+	assert.Equal(s.T(), fromRole.Role().Name, mappings[0].FromRole.Name)
+	assert.Equal(s.T(), to.ResourceType, mappings[0].ToRole.ResourceType.Name)
+
+	err = s.Application.RoleMappingService().DeleteDefault(s.Ctx, uuid.NewV4(), mapping.DefaultRoleMappingID)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+
+	err = s.Application.RoleMappingService().DeleteDefault(s.Ctx, rt.ResourceType().ResourceTypeID, mapping.DefaultRoleMappingID)
+	require.NoError(s.T(), err)
+
+	mappings, err = s.Application.RoleMappingService().ListDefaultByResourceType(s.Ctx, rt.ResourceType().ResourceTypeID)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), mappings)
+}
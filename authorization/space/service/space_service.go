@@ -24,12 +24,28 @@ func NewSpaceService(context servicecontext.ServiceContext) service.SpaceService
 
 // CreateSpace creates a new space. The specified spaceCreatorIdentityID is the user creating the space, and the spaceID is the identifier for the
 // space resource. The space creator will be assigned with Admin role in the space.
+// If an organizationID is specified then the space is owned by the organization, i.e. the space resource is registered as a child of the
+// organization resource, and the roles of the organization flow down to the space through the organization's role mappings. The space creator
+// must be allowed to manage the spaces of the organization.
 // IMPORTANT: This is a transactional method, which manages its own transaction/s internally
-// TODO support creating a space for organizations
-func (s *spaceService) CreateSpace(ctx context.Context, spaceCreatorIdentityID uuid.UUID, spaceID string) error {
+func (s *spaceService) CreateSpace(ctx context.Context, spaceCreatorIdentityID uuid.UUID, spaceID string, organizationID *uuid.UUID) error {
 
 	err := s.ExecuteInTransaction(func() error {
-		res, err := s.Services().ResourceService().Register(ctx, authorization.ResourceTypeSpace, &spaceID, nil)
+		var parentResourceID *string
+		if organizationID != nil {
+			org, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, *organizationID, authorization.IdentityResourceTypeOrganization)
+			if err != nil {
+				return err
+			}
+
+			err = s.Services().PermissionService().RequireScope(ctx, spaceCreatorIdentityID, org.ResourceID, authorization.ManageSpacesInOrganizationScope)
+			if err != nil {
+				return err
+			}
+			parentResourceID = &org.ResourceID
+		}
+
+		res, err := s.Services().ResourceService().Register(ctx, authorization.ResourceTypeSpace, &spaceID, parentResourceID)
 		if err != nil {
 			return err
 		}
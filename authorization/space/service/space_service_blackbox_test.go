@@ -8,6 +8,7 @@ import (
 	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
 	"github.com/fabric8-services/fabric8-auth/test"
 
+	errs "github.com/pkg/errors"
 	"github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -24,7 +25,7 @@ func TestRunSpaceServiceBlackBoxTest(t *testing.T) {
 
 func (s *spaceServiceBlackBoxTest) TestCreateByUnknownUserFails() {
 	id := uuid.NewV4()
-	err := s.Application.SpaceService().CreateSpace(s.Ctx, id, uuid.NewV4().String())
+	err := s.Application.SpaceService().CreateSpace(s.Ctx, id, uuid.NewV4().String(), nil)
 	test.AssertError(s.T(), err, errors.NotFoundError{}, "identity with id '%s' not found", id.String())
 }
 
@@ -33,7 +34,7 @@ func (s *spaceServiceBlackBoxTest) TestCreateOK() {
 	g := s.DBTestSuite.NewTestGraph()
 	creator := g.CreateUser()
 
-	err := s.Application.SpaceService().CreateSpace(s.Ctx, creator.Identity().ID, spaceID)
+	err := s.Application.SpaceService().CreateSpace(s.Ctx, creator.Identity().ID, spaceID, nil)
 	require.NoError(s.T(), err)
 
 	// Check if the corresponding authZ resource has been created
@@ -50,10 +51,62 @@ func (s *spaceServiceBlackBoxTest) TestCreateOK() {
 	assert.Equal(s.T(), authorization.SpaceAdminRole, assignedRoles[0].Role.Name)
 
 	// If we try to create another space with the same ID it should fail
-	err = s.Application.SpaceService().CreateSpace(s.Ctx, creator.Identity().ID, spaceID)
+	err = s.Application.SpaceService().CreateSpace(s.Ctx, creator.Identity().ID, spaceID, nil)
 	test.AssertError(s.T(), err, errors.DataConflictError{}, "resource with ID %s already exists", spaceID)
 }
 
+func (s *spaceServiceBlackBoxTest) TestCreateInOrganization() {
+	orgAdmin := s.Graph.CreateUser()
+	orgViewer := s.Graph.CreateUser()
+	org := s.Graph.CreateOrganization(orgAdmin).AddViewer(orgViewer)
+	otherOrgAdmin := s.Graph.CreateUser()
+	org.AddAdmin(otherOrgAdmin)
+	orgID := org.OrganizationID()
+
+	s.T().Run("ok", func(t *testing.T) {
+		spaceID := uuid.NewV4().String()
+		err := s.Application.SpaceService().CreateSpace(s.Ctx, orgAdmin.IdentityID(), spaceID, &orgID)
+		require.NoError(t, err)
+
+		// The space is owned by the organization
+		res, err := s.Application.ResourceRepository().Load(s.Ctx, spaceID)
+		require.NoError(t, err)
+		require.NotNil(t, res.ParentResourceID)
+		assert.Equal(t, org.ResourceID(), *res.ParentResourceID)
+
+		// The roles of the organization flow down to the space
+		hasScope, err := s.Application.PermissionService().HasScope(s.Ctx, otherOrgAdmin.IdentityID(), spaceID, authorization.DeleteSpaceScope)
+		require.NoError(t, err)
+		assert.True(t, hasScope)
+		hasScope, err = s.Application.PermissionService().HasScope(s.Ctx, orgViewer.IdentityID(), spaceID, authorization.ViewTeamsInSpaceScope)
+		require.NoError(t, err)
+		assert.True(t, hasScope)
+		hasScope, err = s.Application.PermissionService().HasScope(s.Ctx, orgViewer.IdentityID(), spaceID, authorization.DeleteSpaceScope)
+		require.NoError(t, err)
+		assert.False(t, hasScope)
+	})
+
+	s.T().Run("forbidden for organization viewer", func(t *testing.T) {
+		err := s.Application.SpaceService().CreateSpace(s.Ctx, orgViewer.IdentityID(), uuid.NewV4().String(), &orgID)
+		require.Error(t, err)
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+	})
+
+	s.T().Run("unknown organization", func(t *testing.T) {
+		unknownID := uuid.NewV4()
+		err := s.Application.SpaceService().CreateSpace(s.Ctx, orgAdmin.IdentityID(), uuid.NewV4().String(), &unknownID)
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+
+	s.T().Run("not an organization", func(t *testing.T) {
+		teamID := s.Graph.CreateTeam().TeamID()
+		err := s.Application.SpaceService().CreateSpace(s.Ctx, orgAdmin.IdentityID(), uuid.NewV4().String(), &teamID)
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+}
+
 func (s *spaceServiceBlackBoxTest) TestDeleteUnknownSpaceFails() {
 	g := s.DBTestSuite.NewTestGraph()
 	spaceID := uuid.NewV4().String()
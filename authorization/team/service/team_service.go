@@ -10,7 +10,10 @@ import (
 	"github.com/fabric8-services/fabric8-auth/application/service/base"
 	servicecontext "github.com/fabric8-services/fabric8-auth/application/service/context"
 	"github.com/fabric8-services/fabric8-auth/authorization"
+	"github.com/fabric8-services/fabric8-auth/authorization/permission/cache"
 	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
+	rolerepo "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
+	tokenrepo "github.com/fabric8-services/fabric8-auth/authorization/token/repository"
 	"github.com/fabric8-services/fabric8-auth/errors"
 	"github.com/fabric8-services/fabric8-auth/log"
 	"github.com/satori/go.uuid"
@@ -99,6 +102,21 @@ func (s *teamServiceImpl) CreateTeam(ctx context.Context, identityID uuid.UUID,
 
 		teamID = teamIdentity.ID
 
+		// Assign the team admin role to the creator of the team, so that they can manage the members of the team
+		adminRole, err := s.Repositories().RoleRepository().Lookup(ctx, authorization.TeamAdminRole, authorization.IdentityResourceTypeTeam)
+		if err != nil {
+			return err
+		}
+
+		err = s.Repositories().IdentityRoleRepository().Create(ctx, &rolerepo.IdentityRole{
+			ResourceID: res.ResourceID,
+			IdentityID: identityID,
+			RoleID:     adminRole.RoleID,
+		})
+		if err != nil {
+			return errors.NewInternalError(ctx, err)
+		}
+
 		log.Debug(ctx, map[string]interface{}{
 			"team_id": teamID.String(),
 		}, "team created")
@@ -170,3 +188,166 @@ func (s *teamServiceImpl) ListTeamsForIdentity(ctx context.Context, identityID u
 
 	return authorization.MergeAssociations(memberships, roles), nil
 }
+
+// ListMembers returns the identities which are direct members of the specified team, if the specified identity has
+// the necessary privileges to view the members of the team
+func (s *teamServiceImpl) ListMembers(ctx context.Context, identityID uuid.UUID, teamID uuid.UUID) ([]account.Identity, error) {
+	team, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, teamID, authorization.IdentityResourceTypeTeam)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Services().PermissionService().RequireScope(ctx, identityID, team.ResourceID, authorization.ViewTeamMembersScope)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Repositories().Identities().ListMembers(ctx, teamID)
+}
+
+// AddMembers adds the specified identities as direct members of the specified team, if the specified identity has
+// the necessary privileges to manage the members of the team
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *teamServiceImpl) AddMembers(ctx context.Context, identityID uuid.UUID, teamID uuid.UUID, memberIDs []uuid.UUID) error {
+	team, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, teamID, authorization.IdentityResourceTypeTeam)
+	if err != nil {
+		return err
+	}
+
+	err = s.Services().PermissionService().RequireScope(ctx, identityID, team.ResourceID, authorization.ManageTeamMembersScope)
+	if err != nil {
+		return err
+	}
+
+	err = s.ExecuteInTransaction(func() error {
+		members, err := s.Repositories().Identities().ListMembers(ctx, teamID)
+		if err != nil {
+			return err
+		}
+		existing := make(map[uuid.UUID]bool)
+		for _, member := range members {
+			existing[member.ID] = true
+		}
+
+		for _, memberID := range memberIDs {
+			if existing[memberID] {
+				return errors.NewDataConflictError(fmt.Sprintf("identity %s is already a member of team %s", memberID, teamID))
+			}
+			err = s.Repositories().Identities().AddMember(ctx, teamID, memberID)
+			if err != nil {
+				return err
+			}
+			existing[memberID] = true
+		}
+
+		// The members inherit the roles of the team, so the permissions embedded in the RPTs are now out of date
+		return s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, s.teamRolesResourceID(team), tokenrepo.TokenStatusStale)
+	})
+	cache.Invalidate()
+
+	return err
+}
+
+// RemoveMembers removes the specified identities from the direct members of the specified team, if the specified identity
+// has the necessary privileges to manage the members of the team
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *teamServiceImpl) RemoveMembers(ctx context.Context, identityID uuid.UUID, teamID uuid.UUID, memberIDs []uuid.UUID) error {
+	team, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, teamID, authorization.IdentityResourceTypeTeam)
+	if err != nil {
+		return err
+	}
+
+	err = s.Services().PermissionService().RequireScope(ctx, identityID, team.ResourceID, authorization.ManageTeamMembersScope)
+	if err != nil {
+		return err
+	}
+
+	err = s.ExecuteInTransaction(func() error {
+		for _, memberID := range memberIDs {
+			err := s.Repositories().Identities().RemoveMember(ctx, teamID, memberID)
+			if err != nil {
+				return err
+			}
+		}
+
+		// The former members no longer inherit the roles of the team, so the permissions embedded in the RPTs are now out of date
+		return s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, s.teamRolesResourceID(team), tokenrepo.TokenStatusStale)
+	})
+	cache.Invalidate()
+
+	return err
+}
+
+// UpdateTeam renames the specified team, if the specified identity has the necessary privileges to manage the teams of
+// the space of the team. The name of the team is held by its resource.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *teamServiceImpl) UpdateTeam(ctx context.Context, identityID uuid.UUID, teamID uuid.UUID, teamName string) error {
+	return s.ExecuteInTransaction(func() error {
+		team, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, teamID, authorization.IdentityResourceTypeTeam)
+		if err != nil {
+			return err
+		}
+
+		err = s.Services().PermissionService().RequireScope(ctx, identityID, s.teamRolesResourceID(team), authorization.ManageTeamsInSpaceScope)
+		if err != nil {
+			return err
+		}
+
+		team.Name = teamName
+		err = s.Repositories().ResourceRepository().Save(ctx, team)
+		if err != nil {
+			return err
+		}
+
+		log.Info(ctx, map[string]interface{}{
+			"team_id":     teamID,
+			"team_name":   teamName,
+			"identity_id": identityID,
+		}, "team renamed")
+		return nil
+	})
+}
+
+// DeleteTeam deletes the specified team, if the specified identity has the necessary privileges to manage the teams of
+// the space of the team. The memberships of the team, the roles assigned to it or for it, and the pending invitations
+// to join it are deleted along with the team.
+// IMPORTANT: This is a transactional method, which manages its own transaction/s internally
+func (s *teamServiceImpl) DeleteTeam(ctx context.Context, identityID uuid.UUID, teamID uuid.UUID) error {
+	return s.ExecuteInTransaction(func() error {
+		team, err := s.Repositories().ResourceRepository().LoadIdentityResource(ctx, teamID, authorization.IdentityResourceTypeTeam)
+		if err != nil {
+			return err
+		}
+
+		err = s.Services().PermissionService().RequireScope(ctx, identityID, s.teamRolesResourceID(team), authorization.ManageTeamsInSpaceScope)
+		if err != nil {
+			return err
+		}
+
+		// The members of the team lose the roles of the team, so the RPTs of the space are out of date too
+		err = s.Repositories().TokenRepository().SetStatusFlagForResource(ctx, s.teamRolesResourceID(team), tokenrepo.TokenStatusStale)
+		if err != nil {
+			return err
+		}
+
+		err = s.Services().ResourceService().Delete(ctx, team.ResourceID)
+		if err != nil {
+			return err
+		}
+
+		log.Info(ctx, map[string]interface{}{
+			"team_id":     teamID,
+			"identity_id": identityID,
+		}, "team deleted")
+		return nil
+	})
+}
+
+// teamRolesResourceID returns the ID of the resource for which the roles of the team are typically assigned, i.e.
+// the space of the team
+func (s *teamServiceImpl) teamRolesResourceID(team *resource.Resource) string {
+	if team.ParentResourceID != nil {
+		return *team.ParentResourceID
+	}
+	return team.ResourceID
+}
@@ -3,9 +3,12 @@ package service_test
 import (
 	"testing"
 
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	rolerepo "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
 	"github.com/fabric8-services/fabric8-auth/errors"
 	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
 
+	errs "github.com/pkg/errors"
 	"github.com/satori/go.uuid"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -289,3 +292,140 @@ func (s *teamServiceBlackBoxTest) TestListTeamsForIdentity() {
 	require.NoError(s.T(), err)
 	require.Len(s.T(), teams, 0)
 }
+
+func (s *teamServiceBlackBoxTest) TestManageTeamMembers() {
+	spaceAdmin := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(spaceAdmin)
+	teamID, err := s.Application.TeamService().CreateTeam(s.Ctx, spaceAdmin.IdentityID(), space.SpaceID(), "TestTeam"+uuid.NewV4().String())
+	require.NoError(s.T(), err)
+
+	member1 := s.Graph.CreateUser()
+	member2 := s.Graph.CreateUser()
+
+	// The creator of the team may manage its members
+	err = s.Application.TeamService().AddMembers(s.Ctx, spaceAdmin.IdentityID(), *teamID, []uuid.UUID{member1.IdentityID(), member2.IdentityID()})
+	require.NoError(s.T(), err)
+
+	members, err := s.Application.TeamService().ListMembers(s.Ctx, spaceAdmin.IdentityID(), *teamID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), members, 2)
+
+	// The same identity cannot be added twice
+	err = s.Application.TeamService().AddMembers(s.Ctx, spaceAdmin.IdentityID(), *teamID, []uuid.UUID{member1.IdentityID()})
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.DataConflictError{}, errs.Cause(err))
+
+	err = s.Application.TeamService().RemoveMembers(s.Ctx, spaceAdmin.IdentityID(), *teamID, []uuid.UUID{member1.IdentityID()})
+	require.NoError(s.T(), err)
+
+	members, err = s.Application.TeamService().ListMembers(s.Ctx, spaceAdmin.IdentityID(), *teamID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), members, 1)
+	require.Equal(s.T(), member2.IdentityID(), members[0].ID)
+
+	// The identity is no longer a member
+	err = s.Application.TeamService().RemoveMembers(s.Ctx, spaceAdmin.IdentityID(), *teamID, []uuid.UUID{member1.IdentityID()})
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *teamServiceBlackBoxTest) TestManageTeamMembersFailsWithoutPermissions() {
+	team := s.Graph.CreateTeam()
+	viewer := s.Graph.CreateUser()
+	viewerRole, err := s.Application.RoleRepository().Lookup(s.Ctx, authorization.TeamViewerRole, authorization.IdentityResourceTypeTeam)
+	require.NoError(s.T(), err)
+	team.AssignRole(viewer.Identity(), viewerRole)
+	randomUser := s.Graph.CreateUser()
+
+	// A team viewer may list the members, but not manage them
+	_, err = s.Application.TeamService().ListMembers(s.Ctx, viewer.IdentityID(), team.TeamID())
+	require.NoError(s.T(), err)
+	err = s.Application.TeamService().AddMembers(s.Ctx, viewer.IdentityID(), team.TeamID(), []uuid.UUID{randomUser.IdentityID()})
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.ForbiddenError{}, errs.Cause(err))
+
+	_, err = s.Application.TeamService().ListMembers(s.Ctx, randomUser.IdentityID(), team.TeamID())
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.ForbiddenError{}, errs.Cause(err))
+	err = s.Application.TeamService().RemoveMembers(s.Ctx, randomUser.IdentityID(), team.TeamID(), []uuid.UUID{viewer.IdentityID()})
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.ForbiddenError{}, errs.Cause(err))
+}
+
+func (s *teamServiceBlackBoxTest) TestListMembersOfUnknownTeamFails() {
+	user := s.Graph.CreateUser()
+
+	_, err := s.Application.TeamService().ListMembers(s.Ctx, user.IdentityID(), uuid.NewV4())
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+
+	// A user is not a team
+	_, err = s.Application.TeamService().ListMembers(s.Ctx, user.IdentityID(), user.IdentityID())
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *teamServiceBlackBoxTest) TestUpdateTeam() {
+	spaceAdmin := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(spaceAdmin)
+	team := s.Graph.CreateTeam(space)
+
+	err := s.Application.TeamService().UpdateTeam(s.Ctx, spaceAdmin.IdentityID(), team.TeamID(), "renamed")
+	require.NoError(s.T(), err)
+
+	res, err := s.Application.ResourceRepository().Load(s.Ctx, team.ResourceID())
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "renamed", res.Name)
+
+	err = s.Application.TeamService().UpdateTeam(s.Ctx, s.Graph.CreateUser().IdentityID(), team.TeamID(), "forbidden")
+	require.IsType(s.T(), errors.ForbiddenError{}, errs.Cause(err))
+
+	err = s.Application.TeamService().UpdateTeam(s.Ctx, spaceAdmin.IdentityID(), uuid.NewV4(), "unknown")
+	require.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *teamServiceBlackBoxTest) TestDeleteTeam() {
+	spaceAdmin := s.Graph.CreateUser()
+	space := s.Graph.CreateSpace().AddAdmin(spaceAdmin)
+	team := s.Graph.CreateTeam(space)
+	member := s.Graph.CreateUser()
+	team.AddMember(member)
+	contributorRole, err := s.Application.RoleRepository().Lookup(s.Ctx, authorization.SpaceContributorRole, authorization.ResourceTypeSpace)
+	require.NoError(s.T(), err)
+	err = s.Application.IdentityRoleRepository().Create(s.Ctx, &rolerepo.IdentityRole{IdentityID: team.TeamID(), ResourceID: space.SpaceID(), RoleID: contributorRole.RoleID})
+	require.NoError(s.T(), err)
+	s.Graph.CreateInvitation(team, s.Graph.CreateUser())
+
+	hasScope, err := s.Application.PermissionService().HasScope(s.Ctx, member.IdentityID(), space.SpaceID(), "contribute")
+	require.NoError(s.T(), err)
+	require.True(s.T(), hasScope)
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		err := s.Application.TeamService().DeleteTeam(s.Ctx, member.IdentityID(), team.TeamID())
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+	})
+
+	s.T().Run("ok", func(t *testing.T) {
+		err := s.Application.TeamService().DeleteTeam(s.Ctx, spaceAdmin.IdentityID(), team.TeamID())
+		require.NoError(t, err)
+
+		_, err = s.Application.Identities().Load(s.Ctx, team.TeamID())
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+		invitations, err := s.Application.InvitationRepository().ListForIdentity(s.Ctx, team.TeamID())
+		require.NoError(t, err)
+		require.Empty(t, invitations)
+		roles, err := s.Application.IdentityRoleRepository().FindIdentityRolesByIdentityAndResource(s.Ctx, space.SpaceID(), team.TeamID())
+		require.NoError(t, err)
+		require.Empty(t, roles)
+
+		// the former member of the team does not inherit the roles of the team anymore
+		hasScope, err := s.Application.PermissionService().HasScope(s.Ctx, member.IdentityID(), space.SpaceID(), "contribute")
+		require.NoError(t, err)
+		require.False(t, hasScope)
+	})
+
+	s.T().Run("not found", func(t *testing.T) {
+		err := s.Application.TeamService().DeleteTeam(s.Ctx, spaceAdmin.IdentityID(), team.TeamID())
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+}
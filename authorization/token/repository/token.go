@@ -15,6 +15,21 @@ import (
 	"github.com/satori/go.uuid"
 )
 
+const (
+	// TokenTypeAccess is the token type used to record access tokens
+	TokenTypeAccess = "ACC"
+	// TokenTypeRefresh is the token type used to record refresh (and offline) tokens
+	TokenTypeRefresh = "REF"
+	// TokenTypePersonal is the token type used to record personal access tokens
+	TokenTypePersonal = "PAT"
+
+	// TokenStatusRevoked is the status flag of a token which has been explicitly revoked
+	TokenStatusRevoked = 1
+	// TokenStatusStale is the status flag of a token whose embedded permissions are out of date,
+	// because the roles for one of its resources have changed since it was issued
+	TokenStatusStale = 2
+)
+
 type Token struct {
 	gormsupport.Lifecycle
 
@@ -29,6 +44,12 @@ type Token struct {
 
 	// The timestamp when the token will expire
 	ExpiryTime time.Time
+
+	// The name given to a personal access token by its owner
+	Name *string
+
+	// The space-separated list of scopes to which a personal access token is restricted
+	Scopes *string
 }
 
 // TableName overrides the table name settings in Gorm to force a specific table name
@@ -63,6 +84,9 @@ type TokenRepository interface {
 	Save(ctx context.Context, token *Token) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	ListForIdentity(ctx context.Context, id uuid.UUID) ([]Token, error)
+	ListPersonalForIdentity(ctx context.Context, id uuid.UUID) ([]Token, error)
+	AddResource(ctx context.Context, tokenID uuid.UUID, resourceID string) error
+	SetStatusFlagForResource(ctx context.Context, resourceID string, status int) error
 }
 
 // CRUD Functions
@@ -112,13 +136,18 @@ func (m *GormTokenRepository) Create(ctx context.Context, token *Token) error {
 	}
 
 	// TODO read token expiry duration from configuration
-	token.ExpiryTime = time.Now().Add(12 * time.Hour)
+	if token.ExpiryTime.IsZero() {
+		token.ExpiryTime = time.Now().Add(12 * time.Hour)
+	}
 
 	err := m.db.Create(token).Error
 	if err != nil {
 		if gormsupport.IsUniqueViolation(err, "token_pkey") {
 			return errors.NewDataConflictError(fmt.Sprintf("token with ID %s already exists", token.TokenID))
 		}
+		if gormsupport.IsForeignKeyViolation(err, "token_identity_id_fkey") {
+			return errors.NewNotFoundError("identity", token.IdentityID.String())
+		}
 
 		log.Error(ctx, map[string]interface{}{
 			"token_id": token.TokenID,
@@ -198,3 +227,65 @@ func (m *GormTokenRepository) ListForIdentity(ctx context.Context, identityID uu
 	}
 	return rows, nil
 }
+
+// ListPersonalForIdentity returns the personal access tokens of the specified identity, including the revoked and
+// expired ones, the most recent first
+func (m *GormTokenRepository) ListPersonalForIdentity(ctx context.Context, identityID uuid.UUID) ([]Token, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "token", "ListPersonalForIdentity"}, time.Now())
+	var rows []Token
+
+	err := m.db.Model(&Token{}).Where("identity_id = ? AND token_type = ?", identityID, TokenTypePersonal).Order("created_at DESC").Find(&rows).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errs.WithStack(err)
+	}
+	return rows, nil
+}
+
+// AddResource associates the token with a resource for which it carries permissions
+func (m *GormTokenRepository) AddResource(ctx context.Context, tokenID uuid.UUID, resourceID string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "token", "AddResource"}, time.Now())
+
+	err := m.db.Exec("INSERT INTO token_resource (token_id, resource_id) VALUES (?, ?)", tokenID, resourceID).Error
+	if err != nil {
+		if gormsupport.IsUniqueViolation(err, "token_resource_pkey") {
+			return errors.NewDataConflictError(fmt.Sprintf("token with ID %s is already associated with resource %s", tokenID, resourceID))
+		}
+		if gormsupport.IsForeignKeyViolation(err, "token_resource_resource_id_fkey") {
+			return errors.NewNotFoundError("resource", resourceID)
+		}
+		log.Error(ctx, map[string]interface{}{
+			"token_id":    tokenID,
+			"resource_id": resourceID,
+			"err":         err,
+		}, "unable to associate the token with the resource")
+		return errs.WithStack(err)
+	}
+	return nil
+}
+
+// SetStatusFlagForResource sets the specified status flag on all the tokens associated with the resource
+// or with any of its descendant resources
+func (m *GormTokenRepository) SetStatusFlagForResource(ctx context.Context, resourceID string, status int) error {
+	defer goa.MeasureSince([]string{"goa", "db", "token", "SetStatusFlagForResource"}, time.Now())
+
+	err := m.db.Exec(`UPDATE token SET status = status | ?, updated_at = now()
+WHERE deleted_at IS NULL
+  AND token_id IN (
+    SELECT tr.token_id FROM token_resource tr WHERE tr.resource_id IN (
+      WITH RECURSIVE r AS (
+        SELECT resource_id FROM resource WHERE resource_id = ?
+        UNION SELECT c.resource_id FROM resource c INNER JOIN r ON c.parent_resource_id = r.resource_id
+      )
+      SELECT resource_id FROM r
+    )
+  )`, status, resourceID).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_id": resourceID,
+			"status":      status,
+			"err":         err,
+		}, "unable to update the status of the tokens for the resource")
+		return errs.WithStack(err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package repository_test
 
 import (
 	"testing"
+	"time"
 
 	tokenRepo "github.com/fabric8-services/fabric8-auth/authorization/token/repository"
 	"github.com/fabric8-services/fabric8-auth/errors"
@@ -107,3 +108,78 @@ func (s *tokenBlackBoxTest) TestSaveFailsForDeletedToken() {
 	err = s.repo.Save(s.Ctx, token.Token())
 	require.Error(s.T(), err, "save token should fail for deleted token")
 }
+
+func (s *tokenBlackBoxTest) TestSetStatusFlagForResource() {
+	parent := s.Graph.CreateResource()
+	child := s.Graph.CreateResource(parent)
+	other := s.Graph.CreateResource()
+
+	parentToken := s.Graph.CreateToken()
+	err := s.repo.AddResource(s.Ctx, parentToken.TokenID(), parent.ResourceID())
+	require.NoError(s.T(), err)
+	childToken := s.Graph.CreateToken()
+	err = s.repo.AddResource(s.Ctx, childToken.TokenID(), child.ResourceID())
+	require.NoError(s.T(), err)
+	otherToken := s.Graph.CreateToken()
+	err = s.repo.AddResource(s.Ctx, otherToken.TokenID(), other.ResourceID())
+	require.NoError(s.T(), err)
+
+	s.T().Run("child resource", func(t *testing.T) {
+		err := s.repo.SetStatusFlagForResource(s.Ctx, child.ResourceID(), tokenRepo.TokenStatusStale)
+		require.NoError(t, err)
+		s.checkStatus(t, parentToken.TokenID(), 0)
+		s.checkStatus(t, childToken.TokenID(), tokenRepo.TokenStatusStale)
+		s.checkStatus(t, otherToken.TokenID(), 0)
+	})
+
+	s.T().Run("parent resource", func(t *testing.T) {
+		err := s.repo.SetStatusFlagForResource(s.Ctx, parent.ResourceID(), tokenRepo.TokenStatusRevoked)
+		require.NoError(t, err)
+		s.checkStatus(t, parentToken.TokenID(), tokenRepo.TokenStatusRevoked)
+		s.checkStatus(t, childToken.TokenID(), tokenRepo.TokenStatusStale|tokenRepo.TokenStatusRevoked)
+		s.checkStatus(t, otherToken.TokenID(), 0)
+	})
+}
+
+func (s *tokenBlackBoxTest) TestAddResourceFailsForUnknownResource() {
+	token := s.Graph.CreateToken()
+
+	err := s.repo.AddResource(s.Ctx, token.TokenID(), uuid.NewV4().String())
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, err)
+}
+
+func (s *tokenBlackBoxTest) TestListPersonalForIdentity() {
+	user := s.Graph.CreateUser()
+	// noise
+	s.Graph.CreateToken(user)
+
+	name := "ci"
+	scopes := "spaces:read resources:write"
+	pat := tokenRepo.Token{
+		IdentityID: user.IdentityID(),
+		TokenType:  tokenRepo.TokenTypePersonal,
+		ExpiryTime: time.Now().Add(24 * time.Hour),
+		Name:       &name,
+		Scopes:     &scopes,
+	}
+	err := s.repo.Create(s.Ctx, &pat)
+	require.NoError(s.T(), err)
+
+	tokens, err := s.repo.ListPersonalForIdentity(s.Ctx, user.IdentityID())
+	require.NoError(s.T(), err)
+	require.Len(s.T(), tokens, 1)
+	require.Equal(s.T(), pat.TokenID, tokens[0].TokenID)
+	require.Equal(s.T(), name, *tokens[0].Name)
+	require.Equal(s.T(), scopes, *tokens[0].Scopes)
+
+	tokens, err = s.repo.ListPersonalForIdentity(s.Ctx, s.Graph.CreateUser().IdentityID())
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), tokens)
+}
+
+func (s *tokenBlackBoxTest) checkStatus(t *testing.T, tokenID uuid.UUID, expected int) {
+	loadedToken, err := s.repo.Load(s.Ctx, tokenID)
+	require.NoError(t, err)
+	require.Equal(t, expected, loadedToken.Status)
+}
@@ -102,9 +102,31 @@ const (
 	varUserAccountPrivateKey             = "useraccount.privatekey"
 	varUserAccountPrivateKeyID           = "useraccount.privatekeyid"
 
+	// Signing key rotation
+	varSigningKeyRotationInterval   = "signingkey.rotation.interval"
+	varSigningKeyRetirementPeriod   = "signingkey.retirement.period"
+	varSigningKeyRefreshInterval    = "signingkey.refresh.interval"
+	varSigningKeyGeneratedKeyBits   = "signingkey.generated.bits"
+	varSigningKeyGeneratedAlgorithm = "signingkey.generated.algorithm"
+	varSigningKeyEncryptionKey      = "signingkey.encryption.key"
+
 	// Token configuration
-	varAccessTokenExpiresIn  = "useraccount.token.access.expiresin"  // In seconds
-	varRefreshTokenExpiresIn = "useraccount.token.refresh.expiresin" // In seconds
+	varAccessTokenExpiresIn      = "useraccount.token.access.expiresin"  // In seconds
+	varRefreshTokenExpiresIn     = "useraccount.token.refresh.expiresin" // In seconds
+	varPersonalAccessTokenMaxTTL = "useraccount.token.personal.maxttl"
+
+	// Service account secrets
+	varServiceAccountSecretOverlap = "serviceaccount.secret.overlap"
+
+	// Permission cache
+	varPermissionCacheTTL = "authorization.permission.cache.ttl"
+
+	// Expired role assignments
+	varExpiredRolesSweepInterval = "authorization.role.expired.sweep.interval"
+
+	// Invitations
+	varInvitationTTL                   = "authorization.invitation.ttl"
+	varExpiredInvitationsSweepInterval = "authorization.invitation.expired.sweep.interval"
 
 	// GitHub linking
 	varGitHubClientID            = "github.client.id"
@@ -250,6 +272,9 @@ func NewConfigurationData(mainConfigFile string, serviceAccountConfigFile string
 	if kid == defaultUserAccountPrivateKeyID {
 		c.appendDefaultConfigErrorMessage("default user account private key ID is used")
 	}
+	if c.GetSigningKeyEncryptionKey() == defaultSigningKeyEncryptionKey {
+		c.appendDefaultConfigErrorMessage("default signing key encryption key is used")
+	}
 	if c.GetPostgresPassword() == defaultDBPassword {
 		c.appendDefaultConfigErrorMessage("default DB password is used")
 	}
@@ -356,6 +381,7 @@ func (c *ConfigurationData) checkServiceAccountConfig() {
 		"fabric8-notification":  true,
 		"rh-che":                true,
 		"fabric8-gemini-server": true,
+		"fabric8-auth-admin":    true,
 	}
 	for _, sa := range c.sa {
 		if sa.Name == "" {
@@ -609,7 +635,8 @@ func (c *ConfigurationData) GetAuthServiceURL() string {
 	}
 }
 
-// GetServiceAccounts returns a map of service account configurations by service account ID
+// GetServiceAccounts returns a map of service account configurations by service account ID.
+// The service accounts stored in the database take precedence over the configured ones with the same ID.
 // Default Service Account names and secrets used in Dev mode:
 // "fabric8-wit" : "witsecret"
 // "fabric8-tenant : ["tenantsecretOld", "tenantsecretNew"]
@@ -620,6 +647,7 @@ func (c *ConfigurationData) GetAuthServiceURL() string {
 // "fabric8-notification : "secret"
 // "rh-che : "secret"
 // "fabric8-gemini-server" : "secret"
+// "fabric8-auth-admin" : "secret"
 func (c *ConfigurationData) GetServiceAccounts() map[string]ServiceAccount {
 	return c.sa
 }
@@ -686,6 +714,30 @@ func (c *ConfigurationData) setConfigDefaults() {
 	// Timeout of a transaction in minutes
 	c.v.SetDefault(varPostgresTransactionTimeout, time.Duration(5*time.Minute))
 
+	// Time after which the cached results of permission checks expire
+	c.v.SetDefault(varPermissionCacheTTL, time.Duration(time.Minute))
+
+	// Interval between two deletions of the expired role assignments
+	c.v.SetDefault(varExpiredRolesSweepInterval, time.Duration(time.Minute))
+
+	// Time after which the invitations which have not been accepted expire
+	c.v.SetDefault(varInvitationTTL, time.Duration(7*24*time.Hour))
+	// Interval between two deletions of the expired invitations
+	c.v.SetDefault(varExpiredInvitationsSweepInterval, time.Duration(time.Hour))
+
+	// Age of the active signing keys at which they are rotated. Zero disables the scheduled rotation
+	c.v.SetDefault(varSigningKeyRotationInterval, time.Duration(0))
+	// Time during which the replaced signing keys remain published, so that the tokens they signed can be verified
+	c.v.SetDefault(varSigningKeyRetirementPeriod, time.Duration(30*24*time.Hour))
+	// Interval between two reloads of the signing keys from the database
+	c.v.SetDefault(varSigningKeyRefreshInterval, time.Duration(time.Minute))
+	// Size of the RSA signing keys generated by the rotation
+	c.v.SetDefault(varSigningKeyGeneratedKeyBits, 2048)
+	// Algorithm of the signing keys generated by the rotation: RS256, ES256 or EdDSA
+	c.v.SetDefault(varSigningKeyGeneratedAlgorithm, "RS256")
+	// Key which encrypts the signing keys stored in the database
+	c.v.SetDefault(varSigningKeyEncryptionKey, defaultSigningKeyEncryptionKey)
+
 	//-----
 	// HTTP
 	//-----
@@ -720,6 +772,10 @@ func (c *ConfigurationData) setConfigDefaults() {
 	in30Days = 30 * 24 * 60 * 60
 	c.v.SetDefault(varAccessTokenExpiresIn, in30Days)
 	c.v.SetDefault(varRefreshTokenExpiresIn, in30Days)
+	// Longest lifespan a user can give to a personal access token
+	c.v.SetDefault(varPersonalAccessTokenMaxTTL, time.Duration(365*24*time.Hour))
+	// Time during which a retired service account secret is still accepted, so that the service can switch to its new secret
+	c.v.SetDefault(varServiceAccountSecretOverlap, time.Duration(24*time.Hour))
 	c.v.SetDefault(varKeycloakClientID, defaultKeycloakClientID)
 	c.v.SetDefault(varKeycloakSecret, defaultKeycloakSecret)
 	c.v.SetDefault(varPublicOauthClientID, defaultPublicOauthClientID)
@@ -816,6 +872,32 @@ func (c *ConfigurationData) GetPostgresTransactionTimeout() time.Duration {
 	return c.v.GetDuration(varPostgresTransactionTimeout)
 }
 
+// GetPermissionCacheTTL returns the duration after which the cached results of permission checks expire.
+// Cached results are also invalidated whenever roles, memberships or resources change in this instance of the service,
+// so the TTL only bounds the time needed to pick up the changes made by the other instances. Zero disables the cache.
+func (c *ConfigurationData) GetPermissionCacheTTL() time.Duration {
+	return c.v.GetDuration(varPermissionCacheTTL)
+}
+
+// GetExpiredRolesSweepInterval returns the interval between two deletions of the expired role assignments.
+// Expired role assignments never grant any permission, so the interval only bounds the time before the users are notified.
+// The expired role assignments are not deleted if the interval is zero or negative.
+func (c *ConfigurationData) GetExpiredRolesSweepInterval() time.Duration {
+	return c.v.GetDuration(varExpiredRolesSweepInterval)
+}
+
+// GetInvitationTTL returns the time after which an invitation which has not been accepted expires.
+// Zero means that invitations never expire.
+func (c *ConfigurationData) GetInvitationTTL() time.Duration {
+	return c.v.GetDuration(varInvitationTTL)
+}
+
+// GetExpiredInvitationsSweepInterval returns the interval between two deletions of the expired invitations.
+// The expired invitations are not deleted if the interval is zero or negative.
+func (c *ConfigurationData) GetExpiredInvitationsSweepInterval() time.Duration {
+	return c.v.GetDuration(varExpiredInvitationsSweepInterval)
+}
+
 // GetPostgresConnectionMaxIdle returns the number of connections that should be keept alive in the database connection pool at
 // any given time. -1 represents no restrictions/default behavior
 func (c *ConfigurationData) GetPostgresConnectionMaxIdle() int {
@@ -922,6 +1004,42 @@ func (c *ConfigurationData) GetUserAccountPrivateKey() ([]byte, string) {
 	return []byte(c.v.GetString(varUserAccountPrivateKey)), c.v.GetString(varUserAccountPrivateKeyID)
 }
 
+// GetSigningKeyRotationInterval returns the age of the active signing keys at which they are replaced by new keys.
+// The scheduled rotation is disabled if zero.
+func (c *ConfigurationData) GetSigningKeyRotationInterval() time.Duration {
+	return c.v.GetDuration(varSigningKeyRotationInterval)
+}
+
+// GetSigningKeyRetirementPeriod returns the time during which the signing keys which have been replaced are still
+// published, so that the tokens they signed can still be verified. The keys are published at least until the longest
+// lived access, refresh or personal access tokens they signed have expired.
+func (c *ConfigurationData) GetSigningKeyRetirementPeriod() time.Duration {
+	return c.v.GetDuration(varSigningKeyRetirementPeriod)
+}
+
+// GetSigningKeyRefreshInterval returns the interval between two reloads of the signing keys from the database.
+// The signing keys are only loaded at startup if the interval is zero or negative.
+func (c *ConfigurationData) GetSigningKeyRefreshInterval() time.Duration {
+	return c.v.GetDuration(varSigningKeyRefreshInterval)
+}
+
+// GetSigningKeyGeneratedKeyBits returns the size of the RSA signing keys generated by the key rotation
+func (c *ConfigurationData) GetSigningKeyGeneratedKeyBits() int {
+	return c.v.GetInt(varSigningKeyGeneratedKeyBits)
+}
+
+// GetSigningKeyGeneratedAlgorithm returns the algorithm of the signing keys generated by the key rotation:
+// RS256, ES256 or EdDSA
+func (c *ConfigurationData) GetSigningKeyGeneratedAlgorithm() string {
+	return c.v.GetString(varSigningKeyGeneratedAlgorithm)
+}
+
+// GetSigningKeyEncryptionKey returns the base64 encoded 256-bit key encryption key which encrypts the private signing
+// keys stored in the database
+func (c *ConfigurationData) GetSigningKeyEncryptionKey() string {
+	return c.v.GetString(varSigningKeyEncryptionKey)
+}
+
 // GetAccessTokenExpiresIn returns lifespan of user access tokens generated by Auth in seconds
 func (c *ConfigurationData) GetAccessTokenExpiresIn() int64 {
 	return c.v.GetInt64(varAccessTokenExpiresIn)
@@ -932,6 +1050,16 @@ func (c *ConfigurationData) GetRefreshTokenExpiresIn() int64 {
 	return c.v.GetInt64(varRefreshTokenExpiresIn)
 }
 
+// GetPersonalAccessTokenMaxTTL returns the longest lifespan a user can give to a personal access token
+func (c *ConfigurationData) GetPersonalAccessTokenMaxTTL() time.Duration {
+	return c.v.GetDuration(varPersonalAccessTokenMaxTTL)
+}
+
+// GetServiceAccountSecretOverlap returns the default time during which a retired service account secret is still accepted
+func (c *ConfigurationData) GetServiceAccountSecretOverlap() time.Duration {
+	return c.v.GetDuration(varServiceAccountSecretOverlap)
+}
+
 // GetDevModePublicKey returns additional public key and its ID which should be used by the Auth service in Dev Mode
 // For example a public key from Keycloak
 // Returns false if in in Dev Mode
@@ -145,6 +145,66 @@ func TestGetTransactionTimeoutOK(t *testing.T) {
 	assert.Equal(t, time.Duration(6*time.Minute), config.GetPostgresTransactionTimeout())
 }
 
+func TestGetPermissionCacheTTLOK(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	key := "AUTH_AUTHORIZATION_PERMISSION_CACHE_TTL"
+	realEnvValue := os.Getenv(key)
+
+	os.Unsetenv(key)
+	defer func() {
+		os.Setenv(key, realEnvValue)
+		resetConfiguration()
+	}()
+
+	assert.Equal(t, time.Duration(time.Minute), config.GetPermissionCacheTTL())
+
+	os.Setenv(key, "30s")
+	resetConfiguration()
+
+	assert.Equal(t, time.Duration(30*time.Second), config.GetPermissionCacheTTL())
+}
+
+func TestGetExpiredRolesSweepIntervalOK(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	key := "AUTH_AUTHORIZATION_ROLE_EXPIRED_SWEEP_INTERVAL"
+	realEnvValue := os.Getenv(key)
+
+	os.Unsetenv(key)
+	defer func() {
+		os.Setenv(key, realEnvValue)
+		resetConfiguration()
+	}()
+
+	assert.Equal(t, time.Duration(time.Minute), config.GetExpiredRolesSweepInterval())
+
+	os.Setenv(key, "10m")
+	resetConfiguration()
+
+	assert.Equal(t, time.Duration(10*time.Minute), config.GetExpiredRolesSweepInterval())
+}
+
+func TestGetInvitationTTLOK(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	key := "AUTH_AUTHORIZATION_INVITATION_TTL"
+	realEnvValue := os.Getenv(key)
+
+	os.Unsetenv(key)
+	defer func() {
+		os.Setenv(key, realEnvValue)
+		resetConfiguration()
+	}()
+
+	assert.Equal(t, time.Duration(7*24*time.Hour), config.GetInvitationTTL())
+
+	os.Setenv(key, "48h")
+	resetConfiguration()
+
+	assert.Equal(t, time.Duration(48*time.Hour), config.GetInvitationTTL())
+}
+
 func TestValidRedirectURLsInDevModeCanBeOverridden(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 
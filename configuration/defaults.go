@@ -81,6 +81,10 @@ vwIDAQAB
 
 	defaultDBPassword = "mysecretpassword"
 
+	// defaultSigningKeyEncryptionKey is the base64 encoded 256-bit key which encrypts the signing keys stored in the
+	// database in dev mode
+	defaultSigningKeyEncryptionKey = "N7Ir3ppd8rBibbUh+8QPxKS1aMjVu5/dViU78qRHWBY="
+
 	defaultGitHubClientSecret = "48d1498c849616dfecf83cf74f22dfb361ee2511"
 
 	defaultLogLevel = "info"
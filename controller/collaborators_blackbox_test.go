@@ -525,7 +525,7 @@ func (rest *TestCollaboratorsREST) createSpaceByIdentity(identity *account.Ident
 	require.NotNil(rest.T(), spaceCtrl)
 
 	id := uuid.NewV4()
-	test.CreateSpaceOK(rest.T(), svc.Context, svc, spaceCtrl, id)
+	test.CreateSpaceOK(rest.T(), svc.Context, svc, spaceCtrl, id, nil)
 	return id
 }
 
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"github.com/fabric8-services/fabric8-auth/app"
+	"github.com/fabric8-services/fabric8-auth/application"
+	rolerepo "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
+	"github.com/fabric8-services/fabric8-auth/jsonapi"
+	"github.com/fabric8-services/fabric8-auth/log"
+
+	"github.com/goadesign/goa"
+)
+
+// DefaultRoleMappingsController implements the default_role_mappings resource.
+type DefaultRoleMappingsController struct {
+	*goa.Controller
+	app application.Application
+}
+
+// NewDefaultRoleMappingsController creates a default_role_mappings controller.
+func NewDefaultRoleMappingsController(service *goa.Service, app application.Application) *DefaultRoleMappingsController {
+	return &DefaultRoleMappingsController{
+		Controller: service.NewController("DefaultRoleMappingsController"),
+		app:        app,
+	}
+}
+
+// List runs the list action.
+func (c *DefaultRoleMappingsController) List(ctx *app.ListDefaultRoleMappingsContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	mappings, err := c.app.RoleMappingService().ListDefaultByResourceType(ctx, ctx.ResourceTypeID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_type_id": ctx.ResourceTypeID,
+			"err":              err,
+		}, "error retrieving the default role mappings for a specific resource type")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	data := make([]*app.DefaultRoleMappingData, len(mappings))
+	for i, m := range mappings {
+		data[i] = convertDefaultRoleMapping(m)
+	}
+	return ctx.OK(&app.DefaultRoleMappingArray{
+		Data: data,
+	})
+}
+
+// Create runs the create action.
+func (c *DefaultRoleMappingsController) Create(ctx *app.CreateDefaultRoleMappingsContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	mapping, err := c.app.RoleMappingService().CreateDefault(ctx, ctx.ResourceTypeID,
+		convertRoleReferenceData(ctx.Payload.FromRole), convertRoleReferenceData(ctx.Payload.ToRole))
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_type_id": ctx.ResourceTypeID,
+			"err":              err,
+		}, "error creating a default role mapping for a specific resource type")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.Created(&app.DefaultRoleMappingSingle{
+		Data: convertDefaultRoleMapping(*mapping),
+	})
+}
+
+// Delete runs the delete action.
+func (c *DefaultRoleMappingsController) Delete(ctx *app.DeleteDefaultRoleMappingsContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	err := c.app.RoleMappingService().DeleteDefault(ctx, ctx.ResourceTypeID, ctx.DefaultRoleMappingID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_type_id":        ctx.ResourceTypeID,
+			"default_role_mapping_id": ctx.DefaultRoleMappingID,
+			"err":                     err,
+		}, "error deleting a default role mapping of a specific resource type")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+func convertDefaultRoleMapping(m rolerepo.DefaultRoleMapping) *app.DefaultRoleMappingData {
+	return &app.DefaultRoleMappingData{
+		ID:             m.DefaultRoleMappingID.String(),
+		ResourceTypeID: m.ResourceTypeID.String(),
+		FromRole:       convertRoleReference(m.FromRole),
+		ToRole:         convertRoleReference(m.ToRole),
+	}
+}
@@ -1,9 +1,12 @@
 package controller
 
 import (
+	"context"
+
 	"github.com/fabric8-services/fabric8-auth/app"
 	"github.com/fabric8-services/fabric8-auth/application"
 	"github.com/fabric8-services/fabric8-auth/authorization/invitation"
+	invitationrepo "github.com/fabric8-services/fabric8-auth/authorization/invitation/repository"
 	"github.com/fabric8-services/fabric8-auth/errors"
 	"github.com/fabric8-services/fabric8-auth/jsonapi"
 	"github.com/fabric8-services/fabric8-auth/log"
@@ -45,19 +48,25 @@ func (c *InvitationController) CreateInvite(ctx *app.CreateInviteInvitationConte
 
 	for _, invitee := range ctx.Payload.Data {
 		// Validate that an identifying parameter has been set
-		if invitee.IdentityID == nil {
+		if invitee.IdentityID == nil && invitee.Email == nil {
 			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterErrorFromString("user identifier", "", "no identifier provided"))
 		}
 
-		// If an identity ID has been provided for the user, convert it to a UUID here
-		var identityID uuid.UUID
-		if invitee.IdentityID != nil && *invitee.IdentityID != "" {
-			identityID, err = uuid.FromString(*invitee.IdentityID)
+		// If an identity ID has been provided for the user, convert it to a UUID here. Otherwise the invitation
+		// is addressed to the e-mail address of a user who does not have an account yet
+		var identityID *uuid.UUID
+		if invitee.IdentityID != nil {
+			id, err := uuid.FromString(*invitee.IdentityID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("identity-id", *invitee.IdentityID).Expected("uuid"))
+			}
+			identityID = &id
 		}
 
 		// Create the Invitation object, and append it to our list of invitations
 		invitations = append(invitations, invitation.Invitation{
-			IdentityID: &identityID,
+			IdentityID: identityID,
+			Email:      invitee.Email,
 			Roles:      invitee.Roles,
 			Member:     *invitee.Member,
 		})
@@ -116,6 +125,133 @@ func (c *InvitationController) RescindInvite(ctx *app.RescindInviteInvitationCon
 	return ctx.OK([]byte{})
 }
 
+// ResendInvite runs the resendInvite action.
+func (c *InvitationController) ResendInvite(ctx *app.ResendInviteInvitationContext) error {
+	currentIdentity, err := login.LoadContextIdentityIfNotDeprovisioned(ctx, c.app)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	invitationID, err := uuid.FromString(ctx.InviteTo)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("invitationID", ctx.InviteTo))
+	}
+
+	err = c.app.InvitationService().Resend(ctx, currentIdentity.ID, invitationID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":          err,
+			"invitationID": invitationID,
+		}, "failed to resend invitation")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+// DeclineInvite runs the declineInvite action.
+func (c *InvitationController) DeclineInvite(ctx *app.DeclineInviteInvitationContext) error {
+	currentIdentity, err := login.LoadContextIdentityIfNotDeprovisioned(ctx, c.app)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	invitationID, err := uuid.FromString(ctx.InviteTo)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("invitationID", ctx.InviteTo))
+	}
+
+	err = c.app.InvitationService().Decline(ctx, currentIdentity.ID, invitationID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":          err,
+			"invitationID": invitationID,
+		}, "failed to decline invitation")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+// ListInvites runs the listInvites action.
+func (c *InvitationController) ListInvites(ctx *app.ListInvitesInvitationContext) error {
+	currentIdentity, err := login.LoadContextIdentityIfNotDeprovisioned(ctx, c.app)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	invitations, err := c.app.InvitationService().ListForInviteTo(ctx, currentIdentity.ID, ctx.InviteTo)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":       err,
+			"invite-to": ctx.InviteTo,
+		}, "failed to list invitations")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	list, err := c.convertInvitations(ctx, invitations)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(list)
+}
+
+// ListUserInvites runs the listUserInvites action.
+func (c *InvitationController) ListUserInvites(ctx *app.ListUserInvitesInvitationContext) error {
+	currentIdentity, err := login.LoadContextIdentityIfNotDeprovisioned(ctx, c.app)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	invitations, err := c.app.InvitationService().ListForUser(ctx, currentIdentity.ID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":         err,
+			"identity-id": currentIdentity.ID,
+		}, "failed to list invitations")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	list, err := c.convertInvitations(ctx, invitations)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(list)
+}
+
+func (c *InvitationController) convertInvitations(ctx context.Context, invitations []invitationrepo.Invitation) (*app.InvitationList, error) {
+	data := make([]*app.InvitationData, len(invitations))
+	for i, inv := range invitations {
+		roles, err := c.app.InvitationRepository().ListRoles(ctx, inv.InvitationID)
+		if err != nil {
+			return nil, err
+		}
+		roleNames := make([]string, len(roles))
+		for j, role := range roles {
+			roleNames[j] = role.Name
+		}
+
+		data[i] = &app.InvitationData{
+			ID:         inv.InvitationID.String(),
+			ResourceID: inv.ResourceID,
+			Email:      inv.Email,
+			Member:     inv.Member,
+			Roles:      roleNames,
+			CreatedAt:  inv.CreatedAt,
+			ExpiresAt:  inv.ExpiresAt,
+		}
+		if inv.InviteTo != nil {
+			inviteTo := inv.InviteTo.String()
+			data[i].InviteTo = &inviteTo
+		}
+		if inv.IdentityID != nil {
+			identityID := inv.IdentityID.String()
+			data[i].IdentityID = &identityID
+		}
+	}
+	return &app.InvitationList{Data: data}, nil
+}
+
 func (c *InvitationController) AcceptInvite(ctx *app.AcceptInviteInvitationContext) error {
 	redirectURL := c.config.GetInvitationAcceptedRedirectURL()
 
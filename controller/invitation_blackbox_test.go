@@ -93,7 +93,43 @@ func (s *TestInvitationREST) TestCreateTeamMemberInvitationSuccess() {
 	// We should have 1 invitation
 	require.Equal(s.T(), 1, len(invitations))
 
-	require.Equal(s.T(), invitee.IdentityID(), invitations[0].IdentityID)
+	require.NotNil(s.T(), invitations[0].IdentityID)
+	require.Equal(s.T(), invitee.IdentityID(), *invitations[0].IdentityID)
+	require.True(s.T(), invitations[0].Member)
+}
+
+/*
+* This test will attempt to create a new invitation for a user who does not have an account yet to become a member of a team
+ */
+func (s *TestInvitationREST) TestCreateTeamMemberEmailInvitationSuccess() {
+	team := s.Graph.CreateTeam()
+
+	r := s.Graph.CreateRole(s.Graph.LoadResourceType(authorization.IdentityResourceTypeTeam))
+	r.AddScope(authorization.ManageTeamMembersScope)
+	team.AssignRole(&s.testIdentity, r.Role())
+
+	service, controller := s.SecuredController(s.testIdentity)
+
+	email := "invitee-" + uuid.NewV4().String() + "@example.com"
+	payload := &app.CreateInviteInvitationPayload{
+		Data: []*app.Invitee{
+			{
+				Email:  &email,
+				Member: boolPointer(true),
+			},
+		},
+	}
+
+	test.CreateInviteInvitationCreated(s.T(), service.Context, service, controller, team.TeamID().String(), payload)
+
+	invitations, err := s.invRepo.ListForIdentity(s.Ctx, team.TeamID())
+	require.NoError(s.T(), err, "could not list invitations")
+
+	// We should have 1 pending invitation
+	require.Equal(s.T(), 1, len(invitations))
+	require.Nil(s.T(), invitations[0].IdentityID)
+	require.NotNil(s.T(), invitations[0].Email)
+	require.Equal(s.T(), email, *invitations[0].Email)
 	require.True(s.T(), invitations[0].Member)
 }
 
@@ -132,7 +168,8 @@ func (s *TestInvitationREST) TestCreateTeamRoleInvitationSuccess() {
 	// We should have 1 invitation
 	require.Equal(s.T(), 1, len(invitations))
 
-	require.Equal(s.T(), invitee.IdentityID(), invitations[0].IdentityID)
+	require.NotNil(s.T(), invitations[0].IdentityID)
+	require.Equal(s.T(), invitee.IdentityID(), *invitations[0].IdentityID)
 	require.False(s.T(), invitations[0].Member)
 
 	roles, err := s.invRepo.ListRoles(s.Ctx, invitations[0].InvitationID)
@@ -358,6 +395,94 @@ func (s *TestInvitationREST) TestRescindInvitationFailsForNonUUIDValue() {
 	require.NotNil(s.T(), response.Header().Get("Location"))
 }
 
+func (s *TestInvitationREST) TestResendInvitation() {
+	team := s.Graph.CreateTeam()
+	invitee := s.Graph.CreateUser()
+	inv := s.Graph.CreateInvitation(team, invitee)
+
+	r := s.Graph.CreateRole(s.Graph.LoadResourceType(authorization.IdentityResourceTypeTeam))
+	r.AddScope(authorization.ManageTeamMembersScope)
+	admin := s.Graph.CreateUser()
+	team.AssignRole(admin.Identity(), r.Role())
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		service, controller := s.SecuredController(*invitee.Identity())
+		test.ResendInviteInvitationForbidden(t, service.Context, service, controller, inv.Invitation().InvitationID.String())
+	})
+
+	s.T().Run("not found", func(t *testing.T) {
+		service, controller := s.SecuredController(*admin.Identity())
+		test.ResendInviteInvitationNotFound(t, service.Context, service, controller, uuid.NewV4().String())
+	})
+
+	s.T().Run("ok", func(t *testing.T) {
+		service, controller := s.SecuredController(*admin.Identity())
+		test.ResendInviteInvitationNoContent(t, service.Context, service, controller, inv.Invitation().InvitationID.String())
+
+		resent, err := s.Application.InvitationRepository().Load(s.Ctx, inv.Invitation().InvitationID)
+		require.NoError(t, err)
+		require.NotEqual(t, inv.Invitation().AcceptCode, resent.AcceptCode)
+		require.NotNil(t, resent.ExpiresAt)
+	})
+}
+
+func (s *TestInvitationREST) TestDeclineInvitation() {
+	team := s.Graph.CreateTeam()
+	invitee := s.Graph.CreateUser()
+	inv := s.Graph.CreateInvitation(team, invitee)
+
+	s.T().Run("forbidden", func(t *testing.T) {
+		service, controller := s.SecuredController(*s.Graph.CreateUser().Identity())
+		test.DeclineInviteInvitationForbidden(t, service.Context, service, controller, inv.Invitation().InvitationID.String())
+	})
+
+	s.T().Run("non uuid value", func(t *testing.T) {
+		service, controller := s.SecuredController(*invitee.Identity())
+		test.DeclineInviteInvitationNotFound(t, service.Context, service, controller, "foo")
+	})
+
+	s.T().Run("ok", func(t *testing.T) {
+		service, controller := s.SecuredController(*invitee.Identity())
+		test.DeclineInviteInvitationNoContent(t, service.Context, service, controller, inv.Invitation().InvitationID.String())
+
+		// The invitation should no longer be there after declining
+		_, err := s.Application.InvitationRepository().Load(s.Ctx, inv.Invitation().InvitationID)
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, err)
+	})
+}
+
+func (s *TestInvitationREST) TestListInvitations() {
+	space := s.Graph.CreateSpace()
+	spaceAdmin := s.Graph.CreateUser()
+	space.AddAdmin(spaceAdmin)
+	invitee := s.Graph.CreateUser()
+	r := s.Graph.CreateRole(s.Graph.LoadResourceType(authorization.ResourceTypeSpace))
+	inv := s.Graph.CreateInvitation(space, invitee, r)
+
+	s.T().Run("for resource", func(t *testing.T) {
+		service, controller := s.SecuredController(*spaceAdmin.Identity())
+		_, list := test.ListInvitesInvitationOK(t, service.Context, service, controller, space.SpaceID())
+		require.Len(t, list.Data, 1)
+		require.Equal(t, inv.Invitation().InvitationID.String(), list.Data[0].ID)
+		require.Equal(t, space.SpaceID(), *list.Data[0].ResourceID)
+		require.Equal(t, invitee.IdentityID().String(), *list.Data[0].IdentityID)
+		require.Equal(t, []string{r.Role().Name}, list.Data[0].Roles)
+	})
+
+	s.T().Run("for resource forbidden", func(t *testing.T) {
+		service, controller := s.SecuredController(*invitee.Identity())
+		test.ListInvitesInvitationForbidden(t, service.Context, service, controller, space.SpaceID())
+	})
+
+	s.T().Run("for current user", func(t *testing.T) {
+		service, controller := s.SecuredController(*invitee.Identity())
+		_, list := test.ListUserInvitesInvitationOK(t, service.Context, service, controller)
+		require.Len(t, list.Data, 1)
+		require.Equal(t, inv.Invitation().InvitationID.String(), list.Data[0].ID)
+	})
+}
+
 func boolPointer(value bool) *bool {
 	return &value
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/fabric8-services/fabric8-auth/app"
 	"github.com/fabric8-services/fabric8-auth/client"
 	"github.com/fabric8-services/fabric8-auth/rest"
+	"github.com/fabric8-services/fabric8-auth/token/jwk"
 	"github.com/goadesign/goa"
 )
 
@@ -43,7 +44,8 @@ func (c *OpenidConfigurationController) Show(ctx *app.ShowOpenidConfigurationCon
 		SubjectTypesSupported: []string{"public"},
 		// id_token_signing_alg_values_supported REQUIRED. JSON array containing a list of the JWS signing algorithms (alg values) supported by the OP for the ID Token to encode the Claims in a JWT [JWT].
 		// The algorithm RS256 MUST be included. The value none MAY be supported, but MUST NOT be used unless the Response Type used returns no ID Token from the Authorization Endpoint (such as when using the Authorization Code Flow).
-		IDTokenSigningAlgValuesSupported: []string{"RS256"},
+		// The tokens are signed with RS256, ES256 or EdDSA depending on the algorithm of the active signing key
+		IDTokenSigningAlgValuesSupported: jwk.SupportedAlgorithms,
 
 		// RECOMMENDED properties
 		UserinfoEndpoint: &userinfoEndpoint,
@@ -50,7 +50,7 @@ func (s *TestOpenIDConfigurationREST) TestShowOpenIDConfiguration() {
 		JwksURI:                           &jwksURI,
 		GrantTypesSupported:               []string{"authorization_code", "refresh_token", "client_credentials"},
 		SubjectTypesSupported:             []string{"public"},
-		IDTokenSigningAlgValuesSupported:  []string{"RS256"},
+		IDTokenSigningAlgValuesSupported:  []string{"RS256", "ES256", "EdDSA"},
 		ScopesSupported:                   []string{"openid", "offline_access"},
 		ClaimsSupported:                   []string{"sub", "iss", "auth_time", "name", "given_name", "family_name", "preferred_username", "email"},
 		TokenEndpointAuthMethodsSupported: []string{"client_secret_post", "client_secret_jwt"},
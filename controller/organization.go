@@ -11,6 +11,7 @@ import (
 	"github.com/fabric8-services/fabric8-auth/login"
 
 	"github.com/fabric8-services/fabric8-auth/authorization"
+	"github.com/fabric8-services/fabric8-auth/authorization/organization"
 	"github.com/goadesign/goa"
 )
 
@@ -84,6 +85,151 @@ func (c *OrganizationController) List(ctx *app.ListOrganizationContext) error {
 	return ctx.OK(&app.OrganizationArray{convertToAppOrganization(orgs)})
 }
 
+// Update runs the update action.
+func (c *OrganizationController) Update(ctx *app.UpdateOrganizationContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	if len(strings.TrimSpace(ctx.Payload.Name)) == 0 {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterErrorFromString("name", ctx.Payload.Name, "organization name cannot be empty"))
+	}
+
+	err = c.app.OrganizationService().UpdateOrganization(ctx, *currentUser, ctx.OrgID, ctx.Payload.Name)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":               err,
+			"organization_id":   ctx.OrgID,
+			"organization_name": ctx.Payload.Name,
+		}, "failed to rename organization")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+// Delete runs the delete action.
+func (c *OrganizationController) Delete(ctx *app.DeleteOrganizationContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	err = c.app.OrganizationService().DeleteOrganization(ctx, *currentUser, ctx.OrgID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":             err,
+			"organization_id": ctx.OrgID,
+		}, "failed to delete organization")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+// ListGroups runs the listGroups action.
+func (c *OrganizationController) ListGroups(ctx *app.ListGroupsOrganizationContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	groups, err := c.app.GroupService().ListGroupsInOrganization(ctx, *currentUser, ctx.OrgID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":             err,
+			"organization_id": ctx.OrgID,
+		}, "failed to list security groups")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.OK(&app.SecurityGroupArray{Data: convertToSecurityGroupData(groups)})
+}
+
+// ListMembers runs the listMembers action.
+func (c *OrganizationController) ListMembers(ctx *app.ListMembersOrganizationContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	members, err := c.app.OrganizationService().ListMembers(ctx, *currentUser, ctx.OrgID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":             err,
+			"organization_id": ctx.OrgID,
+		}, "failed to list organization members")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.OK(&app.OrganizationMemberArray{Data: convertToOrganizationMemberData(members)})
+}
+
+// RemoveMember runs the removeMember action.
+func (c *OrganizationController) RemoveMember(ctx *app.RemoveMemberOrganizationContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	err = c.app.OrganizationService().RemoveMember(ctx, *currentUser, ctx.OrgID, ctx.MemberID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":             err,
+			"organization_id": ctx.OrgID,
+			"member_id":       ctx.MemberID,
+		}, "failed to remove organization member")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+// UpdateMemberRole runs the updateMemberRole action.
+func (c *OrganizationController) UpdateMemberRole(ctx *app.UpdateMemberRoleOrganizationContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	if len(strings.TrimSpace(ctx.Payload.Role)) == 0 {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterErrorFromString("role", ctx.Payload.Role, "role name cannot be empty"))
+	}
+
+	err = c.app.OrganizationService().ChangeMemberRole(ctx, *currentUser, ctx.OrgID, ctx.MemberID, ctx.Payload.Role)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":             err,
+			"organization_id": ctx.OrgID,
+			"member_id":       ctx.MemberID,
+			"role_name":       ctx.Payload.Role,
+		}, "failed to change the role of the organization member")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+// Leave runs the leave action.
+func (c *OrganizationController) Leave(ctx *app.LeaveOrganizationContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	err = c.app.OrganizationService().Leave(ctx, *currentUser, ctx.OrgID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":             err,
+			"organization_id": ctx.OrgID,
+		}, "failed to leave organization")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
 func convertToAppOrganization(orgs []authorization.IdentityAssociation) []*app.OrganizationData {
 	results := []*app.OrganizationData{}
 
@@ -100,3 +246,24 @@ func convertToAppOrganization(orgs []authorization.IdentityAssociation) []*app.O
 
 	return results
 }
+
+func convertToOrganizationMemberData(members []organization.Member) []*app.OrganizationMemberData {
+	results := []*app.OrganizationMemberData{}
+
+	for _, member := range members {
+		memberData := &app.OrganizationMemberData{
+			ID:       member.Identity.ID.String(),
+			Username: member.Identity.Username,
+			Member:   member.Member,
+			Roles:    member.Roles,
+		}
+		if member.Identity.UserID.Valid {
+			userID := member.Identity.UserID.UUID.String()
+			memberData.UserID = &userID
+		}
+
+		results = append(results, memberData)
+	}
+
+	return results
+}
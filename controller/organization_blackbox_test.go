@@ -127,3 +127,117 @@ func (rest *TestOrganizationREST) TestListOrganizationUnauthorized() {
 	service, controller := rest.UnsecuredController()
 	test.ListOrganizationUnauthorized(rest.T(), service.Context, service, controller)
 }
+
+func (rest *TestOrganizationREST) TestListOrganizationMembersSuccess() {
+	admin := rest.Graph.CreateUser()
+	org := rest.Graph.CreateOrganization(admin)
+	member := rest.Graph.CreateUser()
+	org.AddMember(member)
+
+	service, controller := rest.SecuredController(*admin.Identity())
+	_, members := test.ListMembersOrganizationOK(rest.T(), service.Context, service, controller, org.OrganizationID())
+
+	require.Len(rest.T(), members.Data, 2)
+	for _, m := range members.Data {
+		switch m.ID {
+		case admin.IdentityID().String():
+			require.False(rest.T(), m.Member)
+			require.Equal(rest.T(), []string{authorization.OrganizationAdminRole}, m.Roles)
+		case member.IdentityID().String():
+			require.True(rest.T(), m.Member)
+			require.Empty(rest.T(), m.Roles)
+			require.Equal(rest.T(), member.User().Username, m.Username)
+		default:
+			require.Fail(rest.T(), "unexpected member", m.ID)
+		}
+	}
+}
+
+func (rest *TestOrganizationREST) TestListOrganizationMembersForbidden() {
+	org := rest.Graph.CreateOrganization()
+	service, controller := rest.SecuredController(*rest.Graph.CreateUser().Identity())
+
+	test.ListMembersOrganizationForbidden(rest.T(), service.Context, service, controller, org.OrganizationID())
+}
+
+func (rest *TestOrganizationREST) TestRemoveOrganizationMember() {
+	admin := rest.Graph.CreateUser()
+	org := rest.Graph.CreateOrganization(admin)
+	member := rest.Graph.CreateUser()
+	org.AddMember(member)
+	service, controller := rest.SecuredController(*admin.Identity())
+
+	rest.T().Run("ok", func(t *testing.T) {
+		test.RemoveMemberOrganizationNoContent(t, service.Context, service, controller, org.OrganizationID(), member.IdentityID())
+	})
+
+	rest.T().Run("not found", func(t *testing.T) {
+		test.RemoveMemberOrganizationNotFound(t, service.Context, service, controller, org.OrganizationID(), member.IdentityID())
+	})
+
+	rest.T().Run("last admin", func(t *testing.T) {
+		test.RemoveMemberOrganizationConflict(t, service.Context, service, controller, org.OrganizationID(), admin.IdentityID())
+	})
+}
+
+func (rest *TestOrganizationREST) TestUpdateOrganizationMemberRole() {
+	admin := rest.Graph.CreateUser()
+	org := rest.Graph.CreateOrganization(admin)
+	member := rest.Graph.CreateUser()
+	org.AddMember(member)
+	service, controller := rest.SecuredController(*admin.Identity())
+
+	rest.T().Run("ok", func(t *testing.T) {
+		payload := &app.UpdateMemberRoleOrganizationPayload{Role: authorization.OrganizationViewerRole}
+		test.UpdateMemberRoleOrganizationNoContent(t, service.Context, service, controller, org.OrganizationID(), member.IdentityID(), payload)
+
+		_, members := test.ListMembersOrganizationOK(t, service.Context, service, controller, org.OrganizationID())
+		for _, m := range members.Data {
+			if m.ID == member.IdentityID().String() {
+				require.Equal(t, []string{authorization.OrganizationViewerRole}, m.Roles)
+			}
+		}
+	})
+
+	rest.T().Run("unknown role", func(t *testing.T) {
+		payload := &app.UpdateMemberRoleOrganizationPayload{Role: "unknown"}
+		test.UpdateMemberRoleOrganizationBadRequest(t, service.Context, service, controller, org.OrganizationID(), member.IdentityID(), payload)
+	})
+}
+
+func (rest *TestOrganizationREST) TestLeaveOrganization() {
+	admin := rest.Graph.CreateUser()
+	org := rest.Graph.CreateOrganization(admin)
+	member := rest.Graph.CreateUser()
+	org.AddMember(member)
+
+	rest.T().Run("ok", func(t *testing.T) {
+		service, controller := rest.SecuredController(*member.Identity())
+		test.LeaveOrganizationNoContent(t, service.Context, service, controller, org.OrganizationID())
+	})
+
+	rest.T().Run("last admin", func(t *testing.T) {
+		service, controller := rest.SecuredController(*admin.Identity())
+		test.LeaveOrganizationConflict(t, service.Context, service, controller, org.OrganizationID())
+	})
+
+	rest.T().Run("unauthorized", func(t *testing.T) {
+		service, controller := rest.UnsecuredController()
+		test.LeaveOrganizationUnauthorized(t, service.Context, service, controller, org.OrganizationID())
+	})
+}
+
+func (rest *TestOrganizationREST) TestUpdateAndDeleteOrganization() {
+	admin := rest.Graph.CreateUser()
+	org := rest.Graph.CreateOrganization(admin)
+	other := rest.Graph.CreateOrganization()
+	service, controller := rest.SecuredController(*admin.Identity())
+
+	payload := &app.UpdateOrganizationPayload{Name: "Renamed Organization " + uuid.NewV4().String()}
+	test.UpdateOrganizationNoContent(rest.T(), service.Context, service, controller, org.OrganizationID(), payload)
+	test.UpdateOrganizationConflict(rest.T(), service.Context, service, controller, org.OrganizationID(), &app.UpdateOrganizationPayload{Name: other.Resource().Name})
+
+	test.DeleteOrganizationNoContent(rest.T(), service.Context, service, controller, org.OrganizationID())
+	test.DeleteOrganizationNotFound(rest.T(), service.Context, service, controller, org.OrganizationID())
+	test.DeleteOrganizationForbidden(rest.T(), service.Context, service, controller, other.OrganizationID())
+}
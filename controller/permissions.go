@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/fabric8-services/fabric8-auth/app"
+	"github.com/fabric8-services/fabric8-auth/application"
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/jsonapi"
+	"github.com/fabric8-services/fabric8-auth/log"
+	"github.com/fabric8-services/fabric8-auth/login"
+	"github.com/fabric8-services/fabric8-auth/token"
+	"github.com/goadesign/goa"
+	"github.com/satori/go.uuid"
+)
+
+// PermissionsController implements the permissions resource.
+type PermissionsController struct {
+	*goa.Controller
+	app application.Application
+}
+
+// NewPermissionsController creates a permissions controller.
+func NewPermissionsController(service *goa.Service, app application.Application) *PermissionsController {
+	return &PermissionsController{Controller: service.NewController("PermissionsController"), app: app}
+}
+
+// Check runs the check action. Service accounts may check the permissions of any identity,
+// while users may only check their own permissions.
+func (c *PermissionsController) Check(ctx *app.CheckPermissionsContext) error {
+	isServiceAccount := token.IsServiceAccount(ctx)
+	var currentIdentity *uuid.UUID
+	if !isServiceAccount {
+		identityID, err := login.ContextIdentity(ctx)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+		}
+		currentIdentity = identityID
+	}
+
+	checks := make([]authorization.PermissionCheck, len(ctx.Payload.Data))
+	for i, check := range ctx.Payload.Data {
+		identityID, err := uuid.FromString(check.IdentityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("identity_id", check.IdentityID).Expected("uuid"))
+		}
+		if currentIdentity != nil && *currentIdentity != identityID {
+			log.Error(ctx, map[string]interface{}{
+				"identity_id":         currentIdentity,
+				"checked_identity_id": identityID,
+			}, "user tried to check the permissions of another identity")
+			return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError(fmt.Sprintf("identity %s is not allowed to check the permissions of identity %s", currentIdentity, identityID)))
+		}
+		checks[i] = authorization.PermissionCheck{
+			IdentityID: identityID,
+			ResourceID: check.ResourceID,
+			ScopeName:  check.Scope,
+		}
+	}
+
+	granted, err := c.app.PermissionService().HasScopes(ctx, checks)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err": err,
+		}, "unable to check permissions")
+		return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(ctx, err))
+	}
+
+	results := make([]*app.PermissionCheckResultData, len(checks))
+	for i, check := range ctx.Payload.Data {
+		results[i] = &app.PermissionCheckResultData{
+			IdentityID: check.IdentityID,
+			ResourceID: check.ResourceID,
+			Scope:      check.Scope,
+			Granted:    granted[i],
+		}
+	}
+	return ctx.OK(&app.PermissionCheckResultArray{Data: results})
+}
+
+// Explain runs the explain action. Only service accounts may explain permissions.
+func (c *PermissionsController) Explain(ctx *app.ExplainPermissionsContext) error {
+	if !token.IsServiceAccount(ctx) {
+		log.Error(ctx, nil, "the account is not a service account")
+		return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("account not authorized to explain permissions"))
+	}
+	identityID, err := uuid.FromString(ctx.IdentityID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("identity_id", ctx.IdentityID).Expected("uuid"))
+	}
+
+	explanation, err := c.app.PermissionService().ExplainScope(ctx, identityID, ctx.ResourceID, ctx.Scope)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"identity_id": identityID,
+			"resource_id": ctx.ResourceID,
+			"scope":       ctx.Scope,
+			"err":         err,
+		}, "unable to explain permission")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	grants := make([]*app.ScopeGrantData, len(explanation.Grants))
+	for i, grant := range explanation.Grants {
+		memberships := make([]*app.MembershipStepData, len(grant.Memberships))
+		for j, membership := range grant.Memberships {
+			resourceTypeName := membership.ResourceTypeName
+			memberships[j] = &app.MembershipStepData{
+				IdentityID: membership.IdentityID.String(),
+				Type:       &resourceTypeName,
+			}
+		}
+		roleMappings := make([]*app.RoleMappingStepData, len(grant.RoleMappings))
+		for j, mapping := range grant.RoleMappings {
+			roleMappings[j] = &app.RoleMappingStepData{
+				RoleMappingID: mapping.RoleMappingID.String(),
+				ResourceID:    mapping.ResourceID,
+				FromRoleName:  mapping.FromRoleName,
+				ToRoleName:    mapping.ToRoleName,
+			}
+		}
+		grants[i] = &app.ScopeGrantData{
+			IdentityRoleID: grant.IdentityRoleID.String(),
+			ResourceID:     grant.ResourceID,
+			RoleName:       grant.RoleName,
+			AssigneeID:     grant.AssigneeID.String(),
+			Memberships:    memberships,
+			RoleMappings:   roleMappings,
+		}
+	}
+
+	return ctx.OK(&app.PermissionExplanation{
+		Data: &app.PermissionExplanationData{
+			IdentityID: explanation.IdentityID.String(),
+			ResourceID: explanation.ResourceID,
+			Scope:      explanation.ScopeName,
+			Granted:    explanation.Granted,
+			Grants:     grants,
+		},
+	})
+}
+
+// ListResources runs the listResources action. Service accounts may list the resources of any identity,
+// while users may only list their own resources.
+func (c *PermissionsController) ListResources(ctx *app.ListResourcesPermissionsContext) error {
+	var identityID uuid.UUID
+	if token.IsServiceAccount(ctx) {
+		if ctx.IdentityID == nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterErrorFromString("identity_id", nil, "the identity is required for service accounts"))
+		}
+	} else {
+		currentIdentity, err := login.ContextIdentity(ctx)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+		}
+		identityID = *currentIdentity
+	}
+	if ctx.IdentityID != nil {
+		id, err := uuid.FromString(*ctx.IdentityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("identity_id", *ctx.IdentityID).Expected("uuid"))
+		}
+		if identityID != uuid.Nil && identityID != id {
+			log.Error(ctx, map[string]interface{}{
+				"identity_id":        identityID,
+				"listed_identity_id": id,
+			}, "user tried to list the resources of another identity")
+			return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError(fmt.Sprintf("identity %s is not allowed to list the resources of identity %s", identityID, id)))
+		}
+		identityID = id
+	}
+
+	offset, limit := computePagingLimits(ctx.PageOffset, ctx.PageLimit)
+	resources, count, err := c.app.PermissionService().ListResourcesWithScope(ctx, identityID, ctx.ResourceType, ctx.Scope, offset, limit)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"identity_id":   identityID,
+			"resource_type": ctx.ResourceType,
+			"scope":         ctx.Scope,
+			"err":           err,
+		}, "unable to list the resources for which the scope has been granted")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	data := make([]*app.PermittedResourceData, len(resources))
+	for i, res := range resources {
+		data[i] = &app.PermittedResourceData{
+			ID:               res.ResourceID,
+			Name:             res.Name,
+			Type:             res.ResourceType.Name,
+			ParentResourceID: res.ParentResourceID,
+		}
+	}
+	response := app.PermittedResourceList{
+		Links: &app.PagingLinks{},
+		Meta:  &app.PermittedResourceListMeta{TotalCount: count},
+		Data:  data,
+	}
+	additionalQuery := []string{
+		"resource_type=" + url.QueryEscape(ctx.ResourceType),
+		"scope=" + url.QueryEscape(ctx.Scope),
+	}
+	if ctx.IdentityID != nil {
+		additionalQuery = append(additionalQuery, "identity_id="+url.QueryEscape(*ctx.IdentityID))
+	}
+	setPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), len(resources), offset, limit, count, additionalQuery...)
+	return ctx.OK(&response)
+}
@@ -0,0 +1,199 @@
+package controller_test
+
+import (
+	"testing"
+
+	account "github.com/fabric8-services/fabric8-auth/account/repository"
+	"github.com/fabric8-services/fabric8-auth/app"
+	"github.com/fabric8-services/fabric8-auth/app/test"
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	. "github.com/fabric8-services/fabric8-auth/controller"
+	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
+	testsupport "github.com/fabric8-services/fabric8-auth/test"
+	"github.com/goadesign/goa"
+	"github.com/satori/go.uuid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestPermissionsRest struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunPermissionsRest(t *testing.T) {
+	suite.Run(t, &TestPermissionsRest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (rest *TestPermissionsRest) SecuredControllerWithIdentity(identity account.Identity) (*goa.Service, *PermissionsController) {
+	svc := testsupport.ServiceAsUser("Permissions-Service", identity)
+	return svc, NewPermissionsController(svc, rest.Application)
+}
+
+func (rest *TestPermissionsRest) SecuredControllerWithServiceAccount() (*goa.Service, *PermissionsController) {
+	svc := testsupport.ServiceAsServiceAccountUser("Permissions-Service", account.Identity{
+		ID:       uuid.NewV4(),
+		Username: "fabric8-wit",
+	})
+	return svc, NewPermissionsController(svc, rest.Application)
+}
+
+func (rest *TestPermissionsRest) UnSecuredController() (*goa.Service, *PermissionsController) {
+	svc := testsupport.UnsecuredService("Permissions-Service")
+	return svc, NewPermissionsController(svc, rest.Application)
+}
+
+func (rest *TestPermissionsRest) TestCheckPermissionsAsServiceAccountOK() {
+	admin := rest.Graph.CreateUser()
+	viewer := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace().AddAdmin(admin).AddViewer(viewer)
+
+	payload := &app.CheckPermissionsPayload{
+		Data: []*app.PermissionCheckData{
+			{IdentityID: admin.IdentityID().String(), ResourceID: space.SpaceID(), Scope: authorization.ManageRoleAssignmentsInSpaceScope},
+			{IdentityID: viewer.IdentityID().String(), ResourceID: space.SpaceID(), Scope: authorization.ManageRoleAssignmentsInSpaceScope},
+			{IdentityID: viewer.IdentityID().String(), ResourceID: space.SpaceID(), Scope: authorization.ViewRoleAssignmentsInSpaceScope},
+		},
+	}
+	svc, ctrl := rest.SecuredControllerWithServiceAccount()
+	_, result := test.CheckPermissionsOK(rest.T(), svc.Context, svc, ctrl, payload)
+	require.Len(rest.T(), result.Data, 3)
+	for i, expected := range []bool{true, false, true} {
+		assert.Equal(rest.T(), payload.Data[i].IdentityID, result.Data[i].IdentityID)
+		assert.Equal(rest.T(), payload.Data[i].ResourceID, result.Data[i].ResourceID)
+		assert.Equal(rest.T(), payload.Data[i].Scope, result.Data[i].Scope)
+		assert.Equal(rest.T(), expected, result.Data[i].Granted)
+	}
+}
+
+func (rest *TestPermissionsRest) TestCheckOwnPermissionsAsUserOK() {
+	viewer := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace().AddViewer(viewer)
+
+	payload := &app.CheckPermissionsPayload{
+		Data: []*app.PermissionCheckData{
+			{IdentityID: viewer.IdentityID().String(), ResourceID: space.SpaceID(), Scope: authorization.ViewRoleAssignmentsInSpaceScope},
+		},
+	}
+	svc, ctrl := rest.SecuredControllerWithIdentity(*viewer.Identity())
+	_, result := test.CheckPermissionsOK(rest.T(), svc.Context, svc, ctrl, payload)
+	require.Len(rest.T(), result.Data, 1)
+	assert.True(rest.T(), result.Data[0].Granted)
+}
+
+func (rest *TestPermissionsRest) TestCheckOtherIdentityPermissionsAsUserForbidden() {
+	user := rest.Graph.CreateUser()
+	viewer := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace().AddViewer(viewer)
+
+	payload := &app.CheckPermissionsPayload{
+		Data: []*app.PermissionCheckData{
+			{IdentityID: user.IdentityID().String(), ResourceID: space.SpaceID(), Scope: authorization.ViewRoleAssignmentsInSpaceScope},
+			{IdentityID: viewer.IdentityID().String(), ResourceID: space.SpaceID(), Scope: authorization.ViewRoleAssignmentsInSpaceScope},
+		},
+	}
+	svc, ctrl := rest.SecuredControllerWithIdentity(*user.Identity())
+	test.CheckPermissionsForbidden(rest.T(), svc.Context, svc, ctrl, payload)
+}
+
+func (rest *TestPermissionsRest) TestCheckPermissionsWithInvalidIdentityIDBadRequest() {
+	space := rest.Graph.CreateSpace()
+	payload := &app.CheckPermissionsPayload{
+		Data: []*app.PermissionCheckData{
+			{IdentityID: "foo", ResourceID: space.SpaceID(), Scope: authorization.ViewRoleAssignmentsInSpaceScope},
+		},
+	}
+	svc, ctrl := rest.SecuredControllerWithServiceAccount()
+	test.CheckPermissionsBadRequest(rest.T(), svc.Context, svc, ctrl, payload)
+}
+
+func (rest *TestPermissionsRest) TestCheckPermissionsUnauthorized() {
+	payload := &app.CheckPermissionsPayload{
+		Data: []*app.PermissionCheckData{},
+	}
+	svc, ctrl := rest.UnSecuredController()
+	test.CheckPermissionsUnauthorized(rest.T(), svc.Context, svc, ctrl, payload)
+}
+
+func (rest *TestPermissionsRest) TestExplainPermissionAsServiceAccountOK() {
+	admin := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace().AddAdmin(admin)
+
+	svc, ctrl := rest.SecuredControllerWithServiceAccount()
+	_, result := test.ExplainPermissionsOK(rest.T(), svc.Context, svc, ctrl, admin.IdentityID().String(), space.SpaceID(), authorization.ManageRoleAssignmentsInSpaceScope)
+	require.NotNil(rest.T(), result.Data)
+	assert.True(rest.T(), result.Data.Granted)
+	require.Len(rest.T(), result.Data.Grants, 1)
+	grant := result.Data.Grants[0]
+	assert.Equal(rest.T(), space.SpaceID(), grant.ResourceID)
+	assert.Equal(rest.T(), authorization.SpaceAdminRole, grant.RoleName)
+	assert.Equal(rest.T(), admin.IdentityID().String(), grant.AssigneeID)
+	assert.Empty(rest.T(), grant.Memberships)
+	assert.Empty(rest.T(), grant.RoleMappings)
+}
+
+func (rest *TestPermissionsRest) TestExplainPermissionNotGrantedOK() {
+	user := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace()
+
+	svc, ctrl := rest.SecuredControllerWithServiceAccount()
+	_, result := test.ExplainPermissionsOK(rest.T(), svc.Context, svc, ctrl, user.IdentityID().String(), space.SpaceID(), authorization.ManageRoleAssignmentsInSpaceScope)
+	assert.False(rest.T(), result.Data.Granted)
+	assert.Empty(rest.T(), result.Data.Grants)
+}
+
+func (rest *TestPermissionsRest) TestExplainPermissionForUnknownResourceNotFound() {
+	user := rest.Graph.CreateUser()
+
+	svc, ctrl := rest.SecuredControllerWithServiceAccount()
+	test.ExplainPermissionsNotFound(rest.T(), svc.Context, svc, ctrl, user.IdentityID().String(), uuid.NewV4().String(), authorization.ManageRoleAssignmentsInSpaceScope)
+}
+
+func (rest *TestPermissionsRest) TestExplainPermissionAsUserForbidden() {
+	admin := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace().AddAdmin(admin)
+
+	svc, ctrl := rest.SecuredControllerWithIdentity(*admin.Identity())
+	test.ExplainPermissionsForbidden(rest.T(), svc.Context, svc, ctrl, admin.IdentityID().String(), space.SpaceID(), authorization.ManageRoleAssignmentsInSpaceScope)
+}
+
+func (rest *TestPermissionsRest) TestListOwnResourcesAsUserOK() {
+	user := rest.Graph.CreateUser()
+	space1 := rest.Graph.CreateSpace().AddContributor(user)
+	space2 := rest.Graph.CreateSpace().AddAdmin(user)
+	rest.Graph.CreateSpace().AddViewer(user)
+
+	svc, ctrl := rest.SecuredControllerWithIdentity(*user.Identity())
+	limit := 1
+	_, result := test.ListResourcesPermissionsOK(rest.T(), svc.Context, svc, ctrl, nil, &limit, nil, authorization.ResourceTypeSpace, "contribute")
+	require.Len(rest.T(), result.Data, 1)
+	assert.Equal(rest.T(), 2, result.Meta.TotalCount)
+	assert.Contains(rest.T(), []string{space1.SpaceID(), space2.SpaceID()}, result.Data[0].ID)
+	require.NotNil(rest.T(), result.Links.Next)
+	assert.Contains(rest.T(), *result.Links.Next, "resource_type=")
+}
+
+func (rest *TestPermissionsRest) TestListResourcesAsServiceAccountOK() {
+	user := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace().AddContributor(user)
+
+	svc, ctrl := rest.SecuredControllerWithServiceAccount()
+	identityID := user.IdentityID().String()
+	_, result := test.ListResourcesPermissionsOK(rest.T(), svc.Context, svc, ctrl, &identityID, nil, nil, authorization.ResourceTypeSpace, "contribute")
+	require.Len(rest.T(), result.Data, 1)
+	assert.Equal(rest.T(), space.SpaceID(), result.Data[0].ID)
+
+	test.ListResourcesPermissionsBadRequest(rest.T(), svc.Context, svc, ctrl, nil, nil, nil, authorization.ResourceTypeSpace, "contribute")
+}
+
+func (rest *TestPermissionsRest) TestListOtherIdentityResourcesAsUserForbidden() {
+	svc, ctrl := rest.SecuredControllerWithIdentity(*rest.Graph.CreateUser().Identity())
+	otherID := rest.Graph.CreateUser().IdentityID().String()
+	test.ListResourcesPermissionsForbidden(rest.T(), svc.Context, svc, ctrl, &otherID, nil, nil, authorization.ResourceTypeSpace, "contribute")
+}
+
+func (rest *TestPermissionsRest) TestListResourcesWithUnknownScopeBadRequest() {
+	svc, ctrl := rest.SecuredControllerWithIdentity(*rest.Graph.CreateUser().Identity())
+	test.ListResourcesPermissionsBadRequest(rest.T(), svc.Context, svc, ctrl, nil, nil, nil, authorization.ResourceTypeSpace, "unknown")
+}
@@ -1,11 +1,15 @@
 package controller
 
 import (
+	"net/url"
+	"time"
+
 	"github.com/fabric8-services/fabric8-auth/app"
 	"github.com/fabric8-services/fabric8-auth/application"
 	"github.com/fabric8-services/fabric8-auth/errors"
 	"github.com/fabric8-services/fabric8-auth/jsonapi"
 	"github.com/fabric8-services/fabric8-auth/log"
+	"github.com/fabric8-services/fabric8-auth/login"
 	"github.com/fabric8-services/fabric8-auth/token"
 
 	"github.com/goadesign/goa"
@@ -42,6 +46,58 @@ func (c *ResourceController) Delete(ctx *app.DeleteResourceContext) error {
 	return ctx.NoContent()
 }
 
+// List runs the list action.
+func (c *ResourceController) List(ctx *app.ListResourceContext) error {
+	if !token.IsServiceAccount(ctx) {
+		log.Error(ctx, map[string]interface{}{}, "Unable to list resources. Not a service account")
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError("not a service account"))
+	}
+
+	offset, limit := computePagingLimits(ctx.PageOffset, ctx.PageLimit)
+	resources, count, err := c.app.ResourceService().List(ctx, ctx.Type, ctx.ParentResourceID, ctx.NamePrefix, ctx.CreatedAfter, ctx.CreatedBefore, offset, limit)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_type": ctx.Type,
+			"err":           err,
+		}, "unable to list resources")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	data := make([]*app.ResourceData, len(resources))
+	for i, res := range resources {
+		data[i] = &app.ResourceData{
+			ID:               res.ResourceID,
+			Name:             res.Name,
+			Type:             res.ResourceType.Name,
+			ParentResourceID: res.ParentResourceID,
+			CreatedAt:        res.CreatedAt,
+		}
+	}
+	response := app.ResourceList{
+		Links: &app.PagingLinks{},
+		Meta:  &app.ResourceListMeta{TotalCount: count},
+		Data:  data,
+	}
+	var additionalQuery []string
+	if ctx.Type != nil {
+		additionalQuery = append(additionalQuery, "type="+url.QueryEscape(*ctx.Type))
+	}
+	if ctx.ParentResourceID != nil {
+		additionalQuery = append(additionalQuery, "parent_resource_id="+url.QueryEscape(*ctx.ParentResourceID))
+	}
+	if ctx.NamePrefix != nil {
+		additionalQuery = append(additionalQuery, "name_prefix="+url.QueryEscape(*ctx.NamePrefix))
+	}
+	if ctx.CreatedAfter != nil {
+		additionalQuery = append(additionalQuery, "created_after="+url.QueryEscape(ctx.CreatedAfter.Format(time.RFC3339Nano)))
+	}
+	if ctx.CreatedBefore != nil {
+		additionalQuery = append(additionalQuery, "created_before="+url.QueryEscape(ctx.CreatedBefore.Format(time.RFC3339Nano)))
+	}
+	setPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), len(resources), offset, limit, count, additionalQuery...)
+	return ctx.OK(&response)
+}
+
 // Read runs the read action.
 func (c *ResourceController) Read(ctx *app.ReadResourceContext) error {
 
@@ -81,3 +137,47 @@ func (c *ResourceController) Register(ctx *app.RegisterResourceContext) error {
 
 	return ctx.Created(&app.RegisterResourceResponse{ResourceID: &res.ResourceID})
 }
+
+// Move runs the move action.
+func (c *ResourceController) Move(ctx *app.MoveResourceContext) error {
+	if !token.IsServiceAccount(ctx) {
+		log.Error(ctx, map[string]interface{}{}, "Unable to move resource. Not a service account")
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError("not a service account"))
+	}
+
+	err := c.app.ResourceService().Move(ctx, ctx.ResourceID, ctx.Payload.ParentResourceID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_id":        ctx.ResourceID,
+			"parent_resource_id": ctx.Payload.ParentResourceID,
+			"err":                err,
+		}, "unable to move resource")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+// TransferOwnership runs the transferOwnership action.
+func (c *ResourceController) TransferOwnership(ctx *app.TransferOwnershipResourceContext) error {
+	currentIdentity, err := login.ContextIdentity(ctx)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_id": ctx.ResourceID,
+		}, "error getting identity information from token")
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	err = c.app.ResourceService().TransferOwnership(ctx, *currentIdentity, ctx.ResourceID, ctx.Payload.FromIdentityID, ctx.Payload.ToIdentityID, ctx.Payload.IncludeDescendants)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_id":      ctx.ResourceID,
+			"from_identity_id": ctx.Payload.FromIdentityID,
+			"to_identity_id":   ctx.Payload.ToIdentityID,
+			"err":              err,
+		}, "unable to transfer the ownership of the resource")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
@@ -1,6 +1,7 @@
 package controller_test
 
 import (
+	"fmt"
 	"testing"
 
 	account "github.com/fabric8-services/fabric8-auth/account/repository"
@@ -192,3 +193,125 @@ func (rest *TestResourceREST) TestDeleteResource() {
 
 	test.ReadResourceNotFound(rest.T(), rest.service.Context, rest.service, rest.securedController, *created.ResourceID)
 }
+
+func (rest *TestResourceREST) TestTransferOwnershipOK() {
+	owner := rest.Graph.CreateUser()
+	newOwner := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace().AddAdmin(owner)
+
+	svc := testsupport.ServiceAsUser("Resource-Service", *owner.Identity())
+	ctrl := NewResourceController(svc, rest.Application)
+	test.TransferOwnershipResourceNoContent(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), &app.TransferOwnershipResourcePayload{
+		FromIdentityID: owner.IdentityID(),
+		ToIdentityID:   newOwner.IdentityID(),
+	})
+
+	roles, err := rest.Application.IdentityRoleRepository().FindIdentityRolesByIdentityAndResource(rest.Ctx, space.SpaceID(), newOwner.IdentityID())
+	require.NoError(rest.T(), err)
+	require.Len(rest.T(), roles, 1)
+}
+
+func (rest *TestResourceREST) TestTransferOwnershipForbidden() {
+	owner := rest.Graph.CreateUser()
+	contributor := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace().AddAdmin(owner).AddContributor(contributor)
+
+	svc := testsupport.ServiceAsUser("Resource-Service", *contributor.Identity())
+	ctrl := NewResourceController(svc, rest.Application)
+	test.TransferOwnershipResourceForbidden(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), &app.TransferOwnershipResourcePayload{
+		FromIdentityID: owner.IdentityID(),
+		ToIdentityID:   contributor.IdentityID(),
+	})
+}
+
+func (rest *TestResourceREST) TestTransferOwnershipBadRequest() {
+	owner := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace().AddAdmin(owner)
+
+	svc := testsupport.ServiceAsUser("Resource-Service", *owner.Identity())
+	ctrl := NewResourceController(svc, rest.Application)
+	test.TransferOwnershipResourceBadRequest(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), &app.TransferOwnershipResourcePayload{
+		FromIdentityID: owner.IdentityID(),
+		ToIdentityID:   owner.IdentityID(),
+	})
+}
+
+func (rest *TestResourceREST) TestMoveResource() {
+	space := rest.Graph.CreateSpace(rest.Graph.CreateOrganization())
+	org := rest.Graph.CreateOrganization()
+	orgID := org.ResourceID()
+
+	test.MoveResourceNoContent(rest.T(), rest.service.Context, rest.service, rest.securedController, space.SpaceID(), &app.MoveResourcePayload{
+		ParentResourceID: &orgID,
+	})
+	_, readResource := test.ReadResourceOK(rest.T(), rest.service.Context, rest.service, rest.securedController, space.SpaceID())
+	require.NotNil(rest.T(), readResource.ParentResourceID)
+	assert.Equal(rest.T(), org.ResourceID(), *readResource.ParentResourceID)
+
+	// A space may become a top level resource again
+	test.MoveResourceNoContent(rest.T(), rest.service.Context, rest.service, rest.securedController, space.SpaceID(), &app.MoveResourcePayload{})
+	_, readResource = test.ReadResourceOK(rest.T(), rest.service.Context, rest.service, rest.securedController, space.SpaceID())
+	assert.Nil(rest.T(), readResource.ParentResourceID)
+
+	// A space may not belong to another space
+	otherSpaceID := rest.Graph.CreateSpace().SpaceID()
+	test.MoveResourceBadRequest(rest.T(), rest.service.Context, rest.service, rest.securedController, space.SpaceID(), &app.MoveResourcePayload{
+		ParentResourceID: &otherSpaceID,
+	})
+	test.MoveResourceNotFound(rest.T(), rest.service.Context, rest.service, rest.securedController, uuid.NewV4().String(), &app.MoveResourcePayload{
+		ParentResourceID: &orgID,
+	})
+
+	svc, ctrl := rest.SecuredController(account.Identity{Username: "unknown-account"})
+	test.MoveResourceUnauthorized(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), &app.MoveResourcePayload{
+		ParentResourceID: &orgID,
+	})
+}
+
+func (rest *TestResourceREST) TestListResources() {
+	rt := rest.Graph.CreateResourceType()
+	parent := rest.Graph.CreateResource(rt)
+	var children []string
+	for i := 0; i < 3; i++ {
+		children = append(children, rest.Graph.CreateResource(rt, parent, fmt.Sprintf("child-%d-%s", i, uuid.NewV4())).ResourceID())
+	}
+	typeName := rt.ResourceType().Name
+	parentID := parent.ResourceID()
+	namePrefix := "child-"
+
+	rest.T().Run("ok", func(t *testing.T) {
+		limit := 2
+		_, list := test.ListResourceOK(t, rest.service.Context, rest.service, rest.securedController, nil, nil, &namePrefix, &limit, nil, &parentID, &typeName)
+		assert.Equal(t, 3, list.Meta.TotalCount)
+		require.Len(t, list.Data, 2)
+		assert.Equal(t, children[0], list.Data[0].ID)
+		assert.Equal(t, children[1], list.Data[1].ID)
+		assert.Equal(t, typeName, list.Data[0].Type)
+		require.NotNil(t, list.Data[0].ParentResourceID)
+		assert.Equal(t, parentID, *list.Data[0].ParentResourceID)
+		require.NotNil(t, list.Links.Next)
+		assert.Contains(t, *list.Links.Next, "page[offset]=2&page[limit]=2")
+		assert.Contains(t, *list.Links.Next, "parent_resource_id="+parentID)
+		assert.Contains(t, *list.Links.Next, "name_prefix=child-")
+		assert.Nil(t, list.Links.Prev)
+
+		offset := "2"
+		_, list = test.ListResourceOK(t, rest.service.Context, rest.service, rest.securedController, nil, nil, &namePrefix, &limit, &offset, &parentID, &typeName)
+		assert.Equal(t, 3, list.Meta.TotalCount)
+		require.Len(t, list.Data, 1)
+		assert.Equal(t, children[2], list.Data[0].ID)
+		assert.Nil(t, list.Links.Next)
+		require.NotNil(t, list.Links.Prev)
+		assert.Contains(t, *list.Links.Prev, "page[offset]=0&page[limit]=2")
+	})
+
+	rest.T().Run("unknown resource type", func(t *testing.T) {
+		unknownType := "unknown-" + uuid.NewV4().String()
+		test.ListResourceBadRequest(t, rest.service.Context, rest.service, rest.securedController, nil, nil, nil, nil, nil, nil, &unknownType)
+	})
+
+	rest.T().Run("not a service account", func(t *testing.T) {
+		svc, ctrl := rest.SecuredController(account.Identity{Username: "unknown-account"})
+		test.ListResourceUnauthorized(t, svc.Context, svc, ctrl, nil, nil, nil, nil, nil, &parentID, &typeName)
+	})
+}
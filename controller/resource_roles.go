@@ -1,6 +1,9 @@
 package controller
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/fabric8-services/fabric8-auth/app"
 	"github.com/fabric8-services/fabric8-auth/application"
 	role "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
@@ -91,7 +94,14 @@ func (c *ResourceRolesController) AssignRole(ctx *app.AssignRoleResourceRolesCon
 	}
 
 	roleAssignments := make(map[string][]uuid.UUID)
+	roleExpiries := make(map[string]time.Time)
 	for _, assignment := range ctx.Payload.Data {
+		if assignment.ExpiresAt != nil {
+			if expiresAt, found := roleExpiries[assignment.Role]; found && !expiresAt.Equal(*assignment.ExpiresAt) {
+				return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterErrorFromString("expires_at", *assignment.ExpiresAt, fmt.Sprintf("conflicting expiries for role %s", assignment.Role)))
+			}
+			roleExpiries[assignment.Role] = *assignment.ExpiresAt
+		}
 		for _, id := range assignment.Ids {
 
 			identityIDAsUUID, err := uuid.FromString(id)
@@ -110,8 +120,44 @@ func (c *ResourceRolesController) AssignRole(ctx *app.AssignRoleResourceRolesCon
 			}
 		}
 	}
-	err = c.app.RoleManagementService().Assign(ctx, *currentIdentity, roleAssignments, ctx.ResourceID, false)
+	err = c.app.RoleManagementService().Assign(ctx, *currentIdentity, roleAssignments, roleExpiries, ctx.ResourceID, false)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// RevokeRole revokes a specific role for a resource, from one or more identities.
+func (c *ResourceRolesController) RevokeRole(ctx *app.RevokeRoleResourceRolesContext) error {
+	currentIdentity, err := login.ContextIdentity(ctx)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_id": ctx.ResourceID,
+		}, "error getting identity information from token")
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	var identityIDs []uuid.UUID
+	for _, id := range ctx.Payload.Ids {
+		identityIDAsUUID, err := uuid.FromString(id)
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"resource_id": ctx.ResourceID,
+				"identity_id": id,
+				"role":        ctx.RoleName,
+			}, "invalid identity ID")
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("ids", id).Expected("uuid"))
+		}
+		identityIDs = append(identityIDs, identityIDAsUUID)
+	}
+
+	err = c.app.RoleManagementService().RevokeResourceRole(ctx, *currentIdentity, identityIDs, ctx.ResourceID, ctx.RoleName)
 	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_id": ctx.ResourceID,
+			"role_name":   ctx.RoleName,
+			"err":         err,
+		}, "error revoking a role for a specific resource")
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
 	return ctx.NoContent()
@@ -132,6 +178,7 @@ func convertIdentityRoleToAppRole(r role.IdentityRole) *app.IdentityRolesData {
 		AssigneeType: "user", // will change for teams/orgs/groups
 		Inherited:    inherited,
 		RoleName:     r.Role.Name,
+		ExpiresAt:    r.ExpiresAt,
 	}
 	if inherited {
 		rolesData.InheritedFrom = r.Resource.ParentResourceID
@@ -2,6 +2,7 @@ package controller_test
 
 import (
 	"testing"
+	"time"
 
 	account "github.com/fabric8-services/fabric8-auth/account/repository"
 	"github.com/fabric8-services/fabric8-auth/app"
@@ -124,6 +125,36 @@ func (rest *TestResourceRolesRest) TestAssignRoleOK() {
 	test.AssignRoleResourceRolesNoContent(rest.T(), svc.Context, svc, ctrl, res.SpaceID(), payload)
 }
 
+func (rest *TestResourceRolesRest) TestAssignRoleWithExpiryOK() {
+	res := rest.Graph.CreateSpace()
+	testUser := rest.Graph.CreateUser()
+	res.AddViewer(testUser)
+	adminUser := rest.Graph.CreateUser()
+	res.AddAdmin(adminUser)
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	svc, ctrl := rest.SecuredControllerWithIdentity(*adminUser.Identity())
+	payload := &app.AssignRoleResourceRolesPayload{
+		Data: []*app.AssignRoleData{
+			{
+				Role:      authorization.SpaceContributorRole,
+				Ids:       []string{testUser.Identity().ID.String()},
+				ExpiresAt: &expiresAt,
+			},
+		},
+	}
+	test.AssignRoleResourceRolesNoContent(rest.T(), svc.Context, svc, ctrl, res.SpaceID(), payload)
+
+	_, roles := test.ListAssignedByRoleNameResourceRolesOK(rest.T(), svc.Context, svc, ctrl, res.SpaceID(), authorization.SpaceContributorRole)
+	require.Len(rest.T(), roles.Data, 1)
+	require.NotNil(rest.T(), roles.Data[0].ExpiresAt)
+	require.True(rest.T(), expiresAt.Equal(*roles.Data[0].ExpiresAt))
+
+	// An expiry in the past is rejected
+	expiresAt = time.Now().Add(-time.Hour)
+	test.AssignRoleResourceRolesBadRequest(rest.T(), svc.Context, svc, ctrl, res.SpaceID(), payload)
+}
+
 func (rest *TestResourceRolesRest) TestAssignRoleConflict() {
 
 	g := rest.DBTestSuite.NewTestGraph()
@@ -252,6 +283,82 @@ func (rest *TestResourceRolesRest) TestAssignRoleWithIncompleteTokenClaims() {
 	test.AssignRoleResourceRolesUnauthorized(rest.T(), svc.Context, svc, ctrl, res.SpaceID(), payload)
 }
 
+func (rest *TestResourceRolesRest) TestRevokeRoleOK() {
+	g := rest.DBTestSuite.NewTestGraph()
+	res := g.CreateSpace()
+
+	testUser := g.CreateUser()
+	res.AddViewer(testUser).AddContributor(testUser)
+
+	adminUser := g.CreateUser()
+	res.AddAdmin(adminUser)
+
+	svc, ctrl := rest.SecuredControllerWithIdentity(*adminUser.Identity())
+	payload := &app.RevokeRoleResourceRolesPayload{
+		Ids: []string{testUser.Identity().ID.String()},
+	}
+	test.RevokeRoleResourceRolesNoContent(rest.T(), svc.Context, svc, ctrl, res.SpaceID(), authorization.SpaceContributorRole, payload)
+
+	// Only the contributor role has been revoked
+	_, returnedIdentityRoles := test.ListAssignedResourceRolesOK(rest.T(), svc.Context, svc, ctrl, res.SpaceID())
+	require.Len(rest.T(), returnedIdentityRoles.Data, 2)
+	rest.checkExists([]uuid.UUID{adminUser.IdentityID(), testUser.IdentityID()}, []string{"admin", "viewer"}, returnedIdentityRoles)
+
+	// The role cannot be revoked twice
+	test.RevokeRoleResourceRolesNotFound(rest.T(), svc.Context, svc, ctrl, res.SpaceID(), authorization.SpaceContributorRole, payload)
+}
+
+func (rest *TestResourceRolesRest) TestRevokeRoleOfLastAdminConflict() {
+	g := rest.DBTestSuite.NewTestGraph()
+	res := g.CreateSpace()
+
+	adminUser := g.CreateUser()
+	otherAdmin := g.CreateUser()
+	res.AddAdmin(adminUser).AddAdmin(otherAdmin)
+
+	svc, ctrl := rest.SecuredControllerWithIdentity(*adminUser.Identity())
+	test.RevokeRoleResourceRolesNoContent(rest.T(), svc.Context, svc, ctrl, res.SpaceID(), authorization.SpaceAdminRole, &app.RevokeRoleResourceRolesPayload{
+		Ids: []string{otherAdmin.Identity().ID.String()},
+	})
+	test.RevokeRoleResourceRolesConflict(rest.T(), svc.Context, svc, ctrl, res.SpaceID(), authorization.SpaceAdminRole, &app.RevokeRoleResourceRolesPayload{
+		Ids: []string{adminUser.Identity().ID.String()},
+	})
+}
+
+func (rest *TestResourceRolesRest) TestRevokeRoleForbiddenNotAllowedToRevokeRoles() {
+	g := rest.DBTestSuite.NewTestGraph()
+	res := g.CreateSpace()
+
+	testUser := g.CreateUser()
+	res.AddViewer(testUser)
+	contributor := g.CreateUser()
+	res.AddContributor(contributor)
+
+	svc, ctrl := rest.SecuredControllerWithIdentity(*contributor.Identity())
+	test.RevokeRoleResourceRolesForbidden(rest.T(), svc.Context, svc, ctrl, res.SpaceID(), authorization.SpaceViewerRole, &app.RevokeRoleResourceRolesPayload{
+		Ids: []string{testUser.Identity().ID.String()},
+	})
+}
+
+func (rest *TestResourceRolesRest) TestRevokeRoleWithInvalidIdentityIDBadRequest() {
+	g := rest.DBTestSuite.NewTestGraph()
+	res := g.CreateSpace()
+	adminUser := g.CreateUser()
+	res.AddAdmin(adminUser)
+
+	svc, ctrl := rest.SecuredControllerWithIdentity(*adminUser.Identity())
+	test.RevokeRoleResourceRolesBadRequest(rest.T(), svc.Context, svc, ctrl, res.SpaceID(), authorization.SpaceViewerRole, &app.RevokeRoleResourceRolesPayload{
+		Ids: []string{uuid.NewV4().String() + "#$%"},
+	})
+}
+
+func (rest *TestResourceRolesRest) TestRevokeRoleUnauthorized() {
+	svc, ctrl := rest.UnSecuredController()
+	test.RevokeRoleResourceRolesUnauthorized(rest.T(), rest.Ctx, svc, ctrl, uuid.NewV4().String(), authorization.SpaceViewerRole, &app.RevokeRoleResourceRolesPayload{
+		Ids: []string{},
+	})
+}
+
 func (rest *TestResourceRolesRest) checkExists(identities []uuid.UUID, roleNames []string, pool *app.Identityroles) {
 	for _, retrievedRole := range pool.Data {
 		var foundUser bool
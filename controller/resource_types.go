@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/fabric8-services/fabric8-auth/app"
+	"github.com/fabric8-services/fabric8-auth/application"
+	resourcetype "github.com/fabric8-services/fabric8-auth/authorization/resourcetype/repository"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/jsonapi"
+	"github.com/fabric8-services/fabric8-auth/log"
+	"github.com/fabric8-services/fabric8-auth/token"
+
+	"github.com/goadesign/goa"
+)
+
+// ResourceTypesController implements the resource_types resource.
+type ResourceTypesController struct {
+	*goa.Controller
+	app application.Application
+}
+
+// NewResourceTypesController creates a resource_types controller.
+func NewResourceTypesController(service *goa.Service, app application.Application) *ResourceTypesController {
+	return &ResourceTypesController{
+		Controller: service.NewController("ResourceTypesController"),
+		app:        app,
+	}
+}
+
+// checkAuthAdmin returns a forbidden error if the request was not made by the auth admin service account
+func checkAuthAdmin(ctx context.Context) error {
+	if !token.IsSpecificServiceAccount(ctx, token.AuthAdmin) {
+		log.Error(ctx, nil, "the account is not the auth admin service account")
+		return errors.NewForbiddenError("account not authorized to perform this operation")
+	}
+	return nil
+}
+
+// List runs the list action.
+func (c *ResourceTypesController) List(ctx *app.ListResourceTypesContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	resourceTypes, err := c.app.ResourceTypeService().List(ctx)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err": err,
+		}, "unable to list the resource types")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	data := make([]*app.ResourceTypeData, len(resourceTypes))
+	for i, rt := range resourceTypes {
+		data[i], err = c.convertResourceType(ctx, rt)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+	}
+	return ctx.OK(&app.ResourceTypeArray{Data: data})
+}
+
+// Show runs the show action.
+func (c *ResourceTypesController) Show(ctx *app.ShowResourceTypesContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	rt, err := c.app.ResourceTypeService().Load(ctx, ctx.ResourceTypeID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	data, err := c.convertResourceType(ctx, *rt)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.ResourceTypeSingle{Data: data})
+}
+
+// Create runs the create action.
+func (c *ResourceTypesController) Create(ctx *app.CreateResourceTypesContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	rt, err := c.app.ResourceTypeService().Create(ctx, ctx.Payload.Name, ctx.Payload.Scopes)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"name": ctx.Payload.Name,
+			"err":  err,
+		}, "unable to register the resource type")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	data, err := c.convertResourceType(ctx, *rt)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.Created(&app.ResourceTypeSingle{Data: data})
+}
+
+// Delete runs the delete action.
+func (c *ResourceTypesController) Delete(ctx *app.DeleteResourceTypesContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	err := c.app.ResourceTypeService().Delete(ctx, ctx.ResourceTypeID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_type_id": ctx.ResourceTypeID,
+			"err":              err,
+		}, "unable to delete the resource type")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// AddScope runs the addScope action.
+func (c *ResourceTypesController) AddScope(ctx *app.AddScopeResourceTypesContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	_, err := c.app.ResourceTypeService().AddScope(ctx, ctx.ResourceTypeID, ctx.Payload.Name)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_type_id": ctx.ResourceTypeID,
+			"scope_name":       ctx.Payload.Name,
+			"err":              err,
+		}, "unable to add the scope to the resource type")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// DeleteScope runs the deleteScope action.
+func (c *ResourceTypesController) DeleteScope(ctx *app.DeleteScopeResourceTypesContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	err := c.app.ResourceTypeService().DeleteScope(ctx, ctx.ResourceTypeID, ctx.ScopeName)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_type_id": ctx.ResourceTypeID,
+			"scope_name":       ctx.ScopeName,
+			"err":              err,
+		}, "unable to delete the scope of the resource type")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// CreateRole runs the createRole action.
+func (c *ResourceTypesController) CreateRole(ctx *app.CreateRoleResourceTypesContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	_, err := c.app.ResourceTypeService().CreateRole(ctx, ctx.ResourceTypeID, ctx.Payload.Name, ctx.Payload.Scopes)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_type_id": ctx.ResourceTypeID,
+			"role_name":        ctx.Payload.Name,
+			"err":              err,
+		}, "unable to create the role of the resource type")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// DeleteRole runs the deleteRole action.
+func (c *ResourceTypesController) DeleteRole(ctx *app.DeleteRoleResourceTypesContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	err := c.app.ResourceTypeService().DeleteRole(ctx, ctx.ResourceTypeID, ctx.RoleName)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_type_id": ctx.ResourceTypeID,
+			"role_name":        ctx.RoleName,
+			"err":              err,
+		}, "unable to delete the role of the resource type")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// AddRoleScope runs the addRoleScope action.
+func (c *ResourceTypesController) AddRoleScope(ctx *app.AddRoleScopeResourceTypesContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	err := c.app.ResourceTypeService().AddRoleScope(ctx, ctx.ResourceTypeID, ctx.RoleName, ctx.ScopeName)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_type_id": ctx.ResourceTypeID,
+			"role_name":        ctx.RoleName,
+			"scope_name":       ctx.ScopeName,
+			"err":              err,
+		}, "unable to add the scope to the role")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// RemoveRoleScope runs the removeRoleScope action.
+func (c *ResourceTypesController) RemoveRoleScope(ctx *app.RemoveRoleScopeResourceTypesContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	err := c.app.ResourceTypeService().RemoveRoleScope(ctx, ctx.ResourceTypeID, ctx.RoleName, ctx.ScopeName)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_type_id": ctx.ResourceTypeID,
+			"role_name":        ctx.RoleName,
+			"scope_name":       ctx.ScopeName,
+			"err":              err,
+		}, "unable to remove the scope from the role")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// convertResourceType converts the resource type to its REST representation, including its scopes and roles
+func (c *ResourceTypesController) convertResourceType(ctx context.Context, rt resourcetype.ResourceType) (*app.ResourceTypeData, error) {
+	scopes, err := c.app.ResourceTypeService().ListScopes(ctx, rt.ResourceTypeID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := c.app.ResourceTypeService().ListRoles(ctx, rt.ResourceTypeID)
+	if err != nil {
+		return nil, err
+	}
+	scopeNames := make([]string, len(scopes))
+	for i, scope := range scopes {
+		scopeNames[i] = scope.Name
+	}
+	rolesData := []*app.RolesData{}
+	for _, r := range roles {
+		roleData := convertRoleScopeToAppRole(r)
+		roleData.ResourceType = rt.Name
+		if roleData.Scope == nil {
+			roleData.Scope = []string{}
+		}
+		rolesData = append(rolesData, roleData)
+	}
+	return &app.ResourceTypeData{
+		ID:     rt.ResourceTypeID.String(),
+		Name:   rt.Name,
+		Scopes: scopeNames,
+		Roles:  rolesData,
+	}, nil
+}
@@ -0,0 +1,114 @@
+package controller_test
+
+import (
+	"testing"
+
+	account "github.com/fabric8-services/fabric8-auth/account/repository"
+	"github.com/fabric8-services/fabric8-auth/app"
+	"github.com/fabric8-services/fabric8-auth/app/test"
+	. "github.com/fabric8-services/fabric8-auth/controller"
+	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
+	testsupport "github.com/fabric8-services/fabric8-auth/test"
+	"github.com/fabric8-services/fabric8-auth/token"
+
+	"github.com/goadesign/goa"
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestResourceTypesRest struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunResourceTypesRest(t *testing.T) {
+	suite.Run(t, &TestResourceTypesRest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (rest *TestResourceTypesRest) SecuredControllerWithServiceAccount(name string) (*goa.Service, *ResourceTypesController) {
+	svc := testsupport.ServiceAsServiceAccountUser("ResourceTypes-Service", account.Identity{
+		ID:       uuid.NewV4(),
+		Username: name,
+	})
+	return svc, NewResourceTypesController(svc, rest.Application)
+}
+
+func (rest *TestResourceTypesRest) SecuredControllerWithIdentity(identity account.Identity) (*goa.Service, *ResourceTypesController) {
+	svc := testsupport.ServiceAsUser("ResourceTypes-Service", identity)
+	return svc, NewResourceTypesController(svc, rest.Application)
+}
+
+func (rest *TestResourceTypesRest) TestManageResourceTypeOK() {
+	svc, ctrl := rest.SecuredControllerWithServiceAccount(token.AuthAdmin)
+	name := "test.resourcetype/" + uuid.NewV4().String()
+
+	_, created := test.CreateResourceTypesCreated(rest.T(), svc.Context, svc, ctrl, &app.CreateResourceTypesPayload{
+		Name:   name,
+		Scopes: []string{"view"},
+	})
+	require.NotNil(rest.T(), created.Data)
+	assert.Equal(rest.T(), name, created.Data.Name)
+	assert.Equal(rest.T(), []string{"view"}, created.Data.Scopes)
+	assert.Empty(rest.T(), created.Data.Roles)
+	resourceTypeID, err := uuid.FromString(created.Data.ID)
+	require.NoError(rest.T(), err)
+
+	test.AddScopeResourceTypesNoContent(rest.T(), svc.Context, svc, ctrl, resourceTypeID, &app.AddScopeResourceTypesPayload{Name: "edit"})
+	test.AddScopeResourceTypesConflict(rest.T(), svc.Context, svc, ctrl, resourceTypeID, &app.AddScopeResourceTypesPayload{Name: "edit"})
+	test.CreateRoleResourceTypesNoContent(rest.T(), svc.Context, svc, ctrl, resourceTypeID, &app.CreateRoleResourceTypesPayload{
+		Name:   "editor",
+		Scopes: []string{"view"},
+	})
+	test.AddRoleScopeResourceTypesNoContent(rest.T(), svc.Context, svc, ctrl, resourceTypeID, "editor", "edit")
+
+	_, shown := test.ShowResourceTypesOK(rest.T(), svc.Context, svc, ctrl, resourceTypeID)
+	assert.Equal(rest.T(), name, shown.Data.Name)
+	assert.Equal(rest.T(), []string{"edit", "view"}, shown.Data.Scopes)
+	require.Len(rest.T(), shown.Data.Roles, 1)
+	assert.Equal(rest.T(), "editor", shown.Data.Roles[0].RoleName)
+	assert.Equal(rest.T(), name, shown.Data.Roles[0].ResourceType)
+	assert.ElementsMatch(rest.T(), []string{"view", "edit"}, shown.Data.Roles[0].Scope)
+
+	_, list := test.ListResourceTypesOK(rest.T(), svc.Context, svc, ctrl)
+	found := false
+	for _, rt := range list.Data {
+		if rt.ID == created.Data.ID {
+			found = true
+		}
+	}
+	assert.True(rest.T(), found)
+
+	test.RemoveRoleScopeResourceTypesNoContent(rest.T(), svc.Context, svc, ctrl, resourceTypeID, "editor", "edit")
+	test.DeleteScopeResourceTypesNoContent(rest.T(), svc.Context, svc, ctrl, resourceTypeID, "edit")
+	test.DeleteScopeResourceTypesNotFound(rest.T(), svc.Context, svc, ctrl, resourceTypeID, "edit")
+	test.DeleteRoleResourceTypesNoContent(rest.T(), svc.Context, svc, ctrl, resourceTypeID, "editor")
+	test.DeleteResourceTypesNoContent(rest.T(), svc.Context, svc, ctrl, resourceTypeID)
+	test.ShowResourceTypesNotFound(rest.T(), svc.Context, svc, ctrl, resourceTypeID)
+}
+
+func (rest *TestResourceTypesRest) TestCreateDuplicateResourceTypeConflict() {
+	svc, ctrl := rest.SecuredControllerWithServiceAccount(token.AuthAdmin)
+	payload := &app.CreateResourceTypesPayload{Name: "test.resourcetype/" + uuid.NewV4().String()}
+	test.CreateResourceTypesCreated(rest.T(), svc.Context, svc, ctrl, payload)
+	test.CreateResourceTypesConflict(rest.T(), svc.Context, svc, ctrl, payload)
+}
+
+func (rest *TestResourceTypesRest) TestDeleteResourceTypeInUseConflict() {
+	svc, ctrl := rest.SecuredControllerWithServiceAccount(token.AuthAdmin)
+	space := rest.Graph.CreateSpace()
+	test.DeleteResourceTypesConflict(rest.T(), svc.Context, svc, ctrl, space.Resource().ResourceTypeID)
+}
+
+func (rest *TestResourceTypesRest) TestManageResourceTypeAsOtherServiceAccountForbidden() {
+	svc, ctrl := rest.SecuredControllerWithServiceAccount(token.WIT)
+	test.ListResourceTypesForbidden(rest.T(), svc.Context, svc, ctrl)
+	test.CreateResourceTypesForbidden(rest.T(), svc.Context, svc, ctrl, &app.CreateResourceTypesPayload{Name: "test.resourcetype/" + uuid.NewV4().String()})
+}
+
+func (rest *TestResourceTypesRest) TestManageResourceTypeAsUserForbidden() {
+	user := rest.Graph.CreateUser()
+	svc, ctrl := rest.SecuredControllerWithIdentity(*user.Identity())
+	test.ListResourceTypesForbidden(rest.T(), svc.Context, svc, ctrl)
+	test.DeleteResourceTypesForbidden(rest.T(), svc.Context, svc, ctrl, uuid.NewV4())
+}
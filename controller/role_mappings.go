@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"github.com/fabric8-services/fabric8-auth/app"
+	"github.com/fabric8-services/fabric8-auth/application"
+	"github.com/fabric8-services/fabric8-auth/authorization/role"
+	rolerepo "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
+	"github.com/fabric8-services/fabric8-auth/jsonapi"
+	"github.com/fabric8-services/fabric8-auth/log"
+	"github.com/fabric8-services/fabric8-auth/login"
+
+	"github.com/goadesign/goa"
+)
+
+// RoleMappingsController implements the role_mappings resource.
+type RoleMappingsController struct {
+	*goa.Controller
+	app application.Application
+}
+
+// NewRoleMappingsController creates a role_mappings controller.
+func NewRoleMappingsController(service *goa.Service, app application.Application) *RoleMappingsController {
+	return &RoleMappingsController{
+		Controller: service.NewController("RoleMappingsController"),
+		app:        app,
+	}
+}
+
+// List runs the list action.
+func (c *RoleMappingsController) List(ctx *app.ListRoleMappingsContext) error {
+	currentIdentity, err := login.LoadContextIdentityIfNotDeprovisioned(ctx, c.app)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	mappings, err := c.app.RoleMappingService().ListByResource(ctx, currentIdentity.ID, ctx.ResourceID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_id": ctx.ResourceID,
+			"err":         err,
+		}, "error retrieving the role mappings for a specific resource")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	data := make([]*app.RoleMappingData, len(mappings))
+	for i, m := range mappings {
+		data[i] = convertRoleMapping(m)
+	}
+	return ctx.OK(&app.RoleMappingArray{
+		Data: data,
+	})
+}
+
+// Create runs the create action.
+func (c *RoleMappingsController) Create(ctx *app.CreateRoleMappingsContext) error {
+	currentIdentity, err := login.LoadContextIdentityIfNotDeprovisioned(ctx, c.app)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	mapping, err := c.app.RoleMappingService().Create(ctx, currentIdentity.ID, ctx.ResourceID,
+		convertRoleReferenceData(ctx.Payload.FromRole), convertRoleReferenceData(ctx.Payload.ToRole))
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_id": ctx.ResourceID,
+			"err":         err,
+		}, "error creating a role mapping for a specific resource")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.Created(&app.RoleMappingSingle{
+		Data: convertRoleMapping(*mapping),
+	})
+}
+
+// Delete runs the delete action.
+func (c *RoleMappingsController) Delete(ctx *app.DeleteRoleMappingsContext) error {
+	currentIdentity, err := login.LoadContextIdentityIfNotDeprovisioned(ctx, c.app)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	err = c.app.RoleMappingService().Delete(ctx, currentIdentity.ID, ctx.ResourceID, ctx.RoleMappingID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"resource_id":     ctx.ResourceID,
+			"role_mapping_id": ctx.RoleMappingID,
+			"err":             err,
+		}, "error deleting a role mapping of a specific resource")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+func convertRoleMapping(m rolerepo.RoleMapping) *app.RoleMappingData {
+	return &app.RoleMappingData{
+		ID:         m.RoleMappingID.String(),
+		ResourceID: m.ResourceID,
+		FromRole:   convertRoleReference(m.FromRole),
+		ToRole:     convertRoleReference(m.ToRole),
+	}
+}
+
+func convertRoleReference(r rolerepo.Role) *app.RoleReferenceData {
+	return &app.RoleReferenceData{
+		RoleName:     r.Name,
+		ResourceType: r.ResourceType.Name,
+	}
+}
+
+func convertRoleReferenceData(r *app.RoleReferenceData) role.RoleReference {
+	return role.RoleReference{
+		RoleName:     r.RoleName,
+		ResourceType: r.ResourceType,
+	}
+}
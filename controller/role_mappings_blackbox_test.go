@@ -0,0 +1,134 @@
+package controller_test
+
+import (
+	"testing"
+
+	account "github.com/fabric8-services/fabric8-auth/account/repository"
+	"github.com/fabric8-services/fabric8-auth/app"
+	"github.com/fabric8-services/fabric8-auth/app/test"
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	. "github.com/fabric8-services/fabric8-auth/controller"
+	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
+	testsupport "github.com/fabric8-services/fabric8-auth/test"
+	"github.com/fabric8-services/fabric8-auth/token"
+
+	"github.com/goadesign/goa"
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestRoleMappingsRest struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunRoleMappingsRest(t *testing.T) {
+	suite.Run(t, &TestRoleMappingsRest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (rest *TestRoleMappingsRest) SecuredControllerWithIdentity(identity account.Identity) (*goa.Service, *RoleMappingsController) {
+	svc := testsupport.ServiceAsUser("RoleMappings-Service", identity)
+	return svc, NewRoleMappingsController(svc, rest.Application)
+}
+
+func (rest *TestRoleMappingsRest) SecuredDefaultControllerWithServiceAccount(name string) (*goa.Service, *DefaultRoleMappingsController) {
+	svc := testsupport.ServiceAsServiceAccountUser("DefaultRoleMappings-Service", account.Identity{
+		ID:       uuid.NewV4(),
+		Username: name,
+	})
+	return svc, NewDefaultRoleMappingsController(svc, rest.Application)
+}
+
+func (rest *TestRoleMappingsRest) newPayload(fromRole, fromResourceType, toRole, toResourceType string) *app.CreateRoleMappingsPayload {
+	return &app.CreateRoleMappingsPayload{
+		FromRole: &app.RoleReferenceData{RoleName: fromRole, ResourceType: fromResourceType},
+		ToRole:   &app.RoleReferenceData{RoleName: toRole, ResourceType: toResourceType},
+	}
+}
+
+func (rest *TestRoleMappingsRest) TestManageRoleMappingsOK() {
+	spaceAdmin := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace(rest.Graph.CreateOrganization()).AddAdmin(spaceAdmin)
+	svc, ctrl := rest.SecuredControllerWithIdentity(*spaceAdmin.Identity())
+
+	payload := rest.newPayload(authorization.OrganizationAdminRole, authorization.IdentityResourceTypeOrganization,
+		authorization.SpaceContributorRole, authorization.ResourceTypeSpace)
+	_, created := test.CreateRoleMappingsCreated(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), payload)
+	require.NotNil(rest.T(), created.Data)
+	assert.Equal(rest.T(), space.SpaceID(), created.Data.ResourceID)
+	assert.Equal(rest.T(), authorization.IdentityResourceTypeOrganization, created.Data.FromRole.ResourceType)
+	assert.Equal(rest.T(), authorization.SpaceContributorRole, created.Data.ToRole.RoleName)
+	test.CreateRoleMappingsConflict(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), payload)
+
+	_, list := test.ListRoleMappingsOK(rest.T(), svc.Context, svc, ctrl, space.SpaceID())
+	require.Len(rest.T(), list.Data, 1)
+	assert.Equal(rest.T(), created.Data.ID, list.Data[0].ID)
+
+	roleMappingID, err := uuid.FromString(created.Data.ID)
+	require.NoError(rest.T(), err)
+	test.DeleteRoleMappingsNoContent(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), roleMappingID)
+	test.DeleteRoleMappingsNotFound(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), roleMappingID)
+
+	_, list = test.ListRoleMappingsOK(rest.T(), svc.Context, svc, ctrl, space.SpaceID())
+	require.Empty(rest.T(), list.Data)
+}
+
+func (rest *TestRoleMappingsRest) TestCreateRoleMappingWithInvalidRoleBadRequest() {
+	spaceAdmin := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace().AddAdmin(spaceAdmin)
+	svc, ctrl := rest.SecuredControllerWithIdentity(*spaceAdmin.Identity())
+
+	payload := rest.newPayload(authorization.SpaceContributorRole, authorization.IdentityResourceTypeOrganization,
+		authorization.SpaceContributorRole, authorization.ResourceTypeSpace)
+	test.CreateRoleMappingsBadRequest(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), payload)
+}
+
+func (rest *TestRoleMappingsRest) TestManageRoleMappingsForbidden() {
+	space := rest.Graph.CreateSpace()
+	viewer := rest.Graph.CreateUser()
+	space.AddViewer(viewer)
+	svc, ctrl := rest.SecuredControllerWithIdentity(*viewer.Identity())
+
+	payload := rest.newPayload(authorization.OrganizationAdminRole, authorization.IdentityResourceTypeOrganization,
+		authorization.SpaceContributorRole, authorization.ResourceTypeSpace)
+	test.CreateRoleMappingsForbidden(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), payload)
+
+	svc, ctrl = rest.SecuredControllerWithIdentity(*rest.Graph.CreateUser().Identity())
+	test.ListRoleMappingsForbidden(rest.T(), svc.Context, svc, ctrl, space.SpaceID())
+}
+
+func (rest *TestRoleMappingsRest) TestManageDefaultRoleMappingsOK() {
+	rt := rest.Graph.CreateResourceType()
+	fromRole := rest.Graph.CreateRole(rt)
+	toRT := rest.Graph.CreateResourceType()
+	toRole := rest.Graph.CreateRole(toRT)
+	svc, ctrl := rest.SecuredDefaultControllerWithServiceAccount(token.AuthAdmin)
+	resourceTypeID := rt.ResourceType().ResourceTypeID
+
+	payload := &app.CreateDefaultRoleMappingsPayload{
+		FromRole: &app.RoleReferenceData{RoleName: fromRole.Role().Name, ResourceType: rt.ResourceType().Name},
+		ToRole:   &app.RoleReferenceData{RoleName: toRole.Role().Name, ResourceType: toRT.ResourceType().Name},
+	}
+	_, created := test.CreateDefaultRoleMappingsCreated(rest.T(), svc.Context, svc, ctrl, resourceTypeID, payload)
+	assert.Equal(rest.T(), resourceTypeID.String(), created.Data.ResourceTypeID)
+	assert.Equal(rest.T(), toRole.Role().Name, created.Data.ToRole.RoleName)
+
+	_, list := test.ListDefaultRoleMappingsOK(rest.T(), svc.Context, svc, ctrl, resourceTypeID)
+	require.Len(rest.T(), list.Data, 1)
+
+	defaultRoleMappingID, err := uuid.FromString(created.Data.ID)
+	require.NoError(rest.T(), err)
+	test.DeleteDefaultRoleMappingsNoContent(rest.T(), svc.Context, svc, ctrl, resourceTypeID, defaultRoleMappingID)
+	test.DeleteDefaultRoleMappingsNotFound(rest.T(), svc.Context, svc, ctrl, resourceTypeID, defaultRoleMappingID)
+}
+
+func (rest *TestRoleMappingsRest) TestManageDefaultRoleMappingsForbidden() {
+	rt := rest.Graph.CreateResourceType()
+	svc, ctrl := rest.SecuredDefaultControllerWithServiceAccount(token.WIT)
+	test.ListDefaultRoleMappingsForbidden(rest.T(), svc.Context, svc, ctrl, rt.ResourceType().ResourceTypeID)
+
+	userSvc := testsupport.ServiceAsUser("DefaultRoleMappings-Service", *rest.Graph.CreateUser().Identity())
+	userCtrl := NewDefaultRoleMappingsController(userSvc, rest.Application)
+	test.DeleteDefaultRoleMappingsForbidden(rest.T(), userSvc.Context, userSvc, userCtrl, rt.ResourceType().ResourceTypeID, uuid.NewV4())
+}
@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"strings"
+
+	account "github.com/fabric8-services/fabric8-auth/account/repository"
+	"github.com/fabric8-services/fabric8-auth/app"
+	"github.com/fabric8-services/fabric8-auth/application"
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/jsonapi"
+	"github.com/fabric8-services/fabric8-auth/log"
+	"github.com/fabric8-services/fabric8-auth/login"
+	"github.com/goadesign/goa"
+)
+
+// SecurityGroupController implements the security_group resource.
+type SecurityGroupController struct {
+	*goa.Controller
+	app application.Application
+}
+
+// NewSecurityGroupController creates a security_group controller.
+func NewSecurityGroupController(service *goa.Service, app application.Application) *SecurityGroupController {
+	return &SecurityGroupController{Controller: service.NewController("SecurityGroupController"), app: app}
+}
+
+// Create runs the create action.
+func (c *SecurityGroupController) Create(ctx *app.CreateSecurityGroupContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	if len(strings.TrimSpace(ctx.Payload.Name)) == 0 {
+		log.Error(ctx, map[string]interface{}{}, "security group name cannot be empty")
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrBadRequest("security group name cannot be empty"))
+	}
+
+	groupID, err := c.app.GroupService().CreateGroup(ctx, *currentUser, ctx.Payload.OrganizationID, ctx.Payload.Name)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":             err,
+			"organization_id": ctx.Payload.OrganizationID,
+			"group_name":      ctx.Payload.Name,
+		}, "failed to create security group")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	groupIDStr := groupID.String()
+
+	return ctx.Created(&app.CreateSecurityGroupResponse{GroupID: &groupIDStr})
+}
+
+// List runs the list action.
+func (c *SecurityGroupController) List(ctx *app.ListSecurityGroupContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	groups, err := c.app.GroupService().ListGroupsForIdentity(ctx, *currentUser)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err": err,
+		}, "failed to list security groups")
+		return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(ctx, err))
+	}
+
+	return ctx.OK(&app.IdentitySecurityGroupArray{Data: convertToIdentitySecurityGroupData(groups)})
+}
+
+// ListMembers runs the listMembers action.
+func (c *SecurityGroupController) ListMembers(ctx *app.ListMembersSecurityGroupContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	members, err := c.app.GroupService().ListMembers(ctx, *currentUser, ctx.GroupID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":      err,
+			"group_id": ctx.GroupID,
+		}, "failed to list security group members")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.OK(&app.SecurityGroupMemberArray{Data: convertToSecurityGroupMemberData(members)})
+}
+
+// AddMembers runs the addMembers action.
+func (c *SecurityGroupController) AddMembers(ctx *app.AddMembersSecurityGroupContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	memberIDs, err := convertMemberIDs(ctx.Payload.Ids)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	err = c.app.GroupService().AddMembers(ctx, *currentUser, ctx.GroupID, memberIDs)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":      err,
+			"group_id": ctx.GroupID,
+		}, "failed to add security group members")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+// RemoveMembers runs the removeMembers action.
+func (c *SecurityGroupController) RemoveMembers(ctx *app.RemoveMembersSecurityGroupContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	memberIDs, err := convertMemberIDs(ctx.Payload.Ids)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	err = c.app.GroupService().RemoveMembers(ctx, *currentUser, ctx.GroupID, memberIDs)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":      err,
+			"group_id": ctx.GroupID,
+		}, "failed to remove security group members")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+// AssignRole runs the assignRole action.
+func (c *SecurityGroupController) AssignRole(ctx *app.AssignRoleSecurityGroupContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	err = c.app.GroupService().AssignRole(ctx, *currentUser, ctx.GroupID, ctx.Payload.ResourceID, ctx.Payload.Role)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":         err,
+			"group_id":    ctx.GroupID,
+			"resource_id": ctx.Payload.ResourceID,
+			"role_name":   ctx.Payload.Role,
+		}, "failed to assign role to security group")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+func convertToSecurityGroupMemberData(members []account.Identity) []*app.SecurityGroupMemberData {
+	results := []*app.SecurityGroupMemberData{}
+
+	for _, member := range members {
+		memberData := &app.SecurityGroupMemberData{
+			ID:       member.ID.String(),
+			Username: member.Username,
+		}
+		if member.UserID.Valid {
+			userID := member.UserID.UUID.String()
+			memberData.UserID = &userID
+		}
+
+		results = append(results, memberData)
+	}
+
+	return results
+}
+
+func convertToIdentitySecurityGroupData(groups []authorization.IdentityAssociation) []*app.IdentitySecurityGroupData {
+	results := []*app.IdentitySecurityGroupData{}
+
+	for _, group := range groups {
+		results = append(results, &app.IdentitySecurityGroupData{
+			ID:     group.IdentityID.String(),
+			Name:   group.ResourceName,
+			Member: group.Member,
+			Roles:  group.Roles,
+		})
+	}
+
+	return results
+}
+
+func convertToSecurityGroupData(groups []account.Identity) []*app.SecurityGroupData {
+	results := []*app.SecurityGroupData{}
+
+	for _, group := range groups {
+		results = append(results, &app.SecurityGroupData{
+			ID:   group.ID.String(),
+			Name: group.IdentityResource.Name,
+		})
+	}
+
+	return results
+}
@@ -0,0 +1,98 @@
+package controller_test
+
+import (
+	"testing"
+
+	account "github.com/fabric8-services/fabric8-auth/account/repository"
+	"github.com/fabric8-services/fabric8-auth/app"
+	"github.com/fabric8-services/fabric8-auth/app/test"
+	"github.com/fabric8-services/fabric8-auth/authorization"
+	. "github.com/fabric8-services/fabric8-auth/controller"
+	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
+	testsupport "github.com/fabric8-services/fabric8-auth/test"
+
+	"github.com/goadesign/goa"
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSecurityGroupREST struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunSecurityGroupREST(t *testing.T) {
+	suite.Run(t, &TestSecurityGroupREST{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (rest *TestSecurityGroupREST) SecuredController(identity account.Identity) (*goa.Service, *SecurityGroupController) {
+	svc := testsupport.ServiceAsUser("SecurityGroup-Service", identity)
+	return svc, NewSecurityGroupController(svc, rest.Application)
+}
+
+func (rest *TestSecurityGroupREST) UnsecuredController() (*goa.Service, *SecurityGroupController) {
+	svc := goa.New("SecurityGroup-Service")
+	return svc, NewSecurityGroupController(svc, rest.Application)
+}
+
+func (rest *TestSecurityGroupREST) TestCreateAndListSecurityGroups() {
+	orgAdmin := rest.Graph.CreateUser()
+	org := rest.Graph.CreateOrganization(orgAdmin)
+	service, controller := rest.SecuredController(*orgAdmin.Identity())
+
+	payload := &app.CreateSecurityGroupPayload{OrganizationID: org.OrganizationID(), Name: "release-managers"}
+	_, created := test.CreateSecurityGroupCreated(rest.T(), service.Context, service, controller, payload)
+	require.NotNil(rest.T(), created.GroupID)
+
+	_, groups := test.ListSecurityGroupOK(rest.T(), service.Context, service, controller)
+	require.Len(rest.T(), groups.Data, 1)
+	require.Equal(rest.T(), *created.GroupID, groups.Data[0].ID)
+	require.Equal(rest.T(), "release-managers", groups.Data[0].Name)
+	require.Equal(rest.T(), []string{authorization.SecurityGroupAdminRole}, groups.Data[0].Roles)
+}
+
+func (rest *TestSecurityGroupREST) TestCreateSecurityGroupForbidden() {
+	org := rest.Graph.CreateOrganization()
+	service, controller := rest.SecuredController(*rest.Graph.CreateUser().Identity())
+
+	payload := &app.CreateSecurityGroupPayload{OrganizationID: org.OrganizationID(), Name: "release-managers"}
+	test.CreateSecurityGroupForbidden(rest.T(), service.Context, service, controller, payload)
+}
+
+func (rest *TestSecurityGroupREST) TestCreateSecurityGroupUnauthorized() {
+	service, controller := rest.UnsecuredController()
+
+	payload := &app.CreateSecurityGroupPayload{OrganizationID: uuid.NewV4(), Name: "release-managers"}
+	test.CreateSecurityGroupUnauthorized(rest.T(), service.Context, service, controller, payload)
+}
+
+func (rest *TestSecurityGroupREST) TestManageSecurityGroupMembersAndRoles() {
+	orgAdmin := rest.Graph.CreateUser()
+	group := rest.Graph.CreateGroup(rest.Graph.CreateOrganization(orgAdmin))
+	space := rest.Graph.CreateSpace().AddAdmin(orgAdmin)
+	member := rest.Graph.CreateUser()
+	service, controller := rest.SecuredController(*orgAdmin.Identity())
+
+	addPayload := &app.AddMembersSecurityGroupPayload{Ids: []string{member.IdentityID().String()}}
+	test.AddMembersSecurityGroupNoContent(rest.T(), service.Context, service, controller, group.GroupID(), addPayload)
+	test.AddMembersSecurityGroupConflict(rest.T(), service.Context, service, controller, group.GroupID(), addPayload)
+
+	_, members := test.ListMembersSecurityGroupOK(rest.T(), service.Context, service, controller, group.GroupID())
+	require.Len(rest.T(), members.Data, 1)
+	require.Equal(rest.T(), member.IdentityID().String(), members.Data[0].ID)
+
+	rolePayload := &app.AssignRoleSecurityGroupPayload{ResourceID: space.SpaceID(), Role: authorization.SpaceContributorRole}
+	test.AssignRoleSecurityGroupNoContent(rest.T(), service.Context, service, controller, group.GroupID(), rolePayload)
+	test.AssignRoleSecurityGroupConflict(rest.T(), service.Context, service, controller, group.GroupID(), rolePayload)
+
+	hasScope, err := rest.Application.PermissionService().HasScope(rest.Ctx, member.IdentityID(), space.SpaceID(), "contribute")
+	require.NoError(rest.T(), err)
+	require.True(rest.T(), hasScope)
+
+	removePayload := &app.RemoveMembersSecurityGroupPayload{Ids: []string{member.IdentityID().String()}}
+	test.RemoveMembersSecurityGroupNoContent(rest.T(), service.Context, service, controller, group.GroupID(), removePayload)
+
+	hasScope, err = rest.Application.PermissionService().HasScope(rest.Ctx, member.IdentityID(), space.SpaceID(), "contribute")
+	require.NoError(rest.T(), err)
+	require.False(rest.T(), hasScope)
+}
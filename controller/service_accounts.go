@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"time"
+
+	serviceaccount "github.com/fabric8-services/fabric8-auth/account/serviceaccount/repository"
+	"github.com/fabric8-services/fabric8-auth/app"
+	"github.com/fabric8-services/fabric8-auth/application"
+	"github.com/fabric8-services/fabric8-auth/jsonapi"
+	"github.com/fabric8-services/fabric8-auth/log"
+
+	"github.com/goadesign/goa"
+)
+
+// ServiceAccountsController implements the service_accounts resource.
+type ServiceAccountsController struct {
+	*goa.Controller
+	app application.Application
+}
+
+// NewServiceAccountsController creates a service_accounts controller.
+func NewServiceAccountsController(service *goa.Service, app application.Application) *ServiceAccountsController {
+	return &ServiceAccountsController{
+		Controller: service.NewController("ServiceAccountsController"),
+		app:        app,
+	}
+}
+
+// List runs the list action.
+func (c *ServiceAccountsController) List(ctx *app.ListServiceAccountsContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	accounts, err := c.app.ServiceAccountService().List(ctx)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err": err,
+		}, "unable to list the service accounts")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	data := make([]*app.ServiceAccountData, len(accounts))
+	for i, account := range accounts {
+		data[i] = convertServiceAccount(account, nil)
+	}
+	return ctx.OK(&app.ServiceAccountArray{Data: data})
+}
+
+// Show runs the show action.
+func (c *ServiceAccountsController) Show(ctx *app.ShowServiceAccountsContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	account, secrets, err := c.app.ServiceAccountService().Load(ctx, ctx.ServiceAccountID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	data := convertServiceAccount(*account, []*app.ServiceAccountSecretData{})
+	for _, secret := range secrets {
+		data.Secrets = append(data.Secrets, convertServiceAccountSecret(secret, nil))
+	}
+	return ctx.OK(&app.ServiceAccountSingle{Data: data})
+}
+
+// Create runs the create action.
+func (c *ServiceAccountsController) Create(ctx *app.CreateServiceAccountsContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	account, secret, plainSecret, err := c.app.ServiceAccountService().Create(ctx, ctx.Payload.ID, ctx.Payload.Name)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"service_account_id": ctx.Payload.ID,
+			"name":               ctx.Payload.Name,
+			"err":                err,
+		}, "unable to create the service account")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	data := convertServiceAccount(*account, []*app.ServiceAccountSecretData{convertServiceAccountSecret(*secret, &plainSecret)})
+	return ctx.Created(&app.ServiceAccountSingle{Data: data})
+}
+
+// AddSecret runs the addSecret action.
+func (c *ServiceAccountsController) AddSecret(ctx *app.AddSecretServiceAccountsContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	secret, plainSecret, err := c.app.ServiceAccountService().AddSecret(ctx, ctx.ServiceAccountID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"service_account_id": ctx.ServiceAccountID,
+			"err":                err,
+		}, "unable to add a secret to the service account")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.Created(&app.ServiceAccountSecretSingle{Data: convertServiceAccountSecret(*secret, &plainSecret)})
+}
+
+// RetireSecret runs the retireSecret action.
+func (c *ServiceAccountsController) RetireSecret(ctx *app.RetireSecretServiceAccountsContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	var overlap *time.Duration
+	if ctx.Overlap != nil {
+		d := time.Duration(*ctx.Overlap) * time.Second
+		overlap = &d
+	}
+	secret, err := c.app.ServiceAccountService().RetireSecret(ctx, ctx.ServiceAccountID, ctx.SecretID, overlap)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"service_account_id": ctx.ServiceAccountID,
+			"secret_id":          ctx.SecretID,
+			"err":                err,
+		}, "unable to retire the secret of the service account")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.ServiceAccountSecretSingle{Data: convertServiceAccountSecret(*secret, nil)})
+}
+
+// convertServiceAccount converts the service account to its REST representation, along with the given secrets
+func convertServiceAccount(account serviceaccount.ServiceAccount, secrets []*app.ServiceAccountSecretData) *app.ServiceAccountData {
+	return &app.ServiceAccountData{
+		ID:         account.ServiceAccountID.String(),
+		Name:       account.Name,
+		CreatedAt:  account.CreatedAt,
+		LastUsedAt: account.LastUsedAt,
+		Secrets:    secrets,
+	}
+}
+
+// convertServiceAccountSecret converts the secret to its REST representation. The plain value of the secret is only
+// given when the secret has just been generated.
+func convertServiceAccountSecret(secret serviceaccount.ServiceAccountSecret, plainSecret *string) *app.ServiceAccountSecretData {
+	return &app.ServiceAccountSecretData{
+		ID:         secret.SecretID.String(),
+		Secret:     plainSecret,
+		CreatedAt:  secret.CreatedAt,
+		ExpiresAt:  secret.ExpiresAt,
+		LastUsedAt: secret.LastUsedAt,
+	}
+}
@@ -0,0 +1,123 @@
+package controller_test
+
+import (
+	"testing"
+
+	account "github.com/fabric8-services/fabric8-auth/account/repository"
+	"github.com/fabric8-services/fabric8-auth/app"
+	"github.com/fabric8-services/fabric8-auth/app/test"
+	. "github.com/fabric8-services/fabric8-auth/controller"
+	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
+	testsupport "github.com/fabric8-services/fabric8-auth/test"
+	"github.com/fabric8-services/fabric8-auth/token"
+
+	"github.com/goadesign/goa"
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestServiceAccountsRest struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunServiceAccountsRest(t *testing.T) {
+	suite.Run(t, &TestServiceAccountsRest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (rest *TestServiceAccountsRest) SecuredControllerWithServiceAccount(name string) (*goa.Service, *ServiceAccountsController) {
+	svc := testsupport.ServiceAsServiceAccountUser("ServiceAccounts-Service", account.Identity{
+		ID:       uuid.NewV4(),
+		Username: name,
+	})
+	return svc, NewServiceAccountsController(svc, rest.Application)
+}
+
+func (rest *TestServiceAccountsRest) SecuredControllerWithIdentity(identity account.Identity) (*goa.Service, *ServiceAccountsController) {
+	svc := testsupport.ServiceAsUser("ServiceAccounts-Service", identity)
+	return svc, NewServiceAccountsController(svc, rest.Application)
+}
+
+func (rest *TestServiceAccountsRest) TestManageServiceAccountOK() {
+	svc, ctrl := rest.SecuredControllerWithServiceAccount(token.AuthAdmin)
+	name := "sa-" + uuid.NewV4().String()
+
+	_, created := test.CreateServiceAccountsCreated(rest.T(), svc.Context, svc, ctrl, &app.CreateServiceAccountsPayload{Name: name})
+	require.NotNil(rest.T(), created.Data)
+	assert.Equal(rest.T(), name, created.Data.Name)
+	assert.Nil(rest.T(), created.Data.LastUsedAt)
+	require.Len(rest.T(), created.Data.Secrets, 1)
+	require.NotNil(rest.T(), created.Data.Secrets[0].Secret)
+	serviceAccountID, err := uuid.FromString(created.Data.ID)
+	require.NoError(rest.T(), err)
+	oldSecretID, err := uuid.FromString(created.Data.Secrets[0].ID)
+	require.NoError(rest.T(), err)
+
+	// The secret is accepted
+	saID, saName, err := rest.Application.ServiceAccountService().Authenticate(rest.Ctx, created.Data.ID, *created.Data.Secrets[0].Secret)
+	require.NoError(rest.T(), err)
+	assert.Equal(rest.T(), created.Data.ID, saID)
+	assert.Equal(rest.T(), name, saName)
+
+	// Rotate the secret
+	_, added := test.AddSecretServiceAccountsCreated(rest.T(), svc.Context, svc, ctrl, serviceAccountID)
+	require.NotNil(rest.T(), added.Data.Secret)
+	overlap := 3600
+	_, retired := test.RetireSecretServiceAccountsOK(rest.T(), svc.Context, svc, ctrl, serviceAccountID, oldSecretID, &overlap)
+	assert.Equal(rest.T(), created.Data.Secrets[0].ID, retired.Data.ID)
+	assert.Nil(rest.T(), retired.Data.Secret)
+	require.NotNil(rest.T(), retired.Data.ExpiresAt)
+	test.RetireSecretServiceAccountsConflict(rest.T(), svc.Context, svc, ctrl, serviceAccountID, oldSecretID, nil)
+	test.RetireSecretServiceAccountsNotFound(rest.T(), svc.Context, svc, ctrl, serviceAccountID, uuid.NewV4(), nil)
+
+	_, shown := test.ShowServiceAccountsOK(rest.T(), svc.Context, svc, ctrl, serviceAccountID)
+	assert.Equal(rest.T(), name, shown.Data.Name)
+	require.NotNil(rest.T(), shown.Data.LastUsedAt)
+	require.Len(rest.T(), shown.Data.Secrets, 2)
+	assert.Equal(rest.T(), created.Data.Secrets[0].ID, shown.Data.Secrets[0].ID)
+	assert.NotNil(rest.T(), shown.Data.Secrets[0].ExpiresAt)
+	assert.NotNil(rest.T(), shown.Data.Secrets[0].LastUsedAt)
+	assert.Equal(rest.T(), added.Data.ID, shown.Data.Secrets[1].ID)
+	assert.Nil(rest.T(), shown.Data.Secrets[1].ExpiresAt)
+	for _, secret := range shown.Data.Secrets {
+		assert.Nil(rest.T(), secret.Secret)
+	}
+
+	_, list := test.ListServiceAccountsOK(rest.T(), svc.Context, svc, ctrl)
+	found := false
+	for _, sa := range list.Data {
+		if sa.ID == created.Data.ID {
+			found = true
+		}
+	}
+	assert.True(rest.T(), found)
+}
+
+func (rest *TestServiceAccountsRest) TestCreateServiceAccountWithID() {
+	svc, ctrl := rest.SecuredControllerWithServiceAccount(token.AuthAdmin)
+	id := uuid.NewV4()
+	payload := &app.CreateServiceAccountsPayload{ID: &id, Name: "sa-" + uuid.NewV4().String()}
+	_, created := test.CreateServiceAccountsCreated(rest.T(), svc.Context, svc, ctrl, payload)
+	assert.Equal(rest.T(), id.String(), created.Data.ID)
+	test.CreateServiceAccountsConflict(rest.T(), svc.Context, svc, ctrl, payload)
+}
+
+func (rest *TestServiceAccountsRest) TestUnknownServiceAccountNotFound() {
+	svc, ctrl := rest.SecuredControllerWithServiceAccount(token.AuthAdmin)
+	test.ShowServiceAccountsNotFound(rest.T(), svc.Context, svc, ctrl, uuid.NewV4())
+	test.AddSecretServiceAccountsNotFound(rest.T(), svc.Context, svc, ctrl, uuid.NewV4())
+}
+
+func (rest *TestServiceAccountsRest) TestManageServiceAccountAsOtherServiceAccountForbidden() {
+	svc, ctrl := rest.SecuredControllerWithServiceAccount(token.WIT)
+	test.ListServiceAccountsForbidden(rest.T(), svc.Context, svc, ctrl)
+	test.CreateServiceAccountsForbidden(rest.T(), svc.Context, svc, ctrl, &app.CreateServiceAccountsPayload{Name: "sa-" + uuid.NewV4().String()})
+}
+
+func (rest *TestServiceAccountsRest) TestManageServiceAccountAsUserForbidden() {
+	user := rest.Graph.CreateUser()
+	svc, ctrl := rest.SecuredControllerWithIdentity(*user.Identity())
+	test.ListServiceAccountsForbidden(rest.T(), svc.Context, svc, ctrl)
+	test.AddSecretServiceAccountsForbidden(rest.T(), svc.Context, svc, ctrl, uuid.NewV4())
+}
@@ -4,6 +4,7 @@ import (
 	account "github.com/fabric8-services/fabric8-auth/account/repository"
 	"github.com/fabric8-services/fabric8-auth/app"
 	"github.com/fabric8-services/fabric8-auth/application"
+	accessrequest "github.com/fabric8-services/fabric8-auth/authorization/accessrequest/repository"
 	"github.com/fabric8-services/fabric8-auth/errors"
 	"github.com/fabric8-services/fabric8-auth/jsonapi"
 	"github.com/fabric8-services/fabric8-auth/log"
@@ -31,11 +32,12 @@ func (c *SpaceController) Create(ctx *app.CreateSpaceContext) error {
 	}
 
 	// Create AuthZ resource for the space as part of soft migration from deprecated Keycloak AuthZ API to new OSIO AuthZ API
-	err = c.app.SpaceService().CreateSpace(ctx, currentIdentity.ID, ctx.SpaceID.String())
+	err = c.app.SpaceService().CreateSpace(ctx, currentIdentity.ID, ctx.SpaceID.String(), ctx.OrganizationID)
 	if err != nil {
 		log.Error(ctx, map[string]interface{}{
-			"err":      err,
-			"space_id": ctx.SpaceID,
+			"err":             err,
+			"space_id":        ctx.SpaceID,
+			"organization_id": ctx.OrganizationID,
 		}, "unable to register resource for space or assign space admin role to space creator")
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
@@ -101,3 +103,100 @@ func convertToTeamData(teams []account.Identity) []*app.TeamData {
 
 	return results
 }
+
+// CreateAccessRequest runs the createAccessRequest action.
+func (c *SpaceController) CreateAccessRequest(ctx *app.CreateAccessRequestSpaceContext) error {
+	currentIdentity, err := login.LoadContextIdentityIfNotDeprovisioned(ctx, c.app)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	request, err := c.app.AccessRequestService().Create(ctx, currentIdentity.ID, ctx.SpaceID, ctx.Payload.Role, ctx.Payload.Message)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":      err,
+			"space_id": ctx.SpaceID,
+		}, "unable to request access to space")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.Created(&app.AccessRequestSingle{Data: convertToAccessRequestData(*request)})
+}
+
+// ListAccessRequests runs the listAccessRequests action.
+func (c *SpaceController) ListAccessRequests(ctx *app.ListAccessRequestsSpaceContext) error {
+	currentIdentity, err := login.LoadContextIdentityIfNotDeprovisioned(ctx, c.app)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	requests, err := c.app.AccessRequestService().ListPending(ctx, currentIdentity.ID, ctx.SpaceID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":      err,
+			"space_id": ctx.SpaceID,
+		}, "failed to list access requests")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	data := make([]*app.AccessRequestData, len(requests))
+	for i, request := range requests {
+		data[i] = convertToAccessRequestData(request)
+	}
+	return ctx.OK(&app.AccessRequestArray{Data: data})
+}
+
+// ApproveAccessRequest runs the approveAccessRequest action.
+func (c *SpaceController) ApproveAccessRequest(ctx *app.ApproveAccessRequestSpaceContext) error {
+	currentIdentity, err := login.LoadContextIdentityIfNotDeprovisioned(ctx, c.app)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	err = c.app.AccessRequestService().Approve(ctx, currentIdentity.ID, ctx.SpaceID, ctx.AccessRequestID, ctx.Payload.Role)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":               err,
+			"space_id":          ctx.SpaceID,
+			"access_request_id": ctx.AccessRequestID,
+		}, "unable to approve access request")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+// DenyAccessRequest runs the denyAccessRequest action.
+func (c *SpaceController) DenyAccessRequest(ctx *app.DenyAccessRequestSpaceContext) error {
+	currentIdentity, err := login.LoadContextIdentityIfNotDeprovisioned(ctx, c.app)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	err = c.app.AccessRequestService().Deny(ctx, currentIdentity.ID, ctx.SpaceID, ctx.AccessRequestID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":               err,
+			"space_id":          ctx.SpaceID,
+			"access_request_id": ctx.AccessRequestID,
+		}, "unable to deny access request")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+func convertToAccessRequestData(request accessrequest.AccessRequest) *app.AccessRequestData {
+	data := &app.AccessRequestData{
+		ID:         request.AccessRequestID.String(),
+		SpaceID:    request.ResourceID,
+		IdentityID: request.IdentityID.String(),
+		Message:    request.Message,
+		Status:     request.Status,
+		CreatedAt:  request.CreatedAt,
+	}
+	if request.Role != nil {
+		data.Role = &request.Role.Name
+	}
+	return data
+}
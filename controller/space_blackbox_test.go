@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	account "github.com/fabric8-services/fabric8-auth/account/repository"
+	"github.com/fabric8-services/fabric8-auth/app"
 	"github.com/fabric8-services/fabric8-auth/app/test"
 	"github.com/fabric8-services/fabric8-auth/application/service"
 	"github.com/fabric8-services/fabric8-auth/authorization"
@@ -66,21 +67,21 @@ func (rest *TestSpaceREST) TestFailCreateSpaceUnauthorized() {
 	// given
 	svc, ctrl := rest.UnSecuredController()
 	// when/then
-	test.CreateSpaceUnauthorized(rest.T(), svc.Context, svc, ctrl, uuid.NewV4())
+	test.CreateSpaceUnauthorized(rest.T(), svc.Context, svc, ctrl, uuid.NewV4(), nil)
 }
 
 func (rest *TestSpaceREST) TestCreateSpaceUnauthorizedDeprovisionedUser() {
 	// given
 	svc, ctrl := rest.UnSecuredControllerWithDeprovisionedIdentity()
 	// when/then
-	test.CreateSpaceUnauthorized(rest.T(), svc.Context, svc, ctrl, uuid.NewV4())
+	test.CreateSpaceUnauthorized(rest.T(), svc.Context, svc, ctrl, uuid.NewV4(), nil)
 }
 
 func (rest *TestSpaceREST) TestCreateSpaceOK() {
 	svc, ctrl, creator := rest.SecuredController()
 	spaceID := uuid.NewV4()
 
-	_, created := test.CreateSpaceOK(rest.T(), svc.Context, svc, ctrl, spaceID)
+	_, created := test.CreateSpaceOK(rest.T(), svc.Context, svc, ctrl, spaceID, nil)
 	require.NotNil(rest.T(), created.Data)
 	assert.Equal(rest.T(), spaceID.String(), created.Data.ResourceID)
 
@@ -98,6 +99,27 @@ func (rest *TestSpaceREST) TestCreateSpaceOK() {
 	assert.Equal(rest.T(), authorization.SpaceAdminRole, assignedRoles[0].Role.Name)
 }
 
+func (rest *TestSpaceREST) TestCreateSpaceInOrganization() {
+	orgAdmin := rest.Graph.CreateUser()
+	org := rest.Graph.CreateOrganization(orgAdmin)
+	orgID := org.OrganizationID()
+	spaceID := uuid.NewV4()
+
+	svc, ctrl := rest.SecuredControllerForIdentity(*orgAdmin.Identity())
+	test.CreateSpaceOK(rest.T(), svc.Context, svc, ctrl, spaceID, &orgID)
+
+	res, err := rest.Application.ResourceRepository().Load(context.Background(), spaceID.String())
+	require.NoError(rest.T(), err)
+	require.NotNil(rest.T(), res.ParentResourceID)
+	assert.Equal(rest.T(), org.ResourceID(), *res.ParentResourceID)
+
+	unknownID := uuid.NewV4()
+	test.CreateSpaceNotFound(rest.T(), svc.Context, svc, ctrl, uuid.NewV4(), &unknownID)
+
+	svc, ctrl, _ = rest.SecuredController()
+	test.CreateSpaceForbidden(rest.T(), svc.Context, svc, ctrl, uuid.NewV4(), &orgID)
+}
+
 func (rest *TestSpaceREST) TestFailDeleteSpaceUnauthorized() {
 	// given
 	svc, ctrl := rest.UnSecuredController()
@@ -117,7 +139,7 @@ func (rest *TestSpaceREST) TestDeleteSpaceOK() {
 	// Create a space
 	svc, ctrl, _ := rest.SecuredController()
 	id := uuid.NewV4()
-	test.CreateSpaceOK(rest.T(), svc.Context, svc, ctrl, id)
+	test.CreateSpaceOK(rest.T(), svc.Context, svc, ctrl, id, nil)
 
 	// Check if the corresponding authZ resource has been created
 	_, err := rest.resourceService.Read(context.Background(), id.String())
@@ -142,7 +164,7 @@ func (rest *TestSpaceREST) TestDeleteSpaceIfUserIsNotSpaceOwnerForbidden() {
 	svcOwner, ctrlOwner, _ := rest.SecuredController()
 	svcNotOwner, ctrlNotOwner, _ := rest.SecuredController()
 	id := uuid.NewV4()
-	test.CreateSpaceOK(rest.T(), svcOwner.Context, svcOwner, ctrlOwner, id)
+	test.CreateSpaceOK(rest.T(), svcOwner.Context, svcOwner, ctrlOwner, id, nil)
 
 	// Try to delete
 	test.DeleteSpaceForbidden(rest.T(), svcNotOwner.Context, svcNotOwner, ctrlNotOwner, id)
@@ -205,3 +227,73 @@ func (rest *TestSpaceREST) TestListTeamUnauthorized() {
 	service, controller = rest.UnSecuredController()
 	test.ListTeamsSpaceUnauthorized(rest.T(), service.Context, service, controller, g.SpaceByID("space").SpaceID())
 }
+
+func (rest *TestSpaceREST) TestAccessRequestWorkflow() {
+	admin := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace().AddAdmin(admin)
+	requester := rest.Graph.CreateUser()
+	role := authorization.SpaceContributorRole
+	message := "let me in"
+
+	svc, ctrl := rest.SecuredControllerForIdentity(*requester.Identity())
+	_, created := test.CreateAccessRequestSpaceCreated(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), &app.CreateAccessRequestSpacePayload{
+		Role:    &role,
+		Message: &message,
+	})
+	require.NotNil(rest.T(), created.Data)
+	assert.Equal(rest.T(), space.SpaceID(), created.Data.SpaceID)
+	assert.Equal(rest.T(), requester.IdentityID().String(), created.Data.IdentityID)
+	assert.Equal(rest.T(), "pending", created.Data.Status)
+	require.NotNil(rest.T(), created.Data.Role)
+	assert.Equal(rest.T(), role, *created.Data.Role)
+
+	// A second request is a conflict
+	test.CreateAccessRequestSpaceConflict(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), &app.CreateAccessRequestSpacePayload{})
+
+	// The requester can not list nor approve the requests
+	test.ListAccessRequestsSpaceForbidden(rest.T(), svc.Context, svc, ctrl, space.SpaceID())
+	accessRequestID, err := uuid.FromString(created.Data.ID)
+	require.NoError(rest.T(), err)
+	test.ApproveAccessRequestSpaceForbidden(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), accessRequestID, &app.ApproveAccessRequestSpacePayload{})
+
+	svc, ctrl = rest.SecuredControllerForIdentity(*admin.Identity())
+	_, list := test.ListAccessRequestsSpaceOK(rest.T(), svc.Context, svc, ctrl, space.SpaceID())
+	require.Len(rest.T(), list.Data, 1)
+	assert.Equal(rest.T(), created.Data.ID, list.Data[0].ID)
+	assert.Equal(rest.T(), message, *list.Data[0].Message)
+
+	test.ApproveAccessRequestSpaceNotFound(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), uuid.NewV4(), &app.ApproveAccessRequestSpacePayload{})
+	test.ApproveAccessRequestSpaceNoContent(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), accessRequestID, &app.ApproveAccessRequestSpacePayload{})
+
+	roles, err := rest.Application.IdentityRoleRepository().FindIdentityRolesByIdentityAndResource(rest.Ctx, space.SpaceID(), requester.IdentityID())
+	require.NoError(rest.T(), err)
+	require.Len(rest.T(), roles, 1)
+
+	// The request is not pending anymore
+	_, list = test.ListAccessRequestsSpaceOK(rest.T(), svc.Context, svc, ctrl, space.SpaceID())
+	assert.Empty(rest.T(), list.Data)
+	test.DenyAccessRequestSpaceBadRequest(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), accessRequestID)
+}
+
+func (rest *TestSpaceREST) TestDenyAccessRequest() {
+	admin := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace().AddAdmin(admin)
+	requester := rest.Graph.CreateUser()
+	request, err := rest.Application.AccessRequestService().Create(rest.Ctx, requester.IdentityID(), space.SpaceID(), nil, nil)
+	require.NoError(rest.T(), err)
+
+	svc, ctrl := rest.SecuredControllerForIdentity(*requester.Identity())
+	test.DenyAccessRequestSpaceForbidden(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), request.AccessRequestID)
+
+	svc, ctrl = rest.SecuredControllerForIdentity(*admin.Identity())
+	test.DenyAccessRequestSpaceNoContent(rest.T(), svc.Context, svc, ctrl, space.SpaceID(), request.AccessRequestID)
+
+	roles, err := rest.Application.IdentityRoleRepository().FindIdentityRolesByIdentityAndResource(rest.Ctx, space.SpaceID(), requester.IdentityID())
+	require.NoError(rest.T(), err)
+	assert.Empty(rest.T(), roles)
+}
+
+func (rest *TestSpaceREST) TestCreateAccessRequestUnauthorized() {
+	svc, ctrl := rest.UnSecuredController()
+	test.CreateAccessRequestSpaceUnauthorized(rest.T(), svc.Context, svc, ctrl, rest.Graph.CreateSpace().SpaceID(), &app.CreateAccessRequestSpacePayload{})
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	account "github.com/fabric8-services/fabric8-auth/account/repository"
 	"github.com/fabric8-services/fabric8-auth/app"
 	"github.com/fabric8-services/fabric8-auth/application"
 	"github.com/fabric8-services/fabric8-auth/authorization"
@@ -9,6 +10,7 @@ import (
 	"github.com/fabric8-services/fabric8-auth/log"
 	"github.com/fabric8-services/fabric8-auth/login"
 	"github.com/goadesign/goa"
+	"github.com/satori/go.uuid"
 	"strings"
 )
 
@@ -79,6 +81,147 @@ func (c *TeamController) List(ctx *app.ListTeamContext) error {
 	return ctx.OK(&app.IdentityTeamArray{convertToIdentityTeamData(teams)})
 }
 
+// Update runs the update action.
+func (c *TeamController) Update(ctx *app.UpdateTeamContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	if len(strings.TrimSpace(ctx.Payload.Name)) == 0 {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterErrorFromString("name", ctx.Payload.Name, "team name cannot be empty"))
+	}
+
+	err = c.app.TeamService().UpdateTeam(ctx, *currentUser, ctx.TeamID, ctx.Payload.Name)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":       err,
+			"team_id":   ctx.TeamID,
+			"team_name": ctx.Payload.Name,
+		}, "failed to rename team")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+// Delete runs the delete action.
+func (c *TeamController) Delete(ctx *app.DeleteTeamContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	err = c.app.TeamService().DeleteTeam(ctx, *currentUser, ctx.TeamID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":     err,
+			"team_id": ctx.TeamID,
+		}, "failed to delete team")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+// ListMembers runs the listMembers action.
+func (c *TeamController) ListMembers(ctx *app.ListMembersTeamContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	members, err := c.app.TeamService().ListMembers(ctx, *currentUser, ctx.TeamID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":     err,
+			"team_id": ctx.TeamID,
+		}, "failed to list team members")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.OK(&app.TeamMemberArray{Data: convertToTeamMemberData(members)})
+}
+
+// AddMembers runs the addMembers action.
+func (c *TeamController) AddMembers(ctx *app.AddMembersTeamContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	memberIDs, err := convertMemberIDs(ctx.Payload.Ids)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	err = c.app.TeamService().AddMembers(ctx, *currentUser, ctx.TeamID, memberIDs)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":     err,
+			"team_id": ctx.TeamID,
+		}, "failed to add team members")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+// RemoveMembers runs the removeMembers action.
+func (c *TeamController) RemoveMembers(ctx *app.RemoveMembersTeamContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	memberIDs, err := convertMemberIDs(ctx.Payload.Ids)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	err = c.app.TeamService().RemoveMembers(ctx, *currentUser, ctx.TeamID, memberIDs)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":     err,
+			"team_id": ctx.TeamID,
+		}, "failed to remove team members")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent()
+}
+
+func convertMemberIDs(ids []string) ([]uuid.UUID, error) {
+	var memberIDs []uuid.UUID
+	for _, id := range ids {
+		memberID, err := uuid.FromString(id)
+		if err != nil {
+			return nil, errors.NewBadParameterError("ids", id).Expected("uuid")
+		}
+		memberIDs = append(memberIDs, memberID)
+	}
+	return memberIDs, nil
+}
+
+func convertToTeamMemberData(members []account.Identity) []*app.TeamMemberData {
+	results := []*app.TeamMemberData{}
+
+	for _, member := range members {
+		memberData := &app.TeamMemberData{
+			ID:       member.ID.String(),
+			Username: member.Username,
+		}
+		if member.UserID.Valid {
+			userID := member.UserID.UUID.String()
+			memberData.UserID = &userID
+		}
+
+		results = append(results, memberData)
+	}
+
+	return results
+}
+
 func convertToIdentityTeamData(teams []authorization.IdentityAssociation) []*app.IdentityTeamData {
 	results := []*app.IdentityTeamData{}
 
@@ -121,3 +121,72 @@ func (rest *TestTeamREST) TestListTeamUnauthorized() {
 	service, controller := rest.UnsecuredController()
 	test.ListTeamUnauthorized(rest.T(), service.Context, service, controller)
 }
+
+func (rest *TestTeamREST) TestManageTeamMembersOK() {
+	spaceAdmin := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace().AddAdmin(spaceAdmin)
+	teamID, err := rest.Application.TeamService().CreateTeam(rest.Ctx, spaceAdmin.IdentityID(), space.SpaceID(), "Team-"+uuid.NewV4().String())
+	require.NoError(rest.T(), err)
+	member := rest.Graph.CreateUser()
+
+	service, controller := rest.SecuredController(*spaceAdmin.Identity())
+	addPayload := &app.AddMembersTeamPayload{Ids: []string{member.IdentityID().String()}}
+	test.AddMembersTeamNoContent(rest.T(), service.Context, service, controller, *teamID, addPayload)
+	test.AddMembersTeamConflict(rest.T(), service.Context, service, controller, *teamID, addPayload)
+
+	_, members := test.ListMembersTeamOK(rest.T(), service.Context, service, controller, *teamID)
+	require.Len(rest.T(), members.Data, 1)
+	require.Equal(rest.T(), member.IdentityID().String(), members.Data[0].ID)
+	require.Equal(rest.T(), member.Identity().Username, members.Data[0].Username)
+	require.Equal(rest.T(), member.User().ID.String(), *members.Data[0].UserID)
+
+	removePayload := &app.RemoveMembersTeamPayload{Ids: []string{member.IdentityID().String()}}
+	test.RemoveMembersTeamNoContent(rest.T(), service.Context, service, controller, *teamID, removePayload)
+	test.RemoveMembersTeamNotFound(rest.T(), service.Context, service, controller, *teamID, removePayload)
+
+	_, members = test.ListMembersTeamOK(rest.T(), service.Context, service, controller, *teamID)
+	require.Empty(rest.T(), members.Data)
+}
+
+func (rest *TestTeamREST) TestManageTeamMembersForbidden() {
+	team := rest.Graph.CreateTeam()
+	service, controller := rest.SecuredController(*rest.Graph.CreateUser().Identity())
+	ids := []string{rest.Graph.CreateUser().IdentityID().String()}
+
+	test.ListMembersTeamForbidden(rest.T(), service.Context, service, controller, team.TeamID())
+	test.AddMembersTeamForbidden(rest.T(), service.Context, service, controller, team.TeamID(), &app.AddMembersTeamPayload{Ids: ids})
+	test.RemoveMembersTeamForbidden(rest.T(), service.Context, service, controller, team.TeamID(), &app.RemoveMembersTeamPayload{Ids: ids})
+}
+
+func (rest *TestTeamREST) TestAddTeamMembersBadRequest() {
+	service, controller := rest.SecuredController(rest.testIdentity)
+	payload := &app.AddMembersTeamPayload{Ids: []string{"not-a-uuid"}}
+
+	test.AddMembersTeamBadRequest(rest.T(), service.Context, service, controller, rest.Graph.CreateTeam().TeamID(), payload)
+}
+
+func (rest *TestTeamREST) TestListTeamMembersUnauthorized() {
+	service, controller := rest.UnsecuredController()
+
+	test.ListMembersTeamUnauthorized(rest.T(), service.Context, service, controller, uuid.NewV4())
+}
+
+func (rest *TestTeamREST) TestUpdateAndDeleteTeam() {
+	spaceAdmin := rest.Graph.CreateUser()
+	team := rest.Graph.CreateTeam(rest.Graph.CreateSpace().AddAdmin(spaceAdmin))
+	service, controller := rest.SecuredController(*spaceAdmin.Identity())
+
+	test.UpdateTeamNoContent(rest.T(), service.Context, service, controller, team.TeamID(), &app.UpdateTeamPayload{Name: "renamed"})
+	test.UpdateTeamBadRequest(rest.T(), service.Context, service, controller, team.TeamID(), &app.UpdateTeamPayload{Name: " "})
+
+	test.DeleteTeamNoContent(rest.T(), service.Context, service, controller, team.TeamID())
+	test.DeleteTeamNotFound(rest.T(), service.Context, service, controller, team.TeamID())
+}
+
+func (rest *TestTeamREST) TestUpdateAndDeleteTeamForbidden() {
+	team := rest.Graph.CreateTeam()
+	service, controller := rest.SecuredController(*rest.Graph.CreateUser().Identity())
+
+	test.UpdateTeamForbidden(rest.T(), service.Context, service, controller, team.TeamID(), &app.UpdateTeamPayload{Name: "renamed"})
+	test.DeleteTeamForbidden(rest.T(), service.Context, service, controller, team.TeamID())
+}
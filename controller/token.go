@@ -6,12 +6,15 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
+	jwt "github.com/dgrijalva/jwt-go"
 	account "github.com/fabric8-services/fabric8-auth/account/repository"
 	"github.com/fabric8-services/fabric8-auth/app"
 	"github.com/fabric8-services/fabric8-auth/application"
 	"github.com/fabric8-services/fabric8-auth/application/transaction"
+	tokenrepo "github.com/fabric8-services/fabric8-auth/authorization/token/repository"
 	"github.com/fabric8-services/fabric8-auth/client"
 	"github.com/fabric8-services/fabric8-auth/errors"
 	"github.com/fabric8-services/fabric8-auth/jsonapi"
@@ -20,15 +23,21 @@ import (
 	"github.com/fabric8-services/fabric8-auth/rest"
 	"github.com/fabric8-services/fabric8-auth/token"
 	"github.com/fabric8-services/fabric8-auth/token/jwk"
+	keyrepo "github.com/fabric8-services/fabric8-auth/token/keystore/repository"
 	"github.com/fabric8-services/fabric8-auth/token/link"
 	"github.com/fabric8-services/fabric8-auth/token/provider"
 	"github.com/goadesign/goa"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
 	errs "github.com/pkg/errors"
 	"github.com/satori/go.uuid"
-	"golang.org/x/crypto/bcrypt"
 	"golang.org/x/oauth2"
 )
 
+// KeyRotator rotates the keys which sign the tokens
+type KeyRotator interface {
+	Rotate(ctx context.Context, accountType string) error
+}
+
 // TokenController implements the login resource.
 type TokenController struct {
 	*goa.Controller
@@ -39,10 +48,11 @@ type TokenController struct {
 	Configuration LoginConfiguration
 
 	providerConfigFactory link.OauthProviderFactory
+	keyRotator            KeyRotator
 }
 
 // NewTokenController creates a token controller.
-func NewTokenController(service *goa.Service, app application.Application, auth login.KeycloakOAuthService, linkService link.LinkOAuthService, providerConfigFactory link.OauthProviderFactory, tokenManager token.Manager, configuration LoginConfiguration) *TokenController {
+func NewTokenController(service *goa.Service, app application.Application, auth login.KeycloakOAuthService, linkService link.LinkOAuthService, providerConfigFactory link.OauthProviderFactory, tokenManager token.Manager, keyRotator KeyRotator, configuration LoginConfiguration) *TokenController {
 	return &TokenController{
 		Controller:            service.NewController("token"),
 		Auth:                  auth,
@@ -50,6 +60,7 @@ func NewTokenController(service *goa.Service, app application.Application, auth
 		TokenManager:          tokenManager,
 		Configuration:         configuration,
 		providerConfigFactory: providerConfigFactory,
+		keyRotator:            keyRotator,
 		app: app,
 	}
 }
@@ -66,6 +77,28 @@ func (c *TokenController) Keys(ctx *app.KeysTokenContext) error {
 	return ctx.OK(&app.PublicKeys{Keys: publicKeys.Keys})
 }
 
+// RotateKeys rotates the keys which sign the tokens of the given account type, or of all the account types
+func (c *TokenController) RotateKeys(ctx *app.RotateKeysTokenContext) error {
+	if err := checkAuthAdmin(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	accountTypes := []string{keyrepo.AccountTypeUser, keyrepo.AccountTypeServiceAccount}
+	if ctx.AccountType != nil {
+		accountTypes = []string{*ctx.AccountType}
+	}
+	for _, accountType := range accountTypes {
+		err := c.keyRotator.Rotate(ctx, accountType)
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"err":          err,
+				"account_type": accountType,
+			}, "unable to rotate the signing keys")
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+	}
+	return ctx.NoContent()
+}
+
 // Refresh obtains a new access token using the refresh token.
 func (c *TokenController) Refresh(ctx *app.RefreshTokenContext) error {
 	refreshToken := ctx.Payload.RefreshToken
@@ -338,7 +371,8 @@ func (c *TokenController) Delete(ctx *app.DeleteTokenContext) error {
 }
 
 // Exchange provides OAuth2 and OpenID Connect token exchange.
-// Currently only grant_type="client_credentials", "authorization_code", and "refresh_token" are supported.
+// Currently only grant_type="client_credentials", "authorization_code", "refresh_token"
+// and "urn:ietf:params:oauth:grant-type:uma-ticket" are supported.
 //
 // grant_type="client_credentials" allows clients to authenticate using a service account ID and secret value.
 // A service account token is returned as the result of successful exchange.
@@ -346,6 +380,9 @@ func (c *TokenController) Delete(ctx *app.DeleteTokenContext) error {
 // grant_type="authorization_code" is part of OAuth2 authorization flow.
 //
 // grant_type="refresh_token" covers OpenID Connect token refresh flow.
+//
+// grant_type="urn:ietf:params:oauth:grant-type:uma-ticket" exchanges the user access token for a Requesting Party Token (RPT)
+// with the scopes the user has been granted for the requested resource.
 func (c *TokenController) Exchange(ctx *app.ExchangeTokenContext) error {
 	payload := ctx.Payload
 	if payload == nil {
@@ -368,8 +405,10 @@ func (c *TokenController) Exchange(ctx *app.ExchangeTokenContext) error {
 		notApprovedRedirect, token, err = c.exchangeWithGrantTypeAuthorizationCode(ctx)
 	case "refresh_token":
 		token, err = c.exchangeWithGrantTypeRefreshToken(ctx)
+	case "urn:ietf:params:oauth:grant-type:uma-ticket":
+		token, err = c.exchangeWithGrantTypeUMATicket(ctx)
 	default:
-		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("grant_type", payload.GrantType).Expected("grant_type=client_credentials or grant_type=authorization_code or grant_type=refresh_token"))
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("grant_type", payload.GrantType).Expected("grant_type=client_credentials or grant_type=authorization_code or grant_type=refresh_token or grant_type=urn:ietf:params:oauth:grant-type:uma-ticket"))
 	}
 
 	if err != nil {
@@ -384,6 +423,202 @@ func (c *TokenController) Exchange(ctx *app.ExchangeTokenContext) error {
 	return ctx.OK(token)
 }
 
+// Revoke revokes the given access or refresh token as defined by RFC 7009.
+// The possession of the token is the authorization to revoke it.
+func (c *TokenController) Revoke(ctx *app.RevokeTokenContext) error {
+	payload := ctx.Payload
+	if payload == nil || payload.Token == "" {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("token", "").Expected("not empty token"))
+	}
+	err := c.TokenManager.RevokeToken(ctx, payload.Token)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err": err,
+		}, "unable to revoke token")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	ctx.ResponseData.Header().Set("Cache-Control", "no-cache")
+	return ctx.OK([]byte{})
+}
+
+// Introspect returns the state of the given access or refresh token as defined by RFC 7662.
+// Only service accounts are allowed to introspect tokens.
+func (c *TokenController) Introspect(ctx *app.IntrospectTokenContext) error {
+	if !token.IsServiceAccount(ctx) {
+		log.Error(ctx, map[string]interface{}{}, "unable to introspect token. Not a service account")
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError("not a service account"))
+	}
+	payload := ctx.Payload
+	if payload == nil || payload.Token == "" {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("token", "").Expected("not empty token"))
+	}
+	ctx.ResponseData.Header().Set("Cache-Control", "no-store")
+
+	claims, err := c.TokenManager.ParseTokenWithMapClaims(ctx, payload.Token)
+	if err != nil {
+		// Invalid, expired and revoked tokens are all reported as inactive
+		return ctx.OK(&app.TokenIntrospectionResult{Active: false})
+	}
+	result := &app.TokenIntrospectionResult{
+		Active:    true,
+		Scope:     stringClaim(claims, "scope"),
+		ClientID:  stringClaim(claims, "azp"),
+		Username:  stringClaim(claims, "preferred_username"),
+		TokenType: stringClaim(claims, "typ"),
+		Sub:       stringClaim(claims, "sub"),
+		Aud:       audienceClaim(claims),
+		Iss:       stringClaim(claims, "iss"),
+		Jti:       stringClaim(claims, "jti"),
+		Exp:       intClaim(claims, "exp"),
+		Iat:       intClaim(claims, "iat"),
+		Nbf:       intClaim(claims, "nbf"),
+	}
+	return ctx.OK(result)
+}
+
+// CreatePersonal creates a personal access token for the current user, restricted to the requested scopes.
+// Personal access tokens can not be used to create other personal access tokens.
+func (c *TokenController) CreatePersonal(ctx *app.CreatePersonalTokenContext) error {
+	if jwtToken := goajwt.ContextJWT(ctx); jwtToken != nil {
+		if claims, ok := jwtToken.Claims.(jwt.MapClaims); ok && token.IsPersonalAccessToken(claims) {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("personal access tokens can not be used to create personal access tokens"))
+		}
+	}
+	currentIdentity, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	identity, err := c.app.Identities().LoadWithUser(ctx, *currentIdentity)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+
+	tokenString, t, err := c.TokenManager.GeneratePersonalAccessToken(ctx, *identity, ctx.Payload.Name, ctx.Payload.Scopes, ctx.Payload.ExpiresAt)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":         err,
+			"identity_id": currentIdentity,
+		}, "unable to create personal access token")
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	data := convertToPersonalAccessTokenData(*t)
+	data.Token = &tokenString
+	ctx.ResponseData.Header().Set("Cache-Control", "no-store")
+	return ctx.Created(&app.PersonalAccessTokenSingle{Data: data})
+}
+
+// ListPersonal lists the personal access tokens of the current user. The tokens themselves are not returned.
+func (c *TokenController) ListPersonal(ctx *app.ListPersonalTokenContext) error {
+	currentIdentity, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	tokens, err := c.app.TokenRepository().ListPersonalForIdentity(ctx, *currentIdentity)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	result := &app.PersonalAccessTokenArray{Data: []*app.PersonalAccessTokenData{}}
+	for _, t := range tokens {
+		result.Data = append(result.Data, convertToPersonalAccessTokenData(t))
+	}
+	return ctx.OK(result)
+}
+
+// RevokePersonal revokes a personal access token of the current user
+func (c *TokenController) RevokePersonal(ctx *app.RevokePersonalTokenContext) error {
+	currentIdentity, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	err = transaction.Transactional(c.app, func(tr transaction.TransactionalResources) error {
+		t, err := tr.TokenRepository().Load(ctx, ctx.TokenID)
+		if err != nil {
+			return err
+		}
+		// The tokens of the other users are not disclosed
+		if t.IdentityID != *currentIdentity || t.TokenType != tokenrepo.TokenTypePersonal {
+			return errors.NewNotFoundError("personal access token", ctx.TokenID.String())
+		}
+		t.Status = t.Status | tokenrepo.TokenStatusRevoked
+		return tr.TokenRepository().Save(ctx, t)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	log.Info(ctx, map[string]interface{}{
+		"token_id":    ctx.TokenID,
+		"identity_id": currentIdentity,
+	}, "personal access token revoked")
+	return ctx.NoContent()
+}
+
+// convertToPersonalAccessTokenData converts a recorded personal access token to its representation in the API
+func convertToPersonalAccessTokenData(t tokenrepo.Token) *app.PersonalAccessTokenData {
+	status := "active"
+	if t.Status&tokenrepo.TokenStatusRevoked != 0 {
+		status = "revoked"
+	} else if t.ExpiryTime.Before(time.Now()) {
+		status = "expired"
+	}
+	data := &app.PersonalAccessTokenData{
+		ID:        t.TokenID.String(),
+		Scopes:    []string{},
+		Status:    status,
+		ExpiresAt: t.ExpiryTime,
+		CreatedAt: t.CreatedAt,
+	}
+	if t.Name != nil {
+		data.Name = *t.Name
+	}
+	if t.Scopes != nil {
+		data.Scopes = strings.Fields(*t.Scopes)
+	}
+	return data
+}
+
+// stringClaim returns the value of the given claim if it's a non-empty string
+func stringClaim(claims jwt.MapClaims, name string) *string {
+	if value, ok := claims[name].(string); ok && value != "" {
+		return &value
+	}
+	return nil
+}
+
+// audienceClaim returns the audience of the token, which is either a single string or an array of strings
+func audienceClaim(claims jwt.MapClaims) interface{} {
+	switch aud := claims["aud"].(type) {
+	case string:
+		if aud != "" {
+			return aud
+		}
+	case []interface{}:
+		audience := make([]string, 0, len(aud))
+		for _, value := range aud {
+			if s, ok := value.(string); ok {
+				audience = append(audience, s)
+			}
+		}
+		if len(audience) > 0 {
+			return audience
+		}
+	}
+	return nil
+}
+
+// intClaim returns the value of the given claim if it's a non-zero number
+func intClaim(claims jwt.MapClaims, name string) *int {
+	if claims[name] == nil {
+		return nil
+	}
+	value, err := token.NumberToInt(claims[name])
+	if err != nil || value == 0 {
+		return nil
+	}
+	result := int(value)
+	return &result
+}
+
 func (c *TokenController) exchangeWithGrantTypeRefreshToken(ctx *app.ExchangeTokenContext) (*app.OauthToken, error) {
 
 	payload := ctx.Payload
@@ -430,6 +665,66 @@ func (c *TokenController) exchangeWithGrantTypeRefreshToken(ctx *app.ExchangeTok
 	return token, nil
 }
 
+func (c *TokenController) exchangeWithGrantTypeUMATicket(ctx *app.ExchangeTokenContext) (*app.OauthToken, error) {
+	payload := ctx.Payload
+	if payload.ResourceID == nil {
+		return nil, errors.NewBadParameterError("resource_id", nil).Expected("not nil")
+	}
+
+	if payload.ClientID != c.Configuration.GetPublicOauthClientID() {
+		log.Error(ctx, map[string]interface{}{
+			"client_id": payload.ClientID,
+		}, "unknown oauth client id")
+		return nil, errors.NewUnauthorizedError("invalid oauth client id")
+	}
+
+	accessToken := goajwt.ContextJWT(ctx)
+	if accessToken == nil {
+		return nil, errors.NewUnauthorizedError("missing access token")
+	}
+	identity, err := login.LoadContextIdentityIfNotDeprovisioned(ctx, c.app)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.app.ResourceRepository().Load(ctx, *payload.ResourceID)
+	if err != nil {
+		if notFound, _ := errors.IsNotFoundError(err); notFound {
+			return nil, errors.NewBadParameterError("resource_id", *payload.ResourceID).Expected("existing resource")
+		}
+		return nil, err
+	}
+	scopes, err := c.app.PermissionService().ListScopes(ctx, identity.ID, res.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	if len(scopes) == 0 {
+		return nil, errors.NewForbiddenError(fmt.Sprintf("identity %s has no permissions for resource %s", identity.ID, res.ResourceID))
+	}
+
+	rpt, err := c.TokenManager.GenerateRPT(ctx, accessToken.Raw, token.Permissions{
+		ResourceSetName: &res.Name,
+		ResourceSetID:   &res.ResourceID,
+		Scopes:          scopes,
+	})
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":         err,
+			"identity_id": identity.ID,
+			"resource_id": res.ResourceID,
+		}, "unable to generate RPT")
+		return nil, err
+	}
+	ctx.ResponseData.Header().Set("Cache-Control", "no-cache")
+
+	expiresIn := strconv.FormatInt(int64(rpt.Expiry.Sub(time.Now()).Seconds()), 10)
+	return &app.OauthToken{
+		AccessToken: &rpt.AccessToken,
+		ExpiresIn:   &expiresIn,
+		TokenType:   &rpt.TokenType,
+	}, nil
+}
+
 func (c *TokenController) exchangeWithGrantTypeAuthorizationCode(ctx *app.ExchangeTokenContext) (*string, *app.OauthToken, error) {
 	payload := ctx.Payload
 	if payload.Code == nil {
@@ -519,34 +814,20 @@ func (c *TokenController) exchangeWithGrantTypeClientCredentials(ctx *app.Exchan
 		return nil, errors.NewBadParameterError("client_secret", "nil").Expected("Service Account secret")
 	}
 
-	sa, found := c.Configuration.GetServiceAccounts()[payload.ClientID]
-	if !found {
-		log.Error(ctx, map[string]interface{}{
-			"client_id":     payload.ClientID,
-			"client_secret": *payload.ClientSecret,
-		}, "Unknown Service Account ID")
-		return nil, errors.NewUnauthorizedError("invalid Service Account ID or secret")
-	}
-	secret := []byte(*payload.ClientSecret)
-	for _, hash := range sa.Secrets {
-		if bcrypt.CompareHashAndPassword([]byte(hash), secret) == nil {
-			tokenType := "bearer"
-			accessToken, err := c.TokenManager.GenerateServiceAccountToken(sa.ID, sa.Name)
-			if err != nil {
-				return nil, err
-			}
-			pat := &app.OauthToken{
-				AccessToken: &accessToken,
-				TokenType:   &tokenType,
-			}
-			return pat, nil
-		}
+	saID, saName, err := c.app.ServiceAccountService().Authenticate(ctx, payload.ClientID, *payload.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	tokenType := "bearer"
+	accessToken, err := c.TokenManager.GenerateServiceAccountToken(saID, saName)
+	if err != nil {
+		return nil, err
+	}
+	pat := &app.OauthToken{
+		AccessToken: &accessToken,
+		TokenType:   &tokenType,
 	}
-	log.Error(ctx, map[string]interface{}{
-		"client_id":     payload.ClientID,
-		"client_secret": *payload.ClientSecret,
-	}, "Service Account secret doesn't match")
-	return nil, errors.NewUnauthorizedError("invalid Service Account ID or secret")
+	return pat, nil
 }
 
 // updateProfileIfEmpty checks if the username is missing in the token record (may happen to old accounts)
@@ -2,10 +2,13 @@ package controller_test
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,8 +16,10 @@ import (
 	account "github.com/fabric8-services/fabric8-auth/account/repository"
 	"github.com/fabric8-services/fabric8-auth/app"
 	"github.com/fabric8-services/fabric8-auth/app/test"
+	"github.com/fabric8-services/fabric8-auth/authorization"
 	. "github.com/fabric8-services/fabric8-auth/controller"
 	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/goamiddleware"
 	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
 	"github.com/fabric8-services/fabric8-auth/login"
 	testsupport "github.com/fabric8-services/fabric8-auth/test"
@@ -77,7 +82,7 @@ func (rest *TestTokenREST) UnSecuredController() (*goa.Service, *TokenController
 	loginService.TokenManager = manager
 	loginService.App = rest.Application
 
-	return svc, NewTokenController(svc, rest.Application, loginService, nil, nil, manager, rest.Configuration)
+	return svc, NewTokenController(svc, rest.Application, loginService, nil, nil, manager, nil, rest.Configuration)
 }
 
 func (rest *TestTokenREST) SecuredControllerWithNonExistentIdentity() (*goa.Service, *TokenController) {
@@ -112,7 +117,7 @@ func (rest *TestTokenREST) SecuredControllerWithIdentity(identity account.Identi
 	svc := testsupport.ServiceAsUser("Token-Service", identity)
 
 	linkService := &DummyLinkService{}
-	return svc, NewTokenController(svc, rest.Application, loginService, linkService, nil, loginService.TokenManager, rest.Configuration)
+	return svc, NewTokenController(svc, rest.Application, loginService, linkService, nil, loginService.TokenManager, nil, rest.Configuration)
 }
 
 func (rest *TestTokenREST) TestPublicKeys() {
@@ -134,6 +139,50 @@ func (rest *TestTokenREST) TestPublicKeys() {
 	})
 }
 
+type dummyKeyRotator struct {
+	rotated []string
+}
+
+func (r *dummyKeyRotator) Rotate(ctx context.Context, accountType string) error {
+	r.rotated = append(r.rotated, accountType)
+	return nil
+}
+
+func (rest *TestTokenREST) SecuredControllerWithServiceAccount(name string) (*goa.Service, *TokenController, *dummyKeyRotator) {
+	svc := testsupport.ServiceAsServiceAccountUser("Token-Service", account.Identity{
+		ID:       uuid.NewV4(),
+		Username: name,
+	})
+	rotator := &dummyKeyRotator{}
+	return svc, NewTokenController(svc, rest.Application, nil, nil, nil, testtoken.TokenManager, rotator, rest.Configuration), rotator
+}
+
+func (rest *TestTokenREST) TestRotateKeys() {
+	rest.T().Run("all account types", func(t *testing.T) {
+		svc, ctrl, rotator := rest.SecuredControllerWithServiceAccount(token.AuthAdmin)
+		test.RotateKeysTokenNoContent(t, svc.Context, svc, ctrl, nil)
+		assert.Equal(t, []string{"user", "service_account"}, rotator.rotated)
+	})
+
+	rest.T().Run("one account type", func(t *testing.T) {
+		svc, ctrl, rotator := rest.SecuredControllerWithServiceAccount(token.AuthAdmin)
+		accountType := "service_account"
+		test.RotateKeysTokenNoContent(t, svc.Context, svc, ctrl, &accountType)
+		assert.Equal(t, []string{"service_account"}, rotator.rotated)
+	})
+
+	rest.T().Run("forbidden for other service accounts", func(t *testing.T) {
+		svc, ctrl, rotator := rest.SecuredControllerWithServiceAccount(token.WIT)
+		test.RotateKeysTokenForbidden(t, svc.Context, svc, ctrl, nil)
+		assert.Empty(t, rotator.rotated)
+	})
+
+	rest.T().Run("forbidden for users", func(t *testing.T) {
+		svc, ctrl := rest.SecuredController()
+		test.RotateKeysTokenForbidden(t, svc.Context, svc, ctrl, nil)
+	})
+}
+
 func (rest *TestTokenREST) checkPEM(keys *app.PublicKeys) {
 	compareWithGolden(rest.T(), filepath.Join(rest.testDir, "keys", "ok_pem.golden.json"), keys)
 }
@@ -252,6 +301,17 @@ func (rest *TestTokenREST) TestExchangeWithCorrectCredentialsOK() {
 	rest.checkServiceAccountCredentials("fabric8-tenant", "c211f1bd-17a7-4f8c-9f80-0917d167889d", "tenantsecretNew")
 }
 
+func (rest *TestTokenREST) TestExchangeWithStoredServiceAccountCredentialsOK() {
+	name := "sa-" + uuid.NewV4().String()
+	sa, _, secret, err := rest.Application.ServiceAccountService().Create(context.Background(), nil, name)
+	require.NoError(rest.T(), err)
+	rest.checkServiceAccountCredentials(name, sa.ServiceAccountID.String(), secret)
+
+	service, controller := rest.SecuredController()
+	wrongSecret := "wrong"
+	test.ExchangeTokenUnauthorized(rest.T(), service.Context, service, controller, &app.TokenExchange{GrantType: "client_credentials", ClientSecret: &wrongSecret, ClientID: sa.ServiceAccountID.String()})
+}
+
 func (rest *TestTokenREST) TestExchangeWithWrongCodeFails() {
 	rest.exchangeStrategy = "401"
 	service, controller := rest.SecuredController()
@@ -302,6 +362,279 @@ func (rest *TestTokenREST) TestGenerateOK() {
 	validateToken(rest.T(), result[0])
 }
 
+func (rest *TestTokenREST) TestExchangeWithUMATicket() {
+	viewer := rest.Graph.CreateUser()
+	space := rest.Graph.CreateSpace().AddViewer(viewer)
+	manager, err := token.NewManager(rest.Configuration, token.WithTokenRepository(rest.Application.TokenRepository()))
+	require.NoError(rest.T(), err)
+
+	umaController := func(identity account.Identity) (*goa.Service, *TokenController) {
+		tokenSet, err := manager.GenerateUserTokenForIdentity(testtoken.ContextWithRequest(nil), identity, false)
+		require.NoError(rest.T(), err)
+		accessToken, err := manager.Parse(context.Background(), tokenSet.AccessToken)
+		require.NoError(rest.T(), err)
+		svc := testsupport.ServiceAsUser("Token-Service", identity)
+		svc.Context = goajwt.WithJWT(svc.Context, accessToken)
+		return svc, NewTokenController(svc, rest.Application, nil, nil, nil, manager, nil, rest.Configuration)
+	}
+	clientID := rest.Configuration.GetPublicOauthClientID()
+	resourceID := space.SpaceID()
+	payload := &app.TokenExchange{GrantType: "urn:ietf:params:oauth:grant-type:uma-ticket", ClientID: clientID, ResourceID: &resourceID}
+
+	rest.T().Run("ok", func(t *testing.T) {
+		svc, ctrl := umaController(*viewer.Identity())
+		_, result := test.ExchangeTokenOK(t, svc.Context, svc, ctrl, payload)
+		require.NotNil(t, result.AccessToken)
+		assert.Equal(t, "Bearer", *result.TokenType)
+
+		claims, err := manager.ParseToken(context.Background(), *result.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, viewer.IdentityID().String(), claims.Subject)
+		require.NotNil(t, claims.Authorization)
+		require.Len(t, claims.Authorization.Permissions, 1)
+		permissions := claims.Authorization.Permissions[0]
+		require.NotNil(t, permissions.ResourceSetID)
+		assert.Equal(t, space.SpaceID(), *permissions.ResourceSetID)
+		assert.Contains(t, permissions.Scopes, authorization.ViewRoleAssignmentsInSpaceScope)
+		assert.NotContains(t, permissions.Scopes, authorization.ManageRoleAssignmentsInSpaceScope)
+		assert.Equal(t, claims.ExpiresAt, permissions.Expiry)
+
+		// the RPT becomes stale as soon as the roles for the resource change
+		err = rest.Application.RoleManagementService().ForceAssign(rest.Ctx, rest.Graph.CreateUser().IdentityID(), authorization.SpaceContributorRole, *space.Resource())
+		require.NoError(t, err)
+		_, err = manager.ParseToken(context.Background(), *result.AccessToken)
+		assert.Error(t, err)
+	})
+
+	rest.T().Run("forbidden without permissions", func(t *testing.T) {
+		svc, ctrl := umaController(*rest.Graph.CreateUser().Identity())
+		test.ExchangeTokenForbidden(t, svc.Context, svc, ctrl, payload)
+	})
+
+	rest.T().Run("unknown resource", func(t *testing.T) {
+		svc, ctrl := umaController(*viewer.Identity())
+		unknownResourceID := uuid.NewV4().String()
+		test.ExchangeTokenBadRequest(t, svc.Context, svc, ctrl, &app.TokenExchange{GrantType: payload.GrantType, ClientID: clientID, ResourceID: &unknownResourceID})
+	})
+
+	rest.T().Run("missing resource", func(t *testing.T) {
+		svc, ctrl := umaController(*viewer.Identity())
+		test.ExchangeTokenBadRequest(t, svc.Context, svc, ctrl, &app.TokenExchange{GrantType: payload.GrantType, ClientID: clientID})
+	})
+}
+
+func (rest *TestTokenREST) revocationController(svc *goa.Service) (*TokenController, token.Manager) {
+	manager, err := token.NewManager(rest.Configuration, token.WithTokenRepository(rest.Application.TokenRepository()))
+	require.NoError(rest.T(), err)
+	return NewTokenController(svc, rest.Application, nil, nil, nil, manager, nil, rest.Configuration), manager
+}
+
+func (rest *TestTokenREST) TestRevokeAndIntrospectToken() {
+	identity, err := testsupport.CreateTestIdentity(rest.DB, uuid.NewV4().String(), "KC")
+	require.NoError(rest.T(), err)
+	sa := account.Identity{
+		ID:       uuid.NewV4(),
+		Username: "fabric8-tenant",
+	}
+	svc := testsupport.ServiceAsServiceAccountUser("Token-Service", sa)
+	ctrl, manager := rest.revocationController(svc)
+
+	tokenSet, err := manager.GenerateUserTokenForIdentity(context.Background(), identity, false)
+	require.NoError(rest.T(), err)
+
+	rest.T().Run("generated tokens are recorded", func(t *testing.T) {
+		for _, tokenString := range []string{tokenSet.AccessToken, tokenSet.RefreshToken} {
+			claims, err := manager.ParseTokenWithMapClaims(context.Background(), tokenString)
+			require.NoError(t, err)
+			tokenID, err := uuid.FromString(claims["jti"].(string))
+			require.NoError(t, err)
+			recorded, err := rest.Application.TokenRepository().Load(context.Background(), tokenID)
+			require.NoError(t, err)
+			assert.Equal(t, identity.ID, recorded.IdentityID)
+			assert.True(t, recorded.Valid())
+		}
+	})
+
+	rest.T().Run("active token introspected", func(t *testing.T) {
+		_, result := test.IntrospectTokenOK(t, svc.Context, svc, ctrl, &app.TokenIntrospection{Token: tokenSet.AccessToken})
+		assert.True(t, result.Active)
+		require.NotNil(t, result.Sub)
+		assert.Equal(t, identity.ID.String(), *result.Sub)
+		require.NotNil(t, result.Exp)
+	})
+
+	rest.T().Run("revoked token introspected as inactive", func(t *testing.T) {
+		unsecuredSvc := testsupport.UnsecuredService("Token-Service")
+		unsecuredCtrl, _ := rest.revocationController(unsecuredSvc)
+		test.RevokeTokenOK(t, unsecuredSvc.Context, unsecuredSvc, unsecuredCtrl, &app.TokenRevocation{Token: tokenSet.AccessToken})
+
+		_, result := test.IntrospectTokenOK(t, svc.Context, svc, ctrl, &app.TokenIntrospection{Token: tokenSet.AccessToken})
+		assert.False(t, result.Active)
+		assert.Nil(t, result.Sub)
+
+		_, err := manager.ParseToken(context.Background(), tokenSet.AccessToken)
+		assert.Error(t, err)
+
+		// the refresh token is not affected
+		_, result = test.IntrospectTokenOK(t, svc.Context, svc, ctrl, &app.TokenIntrospection{Token: tokenSet.RefreshToken})
+		assert.True(t, result.Active)
+	})
+
+	rest.T().Run("revoking an invalid token is ignored", func(t *testing.T) {
+		unsecuredSvc := testsupport.UnsecuredService("Token-Service")
+		unsecuredCtrl, _ := rest.revocationController(unsecuredSvc)
+		test.RevokeTokenOK(t, unsecuredSvc.Context, unsecuredSvc, unsecuredCtrl, &app.TokenRevocation{Token: "foo"})
+		test.RevokeTokenOK(t, unsecuredSvc.Context, unsecuredSvc, unsecuredCtrl, &app.TokenRevocation{Token: tokenSet.AccessToken})
+	})
+
+	rest.T().Run("introspection unauthorized for users", func(t *testing.T) {
+		userSvc := testsupport.ServiceAsUser("Token-Service", identity)
+		userCtrl, _ := rest.revocationController(userSvc)
+		test.IntrospectTokenUnauthorized(t, userSvc.Context, userSvc, userCtrl, &app.TokenIntrospection{Token: tokenSet.RefreshToken})
+	})
+}
+
+func (rest *TestTokenREST) TestRevokeAndIntrospectFormEncodedToken() {
+	identity, err := testsupport.CreateTestIdentity(rest.DB, uuid.NewV4().String(), "KC")
+	require.NoError(rest.T(), err)
+	svc := testsupport.ServiceAsServiceAccountUser("Token-Service", account.Identity{
+		ID:       uuid.NewV4(),
+		Username: "fabric8-tenant",
+	})
+	ctrl, manager := rest.revocationController(svc)
+	app.UseJWTMiddleware(svc, goamiddleware.JWTSecurity(app.NewJWTSecurity()))
+	app.MountTokenController(svc, ctrl)
+
+	tokenSet, err := manager.GenerateUserTokenForIdentity(context.Background(), identity, false)
+	require.NoError(rest.T(), err)
+
+	// The requests are form-encoded as required by RFC 7009 and RFC 7662
+	post := func(t *testing.T, path string, values url.Values) *httptest.ResponseRecorder {
+		rq := httptest.NewRequest("POST", path, strings.NewReader(values.Encode()))
+		rq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rw := httptest.NewRecorder()
+		svc.Mux.ServeHTTP(rw, rq)
+		return rw
+	}
+	introspect := func(t *testing.T, tokenString string) map[string]interface{} {
+		rw := post(t, "/api/token/introspect", url.Values{"token": {tokenString}, "token_type_hint": {"access_token"}})
+		require.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+		result := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &result))
+		return result
+	}
+
+	rest.T().Run("active token introspected", func(t *testing.T) {
+		result := introspect(t, tokenSet.AccessToken)
+		assert.Equal(t, true, result["active"])
+		assert.Equal(t, identity.ID.String(), result["sub"])
+	})
+
+	rest.T().Run("array audience introspected", func(t *testing.T) {
+		tokenString, err := testtoken.GenerateAccessTokenWithClaims(map[string]interface{}{
+			"aud": []string{"https://openshift.io", "https://api.openshift.io"},
+		})
+		require.NoError(t, err)
+		result := introspect(t, tokenString)
+		assert.Equal(t, true, result["active"])
+		assert.Equal(t, []interface{}{"https://openshift.io", "https://api.openshift.io"}, result["aud"])
+	})
+
+	rest.T().Run("revoked token introspected as inactive", func(t *testing.T) {
+		rw := post(t, "/api/token/revoke", url.Values{"token": {tokenSet.AccessToken}, "token_type_hint": {"access_token"}})
+		require.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+		result := introspect(t, tokenSet.AccessToken)
+		assert.Equal(t, false, result["active"])
+	})
+}
+
+func (rest *TestTokenREST) TestPersonalAccessTokens() {
+	user := rest.Graph.CreateUser()
+	svc := testsupport.ServiceAsUser("Token-Service", *user.Identity())
+	ctrl, manager := rest.revocationController(svc)
+	expiresAt := time.Now().Add(24 * time.Hour)
+	payload := &app.CreatePersonalTokenPayload{
+		Name:      "ci",
+		Scopes:    []string{"spaces:read", "resources:write"},
+		ExpiresAt: expiresAt,
+	}
+
+	_, created := test.CreatePersonalTokenCreated(rest.T(), svc.Context, svc, ctrl, payload)
+	require.NotNil(rest.T(), created.Data.Token)
+	assert.Equal(rest.T(), "ci", created.Data.Name)
+	assert.Equal(rest.T(), []string{"spaces:read", "resources:write"}, created.Data.Scopes)
+	assert.Equal(rest.T(), "active", created.Data.Status)
+	assert.WithinDuration(rest.T(), expiresAt, created.Data.ExpiresAt, time.Second)
+
+	rest.T().Run("token carries its scopes", func(t *testing.T) {
+		claims, err := manager.ParseTokenWithMapClaims(context.Background(), *created.Data.Token)
+		require.NoError(t, err)
+		assert.Equal(t, user.IdentityID().String(), claims["sub"])
+		assert.Equal(t, "spaces:read resources:write", claims["scope"])
+		assert.True(t, token.IsPersonalAccessToken(claims))
+		// Only the auth service accepts personal access tokens
+		assert.Equal(t, token.PersonalAccessTokenType, claims["typ"])
+		assert.Equal(t, claims["iss"], claims["aud"])
+	})
+
+	rest.T().Run("list tokens", func(t *testing.T) {
+		_, list := test.ListPersonalTokenOK(t, svc.Context, svc, ctrl)
+		require.Len(t, list.Data, 1)
+		assert.Equal(t, created.Data.ID, list.Data[0].ID)
+		assert.Nil(t, list.Data[0].Token)
+
+		otherSvc := testsupport.ServiceAsUser("Token-Service", *rest.Graph.CreateUser().Identity())
+		otherCtrl, _ := rest.revocationController(otherSvc)
+		_, list = test.ListPersonalTokenOK(t, otherSvc.Context, otherSvc, otherCtrl)
+		assert.Empty(t, list.Data)
+	})
+
+	rest.T().Run("invalid scopes or expiry", func(t *testing.T) {
+		test.CreatePersonalTokenBadRequest(t, svc.Context, svc, ctrl, &app.CreatePersonalTokenPayload{Name: "ci", Scopes: []string{"spaces"}, ExpiresAt: expiresAt})
+		test.CreatePersonalTokenBadRequest(t, svc.Context, svc, ctrl, &app.CreatePersonalTokenPayload{Name: "ci", Scopes: []string{"spaces:read"}, ExpiresAt: time.Now().Add(-time.Hour)})
+		test.CreatePersonalTokenBadRequest(t, svc.Context, svc, ctrl, &app.CreatePersonalTokenPayload{Name: "ci", Scopes: []string{"spaces:read"}, ExpiresAt: time.Now().Add(rest.Configuration.GetPersonalAccessTokenMaxTTL() + time.Hour)})
+	})
+
+	rest.T().Run("personal access token can not create tokens", func(t *testing.T) {
+		jwtToken, err := manager.Parse(context.Background(), *created.Data.Token)
+		require.NoError(t, err)
+		patSvc := goa.New("Token-Service")
+		patSvc.Context = goajwt.WithJWT(patSvc.Context, jwtToken)
+		patCtrl, _ := rest.revocationController(patSvc)
+		test.CreatePersonalTokenForbidden(t, patSvc.Context, patSvc, patCtrl, payload)
+	})
+
+	rest.T().Run("personal access token can not be exchanged for RPT", func(t *testing.T) {
+		space := rest.Graph.CreateSpace().AddViewer(user)
+		jwtToken, err := manager.Parse(context.Background(), *created.Data.Token)
+		require.NoError(t, err)
+		patSvc := testsupport.ServiceAsUser("Token-Service", *user.Identity())
+		patSvc.Context = goajwt.WithJWT(patSvc.Context, jwtToken)
+		patCtrl, _ := rest.revocationController(patSvc)
+		resourceID := space.SpaceID()
+		test.ExchangeTokenForbidden(t, patSvc.Context, patSvc, patCtrl, &app.TokenExchange{GrantType: "urn:ietf:params:oauth:grant-type:uma-ticket", ClientID: rest.Configuration.GetPublicOauthClientID(), ResourceID: &resourceID})
+	})
+
+	rest.T().Run("revoke token", func(t *testing.T) {
+		tokenID, err := uuid.FromString(created.Data.ID)
+		require.NoError(t, err)
+
+		// Only the owner can revoke the token
+		otherSvc := testsupport.ServiceAsUser("Token-Service", *rest.Graph.CreateUser().Identity())
+		otherCtrl, _ := rest.revocationController(otherSvc)
+		test.RevokePersonalTokenNotFound(t, otherSvc.Context, otherSvc, otherCtrl, tokenID)
+		test.RevokePersonalTokenNotFound(t, svc.Context, svc, ctrl, uuid.NewV4())
+
+		test.RevokePersonalTokenNoContent(t, svc.Context, svc, ctrl, tokenID)
+		_, list := test.ListPersonalTokenOK(t, svc.Context, svc, ctrl)
+		require.Len(t, list.Data, 1)
+		assert.Equal(t, "revoked", list.Data[0].Status)
+
+		_, err = manager.Parse(context.Background(), *created.Data.Token)
+		assert.Error(t, err)
+	})
+}
+
 func validateToken(t *testing.T, token *app.AuthToken) {
 	assert.NotNil(t, token, "Token data is nil")
 	assert.NotEmpty(t, token.Token.AccessToken, "Access token is empty")
@@ -363,7 +696,7 @@ func (rest *TestTokenREST) TestExchangeWithCorrectCodeButNotApprovedUserOK() {
 	tokenManager, err := token.NewManager(rest.Configuration)
 	require.Nil(rest.T(), err)
 	oauthService := &NotApprovedOAuthService{}
-	controller := NewTokenController(svc, rest.Application, oauthService, &DummyLinkService{}, nil, tokenManager, rest.Configuration)
+	controller := NewTokenController(svc, rest.Application, oauthService, &DummyLinkService{}, nil, tokenManager, nil, rest.Configuration)
 
 	code := "XYZ"
 	_, errResp := test.ExchangeTokenForbidden(rest.T(), svc.Context, svc, controller, &app.TokenExchange{GrantType: "authorization_code", ClientID: rest.Configuration.GetPublicOauthClientID(), Code: &code})
@@ -371,7 +704,7 @@ func (rest *TestTokenREST) TestExchangeWithCorrectCodeButNotApprovedUserOK() {
 
 	oauthService = &NotApprovedOAuthService{}
 	oauthService.Scenario = "approved"
-	controller = NewTokenController(svc, rest.Application, oauthService, &DummyLinkService{}, nil, tokenManager, rest.Configuration)
+	controller = NewTokenController(svc, rest.Application, oauthService, &DummyLinkService{}, nil, tokenManager, nil, rest.Configuration)
 
 	code = "XYZ"
 	_, returnedToken := test.ExchangeTokenOK(rest.T(), svc.Context, svc, controller, &app.TokenExchange{GrantType: "authorization_code", ClientID: rest.Configuration.GetPublicOauthClientID(), Code: &code})
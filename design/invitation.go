@@ -53,6 +53,68 @@ var _ = a.Resource("invitation", func() {
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.BadRequest, JSONAPIErrors)
 	})
+
+	a.Action("resendInvite", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:inviteTo/resend"),
+		)
+		a.Params(func() {
+			a.Param("inviteTo", d.String, "Unique identifier of the invitation to resend")
+		})
+		a.Description("Renew the acceptance code and the expiry of an invitation, and send the invitation e-mail again")
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+	})
+
+	a.Action("declineInvite", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:inviteTo/decline"),
+		)
+		a.Params(func() {
+			a.Param("inviteTo", d.String, "Unique identifier of the invitation to decline")
+		})
+		a.Description("Decline an invitation issued to the current user")
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+	})
+
+	a.Action("listInvites", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:inviteTo"),
+		)
+		a.Params(func() {
+			a.Param("inviteTo", d.String, "Unique identifier of the organization, team, security group or resource")
+		})
+		a.Description("List the pending invitations to an organization, team, security group or resource")
+		a.Response(d.OK, invitationList)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+	})
+
+	a.Action("listUserInvites", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the pending invitations issued to the current user")
+		a.Response(d.OK, invitationList)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
 })
 
 var CreateInvitationRequestMedia = a.MediaType("application/vnd.create_invitation_request+json", func() {
@@ -68,6 +130,28 @@ var CreateInvitationRequestMedia = a.MediaType("application/vnd.create_invitatio
 
 var invitee = a.Type("Invitee", func() {
 	a.Attribute("identity-id", d.String, "unique id for the user identity")
+	a.Attribute("email", d.String, "e-mail address of a user who does not have an account yet", func() {
+		a.Format("email")
+	})
 	a.Attribute("member", d.Boolean, "if true invites the user to become a member")
 	a.Attribute("roles", a.ArrayOf(d.String), "An array of role names")
 })
+
+var invitationData = a.Type("InvitationData", func() {
+	a.Attribute("id", d.String, "unique id of the invitation")
+	a.Attribute("invite-to", d.String, "id of the organization, team or security group identity which the invitation is for")
+	a.Attribute("resource-id", d.String, "id of the resource which the invitation is for")
+	a.Attribute("identity-id", d.String, "id of the invited user identity, unless the user does not have an account yet")
+	a.Attribute("email", d.String, "e-mail address of the invited user who does not have an account yet")
+	a.Attribute("member", d.Boolean, "true if the user is invited to become a member")
+	a.Attribute("roles", a.ArrayOf(d.String), "An array of role names")
+	a.Attribute("created-at", d.DateTime, "The time at which the invitation was issued")
+	a.Attribute("expires-at", d.DateTime, "The time after which the invitation can not be accepted anymore")
+	a.Required("id", "member", "created-at")
+})
+
+var invitationList = JSONList(
+	"Invitation", "Holds the list of pending invitations",
+	invitationData,
+	nil,
+	nil)
@@ -33,6 +33,128 @@ var _ = a.Resource("organization", func() {
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.BadRequest, JSONAPIErrors)
 	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:orgID"),
+		)
+		a.Params(func() {
+			a.Param("orgID", d.UUID, "ID of the organization")
+		})
+		a.Description("Rename an organization")
+		a.Payload(updateOrganizationRequestMedia)
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:orgID"),
+		)
+		a.Params(func() {
+			a.Param("orgID", d.UUID, "ID of the organization")
+		})
+		a.Description("Delete an organization, along with its memberships, roles, security groups and pending invitations")
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("listGroups", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:orgID/groups"),
+		)
+		a.Params(func() {
+			a.Param("orgID", d.UUID, "ID of the organization")
+		})
+		a.Description("Lists the security groups of an organization")
+		a.Response(d.OK, securityGroupArray)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("listMembers", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:orgID/members"),
+		)
+		a.Params(func() {
+			a.Param("orgID", d.UUID, "ID of the organization")
+		})
+		a.Description("Lists the members of an organization along with their roles")
+		a.Response(d.OK, organizationMemberArray)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("removeMember", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:orgID/members/:memberID"),
+		)
+		a.Params(func() {
+			a.Param("orgID", d.UUID, "ID of the organization")
+			a.Param("memberID", d.UUID, "identity ID of the member")
+		})
+		a.Description("Removes a member from an organization, along with the roles assigned to the member for the organization")
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("updateMemberRole", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("/:orgID/members/:memberID/role"),
+		)
+		a.Params(func() {
+			a.Param("orgID", d.UUID, "ID of the organization")
+			a.Param("memberID", d.UUID, "identity ID of the member")
+		})
+		a.Description("Replaces the roles assigned to a member for an organization with the specified role")
+		a.Payload(updateOrganizationMemberRoleMedia)
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("leave", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:orgID/leave"),
+		)
+		a.Params(func() {
+			a.Param("orgID", d.UUID, "ID of the organization")
+		})
+		a.Description("Removes the current user from the members of an organization")
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
 })
 
 var CreateOrganizationRequestMedia = a.MediaType("application/vnd.create_organization_request+json", func() {
@@ -45,6 +167,19 @@ var CreateOrganizationRequestMedia = a.MediaType("application/vnd.create_organiz
 	})
 })
 
+var updateOrganizationRequestMedia = a.MediaType("application/vnd.update_organization_request+json", func() {
+	a.TypeName("UpdateOrganizationRequest")
+	a.Description("Request payload required to rename an organization")
+	a.Attributes(func() {
+		a.Attribute("name", d.String, "The new name of the organization")
+		a.Required("name")
+	})
+	a.View("default", func() {
+		a.Attribute("name")
+		a.Required("name")
+	})
+})
+
 var CreateOrganizationResponseMedia = a.MediaType("application/vnd.create_organization_response+json", func() {
 	a.Description("Response returned when creating a new organization")
 	a.Attributes(func() {
@@ -77,3 +212,39 @@ var organizationData = a.Type("OrganizationData", func() {
 	a.Attribute("roles", a.ArrayOf(d.String), "roles assigned to the user for the organization")
 	a.Required("id", "name", "member", "roles")
 })
+
+var organizationMemberArray = a.MediaType("application/vnd.organization-member-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("OrganizationMemberArray")
+	a.Description("Organization Member Array")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(organizationMemberData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var organizationMemberData = a.Type("OrganizationMemberData", func() {
+	a.Attribute("id", d.String, "unique id of the member identity")
+	a.Attribute("username", d.String, "username of the member identity")
+	a.Attribute("user_id", d.String, "unique id of the user of the member identity, if any")
+	a.Attribute("member", d.Boolean, "flag indicating whether the identity was added to the members of the organization")
+	a.Attribute("roles", a.ArrayOf(d.String), "roles assigned to the identity for the organization")
+	a.Required("id", "username", "member", "roles")
+})
+
+var updateOrganizationMemberRoleMedia = a.MediaType("application/vnd.update-organization-member-role+json", func() {
+	a.TypeName("UpdateOrganizationMemberRole")
+	a.Description("Role to assign to a member of an organization")
+	a.Attributes(func() {
+		a.Attribute("role", d.String, "name of the role")
+		a.Required("role")
+	})
+	a.View("default", func() {
+		a.Attribute("role")
+		a.Required("role")
+	})
+})
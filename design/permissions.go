@@ -0,0 +1,176 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("permissions", func() {
+
+	a.BasePath("/permissions")
+
+	a.Action("check", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/check"),
+		)
+		a.Payload(permissionCheckArray)
+		a.Description("Check whether identities have been granted scopes for resources. Service accounts may check the permissions of any identity, users only their own permissions")
+		a.Response(d.OK, permissionCheckResultArray)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("explain", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/explain"),
+		)
+		a.Params(func() {
+			a.Param("identity_id", d.String, "The ID of the identity for which the permission is explained")
+			a.Param("resource_id", d.String, "The ID of the resource for which the permission is explained")
+			a.Param("scope", d.String, "The name of the scope which is explained")
+			a.Required("identity_id", "resource_id", "scope")
+		})
+		a.Description("Explain how an identity has been granted a scope for a resource, i.e. which roles were assigned for which resources, and through which memberships and role mappings they apply. Only available to service accounts")
+		a.Response(d.OK, permissionExplanation)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("listResources", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/resources"),
+		)
+		a.Params(func() {
+			a.Param("identity_id", d.String, "The ID of the identity for which the resources are listed. Defaults to the current user, only service accounts may list the resources of other identities")
+			a.Param("resource_type", d.String, "The type of the listed resources")
+			a.Param("scope", d.String, "The name of the scope which must have been granted for the listed resources")
+			a.Param("page[offset]", d.String, "Paging start position")
+			a.Param("page[limit]", d.Integer, "Paging size")
+			a.Required("resource_type", "scope")
+		})
+		a.Description("List the resources of a type for which an identity has been granted a scope, directly or through memberships, resource ancestry and role mappings")
+		a.Response(d.OK, permittedResourceList)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var permissionCheckArray = a.MediaType("application/vnd.permission-check-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("PermissionCheckArray")
+	a.Description("Permission Check Array")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(permissionCheckData), func() {
+			a.MaxLength(100)
+		})
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var permissionCheckData = a.Type("PermissionCheckData", func() {
+	a.Attribute("identity_id", d.String, "The ID of the identity for which the permission is checked")
+	a.Attribute("resource_id", d.String, "The ID of the resource for which the permission is checked")
+	a.Attribute("scope", d.String, "The name of the scope which is checked")
+	a.Required("identity_id", "resource_id", "scope")
+})
+
+var permissionCheckResultArray = a.MediaType("application/vnd.permission-check-result-array+json", func() {
+	a.TypeName("PermissionCheckResultArray")
+	a.Description("Permission Check Result Array")
+	a.Attributes(func() {
+		// the results are in the same order as the checks in the request
+		a.Attribute("data", a.ArrayOf(permissionCheckResultData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var permissionCheckResultData = a.Type("PermissionCheckResultData", func() {
+	a.Attribute("identity_id", d.String, "The ID of the identity for which the permission was checked")
+	a.Attribute("resource_id", d.String, "The ID of the resource for which the permission was checked")
+	a.Attribute("scope", d.String, "The name of the scope which was checked")
+	a.Attribute("granted", d.Boolean, "Whether the identity has been granted the scope for the resource")
+	a.Required("identity_id", "resource_id", "scope", "granted")
+})
+
+var permissionExplanation = a.MediaType("application/vnd.permission-explanation+json", func() {
+	a.TypeName("PermissionExplanation")
+	a.Description("Explanation of a permission")
+	a.Attributes(func() {
+		a.Attribute("data", permissionExplanationData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var permissionExplanationData = a.Type("PermissionExplanationData", func() {
+	a.Attribute("identity_id", d.String, "The ID of the identity for which the permission was explained")
+	a.Attribute("resource_id", d.String, "The ID of the resource for which the permission was explained")
+	a.Attribute("scope", d.String, "The name of the scope which was explained")
+	a.Attribute("granted", d.Boolean, "Whether the identity has been granted the scope for the resource")
+	a.Attribute("grants", a.ArrayOf(scopeGrantData), "The role assignments which grant the scope")
+	a.Required("identity_id", "resource_id", "scope", "granted", "grants")
+})
+
+var scopeGrantData = a.Type("ScopeGrantData", func() {
+	a.Attribute("identity_role_id", d.String, "The ID of the role assignment")
+	a.Attribute("resource_id", d.String, "The ID of the resource for which the role was assigned, either the explained resource or one of its ancestors")
+	a.Attribute("role_name", d.String, "The name of the assigned role")
+	a.Attribute("assignee_id", d.String, "The ID of the identity which was assigned the role, either the explained identity or a team, organization or security group it is a member of")
+	a.Attribute("memberships", a.ArrayOf(membershipStepData), "The chain of memberships from the explained identity to the assignee")
+	a.Attribute("role_mappings", a.ArrayOf(roleMappingStepData), "The chain of role mappings applied to the assigned role")
+	a.Required("identity_role_id", "resource_id", "role_name", "assignee_id", "memberships", "role_mappings")
+})
+
+var membershipStepData = a.Type("MembershipStepData", func() {
+	a.Attribute("identity_id", d.String, "The ID of the identity which the previous identity in the chain is a member of")
+	a.Attribute("type", d.String, "The resource type of the identity, such as identity/team or identity/organization")
+	a.Required("identity_id")
+})
+
+var roleMappingStepData = a.Type("RoleMappingStepData", func() {
+	a.Attribute("role_mapping_id", d.String, "The ID of the role mapping")
+	a.Attribute("resource_id", d.String, "The ID of the resource for which the role mapping is defined")
+	a.Attribute("from_role_name", d.String, "The name of the role which is mapped")
+	a.Attribute("to_role_name", d.String, "The name of the role which it is mapped to")
+	a.Required("role_mapping_id", "resource_id", "from_role_name", "to_role_name")
+})
+
+var permittedResourceData = a.Type("PermittedResourceData", func() {
+	a.Attribute("id", d.String, "The ID of the resource")
+	a.Attribute("name", d.String, "The name of the resource")
+	a.Attribute("type", d.String, "The type of the resource")
+	a.Attribute("parent_resource_id", d.String, "The ID of the parent resource")
+	a.Required("id", "name", "type")
+})
+
+var permittedResourceListMeta = a.Type("PermittedResourceListMeta", func() {
+	a.Attribute("totalCount", d.Integer)
+	a.Required("totalCount")
+})
+
+var permittedResourceList = JSONList(
+	"PermittedResource", "Holds the paginated list of the resources for which an identity has been granted a scope",
+	permittedResourceData,
+	pagingLinks,
+	permittedResourceListMeta)
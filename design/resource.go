@@ -24,6 +24,26 @@ var _ = a.Resource("resource", func() {
 		a.Response(d.NotFound, JSONAPIErrors)
 	})
 
+	a.Action("list", func() {
+		a.Routing(
+			a.GET(""),
+		)
+		a.Params(func() {
+			a.Param("type", d.String, "The name of the type of the listed resources")
+			a.Param("parent_resource_id", d.String, "The identifier of the parent of the listed resources")
+			a.Param("name_prefix", d.String, "The prefix of the name of the listed resources")
+			a.Param("created_after", d.DateTime, "Only list the resources created at or after this time")
+			a.Param("created_before", d.DateTime, "Only list the resources created before this time")
+			a.Param("page[offset]", d.String, "Paging start position")
+			a.Param("page[limit]", d.Integer, "Paging size")
+		})
+		a.Description("List the registered resources, ordered by creation time")
+		a.Response(d.OK, resourceList)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+	})
+
 	a.Action("read", func() {
 		a.Routing(
 			a.GET("/:resourceId"),
@@ -56,6 +76,40 @@ var _ = a.Resource("resource", func() {
 		a.Response(d.NoContent)
 	})
 
+	a.Action("move", func() {
+		a.Routing(
+			a.PUT("/:resourceId/parent"),
+		)
+		a.Params(func() {
+			a.Param("resourceId", d.String, "Identifier of the resource to move")
+		})
+		a.Description("Move a resource under another parent resource, or make it a top level resource if no parent resource is specified")
+		a.Payload(moveResourceMedia)
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("transferOwnership", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:resourceId/ownership"),
+		)
+		a.Params(func() {
+			a.Param("resourceId", d.String, "Identifier of the resource which ownership is transferred")
+		})
+		a.Description("Transfer the ownership of a resource, and optionally of its descendants, from one identity to another")
+		a.Payload(transferResourceOwnershipMedia)
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
 })
 
 // ResourceMedia represents a protected resource
@@ -99,3 +153,50 @@ var RegisterResourceResponseMedia = a.MediaType("application/vnd.register_resour
 		a.Attribute("resource_id")
 	})
 })
+
+var moveResourceMedia = a.MediaType("application/vnd.move_resource+json", func() {
+	a.Description("Payload for moving a resource under another parent resource")
+	a.Attributes(func() {
+		a.Attribute("parent_resource_id", d.String, "The identifier of the new parent resource. The resource becomes a top level resource if not specified")
+	})
+	a.View("default", func() {
+		a.Attribute("parent_resource_id")
+	})
+})
+
+var resourceData = a.Type("ResourceData", func() {
+	a.Attribute("id", d.String, "The identifier of the resource")
+	a.Attribute("name", d.String, "The name of the resource")
+	a.Attribute("type", d.String, "The type of the resource")
+	a.Attribute("parent_resource_id", d.String, "The identifier of the parent resource")
+	a.Attribute("created_at", d.DateTime, "The time at which the resource was created")
+	a.Required("id", "name", "type", "created_at")
+})
+
+var resourceListMeta = a.Type("ResourceListMeta", func() {
+	a.Attribute("totalCount", d.Integer)
+	a.Required("totalCount")
+})
+
+var resourceList = JSONList(
+	"Resource", "Holds the paginated list of the registered resources",
+	resourceData,
+	pagingLinks,
+	resourceListMeta)
+
+var transferResourceOwnershipMedia = a.MediaType("application/vnd.transfer_resource_ownership+json", func() {
+	a.Description("Payload for transferring the ownership of a resource")
+	a.Attributes(func() {
+		a.Attribute("from_identity_id", d.UUID, "The identity which currently owns the resource")
+		a.Attribute("to_identity_id", d.UUID, "The identity which the ownership is transferred to")
+		a.Attribute("include_descendants", d.Boolean, "Whether the ownership of the descendants of the resource is transferred too", func() {
+			a.Default(false)
+		})
+		a.Required("from_identity_id", "to_identity_id")
+	})
+	a.View("default", func() {
+		a.Attribute("from_identity_id")
+		a.Attribute("to_identity_id")
+		a.Attribute("include_descendants")
+	})
+})
@@ -0,0 +1,264 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("resource_types", func() {
+
+	a.BasePath("/resource_types")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the registered resource types. Only available to the auth admin service account")
+		a.Response(d.OK, resourceTypeArray)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:resourceTypeID"),
+		)
+		a.Params(func() {
+			a.Param("resourceTypeID", d.UUID, "ID of the resource type")
+		})
+		a.Description("Show a resource type along with its scopes and roles. Only available to the auth admin service account")
+		a.Response(d.OK, resourceTypeMedia)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Payload(createResourceTypeMedia)
+		a.Description("Register a new resource type along with its scopes. Only available to the auth admin service account")
+		a.Response(d.Created, resourceTypeMedia)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:resourceTypeID"),
+		)
+		a.Params(func() {
+			a.Param("resourceTypeID", d.UUID, "ID of the resource type")
+		})
+		a.Description("Delete a resource type along with its scopes and roles. Only possible if there are no resources of this type and none of its roles is in use. Only available to the auth admin service account")
+		a.Response(d.NoContent)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("addScope", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:resourceTypeID/scopes"),
+		)
+		a.Params(func() {
+			a.Param("resourceTypeID", d.UUID, "ID of the resource type")
+		})
+		a.Payload(createResourceTypeScopeMedia)
+		a.Description("Declare a new scope for a resource type. Only available to the auth admin service account")
+		a.Response(d.NoContent)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("deleteScope", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:resourceTypeID/scopes/:scopeName"),
+		)
+		a.Params(func() {
+			a.Param("resourceTypeID", d.UUID, "ID of the resource type")
+			a.Param("scopeName", d.String, "Name of the scope")
+		})
+		a.Description("Delete a scope of a resource type, and remove it from the roles which grant it. Only available to the auth admin service account")
+		a.Response(d.NoContent)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("createRole", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:resourceTypeID/roles"),
+		)
+		a.Params(func() {
+			a.Param("resourceTypeID", d.UUID, "ID of the resource type")
+		})
+		a.Payload(createResourceTypeRoleMedia)
+		a.Description("Define a new role for a resource type, granting some of its scopes. Only available to the auth admin service account")
+		a.Response(d.NoContent)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("deleteRole", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:resourceTypeID/roles/:roleName"),
+		)
+		a.Params(func() {
+			a.Param("resourceTypeID", d.UUID, "ID of the resource type")
+			a.Param("roleName", d.String, "Name of the role")
+		})
+		a.Description("Delete a role of a resource type. Only possible if the role is not in use. Only available to the auth admin service account")
+		a.Response(d.NoContent)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("addRoleScope", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("/:resourceTypeID/roles/:roleName/scopes/:scopeName"),
+		)
+		a.Params(func() {
+			a.Param("resourceTypeID", d.UUID, "ID of the resource type")
+			a.Param("roleName", d.String, "Name of the role")
+			a.Param("scopeName", d.String, "Name of the scope")
+		})
+		a.Description("Add a scope to a role of a resource type. Only available to the auth admin service account")
+		a.Response(d.NoContent)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("removeRoleScope", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:resourceTypeID/roles/:roleName/scopes/:scopeName"),
+		)
+		a.Params(func() {
+			a.Param("resourceTypeID", d.UUID, "ID of the resource type")
+			a.Param("roleName", d.String, "Name of the role")
+			a.Param("scopeName", d.String, "Name of the scope")
+		})
+		a.Description("Remove a scope from a role of a resource type. Only available to the auth admin service account")
+		a.Response(d.NoContent)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var createResourceTypeMedia = a.MediaType("application/vnd.create-resource-type+json", func() {
+	a.TypeName("CreateResourceType")
+	a.Description("Request payload required to register a new resource type")
+	a.Attributes(func() {
+		a.Attribute("name", d.String, "The name of the new resource type", func() {
+			a.MinLength(1)
+		})
+		a.Attribute("scopes", a.ArrayOf(d.String), "The names of the scopes of the new resource type")
+		a.Required("name")
+	})
+	a.View("default", func() {
+		a.Attribute("name")
+		a.Attribute("scopes")
+		a.Required("name")
+	})
+})
+
+var createResourceTypeScopeMedia = a.MediaType("application/vnd.create-resource-type-scope+json", func() {
+	a.TypeName("CreateResourceTypeScope")
+	a.Description("Request payload required to declare a new scope for a resource type")
+	a.Attributes(func() {
+		a.Attribute("name", d.String, "The name of the new scope", func() {
+			a.MinLength(1)
+		})
+		a.Required("name")
+	})
+	a.View("default", func() {
+		a.Attribute("name")
+		a.Required("name")
+	})
+})
+
+var createResourceTypeRoleMedia = a.MediaType("application/vnd.create-resource-type-role+json", func() {
+	a.TypeName("CreateResourceTypeRole")
+	a.Description("Request payload required to define a new role for a resource type")
+	a.Attributes(func() {
+		a.Attribute("name", d.String, "The name of the new role", func() {
+			a.MinLength(1)
+		})
+		a.Attribute("scopes", a.ArrayOf(d.String), "The names of the scopes granted by the new role")
+		a.Required("name")
+	})
+	a.View("default", func() {
+		a.Attribute("name")
+		a.Attribute("scopes")
+		a.Required("name")
+	})
+})
+
+var resourceTypeMedia = a.MediaType("application/vnd.resource-type+json", func() {
+	a.TypeName("ResourceTypeSingle")
+	a.Description("Resource type along with its scopes and roles")
+	a.Attributes(func() {
+		a.Attribute("data", resourceTypeData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var resourceTypeArray = a.MediaType("application/vnd.resource-type-array+json", func() {
+	a.TypeName("ResourceTypeArray")
+	a.Description("Resource type array")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(resourceTypeData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var resourceTypeData = a.Type("ResourceTypeData", func() {
+	a.Attribute("id", d.String, "The ID of the resource type")
+	a.Attribute("name", d.String, "The name of the resource type")
+	a.Attribute("scopes", a.ArrayOf(d.String), "The names of the scopes of the resource type")
+	a.Attribute("roles", a.ArrayOf(rolesData), "The roles of the resource type, along with the scopes they grant")
+	a.Required("id", "name", "scopes", "roles")
+})
@@ -87,6 +87,21 @@ var _ = a.Resource("resource_roles", func() {
 		a.Response(d.Unauthorized, JSONAPIErrors)
 		a.Response(d.Conflict, JSONAPIErrors)
 	})
+	a.Action("revokeRole", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:resourceID/roles/:roleName"),
+		)
+		a.Payload(revokeRoleMedia)
+		a.Description("Revokes a specific role from one or more identities, for a specific resource")
+		a.Response(d.NoContent)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+	})
 })
 
 // ResourceMedia represents a protected resource
@@ -109,6 +124,7 @@ var identityRolesData = a.Type("identityRolesData", func() {
 	a.Attribute("assignee_type", d.String, "The type of assignee, example: user,group,team")
 	a.Attribute("inherited", d.Boolean)
 	a.Attribute("inherited_from", d.String, "The ID of the resource from this role was inherited")
+	a.Attribute("expires_at", d.DateTime, "The time at which the role assignment expires, if any")
 
 	a.Required("role_name", "assignee_id", "assignee_type", "inherited")
 })
@@ -130,5 +146,19 @@ var assignRoleArray = a.MediaType("application/vnd.assign-role-array+json", func
 var assignRoleData = a.Type("AssignRoleData", func() {
 	a.Attribute("role", d.String, "name of the role to assign")
 	a.Attribute("ids", a.ArrayOf(d.String), "identity ids to assign role to")
+	a.Attribute("expires_at", d.DateTime, "optional time at which the role assignment expires")
 	a.Required("role", "ids")
 })
+
+var revokeRoleMedia = a.MediaType("application/vnd.revoke-role+json", func() {
+	a.TypeName("RevokeRole")
+	a.Description("Role Revocation")
+	a.Attributes(func() {
+		a.Attribute("ids", a.ArrayOf(d.String), "identity ids to revoke the role from")
+		a.Required("ids")
+	})
+	a.View("default", func() {
+		a.Attribute("ids")
+		a.Required("ids")
+	})
+})
@@ -0,0 +1,199 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("role_mappings", func() {
+	a.BasePath("/resources")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:resourceID/role_mappings"),
+		)
+		a.Description("List the role mappings of a resource")
+		a.Response(d.OK, roleMappingArray)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:resourceID/role_mappings"),
+		)
+		a.Description("Create a role mapping for a resource")
+		a.Payload(createRoleMappingMedia)
+		a.Response(d.Created, roleMappingMedia)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:resourceID/role_mappings/:roleMappingID"),
+		)
+		a.Params(func() {
+			a.Param("roleMappingID", d.UUID, "ID of the role mapping")
+		})
+		a.Description("Delete a role mapping of a resource")
+		a.Response(d.NoContent)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("default_role_mappings", func() {
+	a.BasePath("/resource_types")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:resourceTypeID/default_role_mappings"),
+		)
+		a.Params(func() {
+			a.Param("resourceTypeID", d.UUID, "ID of the resource type")
+		})
+		a.Description("List the default role mappings of a resource type")
+		a.Response(d.OK, defaultRoleMappingArray)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:resourceTypeID/default_role_mappings"),
+		)
+		a.Params(func() {
+			a.Param("resourceTypeID", d.UUID, "ID of the resource type")
+		})
+		a.Description("Create a default role mapping for a resource type")
+		a.Payload(createRoleMappingMedia)
+		a.Response(d.Created, defaultRoleMappingMedia)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:resourceTypeID/default_role_mappings/:defaultRoleMappingID"),
+		)
+		a.Params(func() {
+			a.Param("resourceTypeID", d.UUID, "ID of the resource type")
+			a.Param("defaultRoleMappingID", d.UUID, "ID of the default role mapping")
+		})
+		a.Description("Delete a default role mapping of a resource type")
+		a.Response(d.NoContent)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var createRoleMappingMedia = a.MediaType("application/vnd.create-role-mapping+json", func() {
+	a.Description("Role mapping to create")
+	a.Attributes(func() {
+		a.Attribute("from_role", roleReferenceData, "The role which is being mapped from")
+		a.Attribute("to_role", roleReferenceData, "The role which is being mapped to")
+		a.Required("from_role", "to_role")
+	})
+	a.View("default", func() {
+		a.Attribute("from_role")
+		a.Attribute("to_role")
+		a.Required("from_role", "to_role")
+	})
+})
+
+var roleMappingMedia = a.MediaType("application/vnd.role-mapping+json", func() {
+	a.Description("A role mapping of a resource")
+	a.TypeName("RoleMappingSingle")
+	a.Attributes(func() {
+		a.Attribute("data", roleMappingData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var roleMappingArray = a.MediaType("application/vnd.role-mapping-array+json", func() {
+	a.Description("The role mappings of a resource")
+	a.TypeName("RoleMappingArray")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(roleMappingData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var defaultRoleMappingMedia = a.MediaType("application/vnd.default-role-mapping+json", func() {
+	a.Description("A default role mapping of a resource type")
+	a.TypeName("DefaultRoleMappingSingle")
+	a.Attributes(func() {
+		a.Attribute("data", defaultRoleMappingData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var defaultRoleMappingArray = a.MediaType("application/vnd.default-role-mapping-array+json", func() {
+	a.Description("The default role mappings of a resource type")
+	a.TypeName("DefaultRoleMappingArray")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(defaultRoleMappingData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var roleReferenceData = a.Type("RoleReferenceData", func() {
+	a.Attribute("role_name", d.String, "The name of the role")
+	a.Attribute("resource_type", d.String, "The name of the resource type the role belongs to")
+	a.Required("role_name", "resource_type")
+})
+
+var roleMappingData = a.Type("RoleMappingData", func() {
+	a.Attribute("id", d.String, "The ID of the role mapping")
+	a.Attribute("resource_id", d.String, "The ID of the resource the role mapping applies to")
+	a.Attribute("from_role", roleReferenceData, "The role which is being mapped from")
+	a.Attribute("to_role", roleReferenceData, "The role which is being mapped to")
+	a.Required("id", "resource_id", "from_role", "to_role")
+})
+
+var defaultRoleMappingData = a.Type("DefaultRoleMappingData", func() {
+	a.Attribute("id", d.String, "The ID of the default role mapping")
+	a.Attribute("resource_type_id", d.String, "The ID of the resource type the default role mapping applies to")
+	a.Attribute("from_role", roleReferenceData, "The role which is being mapped from")
+	a.Attribute("to_role", roleReferenceData, "The role which is being mapped to")
+	a.Required("id", "resource_type_id", "from_role", "to_role")
+})
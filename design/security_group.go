@@ -0,0 +1,223 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("security_group", func() {
+
+	a.BasePath("/groups")
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create a new security group in an organization")
+		a.Payload(createSecurityGroupRequestMedia)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.Created, createSecurityGroupResponseMedia)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+	})
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("Lists security groups that the user has access to")
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.OK, identitySecurityGroupArray)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+	})
+
+	a.Action("listMembers", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:groupID/members"),
+		)
+		a.Params(func() {
+			a.Param("groupID", d.UUID, "ID of the security group")
+		})
+		a.Description("Lists the members of a security group")
+		a.Response(d.OK, securityGroupMemberArray)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("addMembers", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:groupID/members"),
+		)
+		a.Params(func() {
+			a.Param("groupID", d.UUID, "ID of the security group")
+		})
+		a.Description("Adds one or more identities to the members of a security group")
+		a.Payload(updateSecurityGroupMembersMedia)
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("removeMembers", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:groupID/members"),
+		)
+		a.Params(func() {
+			a.Param("groupID", d.UUID, "ID of the security group")
+		})
+		a.Description("Removes one or more identities from the members of a security group")
+		a.Payload(updateSecurityGroupMembersMedia)
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("assignRole", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:groupID/roles"),
+		)
+		a.Params(func() {
+			a.Param("groupID", d.UUID, "ID of the security group")
+		})
+		a.Description("Assigns a role for a resource to a security group, granting the scopes of the role to all the members of the group")
+		a.Payload(assignSecurityGroupRoleMedia)
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
+
+var createSecurityGroupRequestMedia = a.MediaType("application/vnd.create_security_group_request+json", func() {
+	a.Description("Request payload required to create a new security group")
+	a.Attributes(func() {
+		a.Attribute("organization_id", d.UUID, "The identifier of the organization in which to create the security group")
+		a.Attribute("name", d.String, "The name of the new security group")
+		a.Required("organization_id", "name")
+	})
+	a.View("default", func() {
+		a.Attribute("organization_id")
+		a.Attribute("name")
+	})
+})
+
+var createSecurityGroupResponseMedia = a.MediaType("application/vnd.create_security_group_response+json", func() {
+	a.Description("Response returned when creating a new security group")
+	a.Attributes(func() {
+		a.Attribute("group_id", d.String, "The identifier of the new security group")
+	})
+	a.View("default", func() {
+		a.Attribute("group_id")
+	})
+})
+
+var identitySecurityGroupArray = a.MediaType("application/vnd.identity-security-group-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("IdentitySecurityGroupArray")
+	a.Description("Identity Security Group Array")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(identitySecurityGroupData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var identitySecurityGroupData = a.Type("IdentitySecurityGroupData", func() {
+	a.Attribute("id", d.String, "unique id for the security group")
+	a.Attribute("name", d.String, "name of the security group")
+	a.Attribute("member", d.Boolean, "flag indicating whether the user is a member of the security group")
+	a.Attribute("roles", a.ArrayOf(d.String), "roles assigned to the user for the security group")
+	a.Required("id", "name", "member", "roles")
+})
+
+var securityGroupArray = a.MediaType("application/vnd.security-group-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("SecurityGroupArray")
+	a.Description("Security Group Array")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(securityGroupData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var securityGroupData = a.Type("SecurityGroupData", func() {
+	a.Attribute("id", d.String, "unique id for the security group")
+	a.Attribute("name", d.String, "name of the security group")
+	a.Required("id", "name")
+})
+
+var securityGroupMemberArray = a.MediaType("application/vnd.security-group-member-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("SecurityGroupMemberArray")
+	a.Description("Security Group Member Array")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(securityGroupMemberData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var securityGroupMemberData = a.Type("SecurityGroupMemberData", func() {
+	a.Attribute("id", d.String, "unique id of the member identity")
+	a.Attribute("username", d.String, "username of the member identity")
+	a.Attribute("user_id", d.String, "unique id of the user of the member identity, if any")
+	a.Required("id", "username")
+})
+
+var updateSecurityGroupMembersMedia = a.MediaType("application/vnd.update-security-group-members+json", func() {
+	a.TypeName("UpdateSecurityGroupMembers")
+	a.Description("Identities to add to or remove from the members of a security group")
+	a.Attributes(func() {
+		a.Attribute("ids", a.ArrayOf(d.String), "identity ids of the members")
+		a.Required("ids")
+	})
+	a.View("default", func() {
+		a.Attribute("ids")
+		a.Required("ids")
+	})
+})
+
+var assignSecurityGroupRoleMedia = a.MediaType("application/vnd.assign-security-group-role+json", func() {
+	a.TypeName("AssignSecurityGroupRole")
+	a.Description("Role to assign to a security group for a resource")
+	a.Attributes(func() {
+		a.Attribute("resource_id", d.String, "identifier of the resource")
+		a.Attribute("role", d.String, "name of the role")
+		a.Required("resource_id", "role")
+	})
+	a.View("default", func() {
+		a.Attribute("resource_id")
+		a.Attribute("role")
+		a.Required("resource_id", "role")
+	})
+})
@@ -0,0 +1,166 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("service_accounts", func() {
+
+	a.BasePath("/service_accounts")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the service accounts stored in the database. Only available to the auth admin service account")
+		a.Response(d.OK, serviceAccountArray)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:serviceAccountID"),
+		)
+		a.Params(func() {
+			a.Param("serviceAccountID", d.UUID, "ID of the service account")
+		})
+		a.Description("Show a service account along with its secrets. Only available to the auth admin service account")
+		a.Response(d.OK, serviceAccountMedia)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Payload(createServiceAccountMedia)
+		a.Description("Create a new service account along with its first secret, which can not be retrieved again later. A service account of the service account configuration must be created with its configured ID, and its configured secrets are imported. Only available to the auth admin service account")
+		a.Response(d.Created, serviceAccountMedia)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("addSecret", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:serviceAccountID/secrets"),
+		)
+		a.Params(func() {
+			a.Param("serviceAccountID", d.UUID, "ID of the service account")
+		})
+		a.Description("Generate a new secret for a service account, which can not be retrieved again later. The existing secrets remain valid until they are retired. Only available to the auth admin service account")
+		a.Response(d.Created, serviceAccountSecretMedia)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("retireSecret", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:serviceAccountID/secrets/:secretID"),
+		)
+		a.Params(func() {
+			a.Param("serviceAccountID", d.UUID, "ID of the service account")
+			a.Param("secretID", d.UUID, "ID of the secret")
+			a.Param("overlap", d.Integer, "Number of seconds during which the retired secret is still accepted. Defaults to the configured overlap period", func() {
+				a.Minimum(0)
+			})
+		})
+		a.Description("Retire a secret of a service account, which is still accepted during the overlap period. Only possible if the service account has another secret which has not been retired. Only available to the auth admin service account")
+		a.Response(d.OK, serviceAccountSecretMedia)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var createServiceAccountMedia = a.MediaType("application/vnd.create-service-account+json", func() {
+	a.TypeName("CreateServiceAccount")
+	a.Description("Request payload required to create a new service account")
+	a.Attributes(func() {
+		a.Attribute("id", d.UUID, "The ID of the new service account, used as its client ID. Generated if not specified")
+		a.Attribute("name", d.String, "The name of the new service account", func() {
+			a.MinLength(1)
+		})
+		a.Required("name")
+	})
+	a.View("default", func() {
+		a.Attribute("id")
+		a.Attribute("name")
+		a.Required("name")
+	})
+})
+
+var serviceAccountMedia = a.MediaType("application/vnd.service-account+json", func() {
+	a.TypeName("ServiceAccountSingle")
+	a.Description("Service account along with its secrets")
+	a.Attributes(func() {
+		a.Attribute("data", serviceAccountData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var serviceAccountArray = a.MediaType("application/vnd.service-account-array+json", func() {
+	a.TypeName("ServiceAccountArray")
+	a.Description("Service account array")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(serviceAccountData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var serviceAccountSecretMedia = a.MediaType("application/vnd.service-account-secret+json", func() {
+	a.TypeName("ServiceAccountSecretSingle")
+	a.Description("Service account secret")
+	a.Attributes(func() {
+		a.Attribute("data", serviceAccountSecretData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var serviceAccountData = a.Type("ServiceAccountData", func() {
+	a.Attribute("id", d.String, "The ID of the service account, used as its client ID")
+	a.Attribute("name", d.String, "The name of the service account")
+	a.Attribute("created_at", d.DateTime, "The time at which the service account was created")
+	a.Attribute("last_used_at", d.DateTime, "The last time at which the service account obtained a token")
+	a.Attribute("secrets", a.ArrayOf(serviceAccountSecretData), "The secrets of the service account, including the retired ones. Not listed along with the service accounts")
+	a.Required("id", "name", "created_at")
+})
+
+var serviceAccountSecretData = a.Type("ServiceAccountSecretData", func() {
+	a.Attribute("id", d.String, "The ID of the secret")
+	a.Attribute("secret", d.String, "The value of the secret, only returned when the secret is generated")
+	a.Attribute("created_at", d.DateTime, "The time at which the secret was generated")
+	a.Attribute("expires_at", d.DateTime, "The time after which the retired secret is not accepted anymore")
+	a.Attribute("last_used_at", d.DateTime, "The last time at which the secret was used to obtain a token")
+	a.Required("id", "created_at")
+})
@@ -40,14 +40,17 @@ var _ = a.Resource("space", func() {
 		a.Routing(
 			a.POST("/:spaceID"),
 		)
-		a.Description("Create a space resource for the giving space")
+		a.Description("Create a space resource for the giving space, optionally owned by an organization")
 		a.Params(func() {
 			a.Param("spaceID", d.UUID, "ID of the space")
+			a.Param("organizationID", d.UUID, "ID of the organization owning the space")
 		})
 		a.Response(d.OK, spaceResource)
 		a.Response(d.BadRequest, JSONAPIErrors)
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
 		a.Response(d.Conflict, JSONAPIErrors)
 	})
 
@@ -84,6 +87,78 @@ var _ = a.Resource("space", func() {
 		a.Response(d.Unauthorized, JSONAPIErrors)
 		a.Response(d.Forbidden, JSONAPIErrors)
 	})
+
+	a.Action("createAccessRequest", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:spaceID/access-requests"),
+		)
+		a.Description("Request access to the specified space, optionally naming the desired role")
+		a.Params(func() {
+			a.Param("spaceID", d.String, "ID of the space")
+		})
+		a.Payload(createAccessRequestMedia)
+		a.Response(d.Created, accessRequestMedia)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("listAccessRequests", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:spaceID/access-requests"),
+		)
+		a.Description("Lists the pending access requests for the specified space")
+		a.Params(func() {
+			a.Param("spaceID", d.String, "ID of the space")
+		})
+		a.Response(d.OK, accessRequestArray)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("approveAccessRequest", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:spaceID/access-requests/:accessRequestID/approve"),
+		)
+		a.Description("Approve a pending access request for the specified space, and assign the requesting user with a role in the space")
+		a.Params(func() {
+			a.Param("spaceID", d.String, "ID of the space")
+			a.Param("accessRequestID", d.UUID, "ID of the access request")
+		})
+		a.Payload(approveAccessRequestMedia)
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("denyAccessRequest", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:spaceID/access-requests/:accessRequestID/deny"),
+		)
+		a.Description("Deny a pending access request for the specified space")
+		a.Params(func() {
+			a.Param("spaceID", d.String, "ID of the space")
+			a.Param("accessRequestID", d.UUID, "ID of the access request")
+		})
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
 })
 
 var teamArray = a.MediaType("application/vnd.team-array+json", func() {
@@ -106,3 +181,64 @@ var teamData = a.Type("TeamData", func() {
 	a.Attribute("name", d.String, "name of the team")
 	a.Required("id", "name")
 })
+
+var createAccessRequestMedia = a.MediaType("application/vnd.create-access-request+json", func() {
+	a.Description("Request for access to a space")
+	a.Attributes(func() {
+		a.Attribute("role", d.String, "The name of the role which the user would like to be granted")
+		a.Attribute("message", d.String, "A message to the administrators of the space")
+	})
+	a.View("default", func() {
+		a.Attribute("role")
+		a.Attribute("message")
+	})
+})
+
+var approveAccessRequestMedia = a.MediaType("application/vnd.approve-access-request+json", func() {
+	a.Description("Approval of a request for access to a space")
+	a.Attributes(func() {
+		a.Attribute("role", d.String, "The name of the role to assign, instead of the role named in the request")
+	})
+	a.View("default", func() {
+		a.Attribute("role")
+	})
+})
+
+var accessRequestMedia = a.MediaType("application/vnd.access-request+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("AccessRequestSingle")
+	a.Description("A request for access to a space")
+	a.Attributes(func() {
+		a.Attribute("data", accessRequestData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var accessRequestArray = a.MediaType("application/vnd.access-request-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("AccessRequestArray")
+	a.Description("Access Request Array")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(accessRequestData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var accessRequestData = a.Type("AccessRequestData", func() {
+	a.Attribute("id", d.String, "unique id of the access request")
+	a.Attribute("space_id", d.String, "id of the space")
+	a.Attribute("identity_id", d.String, "id of the identity of the requesting user")
+	a.Attribute("role", d.String, "name of the role requested by the user")
+	a.Attribute("message", d.String, "message from the user to the administrators of the space")
+	a.Attribute("status", d.String, "status of the request, one of pending, approved or denied")
+	a.Attribute("created_at", d.DateTime, "The time at which the request was filed")
+	a.Required("id", "space_id", "identity_id", "status", "created_at")
+})
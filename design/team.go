@@ -34,6 +34,93 @@ var _ = a.Resource("team", func() {
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.BadRequest, JSONAPIErrors)
 	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:teamID"),
+		)
+		a.Params(func() {
+			a.Param("teamID", d.UUID, "ID of the team")
+		})
+		a.Description("Rename a team")
+		a.Payload(updateTeamRequestMedia)
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:teamID"),
+		)
+		a.Params(func() {
+			a.Param("teamID", d.UUID, "ID of the team")
+		})
+		a.Description("Delete a team, along with its memberships, roles and pending invitations")
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("listMembers", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:teamID/members"),
+		)
+		a.Params(func() {
+			a.Param("teamID", d.UUID, "ID of the team")
+		})
+		a.Description("Lists the members of a team")
+		a.Response(d.OK, teamMemberArray)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("addMembers", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:teamID/members"),
+		)
+		a.Params(func() {
+			a.Param("teamID", d.UUID, "ID of the team")
+		})
+		a.Description("Adds one or more identities to the members of a team")
+		a.Payload(updateTeamMembersMedia)
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("removeMembers", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:teamID/members"),
+		)
+		a.Params(func() {
+			a.Param("teamID", d.UUID, "ID of the team")
+		})
+		a.Description("Removes one or more identities from the members of a team")
+		a.Payload(updateTeamMembersMedia)
+		a.Response(d.NoContent)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
 })
 
 var CreateTeamRequestMedia = a.MediaType("application/vnd.create_team_request+json", func() {
@@ -48,6 +135,19 @@ var CreateTeamRequestMedia = a.MediaType("application/vnd.create_team_request+js
 	})
 })
 
+var updateTeamRequestMedia = a.MediaType("application/vnd.update_team_request+json", func() {
+	a.TypeName("UpdateTeamRequest")
+	a.Description("Request payload required to rename a team")
+	a.Attributes(func() {
+		a.Attribute("name", d.String, "The new name of the team")
+		a.Required("name")
+	})
+	a.View("default", func() {
+		a.Attribute("name")
+		a.Required("name")
+	})
+})
+
 var CreateTeamResponseMedia = a.MediaType("application/vnd.create_team_response+json", func() {
 	a.Description("Response returned when creating a new team")
 	a.Attributes(func() {
@@ -81,3 +181,37 @@ var identityTeamData = a.Type("IdentityTeamData", func() {
 	a.Attribute("roles", a.ArrayOf(d.String), "roles assigned to the user for the team")
 	a.Required("id", "name", "space_id", "member", "roles")
 })
+
+var teamMemberArray = a.MediaType("application/vnd.team-member-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("TeamMemberArray")
+	a.Description("Team Member Array")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(teamMemberData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var teamMemberData = a.Type("TeamMemberData", func() {
+	a.Attribute("id", d.String, "unique id of the member identity")
+	a.Attribute("username", d.String, "username of the member identity")
+	a.Attribute("user_id", d.String, "unique id of the user of the member identity, if any")
+	a.Required("id", "username")
+})
+
+var updateTeamMembersMedia = a.MediaType("application/vnd.update-team-members+json", func() {
+	a.TypeName("UpdateTeamMembers")
+	a.Description("Identities to add to or remove from the members of a team")
+	a.Attributes(func() {
+		a.Attribute("ids", a.ArrayOf(d.String), "identity ids of the members")
+		a.Required("ids")
+	})
+	a.View("default", func() {
+		a.Attribute("ids")
+		a.Required("ids")
+	})
+})
@@ -115,6 +115,73 @@ var _ = a.Resource("token", func() {
 		a.Response(d.Forbidden, JSONAPIErrors)
 	})
 
+	a.Action("revoke", func() {
+		a.Routing(
+			a.POST("revoke"),
+		)
+		a.Payload(tokenRevocation)
+		a.Description("Revoke an access or refresh token as defined by RFC 7009. The request body is form-encoded (application/x-www-form-urlencoded) as required by the RFC, or JSON-encoded. Invalid, expired or already revoked tokens are ignored")
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("introspect", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("introspect"),
+		)
+		a.Payload(tokenIntrospection)
+		a.Description("Introspect an access or refresh token as defined by RFC 7662. The request body is form-encoded (application/x-www-form-urlencoded) as required by the RFC, or JSON-encoded. Only available to service accounts")
+		a.Response(d.OK, func() {
+			a.Media(tokenIntrospectionResult)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("createPersonal", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("personal"),
+		)
+		a.Payload(createPersonalAccessTokenMedia)
+		a.Description("Create a personal access token for the current user, restricted to the given scopes and valid until the given time. The token can not be retrieved again later")
+		a.Response(d.Created, personalAccessTokenMedia)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("listPersonal", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("personal"),
+		)
+		a.Description("List the personal access tokens of the current user")
+		a.Response(d.OK, personalAccessTokenArray)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("revokePersonal", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("personal/:tokenID"),
+		)
+		a.Params(func() {
+			a.Param("tokenID", d.UUID, "ID of the personal access token")
+		})
+		a.Description("Revoke a personal access token of the current user")
+		a.Response(d.NoContent)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
 	a.Action("keys", func() {
 		a.Routing(
 			a.GET("keys"),
@@ -131,6 +198,25 @@ var _ = a.Resource("token", func() {
 		})
 	})
 
+	a.Action("rotateKeys", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("keys/rotate"),
+		)
+		a.Params(func() {
+			a.Param("account_type", d.String, func() {
+				a.Enum("user", "service_account")
+				a.Description("Type of the accounts whose signing keys are rotated. The keys of both types are rotated if not set")
+			})
+		})
+		a.Description("Rotate the keys which sign the tokens: the pending key starts signing the new tokens, the active key is still published until it is retired, and a new pending key is published. Only available to the auth admin service account")
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
 	a.Action("generate", func() {
 		a.Routing(
 			a.GET("generate"),
@@ -213,17 +299,72 @@ var refreshToken = a.Type("RefreshToken", func() {
 
 var tokenExchange = a.Type("TokenExchange", func() {
 	a.Attribute("grant_type", d.String, func() {
-		a.Enum("client_credentials", "authorization_code", "refresh_token")
-		a.Description("Grant type. If set to \"client_credentials\" then this token exchange request is for a Protection API Token (PAT). PAT can be used to authenticate the corresponding Service Account. If the Grant Type is \"authorization_code\" we can use a authorization_code to get access_token")
+		a.Enum("client_credentials", "authorization_code", "refresh_token", "urn:ietf:params:oauth:grant-type:uma-ticket")
+		a.Description("Grant type. If set to \"client_credentials\" then this token exchange request is for a Protection API Token (PAT). PAT can be used to authenticate the corresponding Service Account. If the Grant Type is \"authorization_code\" we can use a authorization_code to get access_token. If the Grant Type is \"urn:ietf:params:oauth:grant-type:uma-ticket\" then the access token from the Authorization header is exchanged for a Requesting Party Token (RPT) with the permissions for the requested resource")
 	})
 	a.Attribute("client_id", d.String, "Service Account ID. Used to obtain a PAT for this service account.")
 	a.Attribute("client_secret", d.String, "Service Account secret. Used to obtain a PAT for this service account.")
 	a.Attribute("redirect_uri", d.String, "Must be identical to the redirect URI provided while getting the authorization_code")
 	a.Attribute("code", d.String, "this is the authorization_code you received from /api/authorize endpoint")
 	a.Attribute("refresh_token", d.String, "Refresh Token")
+	a.Attribute("resource_id", d.String, "The ID of the resource for which the permissions are requested in the RPT")
 	a.Required("grant_type", "client_id")
 })
 
+var tokenRevocation = a.Type("TokenRevocation", func() {
+	a.Attribute("token", d.String, "The token to revoke")
+	a.Attribute("token_type_hint", d.String, func() {
+		a.Enum("access_token", "refresh_token")
+		a.Description("A hint about the type of the token submitted for revocation")
+	})
+	a.Required("token")
+})
+
+var tokenIntrospection = a.Type("TokenIntrospection", func() {
+	a.Attribute("token", d.String, "The token to introspect")
+	a.Attribute("token_type_hint", d.String, func() {
+		a.Enum("access_token", "refresh_token")
+		a.Description("A hint about the type of the token submitted for introspection")
+	})
+	a.Required("token")
+})
+
+// tokenIntrospectionResult represents the result of a token introspection
+var tokenIntrospectionResult = a.MediaType("application/vnd.tokenintrospection+json", func() {
+	a.TypeName("TokenIntrospectionResult")
+	a.Description("Token introspection result as defined by RFC 7662")
+	a.Attributes(func() {
+		a.Attribute("active", d.Boolean, "Whether or not the token is currently active")
+		a.Attribute("scope", d.String, "Space-separated list of scopes associated with the token")
+		a.Attribute("client_id", d.String, "Client identifier for the client that requested the token")
+		a.Attribute("username", d.String, "Username of the identity who authorized the token")
+		a.Attribute("token_type", d.String, "Type of the token")
+		a.Attribute("exp", d.Integer, "Expiration time of the token in seconds since epoch")
+		a.Attribute("iat", d.Integer, "Time the token was issued in seconds since epoch")
+		a.Attribute("nbf", d.Integer, "Time before which the token is not valid in seconds since epoch")
+		a.Attribute("sub", d.String, "Subject of the token")
+		a.Attribute("aud", d.Any, "Intended audience of the token, either a string or an array of strings")
+		a.Attribute("iss", d.String, "Issuer of the token")
+		a.Attribute("jti", d.String, "Identifier of the token")
+		a.Required("active")
+	})
+	a.View("default", func() {
+		a.Attribute("active")
+		a.Attribute("scope")
+		a.Attribute("client_id")
+		a.Attribute("username")
+		a.Attribute("token_type")
+		a.Attribute("exp")
+		a.Attribute("iat")
+		a.Attribute("nbf")
+		a.Attribute("sub")
+		a.Attribute("aud")
+		a.Attribute("iss")
+		a.Attribute("jti")
+		a.Required("active")
+	})
+})
+
 // AuthToken represents an authentication JWT Token
 var AuthToken = a.MediaType("application/vnd.authtoken+json", func() {
 	a.TypeName("AuthToken")
@@ -280,3 +421,58 @@ var redirectLocation = a.MediaType("application/vnd.redirectlocation+json", func
 		a.Required("redirect_location")
 	})
 })
+
+var createPersonalAccessTokenMedia = a.MediaType("application/vnd.create-personal-access-token+json", func() {
+	a.Description("Request for a personal access token")
+	a.Attributes(func() {
+		a.Attribute("name", d.String, "The name of the token, to tell it apart from the other tokens of the user")
+		a.Attribute("scopes", a.ArrayOf(d.String), "The scopes to which the token is restricted, e.g. spaces:read or resources:write")
+		a.Attribute("expires_at", d.DateTime, "The time at which the token expires")
+		a.Required("name", "scopes", "expires_at")
+	})
+	a.View("default", func() {
+		a.Attribute("name")
+		a.Attribute("scopes")
+		a.Attribute("expires_at")
+		a.Required("name", "scopes", "expires_at")
+	})
+})
+
+var personalAccessTokenMedia = a.MediaType("application/vnd.personal-access-token+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("PersonalAccessTokenSingle")
+	a.Description("A personal access token")
+	a.Attributes(func() {
+		a.Attribute("data", personalAccessTokenData)
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var personalAccessTokenArray = a.MediaType("application/vnd.personal-access-token-array+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("PersonalAccessTokenArray")
+	a.Description("Personal Access Token Array")
+	a.Attributes(func() {
+		a.Attribute("data", a.ArrayOf(personalAccessTokenData))
+		a.Required("data")
+	})
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Required("data")
+	})
+})
+
+var personalAccessTokenData = a.Type("PersonalAccessTokenData", func() {
+	a.Attribute("id", d.String, "unique id of the token")
+	a.Attribute("name", d.String, "name of the token")
+	a.Attribute("scopes", a.ArrayOf(d.String), "scopes to which the token is restricted")
+	a.Attribute("status", d.String, "status of the token, one of active, revoked or expired")
+	a.Attribute("expires_at", d.DateTime, "The time at which the token expires")
+	a.Attribute("created_at", d.DateTime, "The time at which the token was created")
+	a.Attribute("token", d.String, "the token itself, only returned when the token is created")
+	a.Required("id", "name", "scopes", "status", "expires_at", "created_at")
+})
@@ -1,3 +1,3 @@
 // This package contains a custom goa middleware that aims to extract, when possible,
-// the token from the http requests.
+// the token from the http requests, and a middleware which enforces the JWT security scheme.
 package goamiddleware
@@ -0,0 +1,33 @@
+package goamiddleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/jwt"
+)
+
+// JWTSecurity is a goa middleware which enforces the JWT security scheme of the secured actions.
+// It replaces the goa JWT middleware, which only verifies RSA, ECDSA and HMAC signatures: the token is parsed and
+// verified with the current signing keys by the TokenContext middleware, so the request is accepted if the token
+// has been stored in the context.
+func JWTSecurity(scheme *goa.JWTSecurity) goa.Middleware {
+	return func(nextHandler goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if jwt.ContextJWT(ctx) != nil {
+				return nextHandler(ctx, rw, req)
+			}
+			val := req.Header.Get(scheme.Name)
+			if val == "" {
+				return jwt.ErrJWTError(fmt.Sprintf("missing header %q", scheme.Name))
+			}
+			if !strings.HasPrefix(strings.ToLower(val), "bearer ") {
+				return jwt.ErrJWTError(fmt.Sprintf("invalid or malformed %q header, expected 'Authorization: Bearer JWT-token...'", val))
+			}
+			return jwt.ErrJWTError("JWT validation failed")
+		}
+	}
+}
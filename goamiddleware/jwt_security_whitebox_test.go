@@ -0,0 +1,52 @@
+package goamiddleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	testsuite "github.com/fabric8-services/fabric8-auth/test/suite"
+	testtoken "github.com/fabric8-services/fabric8-auth/test/token"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/jwt"
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestJWTSecurity(t *testing.T) {
+	suite.Run(t, &TestJWTSecuritySuite{})
+}
+
+type TestJWTSecuritySuite struct {
+	testsuite.UnitTestSuite
+}
+
+func (s *TestJWTSecuritySuite) TestSecurity() {
+	h := JWTSecurity(&goa.JWTSecurity{In: goa.LocHeader, Name: "Authorization"})(dummyHandler)
+
+	check := func(ctx context.Context, authorization string, expectedErr string) {
+		rq := &http.Request{Header: make(map[string][]string)}
+		if authorization != "" {
+			rq.Header.Set("Authorization", authorization)
+		}
+		err := h(ctx, httptest.NewRecorder(), rq)
+		require.Error(s.T(), err)
+		assert.Contains(s.T(), err.Error(), expectedErr)
+	}
+
+	// Get 401 if there is no valid token in the context
+	check(context.Background(), "", "401 jwt_security_error: missing header \"Authorization\"")
+	check(context.Background(), "something", "401 jwt_security_error: invalid or malformed \"something\" header")
+	check(context.Background(), "Bearer token", "401 jwt_security_error: JWT validation failed")
+
+	// OK if the token has been validated and stored in the context
+	t, err := testtoken.TokenManager.GenerateServiceAccountToken(uuid.NewV4().String(), "sa-name")
+	require.NoError(s.T(), err)
+	jwtToken, err := testtoken.TokenManager.Parse(context.Background(), t)
+	require.NoError(s.T(), err)
+	check(jwt.WithJWT(context.Background(), jwtToken), "Bearer "+t, "next-handler-error")
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/fabric8-services/fabric8-auth/log"
 	"github.com/fabric8-services/fabric8-auth/token"
 
+	jwtgo "github.com/dgrijalva/jwt-go"
 	"github.com/goadesign/goa"
 	"github.com/goadesign/goa/middleware/security/jwt"
 )
@@ -17,14 +18,16 @@ import (
 // Authorization header when possible. If the Authorization header is missing in the request,
 // no error is returned. However, if the Authorization header contains a
 // token, it will be stored it in the context.
+// Personal access tokens are only accepted for the requests within their scopes.
 func TokenContext(tokenManager token.Manager, scheme *goa.JWTSecurity) goa.Middleware {
 	errUnauthorized := goa.NewErrorClass("token_validation_failed", 401)
+	errForbidden := goa.NewErrorClass("insufficient_scope", 403)
 	return func(nextHandler goa.Handler) goa.Handler {
-		return handler(tokenManager, scheme, nextHandler, errUnauthorized)
+		return handler(tokenManager, scheme, nextHandler, errUnauthorized, errForbidden)
 	}
 }
 
-func handler(tokenManager token.Manager, scheme *goa.JWTSecurity, nextHandler goa.Handler, errUnauthorized goa.ErrorClass) goa.Handler {
+func handler(tokenManager token.Manager, scheme *goa.JWTSecurity, nextHandler goa.Handler, errUnauthorized goa.ErrorClass, errForbidden goa.ErrorClass) goa.Handler {
 	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// TODO: implement the QUERY string handler too
 		if scheme.In != goa.LocHeader {
@@ -37,13 +40,23 @@ func handler(tokenManager token.Manager, scheme *goa.JWTSecurity, nextHandler go
 			incomingToken := strings.Split(val, " ")[1]
 			log.Debug(ctx, nil, "extracted the incoming token %v ", incomingToken)
 
-			token, err := tokenManager.Parse(ctx, incomingToken)
+			jwtToken, err := tokenManager.Parse(ctx, incomingToken)
 			if err != nil {
 				log.Error(ctx, map[string]interface{}{"error": err}, "failed to handle JSON Web Token in TokenContext middleware")
 				tokenManager.AddLoginRequiredHeader(rw)
 				return errUnauthorized("token is invalid")
 			}
-			ctx = jwt.WithJWT(ctx, token)
+			if claims, ok := jwtToken.Claims.(jwtgo.MapClaims); ok && token.IsPersonalAccessToken(claims) {
+				if !token.PersonalAccessTokenAllows(claims, req.Method, req.URL.Path) {
+					log.Warn(ctx, map[string]interface{}{
+						"token_id": claims["jti"],
+						"method":   req.Method,
+						"path":     req.URL.Path,
+					}, "personal access token used outside of its scopes")
+					return errForbidden("the personal access token does not grant access to this endpoint")
+				}
+			}
+			ctx = jwt.WithJWT(ctx, jwtToken)
 		}
 
 		return nextHandler(ctx, rw, req)
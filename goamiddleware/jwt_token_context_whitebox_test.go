@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/textproto"
+	"net/url"
 	"testing"
 
 	testsuite "github.com/fabric8-services/fabric8-auth/test/suite"
@@ -29,10 +30,11 @@ type TestJWTokenContextSuite struct {
 func (s *TestJWTokenContextSuite) TestHandler() {
 	schema := &goa.JWTSecurity{}
 	errUnauthorized := goa.NewErrorClass("token_validation_failed", 401)
+	errForbidden := goa.NewErrorClass("insufficient_scope", 403)
 
 	rw := httptest.NewRecorder()
 	rq := &http.Request{Header: make(map[string][]string)}
-	h := handler(testtoken.TokenManager, schema, dummyHandler, errUnauthorized)
+	h := handler(testtoken.TokenManager, schema, dummyHandler, errUnauthorized, errForbidden)
 
 	err := h(context.Background(), rw, rq)
 	require.Error(s.T(), err)
@@ -72,6 +74,40 @@ func (s *TestJWTokenContextSuite) TestHandler() {
 	assert.NotContains(s.T(), header, "Access-Control-Expose-Headers")
 }
 
+func (s *TestJWTokenContextSuite) TestHandlerWithPersonalAccessToken() {
+	schema := &goa.JWTSecurity{In: "header", Name: "Authorization"}
+	errUnauthorized := goa.NewErrorClass("token_validation_failed", 401)
+	errForbidden := goa.NewErrorClass("insufficient_scope", 403)
+	h := handler(testtoken.TokenManager, schema, dummyHandler, errUnauthorized, errForbidden)
+
+	t, err := testtoken.GenerateToken(uuid.NewV4().String(), "pat-owner")
+	require.NoError(s.T(), err)
+	pat, err := testtoken.UpdateToken(t, map[string]interface{}{
+		"typ":   "Personal",
+		"scope": "spaces:read resources:write",
+	})
+	require.NoError(s.T(), err)
+
+	check := func(method, path string, expectedErr string) {
+		rq := &http.Request{Method: method, URL: &url.URL{Path: path}, Header: make(map[string][]string)}
+		rq.Header.Set("Authorization", "Bearer "+pat)
+		err := h(context.Background(), httptest.NewRecorder(), rq)
+		require.Error(s.T(), err)
+		assert.Contains(s.T(), err.Error(), expectedErr, "%s %s", method, path)
+	}
+
+	// OK within the scopes of the token
+	check("GET", "/api/spaces/"+uuid.NewV4().String()+"/roles", "next-handler-error")
+	check("GET", "/api/resources/"+uuid.NewV4().String(), "next-handler-error")
+	check("PATCH", "/api/resources/"+uuid.NewV4().String(), "next-handler-error")
+
+	// Get 403 outside of the scopes of the token
+	check("PUT", "/api/spaces/"+uuid.NewV4().String()+"/roles", "403 insufficient_scope")
+	check("GET", "/api/users", "403 insufficient_scope")
+	check("POST", "/api/token/personal", "403 insufficient_scope")
+	check("GET", "/favicon.ico", "403 insufficient_scope")
+}
+
 func dummyHandler(ctx context.Context, rw http.ResponseWriter, r *http.Request) error {
 	return errors.New("next-handler-error")
 }
@@ -5,17 +5,20 @@ import (
 	"strconv"
 
 	account "github.com/fabric8-services/fabric8-auth/account/repository"
+	serviceaccount "github.com/fabric8-services/fabric8-auth/account/serviceaccount/repository"
 	"github.com/fabric8-services/fabric8-auth/application/service"
 	"github.com/fabric8-services/fabric8-auth/application/service/context"
 	"github.com/fabric8-services/fabric8-auth/application/service/factory"
 	"github.com/fabric8-services/fabric8-auth/application/transaction"
 	"github.com/fabric8-services/fabric8-auth/auth"
+	accessrequest "github.com/fabric8-services/fabric8-auth/authorization/accessrequest/repository"
 	invitation "github.com/fabric8-services/fabric8-auth/authorization/invitation/repository"
 	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
 	resourcetype "github.com/fabric8-services/fabric8-auth/authorization/resourcetype/repository"
 	role "github.com/fabric8-services/fabric8-auth/authorization/role/repository"
 	token "github.com/fabric8-services/fabric8-auth/authorization/token/repository"
 	"github.com/fabric8-services/fabric8-auth/configuration"
+	signingkey "github.com/fabric8-services/fabric8-auth/token/keystore/repository"
 	"github.com/fabric8-services/fabric8-auth/token/provider"
 
 	"github.com/jinzhu/gorm"
@@ -106,10 +109,18 @@ func (g *GormBase) InvitationRepository() invitation.InvitationRepository {
 	return invitation.NewInvitationRepository(g.db)
 }
 
+func (g *GormBase) AccessRequestRepository() accessrequest.AccessRequestRepository {
+	return accessrequest.NewAccessRequestRepository(g.db)
+}
+
 func (g *GormBase) ResourceRepository() resource.ResourceRepository {
 	return resource.NewResourceRepository(g.db)
 }
 
+func (g *GormBase) ResourceOwnershipTransferRepository() resource.ResourceOwnershipTransferRepository {
+	return resource.NewResourceOwnershipTransferRepository(g.db)
+}
+
 func (g *GormBase) ResourceTypeRepository() resourcetype.ResourceTypeRepository {
 	return resourcetype.NewResourceTypeRepository(g.db)
 }
@@ -122,6 +133,10 @@ func (g *GormBase) RoleRepository() role.RoleRepository {
 	return role.NewRoleRepository(g.db)
 }
 
+func (g *GormBase) RoleScopeRepository() role.RoleScopeRepository {
+	return role.NewRoleScopeRepository(g.db)
+}
+
 func (g *GormBase) IdentityRoleRepository() role.IdentityRoleRepository {
 	return role.NewIdentityRoleRepository(g.db)
 }
@@ -138,6 +153,22 @@ func (g *GormBase) TokenRepository() token.TokenRepository {
 	return token.NewTokenRepository(g.db)
 }
 
+func (g *GormBase) SigningKeyRepository() signingkey.SigningKeyRepository {
+	return signingkey.NewSigningKeyRepository(g.db)
+}
+
+func (g *GormBase) ServiceAccountRepository() serviceaccount.ServiceAccountRepository {
+	return serviceaccount.NewServiceAccountRepository(g.db)
+}
+
+func (g *GormBase) ServiceAccountSecretRepository() serviceaccount.ServiceAccountSecretRepository {
+	return serviceaccount.NewServiceAccountSecretRepository(g.db)
+}
+
+func (g *GormDB) AccessRequestService() service.AccessRequestService {
+	return g.serviceFactory.AccessRequestService()
+}
+
 func (g *GormDB) InvitationService() service.InvitationService {
 	return g.serviceFactory.InvitationService()
 }
@@ -154,14 +185,26 @@ func (g *GormDB) RoleManagementService() service.RoleManagementService {
 	return g.serviceFactory.RoleManagementService()
 }
 
+func (g *GormDB) RoleMappingService() service.RoleMappingService {
+	return g.serviceFactory.RoleMappingService()
+}
+
 func (g *GormDB) TeamService() service.TeamService {
 	return g.serviceFactory.TeamService()
 }
 
+func (g *GormDB) GroupService() service.GroupService {
+	return g.serviceFactory.GroupService()
+}
+
 func (g *GormDB) ResourceService() service.ResourceService {
 	return g.serviceFactory.ResourceService()
 }
 
+func (g *GormDB) ResourceTypeService() service.ResourceTypeService {
+	return g.serviceFactory.ResourceTypeService()
+}
+
 func (g *GormDB) SpaceService() service.SpaceService {
 	return g.serviceFactory.SpaceService()
 }
@@ -178,6 +221,10 @@ func (g *GormDB) WITService() service.WITService {
 	return g.serviceFactory.WITService()
 }
 
+func (g *GormDB) ServiceAccountService() service.ServiceAccountService {
+	return g.serviceFactory.ServiceAccountService()
+}
+
 func (g *GormBase) DB() *gorm.DB {
 	return g.db
 }
@@ -723,6 +723,9 @@ func (keycloak *KeycloakOAuthProvider) CreateOrUpdateIdentityInDB(ctx context.Co
 			return nil, false, errors.New("failed to create user/identity " + err.Error())
 		}
 		newIdentityCreated = true
+
+		// Bind the invitations which were sent to the user before they had an account
+		keycloak.bindEmailInvitations(ctx, identity)
 	} else {
 		identity = &identities[0]
 
@@ -736,10 +739,29 @@ func (keycloak *KeycloakOAuthProvider) CreateOrUpdateIdentityInDB(ctx context.Co
 			}, "Found Keycloak identity is not linked to any User")
 			return nil, false, errors.New("found Keycloak identity is not linked to any User")
 		}
+
+		// Bind the invitations which were sent to the e-mail address of the user since they last logged in, or
+		// before it was verified
+		keycloak.bindEmailInvitations(ctx, identity)
 	}
 	return identity, newIdentityCreated, err
 }
 
+// bindEmailInvitations binds the pending invitations addressed to the e-mail address of the user to their identity,
+// if that e-mail address has been verified. The user is logging in, so the errors are only logged.
+func (keycloak *KeycloakOAuthProvider) bindEmailInvitations(ctx context.Context, identity *account.Identity) {
+	if !identity.User.EmailVerified || identity.User.Email == "" {
+		return
+	}
+	err := keycloak.App.InvitationService().BindEmailInvitations(ctx, identity.ID, identity.User.Email)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"identity_id": identity.ID,
+			"err":         err,
+		}, "unable to bind the pending invitations to the identity")
+	}
+}
+
 func (keycloak *KeycloakOAuthProvider) updateWITUser(ctx context.Context, identity *account.Identity, witURL string, identityID string) error {
 	updateUserPayload := &app.UpdateUsersPayload{
 		Data: &app.UpdateUserData{
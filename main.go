@@ -12,6 +12,9 @@ import (
 	accountservice "github.com/fabric8-services/fabric8-auth/account/service"
 	"github.com/fabric8-services/fabric8-auth/app"
 	"github.com/fabric8-services/fabric8-auth/application/transaction"
+	invitationservice "github.com/fabric8-services/fabric8-auth/authorization/invitation/service"
+	permissioncache "github.com/fabric8-services/fabric8-auth/authorization/permission/cache"
+	roleservice "github.com/fabric8-services/fabric8-auth/authorization/role/service"
 	"github.com/fabric8-services/fabric8-auth/configuration"
 	"github.com/fabric8-services/fabric8-auth/controller"
 	"github.com/fabric8-services/fabric8-auth/goamiddleware"
@@ -24,13 +27,13 @@ import (
 	"github.com/fabric8-services/fabric8-auth/sentry"
 	"github.com/fabric8-services/fabric8-auth/token"
 	"github.com/fabric8-services/fabric8-auth/token/keycloak"
+	"github.com/fabric8-services/fabric8-auth/token/keystore/rotation"
 	"github.com/fabric8-services/fabric8-auth/token/link"
 
 	"github.com/goadesign/goa"
 	"github.com/goadesign/goa/logging/logrus"
 	"github.com/goadesign/goa/middleware"
 	"github.com/goadesign/goa/middleware/gzip"
-	"github.com/goadesign/goa/middleware/security/jwt"
 	"github.com/jinzhu/gorm"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -160,19 +163,36 @@ func main() {
 
 	appDB := gormapplication.NewGormDB(db, config)
 
-	tokenManager, err := token.NewManager(config)
+	// Enable the permission cache
+	permissioncache.Default().SetTTL(config.GetPermissionCacheTTL())
+
+	// Delete the expired role assignments in the background
+	haltExpiredRoleSweeper := roleservice.StartExpiredRoleSweeper(appDB.RoleManagementService(), config.GetExpiredRolesSweepInterval())
+	defer haltExpiredRoleSweeper()
+
+	// Delete the expired invitations in the background
+	haltExpiredInvitationSweeper := invitationservice.StartExpiredInvitationSweeper(appDB.InvitationService(), config.GetExpiredInvitationsSweepInterval())
+	defer haltExpiredInvitationSweeper()
+
+	// Sign the tokens with the keys stored in the database, so that they can be rotated without restarting the service
+	keyRotator := rotation.NewRotator(appDB, config)
+	tokenManager, err := token.NewManager(config, token.WithTokenRepository(appDB.TokenRepository()), token.WithKeyLoader(keyRotator))
 	if err != nil {
 		log.Panic(nil, map[string]interface{}{
 			"err": err,
 		}, "failed to create token manager")
 	}
+	// Reload, rotate and retire the signing keys in the background
+	haltKeyRotator := keyRotator.Start()
+	defer haltKeyRotator()
+
 	// Middleware that extracts and stores the token in the context
 	jwtMiddlewareTokenContext := goamiddleware.TokenContext(tokenManager, app.NewJWTSecurity())
 	service.Use(jwtMiddlewareTokenContext)
 
 	service.Use(login.InjectTokenManager(tokenManager))
 	service.Use(log.LogRequest(config.IsPostgresDeveloperModeEnabled()))
-	app.UseJWTMiddleware(service, jwt.New(tokenManager.PublicKeys(), nil, app.NewJWTSecurity()))
+	app.UseJWTMiddleware(service, goamiddleware.JWTSecurity(app.NewJWTSecurity()))
 
 	var tenantService accountservice.TenantService
 	if config.GetTenantServiceURL() != "" {
@@ -210,7 +230,7 @@ func main() {
 	linkService := link.NewLinkServiceWithFactory(config, appDB, providerFactory)
 
 	// Mount "token" controller
-	tokenCtrl := controller.NewTokenController(service, appDB, loginService, linkService, providerFactory, tokenManager, config)
+	tokenCtrl := controller.NewTokenController(service, appDB, loginService, linkService, providerFactory, tokenManager, keyRotator, config)
 	app.MountTokenController(service, tokenCtrl)
 
 	// Mount "status" controller
@@ -269,6 +289,30 @@ func main() {
 	teamCtrl := controller.NewTeamController(service, appDB)
 	app.MountTeamController(service, teamCtrl)
 
+	// Mount "security groups" controller
+	securityGroupCtrl := controller.NewSecurityGroupController(service, appDB)
+	app.MountSecurityGroupController(service, securityGroupCtrl)
+
+	// Mount "permissions" controller
+	permissionsCtrl := controller.NewPermissionsController(service, appDB)
+	app.MountPermissionsController(service, permissionsCtrl)
+
+	// Mount "resource_types" controller
+	resourceTypesCtrl := controller.NewResourceTypesController(service, appDB)
+	app.MountResourceTypesController(service, resourceTypesCtrl)
+
+	// Mount "service_accounts" controller
+	serviceAccountsCtrl := controller.NewServiceAccountsController(service, appDB)
+	app.MountServiceAccountsController(service, serviceAccountsCtrl)
+
+	// Mount "role_mappings" controller
+	roleMappingsCtrl := controller.NewRoleMappingsController(service, appDB)
+	app.MountRoleMappingsController(service, roleMappingsCtrl)
+
+	// Mount "default_role_mappings" controller
+	defaultRoleMappingsCtrl := controller.NewDefaultRoleMappingsController(service, appDB)
+	app.MountDefaultRoleMappingsController(service, defaultRoleMappingsCtrl)
+
 	// Mount "invitations" controller
 	invitationCtrl := controller.NewInvitationController(service, appDB, config)
 	app.MountInvitationController(service, invitationCtrl)
@@ -212,6 +212,45 @@ func GetMigrations(configuration MigrationConfiguration) Migrations {
 	// Version 34
 	m = append(m, steps{ExecuteSQLFile("034-rename-token-table.sql")})
 
+	// Version 35
+	m = append(m, steps{ExecuteSQLFile("035-token-resource.sql")})
+
+	// Version 36
+	m = append(m, steps{ExecuteSQLFile("036-resource-ownership-transfer.sql")})
+
+	// Version 37
+	m = append(m, steps{ExecuteSQLFile("037-identity-role-expiry.sql")})
+
+	// Version 38
+	m = append(m, steps{ExecuteSQLFile("038-team-roles.sql")})
+
+	// Version 39
+	m = append(m, steps{ExecuteSQLFile("039-organization-roles.sql")})
+
+	// Version 40
+	m = append(m, steps{ExecuteSQLFile("040-security-group-roles.sql")})
+
+	// Version 41
+	m = append(m, steps{ExecuteSQLFile("041-organization-space-role-mappings.sql")})
+
+	// Version 42
+	m = append(m, steps{ExecuteSQLFile("042-invitation-email.sql")})
+
+	// Version 43
+	m = append(m, steps{ExecuteSQLFile("043-invitation-expiry.sql")})
+
+	// Version 44
+	m = append(m, steps{ExecuteSQLFile("044-access-request.sql")})
+
+	// Version 45
+	m = append(m, steps{ExecuteSQLFile("045-personal-access-token.sql")})
+
+	// Version 46
+	m = append(m, steps{ExecuteSQLFile("046-signing-key.sql")})
+
+	// Version 47
+	m = append(m, steps{ExecuteSQLFile("047-service-account.sql")})
+
 	// Version N
 	//
 	// In order to add an upgrade, simply append an array of MigrationFunc to the
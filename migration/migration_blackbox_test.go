@@ -118,6 +118,19 @@ func TestMigrations(t *testing.T) {
 	t.Run("TestMigration30", testMigration30)
 	t.Run("TestMigration31", testMigration31)
 	t.Run("TestMigration33", testMigration33)
+	t.Run("TestMigration35", testMigration35)
+	t.Run("TestMigration36", testMigration36)
+	t.Run("TestMigration37", testMigration37)
+	t.Run("TestMigration38", testMigration38)
+	t.Run("TestMigration39", testMigration39)
+	t.Run("TestMigration40", testMigration40)
+	t.Run("TestMigration41", testMigration41)
+	t.Run("TestMigration42", testMigration42)
+	t.Run("TestMigration43", testMigration43)
+	t.Run("TestMigration44", testMigration44)
+	t.Run("TestMigration45", testMigration45)
+	t.Run("TestMigration46", testMigration46)
+	t.Run("TestMigration47", testMigration47)
 
 	// Perform the migration
 	if err := migration.Migrate(sqlDB, databaseName, conf); err != nil {
@@ -445,6 +458,98 @@ func testMigration33(t *testing.T) {
 	assert.False(t, dialect.HasTable("space_resources"))
 }
 
+func testMigration35(t *testing.T) {
+	migrateToVersion(sqlDB, migrations[:(36)], (36))
+	assert.True(t, dialect.HasTable("token_resource"))
+	assert.True(t, dialect.HasIndex("token_resource", "idx_token_resource_resource_id"))
+}
+
+func testMigration36(t *testing.T) {
+	migrateToVersion(sqlDB, migrations[:(37)], (37))
+	assert.True(t, dialect.HasTable("resource_ownership_transfer"))
+	assert.True(t, dialect.HasIndex("resource_ownership_transfer", "idx_resource_ownership_transfer_resource_id"))
+}
+
+func testMigration37(t *testing.T) {
+	migrateToVersion(sqlDB, migrations[:(38)], (38))
+	assert.True(t, dialect.HasColumn("identity_role", "expires_at"))
+	assert.True(t, dialect.HasIndex("identity_role", "idx_identity_role_expires_at"))
+}
+
+func testMigration38(t *testing.T) {
+	migrateToVersion(sqlDB, migrations[:(38)], (38))
+	require.Nil(t, runSQLscript(sqlDB, "038-insert-team.sql"))
+
+	migrateToVersion(sqlDB, migrations[:(39)], (39))
+	countRows(t, "SELECT count(role_id) FROM role WHERE resource_type_id = (SELECT resource_type_id FROM resource_type WHERE name = 'identity/team')", 2)
+	countRows(t, "SELECT count(resource_type_scope_id) FROM resource_type_scope WHERE resource_type_id = (SELECT resource_type_id FROM resource_type WHERE name = 'identity/team')", 2)
+	countRows(t, "SELECT count(scope_id) FROM role_scope WHERE role_id = 'e3b7c1a2-5f4d-4a8e-9c0b-7d2e6f1a3b84'", 2)
+	// the admin of the space of the existing team becomes its admin
+	countRows(t, "SELECT count(identity_role_id) FROM identity_role WHERE resource_id = '7f3b5a86-2b6a-4e5d-9c83-5d9a4fae3b42' AND identity_id = '5d1d3e64-0f4e-4c3b-9a61-3b7e2d8c1f20' AND role_id = 'e3b7c1a2-5f4d-4a8e-9c0b-7d2e6f1a3b84'", 1)
+}
+
+func testMigration39(t *testing.T) {
+	migrateToVersion(sqlDB, migrations[:(40)], (40))
+	countRows(t, "SELECT count(role_id) FROM role WHERE resource_type_id = (SELECT resource_type_id FROM resource_type WHERE name = 'identity/organization')", 2)
+	countRows(t, "SELECT count(role_id) FROM role_scope WHERE scope_id = 'b5d9e3c4-7a6f-4c0a-9e2d-9f4a8b3c5da6'", 2)
+}
+
+func testMigration40(t *testing.T) {
+	migrateToVersion(sqlDB, migrations[:(41)], (41))
+	countRows(t, "SELECT count(resource_type_scope_id) FROM resource_type_scope WHERE resource_type_id = (SELECT resource_type_id FROM resource_type WHERE name = 'identity/group')", 2)
+	countRows(t, "SELECT count(role_id) FROM role WHERE resource_type_id = (SELECT resource_type_id FROM resource_type WHERE name = 'identity/group')", 2)
+	countRows(t, "SELECT count(role_id) FROM role_scope WHERE role_id = 'f91d2708-bead-4a4e-8268-3d8ecf7091ea'", 2)
+}
+
+func testMigration41(t *testing.T) {
+	migrateToVersion(sqlDB, migrations[:(42)], (42))
+	countRows(t, "SELECT count(default_role_mapping_id) FROM default_role_mapping WHERE resource_type_id = (SELECT resource_type_id FROM resource_type WHERE name = 'identity/organization')", 2)
+}
+
+func testMigration42(t *testing.T) {
+	migrateToVersion(sqlDB, migrations[:(43)], (43))
+	assert.True(t, dialect.HasColumn("invitation", "email"))
+}
+
+func testMigration43(t *testing.T) {
+	migrateToVersion(sqlDB, migrations[:(43)], (43))
+	require.Nil(t, runSQLscript(sqlDB, "043-insert-old-invitation.sql"))
+
+	migrateToVersion(sqlDB, migrations[:(44)], (44))
+	assert.True(t, dialect.HasColumn("invitation", "expires_at"))
+	// the invitation created before the migration never expires
+	countRows(t, "SELECT count(invitation_id) FROM invitation WHERE invitation_id = 'ac6e8d19-5e9d-4b80-8fb6-80cd7d2b6e75' AND expires_at IS NULL", 1)
+}
+
+func testMigration44(t *testing.T) {
+	migrateToVersion(sqlDB, migrations[:(45)], (45))
+	assert.True(t, dialect.HasTable("access_request"))
+	assert.True(t, dialect.HasIndex("access_request", "idx_access_request_resource_id"))
+	assert.True(t, dialect.HasIndex("access_request", "idx_access_request_pending"))
+}
+
+func testMigration45(t *testing.T) {
+	migrateToVersion(sqlDB, migrations[:(46)], (46))
+	assert.True(t, dialect.HasColumn("token", "name"))
+	assert.True(t, dialect.HasColumn("token", "scopes"))
+	assert.True(t, dialect.HasIndex("token", "idx_token_identity_id_type"))
+}
+
+func testMigration46(t *testing.T) {
+	migrateToVersion(sqlDB, migrations[:(47)], (47))
+	assert.True(t, dialect.HasTable("signing_key"))
+	assert.True(t, dialect.HasIndex("signing_key", "idx_signing_key_account_type"))
+	assert.True(t, dialect.HasIndex("signing_key", "idx_signing_key_active"))
+}
+
+func testMigration47(t *testing.T) {
+	migrateToVersion(sqlDB, migrations[:(48)], (48))
+	assert.True(t, dialect.HasTable("service_account"))
+	assert.True(t, dialect.HasIndex("service_account", "idx_service_account_name"))
+	assert.True(t, dialect.HasTable("service_account_secret"))
+	assert.True(t, dialect.HasIndex("service_account_secret", "idx_service_account_secret_service_account_id"))
+}
+
 // runSQLscript loads the given filename from the packaged SQL test files and
 // executes it on the given database. Golang text/template module is used
 // to handle all the optional arguments passed to the sql test files
@@ -0,0 +1,8 @@
+-- the resources for which a token (i.e. a Requesting Party Token) carries permissions
+CREATE TABLE token_resource (
+  token_id uuid NOT NULL REFERENCES token (token_id),
+  resource_id varchar NOT NULL REFERENCES resource (resource_id),
+  PRIMARY KEY (token_id, resource_id)
+);
+
+CREATE INDEX idx_token_resource_resource_id ON token_resource (resource_id);
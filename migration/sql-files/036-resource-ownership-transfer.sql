@@ -0,0 +1,14 @@
+-- the record of the transfers of the ownership (i.e. the admin role) of resources between identities
+CREATE TABLE resource_ownership_transfer (
+  resource_ownership_transfer_id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+  resource_id varchar NOT NULL REFERENCES resource (resource_id),
+  from_identity_id uuid NOT NULL,
+  to_identity_id uuid NOT NULL,
+  transferred_by uuid NOT NULL,
+  include_descendants boolean NOT NULL DEFAULT false,
+  created_at timestamp with time zone,
+  updated_at timestamp with time zone,
+  deleted_at timestamp with time zone
+);
+
+CREATE INDEX idx_resource_ownership_transfer_resource_id ON resource_ownership_transfer (resource_id);
@@ -0,0 +1,4 @@
+-- optional expiry of role assignments, after which the assignment no longer grants any permission
+ALTER TABLE identity_role ADD COLUMN expires_at timestamp with time zone;
+
+CREATE INDEX idx_identity_role_expires_at ON identity_role (expires_at) WHERE expires_at IS NOT NULL;
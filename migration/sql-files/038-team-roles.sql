@@ -0,0 +1,16 @@
+-- the roles and scopes of teams, required to view and manage the members of a team
+INSERT INTO resource_type_scope (resource_type_scope_id, resource_type_id, name, created_at) SELECT 'a1a6a9b6-3d0c-4b62-9b8e-6c1e4cb0a6f1', rt.resource_type_id, 'view', now() FROM resource_type rt WHERE rt.name = 'identity/team';
+INSERT INTO resource_type_scope (resource_type_scope_id, resource_type_id, name, created_at) SELECT 'c2f0e4d8-7b5a-4e0f-8d6b-2f1a9c3e5b70', rt.resource_type_id, 'manage', now() FROM resource_type rt WHERE rt.name = 'identity/team';
+
+INSERT INTO role (role_id, resource_type_id, name, created_at) SELECT 'e3b7c1a2-5f4d-4a8e-9c0b-7d2e6f1a3b84', rt.resource_type_id, 'admin', now() FROM resource_type rt WHERE rt.name = 'identity/team';
+INSERT INTO role (role_id, resource_type_id, name, created_at) SELECT 'f4c8d2b3-6a5e-4b9f-8d1c-8e3f7a2b4c95', rt.resource_type_id, 'viewer', now() FROM resource_type rt WHERE rt.name = 'identity/team';
+
+INSERT INTO role_scope (scope_id, role_id, created_at) VALUES ('a1a6a9b6-3d0c-4b62-9b8e-6c1e4cb0a6f1', 'e3b7c1a2-5f4d-4a8e-9c0b-7d2e6f1a3b84', now());
+INSERT INTO role_scope (scope_id, role_id, created_at) VALUES ('c2f0e4d8-7b5a-4e0f-8d6b-2f1a9c3e5b70', 'e3b7c1a2-5f4d-4a8e-9c0b-7d2e6f1a3b84', now());
+INSERT INTO role_scope (scope_id, role_id, created_at) VALUES ('a1a6a9b6-3d0c-4b62-9b8e-6c1e4cb0a6f1', 'f4c8d2b3-6a5e-4b9f-8d1c-8e3f7a2b4c95', now());
+
+-- the teams created before this migration have no admin yet, so the admins of their space become the admins of the team
+INSERT INTO identity_role (identity_role_id, identity_id, resource_id, role_id, created_at) SELECT uuid_generate_v4(), ir.identity_id, t.resource_id, 'e3b7c1a2-5f4d-4a8e-9c0b-7d2e6f1a3b84', now()
+    FROM resource t, resource_type trt, identity_role ir, role sr
+    WHERE t.resource_type_id = trt.resource_type_id AND trt.name = 'identity/team' AND t.deleted_at IS NULL
+    AND ir.resource_id = t.parent_resource_id AND ir.deleted_at IS NULL AND ir.role_id = sr.role_id AND sr.name = 'admin';
@@ -0,0 +1,7 @@
+-- the scope required to view the members of an organization, and the viewer role of organizations
+INSERT INTO resource_type_scope (resource_type_scope_id, resource_type_id, name, created_at) SELECT 'b5d9e3c4-7a6f-4c0a-9e2d-9f4a8b3c5da6', rt.resource_type_id, 'view', now() FROM resource_type rt WHERE rt.name = 'identity/organization';
+
+INSERT INTO role (role_id, resource_type_id, name, created_at) SELECT 'c6eaf4d5-8b7a-4d1b-8f3e-0a5b9c4d6eb7', rt.resource_type_id, 'viewer', now() FROM resource_type rt WHERE rt.name = 'identity/organization';
+
+INSERT INTO role_scope (scope_id, role_id, created_at) SELECT 'b5d9e3c4-7a6f-4c0a-9e2d-9f4a8b3c5da6', r.role_id, now() FROM role r, resource_type rt WHERE r.resource_type_id = rt.resource_type_id AND r.name = 'admin' AND rt.name = 'identity/organization';
+INSERT INTO role_scope (scope_id, role_id, created_at) VALUES ('b5d9e3c4-7a6f-4c0a-9e2d-9f4a8b3c5da6', 'c6eaf4d5-8b7a-4d1b-8f3e-0a5b9c4d6eb7', now());
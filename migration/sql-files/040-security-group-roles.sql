@@ -0,0 +1,10 @@
+-- the roles and scopes of security groups, required to view and manage the members of a security group
+INSERT INTO resource_type_scope (resource_type_scope_id, resource_type_id, name, created_at) SELECT 'd7fb05e6-9c8b-4e2c-a04f-1b6cad5e7fc8', rt.resource_type_id, 'view', now() FROM resource_type rt WHERE rt.name = 'identity/group';
+INSERT INTO resource_type_scope (resource_type_scope_id, resource_type_id, name, created_at) SELECT 'e80c16f7-ad9c-4f3d-b157-2c7dbe6f80d9', rt.resource_type_id, 'manage', now() FROM resource_type rt WHERE rt.name = 'identity/group';
+
+INSERT INTO role (role_id, resource_type_id, name, created_at) SELECT 'f91d2708-bead-4a4e-8268-3d8ecf7091ea', rt.resource_type_id, 'admin', now() FROM resource_type rt WHERE rt.name = 'identity/group';
+INSERT INTO role (role_id, resource_type_id, name, created_at) SELECT '0a2e3819-cfbe-4b5f-9379-4e9fd08102fb', rt.resource_type_id, 'viewer', now() FROM resource_type rt WHERE rt.name = 'identity/group';
+
+INSERT INTO role_scope (scope_id, role_id, created_at) VALUES ('d7fb05e6-9c8b-4e2c-a04f-1b6cad5e7fc8', 'f91d2708-bead-4a4e-8268-3d8ecf7091ea', now());
+INSERT INTO role_scope (scope_id, role_id, created_at) VALUES ('e80c16f7-ad9c-4f3d-b157-2c7dbe6f80d9', 'f91d2708-bead-4a4e-8268-3d8ecf7091ea', now());
+INSERT INTO role_scope (scope_id, role_id, created_at) VALUES ('d7fb05e6-9c8b-4e2c-a04f-1b6cad5e7fc8', '0a2e3819-cfbe-4b5f-9379-4e9fd08102fb', now());
@@ -0,0 +1,6 @@
+-- default role mappings of organizations, so that the admins and viewers of an organization inherit the same roles in the spaces owned by the organization
+INSERT INTO default_role_mapping (default_role_mapping_id, resource_type_id, from_role_id, to_role_id, created_at) SELECT '3f8c2a6e-9d1b-4e7a-b5c4-6a2d8e1f0b93', ort.resource_type_id, orr.role_id, sr.role_id, now() FROM resource_type ort, role orr, resource_type srt, role sr WHERE ort.name = 'identity/organization' AND orr.resource_type_id = ort.resource_type_id AND orr.name = 'admin' AND srt.name = 'openshift.io/resource/space' AND sr.resource_type_id = srt.resource_type_id AND sr.name = 'admin';
+INSERT INTO default_role_mapping (default_role_mapping_id, resource_type_id, from_role_id, to_role_id, created_at) SELECT '7b1e4d9a-2c6f-4a8b-9e3d-5f0c7a2b8d14', ort.resource_type_id, orr.role_id, sr.role_id, now() FROM resource_type ort, role orr, resource_type srt, role sr WHERE ort.name = 'identity/organization' AND orr.resource_type_id = ort.resource_type_id AND orr.name = 'viewer' AND srt.name = 'openshift.io/resource/space' AND sr.resource_type_id = srt.resource_type_id AND sr.name = 'viewer';
+
+-- apply the new default role mappings to the existing organizations
+INSERT INTO role_mapping (resource_id, from_role_id, to_role_id, created_at) SELECT r.resource_id, drm.from_role_id, drm.to_role_id, now() FROM resource r, default_role_mapping drm WHERE r.resource_type_id = drm.resource_type_id AND r.deleted_at IS NULL AND drm.default_role_mapping_id IN ('3f8c2a6e-9d1b-4e7a-b5c4-6a2d8e1f0b93', '7b1e4d9a-2c6f-4a8b-9e3d-5f0c7a2b8d14');
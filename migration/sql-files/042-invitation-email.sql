@@ -0,0 +1,9 @@
+-- Invitations may be addressed to an e-mail address when the invited user does not have an account yet.
+-- Such invitations are bound to the identity of the user once it has been provisioned.
+ALTER TABLE invitation ALTER COLUMN identity_id DROP NOT NULL;
+ALTER TABLE invitation ADD COLUMN email text;
+
+ALTER TABLE invitation ADD CONSTRAINT invitation_identity_or_email_has_value
+  CHECK (identity_id IS NOT NULL OR email IS NOT NULL);
+
+CREATE INDEX idx_invitation_pending_email ON invitation (lower(email)) WHERE identity_id IS NULL;
@@ -0,0 +1,7 @@
+-- Invitations expire when they have not been accepted in time
+ALTER TABLE invitation ADD COLUMN expires_at timestamp with time zone;
+
+-- The existing invitations are left without any expiry time, i.e. they never expire, since the time to live of the
+-- invitations is configured and may be disabled
+
+CREATE INDEX idx_invitation_expires_at ON invitation (expires_at) WHERE expires_at IS NOT NULL;
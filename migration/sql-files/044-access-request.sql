@@ -0,0 +1,19 @@
+-- the requests filed by users who want to be granted access to a space
+CREATE TABLE access_request (
+  access_request_id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+  resource_id varchar NOT NULL REFERENCES resource (resource_id) ON DELETE CASCADE,
+  identity_id uuid NOT NULL REFERENCES identities (id) ON DELETE CASCADE,
+  role_id uuid REFERENCES role (role_id) ON DELETE SET NULL,
+  message text,
+  status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'denied')),
+  decided_by uuid,
+  decided_at timestamp with time zone,
+  created_at timestamp with time zone,
+  updated_at timestamp with time zone,
+  deleted_at timestamp with time zone
+);
+
+CREATE INDEX idx_access_request_resource_id ON access_request (resource_id);
+
+-- a user may only have one pending request per resource
+CREATE UNIQUE INDEX idx_access_request_pending ON access_request (resource_id, identity_id) WHERE status = 'pending' AND deleted_at IS NULL;
@@ -0,0 +1,6 @@
+-- Personal access tokens are named by their owner and restricted to a set of scopes
+ALTER TABLE token ADD COLUMN name text;
+ALTER TABLE token ADD COLUMN scopes text;
+
+-- Personal access tokens are listed per identity
+CREATE INDEX idx_token_identity_id_type ON token (identity_id, token_type) WHERE deleted_at IS NULL;
@@ -0,0 +1,22 @@
+-- the keys used to sign the user and service account tokens, published to the token consumers until they are retired.
+-- The private keys are encrypted with AES-256-GCM using the key encryption key from the configuration
+-- (signingkey.encryption.key), so private_key holds the base64 encoded nonce and ciphertext, never a plaintext key.
+CREATE TABLE signing_key (
+  key_id text PRIMARY KEY,
+  account_type text NOT NULL CHECK (account_type IN ('user', 'service_account')),
+  state text NOT NULL CHECK (state IN ('pending', 'active', 'retiring', 'retired')),
+  private_key text NOT NULL,
+  activated_at timestamp with time zone,
+  retiring_at timestamp with time zone,
+  retired_at timestamp with time zone,
+  created_at timestamp with time zone,
+  updated_at timestamp with time zone,
+  deleted_at timestamp with time zone
+);
+
+COMMENT ON COLUMN signing_key.private_key IS 'PEM encoded private key encrypted with the configured key encryption key';
+
+CREATE INDEX idx_signing_key_account_type ON signing_key (account_type) WHERE deleted_at IS NULL;
+
+-- only one key signs the tokens of an account type at any time
+CREATE UNIQUE INDEX idx_signing_key_active ON signing_key (account_type) WHERE state = 'active' AND deleted_at IS NULL;
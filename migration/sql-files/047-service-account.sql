@@ -0,0 +1,25 @@
+-- the service accounts which authenticate with the client credentials grant, in addition to the configured ones
+CREATE TABLE service_account (
+  service_account_id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+  name text NOT NULL,
+  last_used_at timestamp with time zone,
+  created_at timestamp with time zone,
+  updated_at timestamp with time zone,
+  deleted_at timestamp with time zone
+);
+
+CREATE UNIQUE INDEX idx_service_account_name ON service_account (name) WHERE deleted_at IS NULL;
+
+-- the bcrypt hashes of the secrets of the service accounts. A retired secret is still accepted until it expires.
+CREATE TABLE service_account_secret (
+  secret_id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+  service_account_id uuid NOT NULL REFERENCES service_account (service_account_id) ON DELETE CASCADE,
+  secret_hash text NOT NULL,
+  expires_at timestamp with time zone,
+  last_used_at timestamp with time zone,
+  created_at timestamp with time zone,
+  updated_at timestamp with time zone,
+  deleted_at timestamp with time zone
+);
+
+CREATE INDEX idx_service_account_secret_service_account_id ON service_account_secret (service_account_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+insert into identities (id, username, registration_completed) values ('5d1d3e64-0f4e-4c3b-9a61-3b7e2d8c1f20', 'migration-test-space-admin', true);
+insert into resource (resource_id, resource_type_id, name) select '6e2a4f75-1a5f-4d4c-8b72-4c8f3e9d2a31', resource_type_id, 'migration-test-space' from resource_type where name = 'openshift.io/resource/space';
+insert into resource (resource_id, resource_type_id, name, parent_resource_id) select '7f3b5a86-2b6a-4e5d-9c83-5d9a4fae3b42', resource_type_id, 'migration-test-team', '6e2a4f75-1a5f-4d4c-8b72-4c8f3e9d2a31' from resource_type where name = 'identity/team';
+insert into identity_role (identity_role_id, resource_id, identity_id, role_id) select '8a4c6b97-3c7b-4f6e-8d94-6eab5b0f4c53', '6e2a4f75-1a5f-4d4c-8b72-4c8f3e9d2a31', '5d1d3e64-0f4e-4c3b-9a61-3b7e2d8c1f20', r.role_id from role r, resource_type rt where r.resource_type_id = rt.resource_type_id and rt.name = 'openshift.io/resource/space' and r.name = 'admin';
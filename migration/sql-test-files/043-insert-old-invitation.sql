@@ -0,0 +1,2 @@
+insert into resource (resource_id, resource_type_id, name) select '9b5d7c08-4d8c-4a7f-9ea5-7fbc6c1a5d64', resource_type_id, 'migration-test-invitation-area' from resource_type where name = 'openshift.io/resource/area';
+insert into invitation (invitation_id, resource_id, email, member, accept_code, created_at) values ('ac6e8d19-5e9d-4b80-8fb6-80cd7d2b6e75', '9b5d7c08-4d8c-4a7f-9ea5-7fbc6c1a5d64', 'migration-test-invitee@example.com', false, 'ac6e8d19-5e9d-4b80-8fb6-80cd7d2b6e75', now() - interval '30 days');
@@ -81,3 +81,167 @@ func NewSpaceInvitationEmail(userID string, spaceName string, inviterName string
 		},
 	}
 }
+
+// NewTeamInvitationEmailToAddress creates a Message for the notification service in order to send an invitation e-mail
+// to a user who does not have an account yet
+//
+// The following custom parameter values are required:
+//
+// email - the e-mail address of the invited user
+// teamName - the name of the team
+// inviter - the name of the user sending the invitation
+// spaceName - the name of the space to which the team belongs
+// acceptToken - the unique acceptance token value
+func NewTeamInvitationEmailToAddress(email string, teamName string, inviterName string, spaceName string, acceptURL string) Message {
+	return Message{
+		MessageID:   uuid.NewV4(),
+		MessageType: "invitation.team.noorg.email",
+		TargetID:    "",
+		Custom: map[string]interface{}{
+			"email":     email,
+			"teamName":  teamName,
+			"inviter":   inviterName,
+			"spaceName": spaceName,
+			"acceptURL": acceptURL,
+		},
+	}
+}
+
+// NewSpaceInvitationEmailToAddress creates a Message for the notification service in order to send an invitation e-mail
+// to a user who does not have an account yet
+//
+// The following custom parameter values are required:
+//
+// email - the e-mail address of the invited user
+// spaceName - the name of the space
+// inviter - the name of the user sending the invitation
+// roleNames - a comma-separated list of role names
+// acceptToken - the unique acceptance token value
+func NewSpaceInvitationEmailToAddress(email string, spaceName string, inviterName string, roleNames string, acceptURL string) Message {
+	return Message{
+		MessageID:   uuid.NewV4(),
+		MessageType: "invitation.space.noorg.email",
+		TargetID:    "",
+		Custom: map[string]interface{}{
+			"email":     email,
+			"spaceName": spaceName,
+			"inviter":   inviterName,
+			"roleNames": roleNames,
+			"acceptURL": acceptURL,
+		},
+	}
+}
+
+// NewResourceOwnershipTransferredEmail creates a Message for the notification service in order to inform a user that the
+// ownership of a resource has been transferred to or from them
+//
+// The following custom parameter values are required:
+//
+// resourceName - the name of the resource
+// resourceType - the name of the resource type
+// previousOwner - the name of the user who owned the resource before the transfer
+// newOwner - the name of the user who owns the resource after the transfer
+// transferredBy - the name of the user who performed the transfer
+func NewResourceOwnershipTransferredEmail(userID string, resourceID string, resourceName string, resourceType string, previousOwner string, newOwner string, transferredBy string) Message {
+	return Message{
+		MessageID:   uuid.NewV4(),
+		MessageType: "resource.ownership.transferred",
+		TargetID:    resourceID,
+		UserID:      &userID,
+		Custom: map[string]interface{}{
+			"resourceName":  resourceName,
+			"resourceType":  resourceType,
+			"previousOwner": previousOwner,
+			"newOwner":      newOwner,
+			"transferredBy": transferredBy,
+		},
+	}
+}
+
+// NewRoleAssignmentExpiredEmail creates a Message for the notification service in order to inform a user that a time-bound
+// role assignment has expired and no longer grants them access to a resource
+//
+// The following custom parameter values are required:
+//
+// resourceName - the name of the resource
+// resourceType - the name of the resource type
+// roleName - the name of the role which has expired
+func NewRoleAssignmentExpiredEmail(userID string, resourceID string, resourceName string, resourceType string, roleName string) Message {
+	return Message{
+		MessageID:   uuid.NewV4(),
+		MessageType: "role.assignment.expired",
+		TargetID:    resourceID,
+		UserID:      &userID,
+		Custom: map[string]interface{}{
+			"resourceName": resourceName,
+			"resourceType": resourceType,
+			"roleName":     roleName,
+		},
+	}
+}
+
+// NewSpaceAccessRequestedEmail creates a Message for the notification service in order to inform an administrator of a
+// space that a user has requested access to the space
+//
+// The following custom parameter values are required:
+//
+// spaceName - the name of the space
+// requester - the name of the user who requested access
+// roleName - the name of the role requested by the user, if any
+// message - the message from the user to the administrators of the space, if any
+func NewSpaceAccessRequestedEmail(userID string, spaceID string, spaceName string, requester string, roleName string, message string) Message {
+	return Message{
+		MessageID:   uuid.NewV4(),
+		MessageType: "space.access.requested",
+		TargetID:    spaceID,
+		UserID:      &userID,
+		Custom: map[string]interface{}{
+			"spaceName": spaceName,
+			"requester": requester,
+			"roleName":  roleName,
+			"message":   message,
+		},
+	}
+}
+
+// NewSpaceAccessRequestApprovedEmail creates a Message for the notification service in order to inform a user that their
+// request for access to a space has been approved
+//
+// The following custom parameter values are required:
+//
+// spaceName - the name of the space
+// roleName - the name of the role granted to the user
+// decidedBy - the name of the administrator who approved the request
+func NewSpaceAccessRequestApprovedEmail(userID string, spaceID string, spaceName string, roleName string, decidedBy string) Message {
+	return Message{
+		MessageID:   uuid.NewV4(),
+		MessageType: "space.access.approved",
+		TargetID:    spaceID,
+		UserID:      &userID,
+		Custom: map[string]interface{}{
+			"spaceName": spaceName,
+			"roleName":  roleName,
+			"decidedBy": decidedBy,
+		},
+	}
+}
+
+// NewSpaceAccessRequestDeniedEmail creates a Message for the notification service in order to inform a user that their
+// request for access to a space has been denied
+//
+// The following custom parameter values are required:
+//
+// spaceName - the name of the space
+// decidedBy - the name of the administrator who denied the request
+func NewSpaceAccessRequestDeniedEmail(userID string, spaceID string, spaceName string, decidedBy string) Message {
+	return Message{
+		MessageID:   uuid.NewV4(),
+		MessageType: "space.access.denied",
+		TargetID:    spaceID,
+		UserID:      &userID,
+		Custom: map[string]interface{}{
+			"spaceName": spaceName,
+			"decidedBy": decidedBy,
+		},
+	}
+}
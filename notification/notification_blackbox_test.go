@@ -30,3 +30,72 @@ func (s *TestNotificationSuite) TestNewUserEmailUpdatedOK() {
 	assert.Equal(s.T(), &userID, msg.UserID)
 	assert.Equal(s.T(), custom, msg.Custom)
 }
+
+func (s *TestNotificationSuite) TestNewResourceOwnershipTransferredEmailOK() {
+	userID := uuid.NewV4().String()
+	resourceID := uuid.NewV4().String()
+
+	msg := notification.NewResourceOwnershipTransferredEmail(userID, resourceID, "myspace", "openshift.io/resource/space", "alice", "bob", "carol")
+	assert.Equal(s.T(), "resource.ownership.transferred", msg.MessageType)
+	assert.Equal(s.T(), resourceID, msg.TargetID)
+	assert.Equal(s.T(), &userID, msg.UserID)
+	assert.Equal(s.T(), "myspace", msg.Custom["resourceName"])
+	assert.Equal(s.T(), "alice", msg.Custom["previousOwner"])
+	assert.Equal(s.T(), "bob", msg.Custom["newOwner"])
+	assert.Equal(s.T(), "carol", msg.Custom["transferredBy"])
+}
+
+func (s *TestNotificationSuite) TestNewRoleAssignmentExpiredEmailOK() {
+	userID := uuid.NewV4().String()
+	resourceID := uuid.NewV4().String()
+
+	msg := notification.NewRoleAssignmentExpiredEmail(userID, resourceID, "myspace", "openshift.io/resource/space", "contributor")
+	assert.Equal(s.T(), "role.assignment.expired", msg.MessageType)
+	assert.Equal(s.T(), resourceID, msg.TargetID)
+	assert.Equal(s.T(), &userID, msg.UserID)
+	assert.Equal(s.T(), "myspace", msg.Custom["resourceName"])
+	assert.Equal(s.T(), "openshift.io/resource/space", msg.Custom["resourceType"])
+	assert.Equal(s.T(), "contributor", msg.Custom["roleName"])
+}
+
+func (s *TestNotificationSuite) TestNewInvitationEmailToAddressOK() {
+	msg := notification.NewTeamInvitationEmailToAddress("jdoe@example.com", "myteam", "alice", "myspace", "https://auth/accept")
+	assert.Equal(s.T(), "invitation.team.noorg.email", msg.MessageType)
+	assert.Nil(s.T(), msg.UserID)
+	assert.Equal(s.T(), "jdoe@example.com", msg.Custom["email"])
+	assert.Equal(s.T(), "myteam", msg.Custom["teamName"])
+	assert.Equal(s.T(), "https://auth/accept", msg.Custom["acceptURL"])
+
+	msg = notification.NewSpaceInvitationEmailToAddress("jdoe@example.com", "myspace", "alice", "contributor", "https://auth/accept")
+	assert.Equal(s.T(), "invitation.space.noorg.email", msg.MessageType)
+	assert.Nil(s.T(), msg.UserID)
+	assert.Equal(s.T(), "jdoe@example.com", msg.Custom["email"])
+	assert.Equal(s.T(), "contributor", msg.Custom["roleNames"])
+}
+
+func (s *TestNotificationSuite) TestNewSpaceAccessRequestEmailsOK() {
+	userID := uuid.NewV4().String()
+	spaceID := uuid.NewV4().String()
+
+	msg := notification.NewSpaceAccessRequestedEmail(userID, spaceID, "myspace", "alice", "contributor", "let me in")
+	assert.Equal(s.T(), "space.access.requested", msg.MessageType)
+	assert.Equal(s.T(), spaceID, msg.TargetID)
+	assert.Equal(s.T(), &userID, msg.UserID)
+	assert.Equal(s.T(), "myspace", msg.Custom["spaceName"])
+	assert.Equal(s.T(), "alice", msg.Custom["requester"])
+	assert.Equal(s.T(), "contributor", msg.Custom["roleName"])
+	assert.Equal(s.T(), "let me in", msg.Custom["message"])
+
+	msg = notification.NewSpaceAccessRequestApprovedEmail(userID, spaceID, "myspace", "contributor", "bob")
+	assert.Equal(s.T(), "space.access.approved", msg.MessageType)
+	assert.Equal(s.T(), spaceID, msg.TargetID)
+	assert.Equal(s.T(), &userID, msg.UserID)
+	assert.Equal(s.T(), "contributor", msg.Custom["roleName"])
+	assert.Equal(s.T(), "bob", msg.Custom["decidedBy"])
+
+	msg = notification.NewSpaceAccessRequestDeniedEmail(userID, spaceID, "myspace", "bob")
+	assert.Equal(s.T(), "space.access.denied", msg.MessageType)
+	assert.Equal(s.T(), &userID, msg.UserID)
+	assert.Equal(s.T(), "myspace", msg.Custom["spaceName"])
+	assert.Equal(s.T(), "bob", msg.Custom["decidedBy"])
+}
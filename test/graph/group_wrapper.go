@@ -0,0 +1,77 @@
+package graph
+
+import (
+	account "github.com/fabric8-services/fabric8-auth/account/repository"
+	resource "github.com/fabric8-services/fabric8-auth/authorization/resource/repository"
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// groupWrapper represents a security group resource domain object
+type groupWrapper struct {
+	baseWrapper
+	identity *account.Identity
+	resource *resource.Resource
+}
+
+func newGroupWrapper(g *TestGraph, params []interface{}) interface{} {
+	w := groupWrapper{baseWrapper: baseWrapper{g}}
+
+	var groupName *string
+	var org *organizationWrapper
+
+	for i := range params {
+		switch t := params[i].(type) {
+		case string:
+			groupName = &t
+		case *organizationWrapper:
+			org = t
+		}
+	}
+
+	if org == nil {
+		org = w.graph.CreateOrganization()
+	}
+
+	if groupName == nil {
+		nm := "Group-" + uuid.NewV4().String()
+		groupName = &nm
+	}
+
+	groupID, err := g.app.GroupService().CreateGroup(g.ctx, org.creator.ID, org.OrganizationID(), *groupName)
+	require.NoError(g.t, err)
+
+	w.identity = g.LoadIdentity(groupID).Identity()
+	w.resource = g.LoadResource(w.identity.IdentityResourceID.String).Resource()
+
+	return &w
+}
+
+func (w *groupWrapper) GroupID() uuid.UUID {
+	return w.identity.ID
+}
+
+func (w *groupWrapper) GroupName() string {
+	return w.resource.Name
+}
+
+func (w *groupWrapper) Identity() *account.Identity {
+	return w.identity
+}
+
+func (w *groupWrapper) Resource() *resource.Resource {
+	return w.resource
+}
+
+func (w *groupWrapper) ResourceID() string {
+	return w.resource.ResourceID
+}
+
+// AddMember adds a user or identity to the members of the group
+func (w *groupWrapper) AddMember(wrapper interface{}) *groupWrapper {
+	identityID := w.identityIDFromWrapper(wrapper)
+
+	err := w.graph.db.Exec("INSERT INTO membership (member_id, member_of) VALUES (?, ?)", identityID, w.identity.ID).Error
+	require.NoError(w.graph.t, err)
+	return w
+}
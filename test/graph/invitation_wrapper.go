@@ -63,9 +63,9 @@ func newInvitationWrapper(g *TestGraph, params []interface{}) interface{} {
 	}
 
 	if identityID != nil {
-		w.invitation.IdentityID = *identityID
+		w.invitation.IdentityID = identityID
 	} else {
-		w.invitation.IdentityID = w.graph.CreateUser().Identity().ID
+		w.invitation.IdentityID = &w.graph.CreateUser().Identity().ID
 	}
 
 	// The invitation is either for an identity (e.g. org, team), or for a resource (e.g. space), but not both
@@ -88,3 +88,18 @@ func (w *organizationWrapper) AddAdmin(wrapper interface{}) *organizationWrapper
 	addRole(w.baseWrapper, w.resource, authorization.IdentityResourceTypeOrganization, w.identityIDFromWrapper(wrapper), authorization.OrganizationAdminRole)
 	return w
 }
+
+// AddViewer assigns the viewer role to a user for the org
+func (w *organizationWrapper) AddViewer(wrapper interface{}) *organizationWrapper {
+	addRole(w.baseWrapper, w.resource, authorization.IdentityResourceTypeOrganization, w.identityIDFromWrapper(wrapper), authorization.OrganizationViewerRole)
+	return w
+}
+
+// AddMember adds a user to the members of the org
+func (w *organizationWrapper) AddMember(wrapper interface{}) *organizationWrapper {
+	identityID := w.identityIDFromWrapper(wrapper)
+
+	err := w.graph.db.Exec("INSERT INTO membership (member_id, member_of) VALUES (?, ?)", identityID, w.identity.ID).Error
+	require.NoError(w.graph.t, err)
+	return w
+}
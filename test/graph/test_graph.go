@@ -30,8 +30,10 @@ func (w *baseWrapper) identityIDFromWrapper(wrapper interface{}) uuid.UUID {
 		return t.identity.ID
 	case *identityWrapper:
 		return t.identity.ID
+	case *groupWrapper:
+		return t.identity.ID
 	}
-	require.True(w.graph.t, false, "wrapper must be either user wrapper or identity wrapper")
+	require.True(w.graph.t, false, "wrapper must be either user wrapper, identity wrapper or group wrapper")
 	return uuid.UUID{}
 }
 
@@ -183,6 +185,15 @@ func (g *TestGraph) OrganizationByID(id string) *organizationWrapper {
 	return g.references[id].(*organizationWrapper)
 }
 
+func (g *TestGraph) CreateGroup(params ...interface{}) *groupWrapper {
+	return g.createAndRegister(newGroupWrapper, params).(*groupWrapper)
+}
+
+func (g *TestGraph) GroupByID(id string) *groupWrapper {
+	require.Contains(g.t, g.references, id, "group with such ID is not registered")
+	return g.references[id].(*groupWrapper)
+}
+
 func (g *TestGraph) CreateIdentity(params ...interface{}) *identityWrapper {
 	return g.createAndRegister(newIdentityWrapper, params).(*identityWrapper)
 }
@@ -0,0 +1,32 @@
+package test
+
+import (
+	"context"
+	"sync"
+
+	"github.com/fabric8-services/fabric8-auth/notification"
+)
+
+// RecordingNotificationService is a notification service implementation which records the messages instead of sending them.
+type RecordingNotificationService struct {
+	mutex    sync.Mutex
+	messages []notification.Message
+}
+
+func (s *RecordingNotificationService) SendAsync(ctx context.Context, msg notification.Message) error {
+	return s.SendMessagesAsync(ctx, []notification.Message{msg})
+}
+
+func (s *RecordingNotificationService) SendMessagesAsync(ctx context.Context, messages []notification.Message) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.messages = append(s.messages, messages...)
+	return nil
+}
+
+// Messages returns the messages recorded so far
+func (s *RecordingNotificationService) Messages() []notification.Message {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]notification.Message{}, s.messages...)
+}
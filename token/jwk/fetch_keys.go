@@ -1,7 +1,7 @@
 package jwk
 
 import (
-	"crypto/rsa"
+	"crypto"
 	"encoding/json"
 	"net/http"
 
@@ -13,16 +13,16 @@ import (
 	"gopkg.in/square/go-jose.v2"
 )
 
-// PrivateKey represents an RSA private key with a Key ID
+// PrivateKey represents an RSA, ECDSA or Ed25519 private key with a Key ID
 type PrivateKey struct {
 	KeyID string
-	Key   *rsa.PrivateKey
+	Key   crypto.Signer
 }
 
-// PublicKey represents an RSA public key with a Key ID
+// PublicKey represents an RSA, ECDSA or Ed25519 public key with a Key ID
 type PublicKey struct {
 	KeyID string
-	Key   *rsa.PublicKey
+	Key   crypto.PublicKey
 }
 
 // JSONKeys the remote keys encoded in a json document
@@ -100,9 +100,8 @@ func unmarshalKey(jsonData []byte) (*PublicKey, error) {
 	if err != nil {
 		return nil, err
 	}
-	rsaKey, ok := key.Key.(*rsa.PublicKey)
-	if !ok {
-		return nil, errors.New("Key is not an *rsa.PublicKey")
+	if _, private := key.Key.(crypto.Signer); private || Algorithm(key.Key) == "" {
+		return nil, errors.Errorf("Key is not a supported public key: %T", key.Key)
 	}
-	return &PublicKey{key.KeyID, rsaKey}, nil
+	return &PublicKey{key.KeyID, key.Key}, nil
 }
@@ -0,0 +1,170 @@
+package jwk
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	// AlgorithmRS256 is the algorithm of the tokens signed with RSA keys
+	AlgorithmRS256 = "RS256"
+	// AlgorithmES256 is the algorithm of the tokens signed with ECDSA keys on the P-256 curve
+	AlgorithmES256 = "ES256"
+	// AlgorithmEdDSA is the algorithm of the tokens signed with Ed25519 keys
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// SupportedAlgorithms are the algorithms of the keys which can sign the tokens
+var SupportedAlgorithms = []string{AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA}
+
+// oidEd25519 is the object identifier of the Ed25519 keys, see RFC 8410
+var oidEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+
+// pkcs8 reflects an ASN.1, PKCS#8 PrivateKey, see RFC 5208
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// pkixPublicKey reflects an ASN.1, PKIX SubjectPublicKeyInfo, see RFC 5280
+type pkixPublicKey struct {
+	Algo      pkix.AlgorithmIdentifier
+	BitString asn1.BitString
+}
+
+// Algorithm returns the algorithm of the tokens signed with the given private key or verified with the given public
+// key, or an empty string if the key is not supported
+func Algorithm(key interface{}) string {
+	switch k := key.(type) {
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		return AlgorithmRS256
+	case *ecdsa.PrivateKey:
+		return ecdsaAlgorithm(k.Curve)
+	case *ecdsa.PublicKey:
+		return ecdsaAlgorithm(k.Curve)
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		return AlgorithmEdDSA
+	}
+	return ""
+}
+
+func ecdsaAlgorithm(curve elliptic.Curve) string {
+	if curve == elliptic.P256() {
+		return AlgorithmES256
+	}
+	return ""
+}
+
+// GenerateKey generates a new private key for the given algorithm. The size of the RSA keys is given by rsaBits.
+func GenerateKey(algorithm string, rsaBits int) (crypto.Signer, error) {
+	switch algorithm {
+	case AlgorithmRS256:
+		return rsa.GenerateKey(rand.Reader, rsaBits)
+	case AlgorithmES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+	return nil, errors.Errorf("unsupported signing algorithm: %s", algorithm)
+}
+
+// ParsePrivateKeyFromPEM parses a PEM encoded RSA (PKCS#1 or PKCS#8), ECDSA (SEC 1 or PKCS#8) or Ed25519 (PKCS#8)
+// private key
+func ParsePrivateKeyFromPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("the key must be PEM encoded")
+	}
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		var p pkcs8
+		if _, err := asn1.Unmarshal(block.Bytes, &p); err == nil && p.Algo.Algorithm.Equal(oidEd25519) {
+			return parseEd25519PrivateKey(p.PrivateKey)
+		}
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, errors.Errorf("unsupported private key type: %s", block.Type)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse the private key")
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok || Algorithm(key) == "" {
+		return nil, errors.Errorf("unsupported private key type: %T", key)
+	}
+	return signer, nil
+}
+
+func parseEd25519PrivateKey(der []byte) (crypto.Signer, error) {
+	var seed []byte
+	if _, err := asn1.Unmarshal(der, &seed); err != nil {
+		return nil, errors.Wrap(err, "unable to parse the Ed25519 private key")
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, errors.Errorf("invalid Ed25519 private key length: %d", len(seed))
+	}
+	// The key pair is fully determined by the seed read from the reader
+	_, key, err := ed25519.GenerateKey(bytes.NewReader(seed))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse the Ed25519 private key")
+	}
+	return key, nil
+}
+
+// EncodePrivateKeyToPEM encodes the given RSA (PKCS#1), ECDSA (SEC 1) or Ed25519 (PKCS#8) private key in PEM format
+func EncodePrivateKeyToPEM(key crypto.Signer) ([]byte, error) {
+	var block *pem.Block
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		block = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(k)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to encode the private key")
+		}
+		block = &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+	case ed25519.PrivateKey:
+		seed, err := asn1.Marshal(k[:ed25519.SeedSize])
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to encode the private key")
+		}
+		der, err := asn1.Marshal(pkcs8{Algo: pkix.AlgorithmIdentifier{Algorithm: oidEd25519}, PrivateKey: seed})
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to encode the private key")
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	default:
+		return nil, errors.Errorf("unsupported private key type: %T", key)
+	}
+	return pem.EncodeToMemory(block), nil
+}
+
+// MarshalPublicKey returns the DER encoded PKIX SubjectPublicKeyInfo of the given RSA, ECDSA or Ed25519 public key
+func MarshalPublicKey(key crypto.PublicKey) ([]byte, error) {
+	if k, ok := key.(ed25519.PublicKey); ok {
+		return asn1.Marshal(pkixPublicKey{
+			Algo:      pkix.AlgorithmIdentifier{Algorithm: oidEd25519},
+			BitString: asn1.BitString{Bytes: k, BitLength: 8 * len(k)},
+		})
+	}
+	return x509.MarshalPKIXPublicKey(key)
+}
@@ -0,0 +1,2 @@
+// Package keystore holds the keys which sign the tokens
+package keystore
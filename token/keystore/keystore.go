@@ -0,0 +1,122 @@
+package keystore
+
+import (
+	"context"
+	"crypto"
+	"sync"
+
+	"github.com/fabric8-services/fabric8-auth/token/jwk"
+	"github.com/fabric8-services/fabric8-auth/token/keystore/repository"
+
+	"github.com/pkg/errors"
+)
+
+// Key represents a signing key in its current state
+type Key struct {
+	KeyID string
+	// The state of the key, one of repository.StatePending, repository.StateActive or repository.StateRetiring
+	State string
+	// The RSA, ECDSA or Ed25519 private key
+	Key crypto.Signer
+}
+
+// Loader keeps the keys of the key stores of the given account types up to date with an external storage
+type Loader interface {
+	Load(ctx context.Context, stores map[string]*KeyStore) error
+}
+
+// KeyStore holds the signing keys of an account type: the active key which signs the new tokens, the pending keys
+// which will sign the tokens after the next rotation, and the retiring keys which signed the tokens before
+// the last rotations. All of them are published so that the tokens can be verified.
+// A KeyStore is safe for concurrent use.
+type KeyStore struct {
+	mux  sync.RWMutex
+	keys []Key
+}
+
+// New returns a new KeyStore which holds the given keys. Exactly one of the keys must be active.
+func New(keys []Key) (*KeyStore, error) {
+	s := &KeyStore{}
+	err := s.Replace(keys)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Replace replaces all the keys of the store with the given ones. Exactly one of the keys must be active,
+// the retired keys are ignored.
+func (s *KeyStore) Replace(keys []Key) error {
+	var published []Key
+	active := 0
+	for _, key := range keys {
+		switch key.State {
+		case repository.StateActive:
+			active++
+		case repository.StatePending, repository.StateRetiring:
+		default:
+			continue
+		}
+		published = append(published, key)
+	}
+	if active != 1 {
+		return errors.Errorf("expected exactly one active signing key but got %d", active)
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.keys = published
+	return nil
+}
+
+// SigningKey returns the active key, which signs the new tokens
+func (s *KeyStore) SigningKey() *jwk.PrivateKey {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	for _, key := range s.keys {
+		if key.State == repository.StateActive {
+			return &jwk.PrivateKey{KeyID: key.KeyID, Key: key.Key}
+		}
+	}
+	return nil
+}
+
+// PrivateKey returns the private key with the given ID, or nil if the store does not hold such a key
+func (s *KeyStore) PrivateKey(keyID string) crypto.Signer {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	for _, key := range s.keys {
+		if key.KeyID == keyID {
+			return key.Key
+		}
+	}
+	return nil
+}
+
+// PublicKey returns the public key with the given ID, or nil if the store does not hold such a key
+func (s *KeyStore) PublicKey(keyID string) crypto.PublicKey {
+	key := s.PrivateKey(keyID)
+	if key == nil {
+		return nil
+	}
+	return key.Public()
+}
+
+// PublicKeys returns the public keys of all the keys held by the store
+func (s *KeyStore) PublicKeys() []*jwk.PublicKey {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	keys := make([]*jwk.PublicKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, &jwk.PublicKey{KeyID: key.KeyID, Key: key.Key.Public()})
+	}
+	return keys
+}
+
+// Keys returns all the keys held by the store
+func (s *KeyStore) Keys() []Key {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	keys := make([]Key, len(s.keys))
+	copy(keys, s.keys)
+	return keys
+}
@@ -0,0 +1,90 @@
+package keystore_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	testsuite "github.com/fabric8-services/fabric8-auth/test/suite"
+	"github.com/fabric8-services/fabric8-auth/token/keystore"
+	"github.com/fabric8-services/fabric8-auth/token/keystore/repository"
+
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestKeyStoreSuite struct {
+	testsuite.UnitTestSuite
+}
+
+func TestRunKeyStoreSuite(t *testing.T) {
+	suite.Run(t, &TestKeyStoreSuite{UnitTestSuite: testsuite.NewUnitTestSuite()})
+}
+
+func (s *TestKeyStoreSuite) newKey(state string) keystore.Key {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(s.T(), err)
+	return keystore.Key{KeyID: uuid.NewV4().String(), State: state, Key: key}
+}
+
+func (s *TestKeyStoreSuite) TestKeys() {
+	pending := s.newKey(repository.StatePending)
+	active := s.newKey(repository.StateActive)
+	retiring := s.newKey(repository.StateRetiring)
+	retired := s.newKey(repository.StateRetired)
+	store, err := keystore.New([]keystore.Key{retiring, active, pending, retired})
+	require.NoError(s.T(), err)
+
+	s.T().Run("active key signs", func(t *testing.T) {
+		signingKey := store.SigningKey()
+		require.NotNil(t, signingKey)
+		assert.Equal(t, active.KeyID, signingKey.KeyID)
+		assert.Equal(t, active.Key, signingKey.Key)
+	})
+
+	s.T().Run("pending, active and retiring keys are published", func(t *testing.T) {
+		publicKeys := store.PublicKeys()
+		require.Len(t, publicKeys, 3)
+		for i, key := range []keystore.Key{retiring, active, pending} {
+			assert.Equal(t, key.KeyID, publicKeys[i].KeyID)
+			assert.Equal(t, key.Key.Public(), publicKeys[i].Key)
+			assert.Equal(t, key.Key.Public(), store.PublicKey(key.KeyID))
+			assert.Equal(t, key.Key, store.PrivateKey(key.KeyID))
+		}
+		assert.Len(t, store.Keys(), 3)
+	})
+
+	s.T().Run("retired keys are dropped", func(t *testing.T) {
+		assert.Nil(t, store.PublicKey(retired.KeyID))
+		assert.Nil(t, store.PrivateKey(retired.KeyID))
+		assert.Nil(t, store.PublicKey(uuid.NewV4().String()))
+	})
+
+	s.T().Run("replace keys", func(t *testing.T) {
+		newActive := s.newKey(repository.StateActive)
+		pending.State = repository.StateRetiring
+		err := store.Replace([]keystore.Key{pending, newActive})
+		require.NoError(t, err)
+		assert.Equal(t, newActive.KeyID, store.SigningKey().KeyID)
+		assert.Len(t, store.PublicKeys(), 2)
+		assert.Nil(t, store.PublicKey(active.KeyID))
+	})
+}
+
+func (s *TestKeyStoreSuite) TestExactlyOneActiveKey() {
+	_, err := keystore.New([]keystore.Key{s.newKey(repository.StatePending)})
+	assert.Error(s.T(), err)
+
+	_, err = keystore.New([]keystore.Key{s.newKey(repository.StateActive), s.newKey(repository.StateActive)})
+	assert.Error(s.T(), err)
+
+	active := s.newKey(repository.StateActive)
+	store, err := keystore.New([]keystore.Key{active})
+	require.NoError(s.T(), err)
+	err = store.Replace(nil)
+	assert.Error(s.T(), err)
+	// The keys are left unchanged
+	assert.Equal(s.T(), active.KeyID, store.SigningKey().KeyID)
+}
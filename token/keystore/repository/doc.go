@@ -0,0 +1,2 @@
+// Package repository provides the APIs for making 'signing_key' related database interactions.
+package repository
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/gormsupport"
+	"github.com/fabric8-services/fabric8-auth/log"
+
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+)
+
+const (
+	// AccountTypeUser is the account type of the keys which sign the user access, refresh and personal access tokens
+	AccountTypeUser = "user"
+	// AccountTypeServiceAccount is the account type of the keys which sign the service account tokens
+	AccountTypeServiceAccount = "service_account"
+
+	// StatePending is the state of a key which is published but does not sign any token yet
+	StatePending = "pending"
+	// StateActive is the state of the key which signs the new tokens. There is one active key per account type
+	StateActive = "active"
+	// StateRetiring is the state of a key which has been replaced by a new active key, and which is still published
+	// so that the tokens it signed can be verified
+	StateRetiring = "retiring"
+	// StateRetired is the state of a key which is not published anymore
+	StateRetired = "retired"
+
+	// signingKeyLockID is the ID of the advisory lock which serializes the changes of the signing keys among
+	// the instances of the service
+	signingKeyLockID = 2281766584
+)
+
+// SigningKey is a private key used to sign the tokens of an account type
+type SigningKey struct {
+	gormsupport.Lifecycle
+
+	// This is the primary key value, published as the "kid" of the key
+	KeyID string `gorm:"primary_key;column:key_id"`
+	// The type of the accounts whose tokens are signed with the key, one of AccountTypeUser or AccountTypeServiceAccount
+	AccountType string `gorm:"column:account_type"`
+	// The state of the key, one of StatePending, StateActive, StateRetiring or StateRetired
+	State string `gorm:"column:state"`
+	// The PEM encoded private key, encrypted with the key encryption key from the configuration
+	PrivateKey string `gorm:"column:private_key"`
+	// The time at which the key started signing tokens
+	ActivatedAt *time.Time `gorm:"column:activated_at"`
+	// The time at which the key stopped signing tokens
+	RetiringAt *time.Time `gorm:"column:retiring_at"`
+	// The time at which the key stopped being published
+	RetiredAt *time.Time `gorm:"column:retired_at"`
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m SigningKey) TableName() string {
+	return "signing_key"
+}
+
+// GormSigningKeyRepository is the implementation of the storage interface for SigningKey.
+type GormSigningKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewSigningKeyRepository creates a new storage type.
+func NewSigningKeyRepository(db *gorm.DB) SigningKeyRepository {
+	return &GormSigningKeyRepository{db: db}
+}
+
+// SigningKeyRepository represents the storage interface.
+type SigningKeyRepository interface {
+	Lock(ctx context.Context) error
+	Load(ctx context.Context, keyID string) (*SigningKey, error)
+	Create(ctx context.Context, key *SigningKey) error
+	Save(ctx context.Context, key *SigningKey) error
+	ListForAccountType(ctx context.Context, accountType string) ([]SigningKey, error)
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m *GormSigningKeyRepository) TableName() string {
+	return "signing_key"
+}
+
+// Lock acquires an advisory lock which is held until the end of the current transaction, so that the signing keys
+// are not changed by two instances of the service at the same time
+func (m *GormSigningKeyRepository) Lock(ctx context.Context) error {
+	defer goa.MeasureSince([]string{"goa", "db", "signing_key", "lock"}, time.Now())
+	err := m.db.Exec("SELECT pg_advisory_xact_lock(?)", signingKeyLockID).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err": err,
+		}, "unable to lock the signing keys")
+		return errs.WithStack(err)
+	}
+	return nil
+}
+
+// Load returns the signing key with the given ID
+func (m *GormSigningKeyRepository) Load(ctx context.Context, keyID string) (*SigningKey, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "signing_key", "load"}, time.Now())
+	var native SigningKey
+	err := m.db.Table(m.TableName()).Where("key_id = ?", keyID).Find(&native).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewNotFoundError("signing key", keyID)
+	}
+	return &native, errs.WithStack(err)
+}
+
+// Create creates a new record.
+func (m *GormSigningKeyRepository) Create(ctx context.Context, key *SigningKey) error {
+	defer goa.MeasureSince([]string{"goa", "db", "signing_key", "create"}, time.Now())
+	err := m.db.Create(key).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"key_id":       key.KeyID,
+			"account_type": key.AccountType,
+			"err":          err,
+		}, "unable to create the signing key")
+		if gormsupport.IsUniqueViolation(err, "signing_key_pkey") {
+			return errors.NewDataConflictError(fmt.Sprintf("signing key with ID %s already exists", key.KeyID))
+		}
+		if gormsupport.IsUniqueViolation(err, "idx_signing_key_active") {
+			return errors.NewDataConflictError(fmt.Sprintf("there is an active %s signing key already", key.AccountType))
+		}
+		return errs.WithStack(err)
+	}
+	log.Info(ctx, map[string]interface{}{
+		"key_id":       key.KeyID,
+		"account_type": key.AccountType,
+		"state":        key.State,
+	}, "Signing key created!")
+	return nil
+}
+
+// Save modifies a single record.
+func (m *GormSigningKeyRepository) Save(ctx context.Context, key *SigningKey) error {
+	defer goa.MeasureSince([]string{"goa", "db", "signing_key", "save"}, time.Now())
+
+	obj, err := m.Load(ctx, key.KeyID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"key_id": key.KeyID,
+			"err":    err,
+		}, "unable to update signing key")
+		return errs.WithStack(err)
+	}
+	err = m.db.Model(obj).Updates(key).Error
+	if err != nil {
+		if gormsupport.IsUniqueViolation(err, "idx_signing_key_active") {
+			return errors.NewDataConflictError(fmt.Sprintf("there is an active %s signing key already", key.AccountType))
+		}
+		return errs.WithStack(err)
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"key_id": key.KeyID,
+		"state":  key.State,
+	}, "Signing key saved!")
+	return nil
+}
+
+// ListForAccountType returns all the signing keys of the specified account type, including the retired ones,
+// oldest first
+func (m *GormSigningKeyRepository) ListForAccountType(ctx context.Context, accountType string) ([]SigningKey, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "signing_key", "listForAccountType"}, time.Now())
+	var rows []SigningKey
+
+	err := m.db.Model(&SigningKey{}).Where("account_type = ?", accountType).Order("created_at").Find(&rows).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errs.WithStack(err)
+	}
+	return rows, nil
+}
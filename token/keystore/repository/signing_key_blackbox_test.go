@@ -0,0 +1,113 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
+	"github.com/fabric8-services/fabric8-auth/token/keystore/repository"
+
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type signingKeyBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	repo repository.SigningKeyRepository
+}
+
+func TestRunSigningKeyBlackBoxTest(t *testing.T) {
+	suite.Run(t, &signingKeyBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *signingKeyBlackBoxTest) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.repo = repository.NewSigningKeyRepository(s.DB)
+}
+
+func (s *signingKeyBlackBoxTest) newKey(accountType string, state string) *repository.SigningKey {
+	key := &repository.SigningKey{
+		KeyID:       uuid.NewV4().String(),
+		AccountType: accountType,
+		State:       state,
+		PrivateKey:  "ZW5jcnlwdGVkIHByaXZhdGUga2V5",
+	}
+	err := s.repo.Create(s.Ctx, key)
+	require.NoError(s.T(), err)
+	return key
+}
+
+func (s *signingKeyBlackBoxTest) TestCreateAndLoad() {
+	key := s.newKey(repository.AccountTypeUser, repository.StatePending)
+
+	loaded, err := s.repo.Load(s.Ctx, key.KeyID)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), repository.AccountTypeUser, loaded.AccountType)
+	require.Equal(s.T(), repository.StatePending, loaded.State)
+	require.Equal(s.T(), key.PrivateKey, loaded.PrivateKey)
+	require.Nil(s.T(), loaded.ActivatedAt)
+
+	// Key IDs are unique
+	err = s.repo.Create(s.Ctx, &repository.SigningKey{
+		KeyID:       key.KeyID,
+		AccountType: repository.AccountTypeServiceAccount,
+		State:       repository.StatePending,
+		PrivateKey:  key.PrivateKey,
+	})
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.DataConflictError{}, err)
+
+	_, err = s.repo.Load(s.Ctx, uuid.NewV4().String())
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.NotFoundError{}, err)
+}
+
+func (s *signingKeyBlackBoxTest) TestOneActiveKeyPerAccountType() {
+	active := s.newKey(repository.AccountTypeUser, repository.StateActive)
+	s.newKey(repository.AccountTypeServiceAccount, repository.StateActive)
+	pending := s.newKey(repository.AccountTypeUser, repository.StatePending)
+
+	err := s.repo.Create(s.Ctx, &repository.SigningKey{
+		KeyID:       uuid.NewV4().String(),
+		AccountType: repository.AccountTypeUser,
+		State:       repository.StateActive,
+		PrivateKey:  active.PrivateKey,
+	})
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.DataConflictError{}, err)
+
+	pending.State = repository.StateActive
+	err = s.repo.Save(s.Ctx, pending)
+	require.Error(s.T(), err)
+	require.IsType(s.T(), errors.DataConflictError{}, err)
+
+	// Once the active key is retiring, another key can be activated
+	now := time.Now()
+	active.State = repository.StateRetiring
+	active.RetiringAt = &now
+	require.NoError(s.T(), s.repo.Save(s.Ctx, active))
+	require.NoError(s.T(), s.repo.Save(s.Ctx, pending))
+
+	loaded, err := s.repo.Load(s.Ctx, active.KeyID)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), repository.StateRetiring, loaded.State)
+	require.NotNil(s.T(), loaded.RetiringAt)
+}
+
+func (s *signingKeyBlackBoxTest) TestListForAccountType() {
+	first := s.newKey(repository.AccountTypeUser, repository.StateActive)
+	second := s.newKey(repository.AccountTypeUser, repository.StatePending)
+	s.newKey(repository.AccountTypeServiceAccount, repository.StateActive)
+
+	keys, err := s.repo.ListForAccountType(s.Ctx, repository.AccountTypeUser)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), keys, 2)
+	require.Equal(s.T(), first.KeyID, keys[0].KeyID)
+	require.Equal(s.T(), second.KeyID, keys[1].KeyID)
+}
+
+func (s *signingKeyBlackBoxTest) TestLock() {
+	require.NoError(s.T(), s.repo.Lock(s.Ctx))
+}
@@ -0,0 +1,2 @@
+// Package rotation stores the signing keys in the database and rotates them
+package rotation
@@ -0,0 +1,367 @@
+package rotation
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"time"
+
+	"github.com/fabric8-services/fabric8-auth/application/transaction"
+	autherrors "github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/log"
+	"github.com/fabric8-services/fabric8-auth/token/jwk"
+	"github.com/fabric8-services/fabric8-auth/token/keystore"
+	"github.com/fabric8-services/fabric8-auth/token/keystore/repository"
+	"github.com/fabric8-services/fabric8-auth/worker"
+
+	"github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+)
+
+// accountTypes are the account types whose signing keys are rotated, in the order in which they are processed
+var accountTypes = []string{repository.AccountTypeUser, repository.AccountTypeServiceAccount}
+
+// configuration represents configuration needed to rotate the signing keys
+type configuration interface {
+	GetSigningKeyRotationInterval() time.Duration
+	GetSigningKeyRetirementPeriod() time.Duration
+	GetSigningKeyRefreshInterval() time.Duration
+	GetSigningKeyGeneratedKeyBits() int
+	GetSigningKeyGeneratedAlgorithm() string
+	GetSigningKeyEncryptionKey() string
+	GetAccessTokenExpiresIn() int64
+	GetRefreshTokenExpiresIn() int64
+	GetPersonalAccessTokenMaxTTL() time.Duration
+}
+
+// Rotator stores the signing keys in the database, so that all the instances of the service share the same keys,
+// and rotates them without restarting the service.
+//
+// A rotation activates the oldest pending key, moves the previously active key to the retiring state and adds a new
+// pending key. Since the pending keys are published before they sign any token, the token consumers which cache
+// the public keys know the new active key already. The retiring keys are retired once the retirement period is over.
+//
+// The private keys are encrypted with the key encryption key from the configuration before they are stored.
+type Rotator struct {
+	db     transaction.TransactionManager
+	config configuration
+	stores map[string]*keystore.KeyStore
+}
+
+// NewRotator returns a new Rotator which stores the signing keys in the given database
+func NewRotator(db transaction.TransactionManager, config configuration) *Rotator {
+	return &Rotator{db: db, config: config}
+}
+
+// Load loads the signing keys of the account types of the given stores from the database, and keeps the stores up to
+// date with the database from now on. If there is no key for an account type in the database yet, then the keys of
+// the store are saved in the database, along with a new pending key.
+func (r *Rotator) Load(ctx context.Context, stores map[string]*keystore.KeyStore) error {
+	r.stores = stores
+	return r.Refresh(ctx)
+}
+
+// Refresh reloads the signing keys from the database, after rotating the active keys which are older than the rotation
+// interval and retiring the keys whose retirement period is over
+func (r *Rotator) Refresh(ctx context.Context) error {
+	for _, accountType := range accountTypes {
+		if _, found := r.stores[accountType]; !found {
+			continue
+		}
+		err := r.update(ctx, accountType, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rotate immediately rotates the signing keys of the given account type
+func (r *Rotator) Rotate(ctx context.Context, accountType string) error {
+	if _, found := r.stores[accountType]; !found {
+		return autherrors.NewBadParameterErrorFromString("account_type", accountType, "unknown account type")
+	}
+	return r.update(ctx, accountType, true)
+}
+
+// Start starts a goroutine which refreshes the signing keys at the configured interval. The refresh is disabled if the
+// interval is zero or negative. The returned function stops it.
+func (r *Rotator) Start() func() {
+	return worker.RunPeriodically(r.config.GetSigningKeyRefreshInterval(), r.Refresh, "refresh the signing keys")
+}
+
+// update applies the due changes to the signing keys of the account type in the database, rotating them if forced,
+// and replaces the keys of the store of the account type with the keys from the database
+func (r *Rotator) update(ctx context.Context, accountType string, forceRotation bool) error {
+	var keys []keystore.Key
+	err := transaction.Transactional(r.db, func(tr transaction.TransactionalResources) error {
+		repo := tr.SigningKeyRepository()
+		err := repo.Lock(ctx)
+		if err != nil {
+			return err
+		}
+		signingKeys, err := repo.ListForAccountType(ctx, accountType)
+		if err != nil {
+			return err
+		}
+		if len(signingKeys) == 0 {
+			signingKeys, err = r.seed(ctx, repo, accountType)
+			if err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		if forceRotation || r.rotationDue(signingKeys, now) {
+			signingKeys, err = r.rotate(ctx, repo, accountType, signingKeys, now)
+			if err != nil {
+				return err
+			}
+		}
+		err = r.retire(ctx, repo, signingKeys, now)
+		if err != nil {
+			return err
+		}
+		keys, err = r.toKeys(signingKeys)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return r.stores[accountType].Replace(keys)
+}
+
+// seed saves the keys of the store of the account type in the database, along with a new pending key
+func (r *Rotator) seed(ctx context.Context, repo repository.SigningKeyRepository, accountType string) ([]repository.SigningKey, error) {
+	now := time.Now()
+	var signingKeys []repository.SigningKey
+	for _, key := range r.stores[accountType].Keys() {
+		privateKey, err := r.encryptPrivateKey(key.KeyID, key.Key)
+		if err != nil {
+			return nil, err
+		}
+		signingKey := repository.SigningKey{
+			KeyID:       key.KeyID,
+			AccountType: accountType,
+			State:       key.State,
+			PrivateKey:  privateKey,
+		}
+		switch key.State {
+		case repository.StateActive:
+			signingKey.ActivatedAt = &now
+		case repository.StateRetiring:
+			signingKey.RetiringAt = &now
+		}
+		err = repo.Create(ctx, &signingKey)
+		if err != nil {
+			return nil, err
+		}
+		signingKeys = append(signingKeys, signingKey)
+	}
+	pending, err := r.generateKey(accountType, repository.StatePending)
+	if err != nil {
+		return nil, err
+	}
+	err = repo.Create(ctx, pending)
+	if err != nil {
+		return nil, err
+	}
+	log.Info(ctx, map[string]interface{}{
+		"account_type":   accountType,
+		"number_of_keys": len(signingKeys) + 1,
+	}, "signing keys initialized from the configuration")
+	return append(signingKeys, *pending), nil
+}
+
+// rotationDue returns true if the active key is older than the rotation interval
+func (r *Rotator) rotationDue(signingKeys []repository.SigningKey, now time.Time) bool {
+	interval := r.config.GetSigningKeyRotationInterval()
+	if interval <= 0 {
+		return false
+	}
+	for _, key := range signingKeys {
+		if key.State == repository.StateActive && key.ActivatedAt != nil {
+			return !key.ActivatedAt.Add(interval).After(now)
+		}
+	}
+	return false
+}
+
+// rotate moves the active key to the retiring state, activates the oldest pending key and adds a new pending key.
+// A new key is activated right away if there is no pending key.
+func (r *Rotator) rotate(ctx context.Context, repo repository.SigningKeyRepository, accountType string, signingKeys []repository.SigningKey, now time.Time) ([]repository.SigningKey, error) {
+	pending := -1
+	for i := range signingKeys {
+		switch signingKeys[i].State {
+		case repository.StateActive:
+			// The active key must be retiring before another key can be activated
+			signingKeys[i].State = repository.StateRetiring
+			signingKeys[i].RetiringAt = &now
+			err := repo.Save(ctx, &signingKeys[i])
+			if err != nil {
+				return nil, err
+			}
+		case repository.StatePending:
+			if pending < 0 {
+				pending = i
+			}
+		}
+	}
+	if pending >= 0 {
+		signingKeys[pending].State = repository.StateActive
+		signingKeys[pending].ActivatedAt = &now
+		err := repo.Save(ctx, &signingKeys[pending])
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		active, err := r.generateKey(accountType, repository.StateActive)
+		if err != nil {
+			return nil, err
+		}
+		active.ActivatedAt = &now
+		err = repo.Create(ctx, active)
+		if err != nil {
+			return nil, err
+		}
+		signingKeys = append(signingKeys, *active)
+	}
+	next, err := r.generateKey(accountType, repository.StatePending)
+	if err != nil {
+		return nil, err
+	}
+	err = repo.Create(ctx, next)
+	if err != nil {
+		return nil, err
+	}
+	log.Info(ctx, map[string]interface{}{
+		"account_type": accountType,
+	}, "signing keys rotated")
+	return append(signingKeys, *next), nil
+}
+
+// retire retires the retiring keys whose retirement period is over
+func (r *Rotator) retire(ctx context.Context, repo repository.SigningKeyRepository, signingKeys []repository.SigningKey, now time.Time) error {
+	period := r.retirementPeriod()
+	for i := range signingKeys {
+		key := &signingKeys[i]
+		if key.State != repository.StateRetiring || key.RetiringAt == nil || key.RetiringAt.Add(period).After(now) {
+			continue
+		}
+		key.State = repository.StateRetired
+		key.RetiredAt = &now
+		err := repo.Save(ctx, key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retirementPeriod returns the time during which the retiring keys are still published, which is the configured
+// retirement period or the longest lifetime of the tokens they signed, whichever is longer. Otherwise the tokens
+// signed with a key, e.g. the personal access tokens, could not be verified anymore before they expire.
+func (r *Rotator) retirementPeriod() time.Duration {
+	period := r.config.GetSigningKeyRetirementPeriod()
+	for _, lifetime := range []time.Duration{
+		time.Duration(r.config.GetAccessTokenExpiresIn()) * time.Second,
+		time.Duration(r.config.GetRefreshTokenExpiresIn()) * time.Second,
+		r.config.GetPersonalAccessTokenMaxTTL(),
+	} {
+		if lifetime > period {
+			period = lifetime
+		}
+	}
+	return period
+}
+
+// generateKey generates a new key of the configured algorithm for the account type, in the given state
+func (r *Rotator) generateKey(accountType string, state string) (*repository.SigningKey, error) {
+	key, err := jwk.GenerateKey(r.config.GetSigningKeyGeneratedAlgorithm(), r.config.GetSigningKeyGeneratedKeyBits())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to generate a signing key")
+	}
+	keyID := uuid.NewV4().String()
+	privateKey, err := r.encryptPrivateKey(keyID, key)
+	if err != nil {
+		return nil, err
+	}
+	return &repository.SigningKey{
+		KeyID:       keyID,
+		AccountType: accountType,
+		State:       state,
+		PrivateKey:  privateKey,
+	}, nil
+}
+
+// toKeys converts the signing keys stored in the database which are not retired into keys
+func (r *Rotator) toKeys(signingKeys []repository.SigningKey) ([]keystore.Key, error) {
+	var keys []keystore.Key
+	for _, signingKey := range signingKeys {
+		if signingKey.State == repository.StateRetired {
+			continue
+		}
+		key, err := r.decryptPrivateKey(signingKey.KeyID, signingKey.PrivateKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse the signing key %s", signingKey.KeyID)
+		}
+		keys = append(keys, keystore.Key{KeyID: signingKey.KeyID, State: signingKey.State, Key: key})
+	}
+	return keys, nil
+}
+
+// encryptPrivateKey encodes the given private key to PEM and encrypts it with the key encryption key. The ID of the key
+// is authenticated along with the ciphertext, so that the encrypted key can not be stored under another ID.
+// The result is the base64 encoded nonce followed by the ciphertext.
+func (r *Rotator) encryptPrivateKey(keyID string, key crypto.Signer) (string, error) {
+	data, err := jwk.EncodePrivateKeyToPEM(key)
+	if err != nil {
+		return "", err
+	}
+	aead, err := r.keyEncryption()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", errors.Wrap(err, "unable to generate a nonce")
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, data, []byte(keyID))), nil
+}
+
+// decryptPrivateKey decrypts the given private key encrypted by encryptPrivateKey and parses it
+func (r *Rotator) decryptPrivateKey(keyID string, encrypted string) (crypto.Signer, error) {
+	aead, err := r.keyEncryption()
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil || len(data) < aead.NonceSize() {
+		return nil, errors.New("the private key is not encrypted")
+	}
+	pem, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(keyID))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to decrypt the private key")
+	}
+	return jwk.ParsePrivateKeyFromPEM(pem)
+}
+
+// keyEncryption returns the AES-256-GCM cipher of the configured key encryption key
+func (r *Rotator) keyEncryption() (cipher.AEAD, error) {
+	kek, err := base64.StdEncoding.DecodeString(r.config.GetSigningKeyEncryptionKey())
+	if err != nil || len(kek) != 32 {
+		return nil, errors.New("the signing key encryption key must be a base64 encoded 256-bit key")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return aead, nil
+}
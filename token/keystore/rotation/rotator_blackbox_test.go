@@ -0,0 +1,259 @@
+package rotation_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-auth/configuration"
+	"github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/gormtestsupport"
+	"github.com/fabric8-services/fabric8-auth/token/jwk"
+	"github.com/fabric8-services/fabric8-auth/token/keystore"
+	"github.com/fabric8-services/fabric8-auth/token/keystore/repository"
+	"github.com/fabric8-services/fabric8-auth/token/keystore/rotation"
+
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type rotatorBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunRotatorBlackBoxTest(t *testing.T) {
+	suite.Run(t, &rotatorBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+type rotationConfig struct {
+	*configuration.ConfigurationData
+	rotationInterval time.Duration
+	retirementPeriod time.Duration
+	algorithm        string
+	encryptionKey    string
+	tokenLifetime    time.Duration
+}
+
+func (c *rotationConfig) GetSigningKeyRotationInterval() time.Duration {
+	return c.rotationInterval
+}
+
+func (c *rotationConfig) GetSigningKeyRetirementPeriod() time.Duration {
+	return c.retirementPeriod
+}
+
+func (c *rotationConfig) GetAccessTokenExpiresIn() int64 {
+	return int64(c.tokenLifetime.Seconds())
+}
+
+func (c *rotationConfig) GetRefreshTokenExpiresIn() int64 {
+	return int64(c.tokenLifetime.Seconds())
+}
+
+func (c *rotationConfig) GetPersonalAccessTokenMaxTTL() time.Duration {
+	return c.tokenLifetime
+}
+
+func (c *rotationConfig) GetSigningKeyGeneratedKeyBits() int {
+	return 1024
+}
+
+func (c *rotationConfig) GetSigningKeyGeneratedAlgorithm() string {
+	if c.algorithm == "" {
+		return c.ConfigurationData.GetSigningKeyGeneratedAlgorithm()
+	}
+	return c.algorithm
+}
+
+func (c *rotationConfig) GetSigningKeyEncryptionKey() string {
+	if c.encryptionKey == "" {
+		return c.ConfigurationData.GetSigningKeyEncryptionKey()
+	}
+	return c.encryptionKey
+}
+
+func (s *rotatorBlackBoxTest) newStore() (*keystore.KeyStore, keystore.Key, keystore.Key) {
+	active := s.newKey(repository.StateActive)
+	deprecated := s.newKey(repository.StateRetiring)
+	store, err := keystore.New([]keystore.Key{active, deprecated})
+	require.NoError(s.T(), err)
+	return store, active, deprecated
+}
+
+func (s *rotatorBlackBoxTest) newKey(state string) keystore.Key {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(s.T(), err)
+	return keystore.Key{KeyID: uuid.NewV4().String(), State: state, Key: key}
+}
+
+func (s *rotatorBlackBoxTest) newRotator(config *rotationConfig) (*rotation.Rotator, *keystore.KeyStore, *keystore.KeyStore) {
+	userStore, _, _ := s.newStore()
+	serviceAccountStore, _, _ := s.newStore()
+	rotator := rotation.NewRotator(s.Application, config)
+	err := rotator.Load(s.Ctx, map[string]*keystore.KeyStore{
+		repository.AccountTypeUser:           userStore,
+		repository.AccountTypeServiceAccount: serviceAccountStore,
+	})
+	require.NoError(s.T(), err)
+	return rotator, userStore, serviceAccountStore
+}
+
+func (s *rotatorBlackBoxTest) keyIDs(store *keystore.KeyStore, state string) []string {
+	var ids []string
+	for _, key := range store.Keys() {
+		if key.State == state {
+			ids = append(ids, key.KeyID)
+		}
+	}
+	return ids
+}
+
+func (s *rotatorBlackBoxTest) TestLoad() {
+	userStore, active, deprecated := s.newStore()
+	rotator := rotation.NewRotator(s.Application, &rotationConfig{ConfigurationData: s.Configuration, retirementPeriod: time.Hour})
+
+	// The keys of the store are saved with a new pending key
+	err := rotator.Load(s.Ctx, map[string]*keystore.KeyStore{repository.AccountTypeUser: userStore})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), active.KeyID, userStore.SigningKey().KeyID)
+	require.Equal(s.T(), []string{deprecated.KeyID}, s.keyIDs(userStore, repository.StateRetiring))
+	require.Len(s.T(), s.keyIDs(userStore, repository.StatePending), 1)
+
+	signingKeys, err := s.Application.SigningKeyRepository().ListForAccountType(s.Ctx, repository.AccountTypeUser)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), signingKeys, 3)
+	signingKeys, err = s.Application.SigningKeyRepository().ListForAccountType(s.Ctx, repository.AccountTypeServiceAccount)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), signingKeys)
+
+	// Another instance of the service loads the keys from the database instead of its own keys
+	otherStore, _, _ := s.newStore()
+	err = rotation.NewRotator(s.Application, &rotationConfig{ConfigurationData: s.Configuration, retirementPeriod: time.Hour}).Load(s.Ctx, map[string]*keystore.KeyStore{repository.AccountTypeUser: otherStore})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), userStore.Keys(), otherStore.Keys())
+}
+
+func (s *rotatorBlackBoxTest) TestPrivateKeysAreEncrypted() {
+	userStore, _, _ := s.newStore()
+	err := rotation.NewRotator(s.Application, &rotationConfig{ConfigurationData: s.Configuration, retirementPeriod: time.Hour}).Load(s.Ctx, map[string]*keystore.KeyStore{repository.AccountTypeUser: userStore})
+	require.NoError(s.T(), err)
+
+	signingKeys, err := s.Application.SigningKeyRepository().ListForAccountType(s.Ctx, repository.AccountTypeUser)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), signingKeys, 3)
+	for _, signingKey := range signingKeys {
+		assert.NotContains(s.T(), signingKey.PrivateKey, "PRIVATE KEY")
+	}
+
+	// The keys can not be loaded with another key encryption key
+	otherStore, _, _ := s.newStore()
+	err = rotation.NewRotator(s.Application, &rotationConfig{
+		ConfigurationData: s.Configuration,
+		retirementPeriod:  time.Hour,
+		encryptionKey:     "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+	}).Load(s.Ctx, map[string]*keystore.KeyStore{repository.AccountTypeUser: otherStore})
+	require.Error(s.T(), err)
+
+	// Nor with an invalid key encryption key
+	err = rotation.NewRotator(s.Application, &rotationConfig{
+		ConfigurationData: s.Configuration,
+		retirementPeriod:  time.Hour,
+		encryptionKey:     "too short",
+	}).Load(s.Ctx, map[string]*keystore.KeyStore{repository.AccountTypeUser: otherStore})
+	require.Error(s.T(), err)
+}
+
+func (s *rotatorBlackBoxTest) TestRotate() {
+	rotator, userStore, serviceAccountStore := s.newRotator(&rotationConfig{ConfigurationData: s.Configuration, retirementPeriod: time.Hour})
+	serviceAccountKeys := serviceAccountStore.Keys()
+	active := userStore.SigningKey().KeyID
+	pending := s.keyIDs(userStore, repository.StatePending)
+
+	err := rotator.Rotate(s.Ctx, repository.AccountTypeUser)
+	require.NoError(s.T(), err)
+
+	// The pending key is activated and the active key is retiring
+	assert.Equal(s.T(), pending[0], userStore.SigningKey().KeyID)
+	assert.Contains(s.T(), s.keyIDs(userStore, repository.StateRetiring), active)
+	newPending := s.keyIDs(userStore, repository.StatePending)
+	require.Len(s.T(), newPending, 1)
+	assert.NotEqual(s.T(), pending[0], newPending[0])
+	// The keys of the other account type are unchanged
+	assert.Equal(s.T(), serviceAccountKeys, serviceAccountStore.Keys())
+
+	loaded, err := s.Application.SigningKeyRepository().Load(s.Ctx, active)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), repository.StateRetiring, loaded.State)
+	assert.NotNil(s.T(), loaded.RetiringAt)
+
+	err = rotator.Rotate(s.Ctx, "unknown")
+	require.Error(s.T(), err)
+	assert.IsType(s.T(), errors.BadParameterError{}, err)
+}
+
+func (s *rotatorBlackBoxTest) TestRefresh() {
+	config := &rotationConfig{ConfigurationData: s.Configuration, retirementPeriod: time.Hour}
+	rotator, userStore, _ := s.newRotator(config)
+	active := userStore.SigningKey().KeyID
+
+	s.T().Run("no scheduled rotation", func(t *testing.T) {
+		require.NoError(t, rotator.Refresh(s.Ctx))
+		assert.Equal(t, active, userStore.SigningKey().KeyID)
+	})
+
+	s.T().Run("rotation is due", func(t *testing.T) {
+		config.rotationInterval = time.Nanosecond
+		defer func() { config.rotationInterval = 0 }()
+		require.NoError(t, rotator.Refresh(s.Ctx))
+		assert.NotEqual(t, active, userStore.SigningKey().KeyID)
+		assert.Contains(t, s.keyIDs(userStore, repository.StateRetiring), active)
+	})
+
+	s.T().Run("tokens signed with the key have not expired yet", func(t *testing.T) {
+		config.retirementPeriod = 0
+		config.tokenLifetime = time.Hour
+		defer func() { config.tokenLifetime = 0 }()
+		require.NoError(t, rotator.Refresh(s.Ctx))
+		assert.Contains(t, s.keyIDs(userStore, repository.StateRetiring), active)
+		assert.NotNil(t, userStore.PublicKey(active))
+	})
+
+	s.T().Run("retirement period is over", func(t *testing.T) {
+		config.retirementPeriod = 0
+		require.NoError(t, rotator.Refresh(s.Ctx))
+		assert.Empty(t, s.keyIDs(userStore, repository.StateRetiring))
+		assert.Nil(t, userStore.PublicKey(active))
+
+		loaded, err := s.Application.SigningKeyRepository().Load(s.Ctx, active)
+		require.NoError(t, err)
+		assert.Equal(t, repository.StateRetired, loaded.State)
+		assert.NotNil(t, loaded.RetiredAt)
+	})
+}
+
+func (s *rotatorBlackBoxTest) TestRotateWithConfiguredAlgorithm() {
+	for _, algorithm := range jwk.SupportedAlgorithms {
+		s.T().Run(algorithm, func(t *testing.T) {
+			rotator, userStore, _ := s.newRotator(&rotationConfig{ConfigurationData: s.Configuration, retirementPeriod: time.Hour, algorithm: algorithm})
+			// The second rotation activates the pending key generated by the first one
+			for i := 0; i < 2; i++ {
+				err := rotator.Rotate(s.Ctx, repository.AccountTypeUser)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, algorithm, jwk.Algorithm(userStore.SigningKey().Key))
+			for _, key := range userStore.Keys() {
+				if key.State == repository.StatePending {
+					assert.Equal(t, algorithm, jwk.Algorithm(key.Key))
+				}
+			}
+			// The keys are read back from the database by another instance of the service
+			otherStore, _, _ := s.newStore()
+			err := rotation.NewRotator(s.Application, &rotationConfig{ConfigurationData: s.Configuration, retirementPeriod: time.Hour}).Load(s.Ctx, map[string]*keystore.KeyStore{repository.AccountTypeUser: otherStore})
+			require.NoError(t, err)
+			assert.Equal(t, userStore.Keys(), otherStore.Keys())
+		})
+	}
+}
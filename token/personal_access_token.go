@@ -0,0 +1,150 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-auth/account/repository"
+	tokenrepo "github.com/fabric8-services/fabric8-auth/authorization/token/repository"
+	autherrors "github.com/fabric8-services/fabric8-auth/errors"
+	"github.com/fabric8-services/fabric8-auth/log"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+)
+
+const (
+	// PersonalAccessTokenType is the value of the "typ" claim of personal access tokens. Personal access tokens are
+	// not "Bearer" tokens, so that the other services which only accept user access tokens refuse them.
+	PersonalAccessTokenType = "Personal"
+
+	// ScopeAccessRead is the access level of the scopes which grant read-only access to an area of the API
+	ScopeAccessRead = "read"
+	// ScopeAccessWrite is the access level of the scopes which grant read and write access to an area of the API
+	ScopeAccessWrite = "write"
+)
+
+// personalAccessTokenScopePattern is the format of the scopes of personal access tokens, i.e. "<area>:<access>" where
+// the area is the first segment of the API path (e.g. "spaces" for "/api/spaces/...") and the access is either
+// "read" or "write"
+var personalAccessTokenScopePattern = regexp.MustCompile(`^([a-z][a-z_-]*):(read|write)$`)
+
+// ValidatePersonalAccessTokenScopes checks that at least one scope is given and that all the scopes are well formed
+func ValidatePersonalAccessTokenScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return autherrors.NewBadParameterErrorFromString("scopes", "", "at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !personalAccessTokenScopePattern.MatchString(scope) {
+			return autherrors.NewBadParameterErrorFromString("scopes", scope, "scopes must have the form <area>:read or <area>:write")
+		}
+	}
+	return nil
+}
+
+// IsPersonalAccessToken returns true if the token with the given claims is a personal access token
+func IsPersonalAccessToken(claims jwt.MapClaims) bool {
+	return claims["typ"] == PersonalAccessTokenType
+}
+
+// PersonalAccessTokenAllows returns true if the personal access token with the given claims grants access to the API
+// endpoint with the given HTTP method and path. Safe methods require the "read" or the "write" access to the area of
+// the endpoint, all the other methods require the "write" access.
+func PersonalAccessTokenAllows(claims jwt.MapClaims, method string, path string) bool {
+	area := apiArea(path)
+	if area == "" {
+		return false
+	}
+	scope, _ := claims["scope"].(string)
+	for _, s := range strings.Fields(scope) {
+		match := personalAccessTokenScopePattern.FindStringSubmatch(s)
+		if match == nil || match[1] != area {
+			continue
+		}
+		if match[2] == ScopeAccessWrite {
+			return true
+		}
+		switch method {
+		case "GET", "HEAD", "OPTIONS":
+			return true
+		}
+	}
+	return false
+}
+
+// apiArea returns the first segment of the given API path, e.g. "spaces" for "/api/spaces/123/roles".
+// An empty string is returned if the path is not an API path.
+func apiArea(path string) string {
+	if !strings.HasPrefix(path, "/api/") {
+		return ""
+	}
+	return strings.SplitN(strings.TrimPrefix(path, "/api/"), "/", 2)[0]
+}
+
+// GeneratePersonalAccessToken generates a signed personal access token for the given identity, restricted to the
+// given scopes and valid until the given expiry time. The token is recorded with its name and scopes, so that it can be
+// listed and revoked by its owner later. A token repository is required.
+// Personal access tokens have their own type and are only intended for the auth service itself, i.e. their audience is
+// the issuer, so that the other services which check the audience and type of the tokens refuse them.
+func (mgm *tokenManager) GeneratePersonalAccessToken(ctx context.Context, identity repository.Identity, name string, scopes []string, expiry time.Time) (string, *tokenrepo.Token, error) {
+	if mgm.tokenRepository == nil {
+		return "", nil, errors.New("token repository is required to generate personal access tokens")
+	}
+	if strings.TrimSpace(name) == "" {
+		return "", nil, autherrors.NewBadParameterErrorFromString("name", name, "the name of the token must not be empty")
+	}
+	err := ValidatePersonalAccessTokenScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	if !expiry.After(now) {
+		return "", nil, autherrors.NewBadParameterErrorFromString("expires_at", expiry, "the expiry time must be in the future")
+	}
+	if maxTTL := mgm.config.GetPersonalAccessTokenMaxTTL(); maxTTL > 0 && expiry.After(now.Add(maxTTL)) {
+		return "", nil, autherrors.NewBadParameterErrorFromString("expires_at", expiry, fmt.Sprintf("personal access tokens can not be valid for more than %s", maxTTL))
+	}
+
+	unsignedToken, err := mgm.GenerateUnsignedUserAccessTokenForIdentity(ctx, identity)
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+	claims := unsignedToken.Claims.(jwt.MapClaims)
+	claims["exp"] = expiry.Unix()
+	claims["scope"] = strings.Join(scopes, " ")
+	claims["aud"] = claims["iss"]
+	claims["typ"] = PersonalAccessTokenType
+	tokenString, err := signToken(unsignedToken, mgm.userAccountKeys)
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	tokenID, err := uuid.FromString(claims["jti"].(string))
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+	scope := claims["scope"].(string)
+	t := &tokenrepo.Token{
+		TokenID:    tokenID,
+		IdentityID: identity.ID,
+		TokenType:  tokenrepo.TokenTypePersonal,
+		ExpiryTime: time.Unix(expiry.Unix(), 0),
+		Name:       &name,
+		Scopes:     &scope,
+	}
+	err = mgm.tokenRepository.Create(ctx, t)
+	if err != nil {
+		return "", nil, err
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"token_id":    tokenID,
+		"identity_id": identity.ID,
+		"scopes":      scope,
+	}, "personal access token generated")
+	return tokenString, t, nil
+}
@@ -3,8 +3,7 @@ package token
 import (
 	"bytes"
 	"context"
-	"crypto/rsa"
-	"crypto/x509"
+	"crypto"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -12,16 +11,20 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fabric8-services/fabric8-auth/account"
 	"github.com/fabric8-services/fabric8-auth/account/repository"
+	tokenrepo "github.com/fabric8-services/fabric8-auth/authorization/token/repository"
 	authclient "github.com/fabric8-services/fabric8-auth/client"
 	autherrors "github.com/fabric8-services/fabric8-auth/errors"
 	"github.com/fabric8-services/fabric8-auth/goasupport"
 	"github.com/fabric8-services/fabric8-auth/log"
 	"github.com/fabric8-services/fabric8-auth/rest"
 	"github.com/fabric8-services/fabric8-auth/token/jwk"
+	"github.com/fabric8-services/fabric8-auth/token/keystore"
+	keyrepo "github.com/fabric8-services/fabric8-auth/token/keystore/repository"
 	"github.com/fabric8-services/fabric8-auth/token/tokencontext"
 
 	"github.com/dgrijalva/jwt-go"
@@ -49,6 +52,7 @@ const (
 	OnlineRegistration = "online-registration"
 	RhChe              = "rh-che"
 	GeminiServer       = "fabric8-gemini-server"
+	AuthAdmin          = "fabric8-auth-admin"
 )
 
 // configuration represents configuration needed to construct a token manager
@@ -61,6 +65,7 @@ type configuration interface {
 	IsPostgresDeveloperModeEnabled() bool
 	GetAccessTokenExpiresIn() int64
 	GetRefreshTokenExpiresIn() int64
+	GetPersonalAccessTokenMaxTTL() time.Duration
 	GetAuthServiceURL() string
 }
 
@@ -86,14 +91,16 @@ type AuthorizationPayload struct {
 
 // Permissions represents a "permissions" in the AuthorizationPayload
 type Permissions struct {
-	ResourceSetName *string `json:"resource_set_name"`
-	ResourceSetID   *string `json:"resource_set_id"`
+	ResourceSetName *string  `json:"resource_set_name"`
+	ResourceSetID   *string  `json:"resource_set_id"`
+	Scopes          []string `json:"scopes"`
+	Expiry          int64    `json:"exp,omitempty"`
 }
 
-// Parser parses a token and exposes the public keys for the Goa JWT middleware.
+// Parser parses a token and exposes the public keys which verify the tokens.
 type Parser interface {
 	Parse(ctx context.Context, tokenString string) (*jwt.Token, error)
-	PublicKeys() []*rsa.PublicKey
+	PublicKeys() []crypto.PublicKey
 }
 
 // Manager generate and find auth token information
@@ -102,7 +109,7 @@ type Manager interface {
 	Locate(ctx context.Context) (uuid.UUID, error)
 	ParseToken(ctx context.Context, tokenString string) (*TokenClaims, error)
 	ParseTokenWithMapClaims(ctx context.Context, tokenString string) (jwt.MapClaims, error)
-	PublicKey(keyID string) *rsa.PublicKey
+	PublicKey(keyID string) crypto.PublicKey
 	JSONWebKeys() jwk.JSONKeys
 	PemKeys() jwk.JSONKeys
 	AuthServiceAccountToken() string
@@ -110,6 +117,9 @@ type Manager interface {
 	GenerateUnsignedServiceAccountToken(saID string, saName string) *jwt.Token
 	GenerateUserToken(ctx context.Context, keycloakToken oauth2.Token, identity *repository.Identity) (*oauth2.Token, error)
 	GenerateUserTokenForIdentity(ctx context.Context, identity repository.Identity, offlineToken bool) (*oauth2.Token, error)
+	GenerateRPT(ctx context.Context, accessToken string, permissions Permissions) (*oauth2.Token, error)
+	GeneratePersonalAccessToken(ctx context.Context, identity repository.Identity, name string, scopes []string, expiry time.Time) (string, *tokenrepo.Token, error)
+	RevokeToken(ctx context.Context, tokenString string) error
 	ConvertTokenSet(tokenSet TokenSet) *oauth2.Token
 	ConvertToken(oauthToken oauth2.Token) (*TokenSet, error)
 	AddLoginRequiredHeaderToUnauthorizedError(err error, rw http.ResponseWriter)
@@ -117,43 +127,74 @@ type Manager interface {
 }
 
 type tokenManager struct {
-	publicKeysMap            map[string]*rsa.PublicKey
-	publicKeys               []*jwk.PublicKey
-	serviceAccountPrivateKey *jwk.PrivateKey
-	userAccountPrivateKey    *jwk.PrivateKey
-	jsonWebKeys              jwk.JSONKeys
-	pemKeys                  jwk.JSONKeys
-	serviceAccountToken      string
-	config                   configuration
+	userAccountKeys     *keystore.KeyStore
+	serviceAccountKeys  *keystore.KeyStore
+	devModePublicKey    *jwk.PublicKey
+	serviceAccountToken string
+	serviceAccountMux   sync.Mutex
+	config              configuration
+	tokenRepository     tokenrepo.TokenRepository
+	keyLoader           keystore.Loader
 }
 
-// NewManager returns a new token Manager for handling tokens
-func NewManager(config configuration) (Manager, error) {
-	tm := &tokenManager{
-		publicKeysMap: map[string]*rsa.PublicKey{},
+// ManagerOption an option to configure the token Manager
+type ManagerOption func(tm *tokenManager)
+
+// WithTokenRepository configures the token Manager to record every user token it generates in the given repository,
+// so that the tokens can be revoked and introspected later. Revoked tokens are rejected when parsed.
+func WithTokenRepository(repo tokenrepo.TokenRepository) ManagerOption {
+	return func(tm *tokenManager) {
+		tm.tokenRepository = repo
+	}
+}
+
+// WithKeyLoader configures the token Manager to sign and verify the tokens with the keys provided by the given loader,
+// instead of the keys from the configuration. The keys from the configuration are handed over to the loader when it
+// has no key yet.
+func WithKeyLoader(loader keystore.Loader) ManagerOption {
+	return func(tm *tokenManager) {
+		tm.keyLoader = loader
 	}
+}
+
+// NewManager returns a new token Manager for handling tokens
+func NewManager(config configuration, options ...ManagerOption) (Manager, error) {
+	tm := &tokenManager{}
 	tm.config = config
+	for _, opt := range options {
+		opt(tm)
+	}
 
-	// Load the user account private key and add it to the manager.
-	// Extract the public key from it and add it to the map of public keys.
+	// Load the user account private keys into the key store of the manager.
 	var err error
 	key, kid := config.GetUserAccountPrivateKey()
 	deprecatedKey, deprecatedKid := config.GetDeprecatedUserAccountPrivateKey()
-	tm.userAccountPrivateKey, err = LoadPrivateKey(tm, key, kid, deprecatedKey, deprecatedKid)
+	tm.userAccountKeys, err = loadKeyStore(key, kid, deprecatedKey, deprecatedKid)
 	if err != nil {
 		log.Error(nil, map[string]interface{}{"err": err}, "unable to load user account private keys")
 		return nil, err
 	}
-	// Load the service account private key and add it to the manager.
-	// Extract the public key from it and add it to the map of public keys.
+	// Load the service account private keys into the key store of the manager.
 	key, kid = config.GetServiceAccountPrivateKey()
 	deprecatedKey, deprecatedKid = config.GetDeprecatedServiceAccountPrivateKey()
-	tm.serviceAccountPrivateKey, err = LoadPrivateKey(tm, key, kid, deprecatedKey, deprecatedKid)
+	tm.serviceAccountKeys, err = loadKeyStore(key, kid, deprecatedKey, deprecatedKid)
 	if err != nil {
 		log.Error(nil, map[string]interface{}{"err": err}, "unable to load service account private keys")
 		return nil, err
 	}
 
+	// Replace the keys from the configuration with the keys of the loader, if any
+	if tm.keyLoader != nil {
+		err = tm.keyLoader.Load(context.Background(), map[string]*keystore.KeyStore{
+			keyrepo.AccountTypeUser:           tm.userAccountKeys,
+			keyrepo.AccountTypeServiceAccount: tm.serviceAccountKeys,
+		})
+		if err != nil {
+			log.Error(nil, map[string]interface{}{"err": err}, "unable to load the signing keys")
+			return nil, err
+		}
+	}
+
 	// Load Keycloak public key if run in dev mode.
 	devMode, key, kid := config.GetDevModePublicKey()
 	if devMode {
@@ -162,36 +203,28 @@ func NewManager(config configuration) (Manager, error) {
 			log.Error(nil, map[string]interface{}{"err": err}, "unable to load dev mode public key")
 			return nil, err
 		}
-		tm.publicKeysMap[kid] = rsaKey
-		tm.publicKeys = append(tm.publicKeys, &jwk.PublicKey{KeyID: kid, Key: rsaKey})
+		tm.devModePublicKey = &jwk.PublicKey{KeyID: kid, Key: rsaKey}
 		log.Info(nil, map[string]interface{}{"kid": kid}, "dev mode public key added")
 	}
 
-	// Convert public keys to JWK format
-	jsonWebKeys, err := toJSONWebKeys(tm.publicKeys)
-	if err != nil {
+	// Make sure that all the public keys can be converted to JWK and PEM formats
+	if _, err := toJSONWebKeys(tm.publishedKeys()); err != nil {
 		log.Error(nil, map[string]interface{}{"err": err}, "unable to convert public keys to JSON Web Keys")
 		return nil, errors.New("unable to convert public keys to JSON Web Keys")
 	}
-	tm.jsonWebKeys = jsonWebKeys
-
-	// Convert public keys to PEM format
-	jsonKeys, err := toPemKeys(tm.publicKeys)
-	if err != nil {
+	if _, err := toPemKeys(tm.publishedKeys()); err != nil {
 		log.Error(nil, map[string]interface{}{"err": err}, "unable to convert public keys to PEM Keys")
 		return nil, errors.New("unable to convert public keys to PEM Keys")
 	}
-	tm.pemKeys = jsonKeys
 
-	tm.initServiceAccountToken()
+	tm.AuthServiceAccountToken()
 
 	return tm, nil
 }
 
-// LoadPrivateKey loads a private key and a deprecated private key.
-// Extracts public keys from them and adds them to the manager
-// Returns the loaded private key.
-func LoadPrivateKey(tm *tokenManager, key []byte, kid string, deprecatedKey []byte, deprecatedKid string) (*jwk.PrivateKey, error) {
+// loadKeyStore loads a private key and a deprecated private key into a new key store.
+// The private key signs the new tokens, the deprecated key is only used to verify the tokens it signed before.
+func loadKeyStore(key []byte, kid string, deprecatedKey []byte, deprecatedKid string) (*keystore.KeyStore, error) {
 	if len(key) == 0 || kid == "" {
 		log.Error(nil, map[string]interface{}{
 			"kid":        kid,
@@ -200,40 +233,45 @@ func LoadPrivateKey(tm *tokenManager, key []byte, kid string, deprecatedKey []by
 		return nil, errors.New("private key or its ID are not set up")
 	}
 
-	// Load the private key. Extract the public key from it
-	rsaServiceAccountKey, err := jwt.ParseRSAPrivateKeyFromPEM(key)
+	// Load the private key
+	privateKey, err := jwk.ParsePrivateKeyFromPEM(key)
 	if err != nil {
 		log.Error(nil, map[string]interface{}{"err": err}, "unable to parse private key")
 		return nil, err
 	}
-	privateKey := &jwk.PrivateKey{KeyID: kid, Key: rsaServiceAccountKey}
-	pk := &rsaServiceAccountKey.PublicKey
-	tm.publicKeysMap[kid] = pk
-	tm.publicKeys = append(tm.publicKeys, &jwk.PublicKey{KeyID: kid, Key: pk})
+	keys := []keystore.Key{{KeyID: kid, State: keyrepo.StateActive, Key: privateKey}}
 	log.Info(nil, map[string]interface{}{"kid": kid}, "public key added")
 
-	// Extract public key from the deprecated key if any and add it to the manager
+	// Load the deprecated key if any
 	if len(deprecatedKey) == 0 || deprecatedKid == "" {
 		log.Debug(nil, map[string]interface{}{
 			"kid":        deprecatedKid,
 			"key_length": len(deprecatedKey),
 		}, "no deprecated private key found")
 	} else {
-		rsaServiceAccountKey, err := jwt.ParseRSAPrivateKeyFromPEM(deprecatedKey)
+		privateKey, err := jwk.ParsePrivateKeyFromPEM(deprecatedKey)
 		if err != nil {
 			log.Error(nil, map[string]interface{}{"err": err}, "unable to parse deprecated private key")
 			return nil, err
 		}
-		pk := &rsaServiceAccountKey.PublicKey
-		tm.publicKeysMap[deprecatedKid] = pk
-		tm.publicKeys = append(tm.publicKeys, &jwk.PublicKey{KeyID: deprecatedKid, Key: pk})
+		keys = append(keys, keystore.Key{KeyID: deprecatedKid, State: keyrepo.StateRetiring, Key: privateKey})
 		log.Info(nil, map[string]interface{}{"kid": deprecatedKid}, "deprecated public key added")
 	}
-	return privateKey, nil
+	return keystore.New(keys)
+}
+
+// publishedKeys returns the public keys of the user account and service account key stores, and the dev mode public
+// key if any
+func (mgm *tokenManager) publishedKeys() []*jwk.PublicKey {
+	keys := append(mgm.userAccountKeys.PublicKeys(), mgm.serviceAccountKeys.PublicKeys()...)
+	if mgm.devModePublicKey != nil {
+		keys = append(keys, mgm.devModePublicKey)
+	}
+	return keys
 }
 
-func toPem(key *rsa.PublicKey) (string, error) {
-	pubASN1, err := x509.MarshalPKIXPublicKey(key)
+func toPem(key crypto.PublicKey) (string, error) {
+	pubASN1, err := jwk.MarshalPublicKey(key)
 	if err != nil {
 		return "", err
 	}
@@ -243,7 +281,7 @@ func toPem(key *rsa.PublicKey) (string, error) {
 func toJSONWebKeys(publicKeys []*jwk.PublicKey) (jwk.JSONKeys, error) {
 	var result []interface{}
 	for _, key := range publicKeys {
-		jwkey := jose.JSONWebKey{Key: key.Key, KeyID: key.KeyID, Algorithm: "RS256", Use: "sig"}
+		jwkey := jose.JSONWebKey{Key: key.Key, KeyID: key.KeyID, Algorithm: jwk.Algorithm(key.Key), Use: "sig"}
 		keyData, err := jwkey.MarshalJSON()
 		if err != nil {
 			return jwk.JSONKeys{}, err
@@ -258,14 +296,23 @@ func toJSONWebKeys(publicKeys []*jwk.PublicKey) (jwk.JSONKeys, error) {
 	return jwk.JSONKeys{Keys: result}, nil
 }
 
-// JSONWebKeys returns all the public keys in JSON Web Keys format
+// JSONWebKeys returns all the public keys in JSON Web Keys format, including the pending and retiring keys
 func (mgm *tokenManager) JSONWebKeys() jwk.JSONKeys {
-	return mgm.jsonWebKeys
+	jsonWebKeys, err := toJSONWebKeys(mgm.publishedKeys())
+	if err != nil {
+		log.Error(nil, map[string]interface{}{"err": err}, "unable to convert public keys to JSON Web Keys")
+	}
+	return jsonWebKeys
 }
 
-// PemKeys returns all the public keys in PEM-like format (PEM without header and footer)
+// PemKeys returns all the public keys in PEM-like format (PEM without header and footer), including the pending and
+// retiring keys
 func (mgm *tokenManager) PemKeys() jwk.JSONKeys {
-	return mgm.pemKeys
+	pemKeys, err := toPemKeys(mgm.publishedKeys())
+	if err != nil {
+		log.Error(nil, map[string]interface{}{"err": err}, "unable to convert public keys to PEM Keys")
+	}
+	return pemKeys
 }
 
 func toPemKeys(publicKeys []*jwk.PublicKey) (jwk.JSONKeys, error) {
@@ -289,6 +336,10 @@ func (mgm *tokenManager) ParseToken(ctx context.Context, tokenString string) (*T
 	}
 	claims := token.Claims.(*TokenClaims)
 	if token.Valid {
+		err = mgm.checkTokenStatus(ctx, claims.Id)
+		if err != nil {
+			return nil, err
+		}
 		return claims, nil
 	}
 	return nil, errors.WithStack(errors.New("token is not valid"))
@@ -302,11 +353,54 @@ func (mgm *tokenManager) ParseTokenWithMapClaims(ctx context.Context, tokenStrin
 	}
 	claims := token.Claims.(jwt.MapClaims)
 	if token.Valid {
+		err = mgm.checkTokenStatus(ctx, claims["jti"])
+		if err != nil {
+			return nil, err
+		}
 		return claims, nil
 	}
 	return nil, errors.WithStack(errors.New("token is not valid"))
 }
 
+// checkTokenStatus returns an error if the token with the given ID has been revoked
+func (mgm *tokenManager) checkTokenStatus(ctx context.Context, jti interface{}) error {
+	if mgm.tokenRepository == nil || jti == nil {
+		return nil
+	}
+	tokenID, err := uuid.FromString(fmt.Sprintf("%s", jti))
+	if err != nil {
+		// Only the tokens with UUID identifiers could have been recorded
+		return nil
+	}
+	t, err := mgm.tokenRepository.Load(ctx, tokenID)
+	if err != nil {
+		if notFound, _ := autherrors.IsNotFoundError(err); notFound {
+			return nil
+		}
+		log.Error(ctx, map[string]interface{}{
+			"err":      err,
+			"token_id": tokenID,
+		}, "unable to load the token status")
+		return err
+	}
+	if t.Status&tokenrepo.TokenStatusRevoked != 0 {
+		log.Warn(ctx, map[string]interface{}{
+			"token_id":    tokenID,
+			"identity_id": t.IdentityID,
+		}, "revoked token used")
+		return errors.New("token has been revoked")
+	}
+	if !t.Valid() {
+		log.Warn(ctx, map[string]interface{}{
+			"token_id":    tokenID,
+			"identity_id": t.IdentityID,
+			"status":      t.Status,
+		}, "stale token used")
+		return errors.New("token permissions are out of date")
+	}
+	return nil
+}
+
 func (mgm *tokenManager) keyFunction(ctx context.Context) jwt.Keyfunc {
 	return func(token *jwt.Token) (interface{}, error) {
 		kid := token.Header["kid"]
@@ -321,6 +415,16 @@ func (mgm *tokenManager) keyFunction(ctx context.Context) jwt.Keyfunc {
 			}, "There is no public key with such ID")
 			return nil, errors.New(fmt.Sprintf("There is no public key with such ID: %s", kid))
 		}
+		// The algorithm of the token must be the algorithm of the key, so that a token can't be verified
+		// with a key which was not meant to verify it
+		if alg := jwk.Algorithm(key); token.Method.Alg() != alg {
+			log.Error(ctx, map[string]interface{}{
+				"kid":             kid,
+				"token_algorithm": token.Method.Alg(),
+				"key_algorithm":   alg,
+			}, "The algorithm of the token does not match the algorithm of the public key")
+			return nil, errors.New(fmt.Sprintf("The algorithm of the token does not match the algorithm of the public key: %s", token.Method.Alg()))
+		}
 		return key, nil
 	}
 }
@@ -342,38 +446,78 @@ func (mgm *tokenManager) Locate(ctx context.Context) (uuid.UUID, error) {
 }
 
 // PublicKey returns the public key by the ID
-func (mgm *tokenManager) PublicKey(keyID string) *rsa.PublicKey {
-	return mgm.publicKeysMap[keyID]
+func (mgm *tokenManager) PublicKey(keyID string) crypto.PublicKey {
+	if key := mgm.userAccountKeys.PublicKey(keyID); key != nil {
+		return key
+	}
+	if key := mgm.serviceAccountKeys.PublicKey(keyID); key != nil {
+		return key
+	}
+	if mgm.devModePublicKey != nil && mgm.devModePublicKey.KeyID == keyID {
+		return mgm.devModePublicKey.Key
+	}
+	return nil
 }
 
 // PublicKeys returns all the public keys
-func (mgm *tokenManager) PublicKeys() []*rsa.PublicKey {
-	keys := make([]*rsa.PublicKey, 0, len(mgm.publicKeysMap))
-	for _, key := range mgm.publicKeys {
+func (mgm *tokenManager) PublicKeys() []crypto.PublicKey {
+	publishedKeys := mgm.publishedKeys()
+	keys := make([]crypto.PublicKey, 0, len(publishedKeys))
+	for _, key := range publishedKeys {
 		keys = append(keys, key.Key)
 	}
 	return keys
 }
 
-// AuthServiceAccountToken returns the service account token which authenticates the Auth service
+// AuthServiceAccountToken returns the service account token which authenticates the Auth service.
+// The token is generated again when the service account signing key changes.
 func (mgm *tokenManager) AuthServiceAccountToken() string {
+	mgm.serviceAccountMux.Lock()
+	defer mgm.serviceAccountMux.Unlock()
+	if mgm.serviceAccountToken != "" && mgm.signedWithActiveKey(mgm.serviceAccountToken, mgm.serviceAccountKeys) {
+		return mgm.serviceAccountToken
+	}
+	tokenStr, err := mgm.GenerateServiceAccountToken(AuthServiceAccountID, Auth)
+	if err != nil {
+		log.Error(nil, map[string]interface{}{"err": err}, "unable to generate the Auth service account token")
+		return mgm.serviceAccountToken
+	}
+	mgm.serviceAccountToken = tokenStr
 	return mgm.serviceAccountToken
 }
 
-func (mgm *tokenManager) initServiceAccountToken() (string, error) {
-	tokenStr, err := mgm.GenerateServiceAccountToken(AuthServiceAccountID, Auth)
+// signedWithActiveKey returns true if the given token was signed with the active key of the given key store
+func (mgm *tokenManager) signedWithActiveKey(tokenString string, keys *keystore.KeyStore) bool {
+	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, jwt.MapClaims{})
 	if err != nil {
-		return "", errors.WithStack(err)
+		return false
 	}
-	mgm.serviceAccountToken = tokenStr
+	return token.Header["kid"] == keys.SigningKey().KeyID
+}
 
-	return mgm.serviceAccountToken, nil
+// newToken returns a new unsigned token whose algorithm and "kid" header are the algorithm and the ID of the active key
+// of the given key store
+func newToken(keys *keystore.KeyStore) *jwt.Token {
+	signingKey := keys.SigningKey()
+	token := jwt.New(jwt.GetSigningMethod(jwk.Algorithm(signingKey.Key)))
+	token.Header["kid"] = signingKey.KeyID
+	return token
+}
+
+// signToken signs the token with the private key of the given key store whose ID is the "kid" header of the token
+func signToken(token *jwt.Token, keys *keystore.KeyStore) (string, error) {
+	kid, _ := token.Header["kid"].(string)
+	key := keys.PrivateKey(kid)
+	if key == nil {
+		return "", errors.Errorf("there is no private key with such ID: %s", kid)
+	}
+	return token.SignedString(key)
 }
 
 // GenerateServiceAccountToken generates and signs a new Service Account Token (Protection API Token)
 func (mgm *tokenManager) GenerateServiceAccountToken(saID string, saName string) (string, error) {
 	token := mgm.GenerateUnsignedServiceAccountToken(saID, saName)
-	tokenStr, err := token.SignedString(mgm.serviceAccountPrivateKey.Key)
+	tokenStr, err := signToken(token, mgm.serviceAccountKeys)
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
@@ -382,8 +526,7 @@ func (mgm *tokenManager) GenerateServiceAccountToken(saID string, saName string)
 
 // GenerateUnsignedServiceAccountToken generates an unsigned Service Account Token (Protection API Token)
 func (mgm *tokenManager) GenerateUnsignedServiceAccountToken(saID string, saName string) *jwt.Token {
-	token := jwt.New(jwt.SigningMethodRS256)
-	token.Header["kid"] = mgm.serviceAccountPrivateKey.KeyID
+	token := newToken(mgm.serviceAccountKeys)
 	claims := token.Claims.(jwt.MapClaims)
 	claims["service_accountname"] = saName
 	claims["sub"] = saID
@@ -400,7 +543,7 @@ func (mgm *tokenManager) GenerateUserToken(ctx context.Context, keycloakToken oa
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	accessToken, err := unsignedAccessToken.SignedString(mgm.userAccountPrivateKey.Key)
+	accessToken, err := signToken(unsignedAccessToken, mgm.userAccountKeys)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -408,10 +551,21 @@ func (mgm *tokenManager) GenerateUserToken(ctx context.Context, keycloakToken oa
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	refreshToken, err := unsignedRefreshToken.SignedString(mgm.userAccountPrivateKey.Key)
+	refreshToken, err := signToken(unsignedRefreshToken, mgm.userAccountKeys)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	// Tokens generated for unknown identities can't be recorded
+	if identity != nil {
+		err = mgm.registerToken(ctx, unsignedAccessToken, identity.ID)
+		if err != nil {
+			return nil, err
+		}
+		err = mgm.registerToken(ctx, unsignedRefreshToken, identity.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
 	token := &oauth2.Token{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
@@ -447,7 +601,7 @@ func (mgm *tokenManager) GenerateUserTokenForIdentity(ctx context.Context, ident
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	accessToken, err := unsignedAccessToken.SignedString(mgm.userAccountPrivateKey.Key)
+	accessToken, err := signToken(unsignedAccessToken, mgm.userAccountKeys)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -455,10 +609,21 @@ func (mgm *tokenManager) GenerateUserTokenForIdentity(ctx context.Context, ident
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	refreshToken, err := unsignedRefreshToken.SignedString(mgm.userAccountPrivateKey.Key)
+	refreshToken, err := signToken(unsignedRefreshToken, mgm.userAccountKeys)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	// Tokens generated for identities which have not been persisted yet can't be recorded
+	if identity.ID != uuid.Nil {
+		err = mgm.registerToken(ctx, unsignedAccessToken, identity.ID)
+		if err != nil {
+			return nil, err
+		}
+		err = mgm.registerToken(ctx, unsignedRefreshToken, identity.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	var nbf int64
 
@@ -480,10 +645,179 @@ func (mgm *tokenManager) GenerateUserTokenForIdentity(ctx context.Context, ident
 	return token, nil
 }
 
+// registerToken records the generated user token so that it can be revoked or introspected later.
+// Nothing is recorded if the manager has not been configured with a token repository.
+func (mgm *tokenManager) registerToken(ctx context.Context, token *jwt.Token, identityID uuid.UUID) error {
+	if mgm.tokenRepository == nil {
+		return nil
+	}
+	claims := token.Claims.(jwt.MapClaims)
+	tokenID, err := uuid.FromString(fmt.Sprintf("%s", claims["jti"]))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = mgm.tokenRepository.Create(ctx, &tokenrepo.Token{
+		TokenID:    tokenID,
+		IdentityID: identityID,
+		TokenType:  tokenTypeFromClaims(claims),
+		ExpiryTime: expiryTimeFromClaims(claims),
+	})
+	if err != nil {
+		if notFound, _ := autherrors.IsNotFoundError(err); notFound {
+			// The identity has not been persisted (yet), e.g. tokens generated in dev mode
+			log.Warn(ctx, map[string]interface{}{
+				"token_id":    tokenID,
+				"identity_id": identityID,
+			}, "token generated for unknown identity has not been recorded")
+			return nil
+		}
+		log.Error(ctx, map[string]interface{}{
+			"err":         err,
+			"token_id":    tokenID,
+			"identity_id": identityID,
+		}, "unable to record the generated token")
+		return err
+	}
+	return nil
+}
+
+// GenerateRPT generates a Requesting Party Token (RPT) for the identity of the given access token, with the
+// given resource permissions embedded in its "authorization" claim. The RPT expires with the access token, or as soon
+// as the roles for the resource change, whichever comes first. A token repository is required to track the latter.
+func (mgm *tokenManager) GenerateRPT(ctx context.Context, accessToken string, permissions Permissions) (*oauth2.Token, error) {
+	if mgm.tokenRepository == nil {
+		return nil, errors.New("token repository is required to generate RPT")
+	}
+	if permissions.ResourceSetID == nil {
+		return nil, autherrors.NewBadParameterErrorFromString("resource_set_id", nil, "missing resource ID in RPT permissions")
+	}
+	accessTokenClaims, err := mgm.ParseTokenWithMapClaims(ctx, accessToken)
+	if err != nil {
+		return nil, autherrors.NewUnauthorizedError(err.Error())
+	}
+	identityID, err := uuid.FromString(fmt.Sprintf("%s", accessTokenClaims["sub"]))
+	if err != nil || accessTokenClaims["service_accountname"] != nil {
+		return nil, autherrors.NewUnauthorizedError("RPT can only be generated for user access tokens")
+	}
+	if IsPersonalAccessToken(accessTokenClaims) {
+		// The RPT would not be revoked with the personal access token
+		return nil, autherrors.NewForbiddenError("RPT can not be generated for personal access tokens")
+	}
+
+	token := newToken(mgm.userAccountKeys)
+	claims := token.Claims.(jwt.MapClaims)
+	for name, value := range accessTokenClaims {
+		claims[name] = value
+	}
+	claims["jti"] = uuid.NewV4().String()
+	claims["iat"] = time.Now().Unix()
+	claims["typ"] = "Bearer"
+	expiry := expiryTimeFromClaims(accessTokenClaims)
+	permissions.Expiry = expiry.Unix()
+	claims["authorization"] = AuthorizationPayload{Permissions: []Permissions{permissions}}
+
+	rpt, err := signToken(token, mgm.userAccountKeys)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = mgm.registerToken(ctx, token, identityID)
+	if err != nil {
+		return nil, err
+	}
+	tokenID, err := uuid.FromString(claims["jti"].(string))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	err = mgm.tokenRepository.AddResource(ctx, tokenID, *permissions.ResourceSetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Token{
+		AccessToken: rpt,
+		TokenType:   "Bearer",
+		Expiry:      expiry,
+	}, nil
+}
+
+// RevokeToken revokes the given user token so that it's not accepted anymore.
+// As required by RFC 7009 no error is returned if the token is invalid, expired or has already been revoked.
+func (mgm *tokenManager) RevokeToken(ctx context.Context, tokenString string) error {
+	if mgm.tokenRepository == nil {
+		return errors.New("token revocation is not enabled")
+	}
+	claims, err := mgm.ParseTokenWithMapClaims(ctx, tokenString)
+	if err != nil {
+		log.Info(ctx, map[string]interface{}{
+			"err": err,
+		}, "ignoring revocation of invalid token")
+		return nil
+	}
+	if claims["service_accountname"] != nil {
+		return autherrors.NewBadParameterErrorFromString("token", "service account token", "revocation of service account tokens is not supported")
+	}
+	tokenID, err := uuid.FromString(fmt.Sprintf("%s", claims["jti"]))
+	if err != nil {
+		return autherrors.NewBadParameterErrorFromString("token", claims["jti"], "token identifier is not a UUID")
+	}
+
+	t, err := mgm.tokenRepository.Load(ctx, tokenID)
+	if err == nil {
+		t.Status = t.Status | tokenrepo.TokenStatusRevoked
+		return mgm.tokenRepository.Save(ctx, t)
+	}
+	if notFound, _ := autherrors.IsNotFoundError(err); !notFound {
+		return err
+	}
+
+	// The token was issued before tokens started being recorded, so record it as revoked now
+	identityID, err := uuid.FromString(fmt.Sprintf("%s", claims["sub"]))
+	if err != nil {
+		return autherrors.NewBadParameterErrorFromString("token", claims["sub"], "token subject is not a UUID")
+	}
+	err = mgm.tokenRepository.Create(ctx, &tokenrepo.Token{
+		TokenID:    tokenID,
+		IdentityID: identityID,
+		Status:     tokenrepo.TokenStatusRevoked,
+		TokenType:  tokenTypeFromClaims(claims),
+		ExpiryTime: expiryTimeFromClaims(claims),
+	})
+	if notFound, _ := autherrors.IsNotFoundError(err); notFound {
+		log.Info(ctx, map[string]interface{}{
+			"token_id":    tokenID,
+			"identity_id": identityID,
+		}, "ignoring revocation of token issued for unknown identity")
+		return nil
+	}
+	return err
+}
+
+// tokenTypeFromClaims returns the type under which the token with the given claims is recorded
+func tokenTypeFromClaims(claims jwt.MapClaims) string {
+	switch claims["typ"] {
+	case "Refresh", "Offline":
+		return tokenrepo.TokenTypeRefresh
+	case PersonalAccessTokenType:
+		return tokenrepo.TokenTypePersonal
+	}
+	return tokenrepo.TokenTypeAccess
+}
+
+// expiryTimeFromClaims returns the expiry time of the token with the given claims.
+// Tokens which never expire (i.e. offline tokens) are given an expiry time far in the future.
+func expiryTimeFromClaims(claims jwt.MapClaims) time.Time {
+	if claims["exp"] != nil {
+		exp, err := NumberToInt(claims["exp"])
+		if err == nil && exp > 0 {
+			return time.Unix(exp, 0)
+		}
+	}
+	return time.Now().AddDate(100, 0, 0)
+}
+
 // GenerateUnsignedUserAccessToken generates an unsigned OAuth2 user access token for the given identity based on the Keycloak token
 func (mgm *tokenManager) GenerateUnsignedUserAccessToken(ctx context.Context, keycloakAccessToken string, identity *repository.Identity) (*jwt.Token, error) {
-	token := jwt.New(jwt.SigningMethodRS256)
-	token.Header["kid"] = mgm.userAccountPrivateKey.KeyID
+	token := newToken(mgm.userAccountKeys)
 
 	kcClaims, err := mgm.ParseToken(ctx, keycloakAccessToken)
 	if err != nil {
@@ -559,8 +893,7 @@ func (mgm *tokenManager) GenerateUnsignedUserAccessToken(ctx context.Context, ke
 
 // GenerateUnsignedUserAccessTokenForIdentity generates an unsigned OAuth2 user access token for the given identity
 func (mgm *tokenManager) GenerateUnsignedUserAccessTokenForIdentity(ctx context.Context, identity repository.Identity) (*jwt.Token, error) {
-	token := jwt.New(jwt.SigningMethodRS256)
-	token.Header["kid"] = mgm.userAccountPrivateKey.KeyID
+	token := newToken(mgm.userAccountKeys)
 
 	req := goa.ContextRequest(ctx)
 	if req == nil {
@@ -602,8 +935,7 @@ func (mgm *tokenManager) GenerateUnsignedUserAccessTokenForIdentity(ctx context.
 
 // GenerateUnsignedUserRefreshToken generates an unsigned OAuth2 user refresh token for the given identity based on the Keycloak token
 func (mgm *tokenManager) GenerateUnsignedUserRefreshToken(ctx context.Context, keycloakRefreshToken string, identity *repository.Identity) (*jwt.Token, error) {
-	token := jwt.New(jwt.SigningMethodRS256)
-	token.Header["kid"] = mgm.userAccountPrivateKey.KeyID
+	token := newToken(mgm.userAccountKeys)
 
 	kcClaims, err := mgm.ParseToken(ctx, keycloakRefreshToken)
 	if err != nil {
@@ -643,8 +975,7 @@ func (mgm *tokenManager) GenerateUnsignedUserRefreshToken(ctx context.Context, k
 
 // GenerateUnsignedUserRefreshTokenForIdentity generates an unsigned OAuth2 user refresh token for the given identity
 func (mgm *tokenManager) GenerateUnsignedUserRefreshTokenForIdentity(ctx context.Context, identity repository.Identity, offlineToken bool) (*jwt.Token, error) {
-	token := jwt.New(jwt.SigningMethodRS256)
-	token.Header["kid"] = mgm.userAccountPrivateKey.KeyID
+	token := newToken(mgm.userAccountKeys)
 
 	req := goa.ContextRequest(ctx)
 	if req == nil {
@@ -761,6 +1092,10 @@ func (mgm *tokenManager) Parse(ctx context.Context, tokenString string) (*jwt.To
 		}, "unable to parse token")
 		return nil, autherrors.NewUnauthorizedError(err.Error())
 	}
+	err = mgm.checkTokenStatus(ctx, jwtToken.Claims.(jwt.MapClaims)["jti"])
+	if err != nil {
+		return nil, autherrors.NewUnauthorizedError(err.Error())
+	}
 	return jwtToken, nil
 }
 
@@ -431,3 +431,27 @@ func (s *TestTokenSuite) TestStringToInt64OK() {
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), int64(2590000), i)
 }
+
+func (s *TestTokenSuite) TestValidatePersonalAccessTokenScopes() {
+	assert.NoError(s.T(), token.ValidatePersonalAccessTokenScopes([]string{"spaces:read", "resources:write"}))
+	for _, scopes := range [][]string{nil, {"spaces"}, {"spaces:admin"}, {"Spaces:read"}, {"spaces:read", ""}} {
+		err := token.ValidatePersonalAccessTokenScopes(scopes)
+		require.Error(s.T(), err, "%v", scopes)
+		assert.IsType(s.T(), errors.BadParameterError{}, err)
+	}
+}
+
+func (s *TestTokenSuite) TestPersonalAccessTokenAllows() {
+	claims := jwt.MapClaims{"typ": token.PersonalAccessTokenType, "scope": "spaces:read resources:write"}
+	assert.True(s.T(), token.IsPersonalAccessToken(claims))
+	assert.True(s.T(), token.PersonalAccessTokenAllows(claims, "GET", "/api/spaces/123"))
+	assert.True(s.T(), token.PersonalAccessTokenAllows(claims, "HEAD", "/api/spaces"))
+	assert.False(s.T(), token.PersonalAccessTokenAllows(claims, "DELETE", "/api/spaces/123"))
+	assert.True(s.T(), token.PersonalAccessTokenAllows(claims, "GET", "/api/resources/123"))
+	assert.True(s.T(), token.PersonalAccessTokenAllows(claims, "POST", "/api/resources"))
+	assert.False(s.T(), token.PersonalAccessTokenAllows(claims, "GET", "/api/user"))
+	assert.False(s.T(), token.PersonalAccessTokenAllows(claims, "GET", "/api/spacesx"))
+	assert.False(s.T(), token.PersonalAccessTokenAllows(claims, "GET", "/status"))
+
+	assert.False(s.T(), token.IsPersonalAccessToken(jwt.MapClaims{"typ": "Bearer", "scope": "spaces:read"}))
+}
@@ -0,0 +1,2 @@
+// Package worker runs the background tasks of the service
+package worker
@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-auth/log"
+)
+
+// RunPeriodically starts a goroutine which calls the given function at the specified interval. Errors are logged with
+// the given description of the task, and do not stop the goroutine: the function is called again at the next tick.
+// The task is disabled if the interval is zero or negative. The returned function stops the goroutine, and waits for
+// the running call of the function to return if any.
+func RunPeriodically(interval time.Duration, task func(ctx context.Context) error, description string) func() {
+	if interval <= 0 {
+		log.Info(nil, map[string]interface{}{
+			"task": description,
+		}, "periodic task disabled")
+		return func() {}
+	}
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				err := task(context.Background())
+				if err != nil {
+					log.Error(nil, map[string]interface{}{
+						"err":  err,
+						"task": description,
+					}, "unable to %s", description)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+		<-stopped
+	}
+}
@@ -0,0 +1,41 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-auth/worker"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPeriodically(t *testing.T) {
+	t.Run("task runs until stopped", func(t *testing.T) {
+		var calls int32
+		stop := worker.RunPeriodically(time.Millisecond, func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			// Errors do not stop the task
+			return errors.New("failure")
+		}, "test the periodic task")
+		time.Sleep(50 * time.Millisecond)
+		stop()
+		stopped := atomic.LoadInt32(&calls)
+		assert.True(t, stopped > 1)
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, stopped, atomic.LoadInt32(&calls))
+	})
+
+	t.Run("task disabled", func(t *testing.T) {
+		for _, interval := range []time.Duration{0, -time.Second} {
+			stop := worker.RunPeriodically(interval, func(ctx context.Context) error {
+				t.Error("disabled task should not run")
+				return nil
+			}, "test the disabled task")
+			time.Sleep(10 * time.Millisecond)
+			stop()
+		}
+	})
+}